@@ -1,10 +1,13 @@
 package actions
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/opentable/sous/config"
@@ -15,6 +18,7 @@ import (
 	"github.com/opentable/sous/util/logging/messages"
 	"github.com/opentable/sous/util/restful"
 	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
 	"github.com/vbauerster/mpb"
 	"github.com/vbauerster/mpb/decor"
 	"golang.org/x/crypto/ssh/terminal"
@@ -38,7 +42,12 @@ func (sd *Deploy) Do() error {
 	if err != nil {
 		return err
 	}
+	return sd.deployVersion(newVersion)
+}
 
+// deployVersion sets the intended version of the target deployment to
+// newVersion, and waits for it to become stable if WaitStable is set.
+func (sd *Deploy) deployVersion(newVersion semv.Version) error {
 	d := server.SingleDeploymentBody{}
 	q := sd.TargetDeploymentID.QueryMap()
 	q["force"] = strconv.FormatBool(sd.Force)
@@ -100,7 +109,7 @@ func (sd *Deploy) Do() error {
 			)
 		}
 
-		result := sd.pollDeployQueue(location, pollTime, bar)
+		result := sd.waitDeployQueue(location, pollTime, bar)
 
 		if terminal.IsTerminal(int(os.Stdin.Fd())) && bar != nil && p != nil {
 			bar.SetTotal(100, true)
@@ -125,53 +134,202 @@ func timeTrack(start time.Time) string {
 	return elapsed.String()
 }
 
-func (sd *Deploy) pollDeployQueue(location string, pollAtempts int, bar *mpb.Bar) error {
-	start := time.Now()
-	response := dto.R11nResponse{}
-	location = "http://" + location
+// maxDeployWait is how long a deploy is followed at most, however long its
+// rollout keeps making progress.
+var maxDeployWait = 2 * time.Hour
 
-	for i := 0; i < pollAtempts; i++ {
-		if bar != nil {
-			bar.IncrBy(5)
-		}
-		if _, err := sd.HTTPClient.Retrieve(location, nil, &response, nil); err != nil {
-			return errors.Wrapf(err, "Failed to deploy, duration: %s", timeTrack(start))
+// deployProgress follows the progress of a queued rectification, as
+// reported by successive responses from the server.
+type deployProgress struct {
+	location    string
+	start       time.Time
+	deadline    time.Time
+	lastRollout string
+	response    dto.R11nResponse
+}
+
+// newDeployProgress starts following the rectification at location. The
+// server gives location without a scheme, so the scheme of serverURL is used,
+// or http if it has none.
+func newDeployProgress(serverURL, location string) *deployProgress {
+	if !strings.Contains(location, "://") {
+		scheme := "http"
+		if u, err := url.Parse(serverURL); err == nil && u.Scheme != "" {
+			scheme = u.Scheme
 		}
+		location = scheme + "://" + location
+	}
+	start := time.Now()
+	return &deployProgress{location: location, start: start, deadline: start.Add(maxDeployWait)}
+}
+
+// serverURL returns the URL of the configured Sous server, if there is one.
+func (sd *Deploy) serverURL() string {
+	if sd.Config == nil {
+		return ""
+	}
+	return sd.Config.Server
+}
+
+// update records response, and reports whether the rectification is finished
+// and whether its rollout progressed. The error is the reason the deploy
+// failed, if it is finished and did.
+func (sd *Deploy) update(p *deployProgress, response dto.R11nResponse) (finished, progressed bool, err error) {
+	p.response = response
+	start := p.start
 
-		queuePosition := response.QueuePosition
+	if response.Rollout != nil && response.Rollout.String() != p.lastRollout {
+		p.lastRollout = response.Rollout.String()
+		messages.ReportLogFieldsMessageToConsole(
+			fmt.Sprintf("\n\t%s", p.lastRollout),
+			logging.InformationLevel,
+			sd.LogSink,
+		)
+		progressed = true
+	}
 
-		if response.Resolution != nil && response.Resolution.Error != nil {
-			return errors.Wrapf(response.Resolution.Error, "Failed to deploy, duration: %s\n", timeTrack(start))
+	if response.Resolution != nil && response.Resolution.Error != nil {
+		if rb := response.Resolution.Rollback; rb != nil {
+			return true, progressed, errors.Wrapf(response.Resolution.Error, "Failed to deploy, %s, duration: %s\n", rb, timeTrack(start))
 		}
+		return true, progressed, errors.Wrapf(response.Resolution.Error, "Failed to deploy, duration: %s\n", timeTrack(start))
+	}
 
-		if queuePosition < 0 && response.Resolution != nil &&
-			response.Resolution.DeployState != nil {
+	if response.QueuePosition < 0 && response.Resolution != nil &&
+		response.Resolution.DeployState != nil {
 
-			if checkFinished(*response.Resolution) {
-				if checkResolutionSuccess(*response.Resolution) {
-					messages.ReportLogFieldsMessageToConsole(
-						fmt.Sprintf("\n\tDeployment Complete %s, %s, duration: %s\n",
-							response.Resolution.DeploymentID.String(), response.Resolution.DeployState.SourceID.Version, timeTrack(start)),
-						logging.InformationLevel,
-						sd.LogSink,
-						logging.NewInterval(start, time.Now()),
-					)
-					return nil
-				}
-				//exit out to error handler
-				return errors.Errorf("Failed to deploy %s: %s", location, response.Resolution.Error)
+		if checkFinished(*response.Resolution) {
+			if checkResolutionSuccess(*response.Resolution) {
+				messages.ReportLogFieldsMessageToConsole(
+					fmt.Sprintf("\n\tDeployment Complete %s, %s, duration: %s\n",
+						response.Resolution.DeploymentID.String(), response.Resolution.DeployState.SourceID.Version, timeTrack(start)),
+					logging.InformationLevel,
+					sd.LogSink,
+					logging.NewInterval(start, time.Now()),
+				)
+				return true, progressed, nil
 			}
-
+			//exit out to error handler
+			return true, progressed, errors.Errorf("Failed to deploy %s: %s", p.location, response.Resolution.Error)
 		}
-		time.Sleep(1 * time.Second)
+
 	}
+	return false, progressed, nil
+}
 
+// gaveUp returns the error reported when p has not finished, either after
+// pollAttempts without progress or by its deadline.
+func (p *deployProgress) gaveUp(pollAtempts int) error {
 	responseJSON := ""
-	if b, err := json.Marshal(response); err == nil {
+	if b, err := json.Marshal(p.response); err == nil {
 		responseJSON = string(b)
 	}
 
-	return errors.Errorf("Failed to deploy %s after %d attempts for duration: %s\n Response: %s\n", location, pollAtempts, timeTrack(start), responseJSON)
+	if p.pastDeadline() {
+		return errors.Errorf("Failed to deploy %s within %s for duration: %s\n Response: %s\n", p.location, maxDeployWait, timeTrack(p.start), responseJSON)
+	}
+	return errors.Errorf("Failed to deploy %s after %d attempts for duration: %s\n Response: %s\n", p.location, pollAtempts, timeTrack(p.start), responseJSON)
+}
+
+// pastDeadline returns true once p has been followed for maxDeployWait.
+func (p *deployProgress) pastDeadline() bool {
+	return !time.Now().Before(p.deadline)
+}
+
+// waitDeployQueue waits for the rectification at location to finish. It
+// follows the server's event stream if it can, and polls otherwise.
+func (sd *Deploy) waitDeployQueue(location string, pollAtempts int, bar *mpb.Bar) error {
+	if streamer, ok := sd.HTTPClient.(restful.EventStreamer); ok {
+		p := newDeployProgress(sd.serverURL(), location)
+		finished, err := sd.streamDeployQueue(streamer, p, pollAtempts, bar)
+		if finished {
+			return err
+		}
+		if err != nil && err != restful.ErrNoEventStream {
+			messages.ReportLogFieldsMessage("Streaming deploy progress failed, polling instead",
+				logging.DebugLevel, sd.LogSink, err)
+		}
+	}
+	return sd.pollDeployQueue(location, pollAtempts, bar)
+}
+
+// streamDeployQueue follows the progress of p from the server's event stream.
+// It gives up if there is no progress for pollAttempts seconds, or at p's
+// deadline. It returns
+// true if the rectification finished, along with the reason it failed if it
+// did.
+func (sd *Deploy) streamDeployQueue(streamer restful.EventStreamer, p *deployProgress, pollAtempts int, bar *mpb.Bar) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	patience := time.Duration(pollAtempts) * time.Second
+	timeout := time.AfterFunc(patience, cancel)
+	defer timeout.Stop()
+	deadline := time.AfterFunc(time.Until(p.deadline), cancel)
+	defer deadline.Stop()
+
+	finished := false
+	var deployErr error
+	err := streamer.StreamEvents(ctx, p.location, nil, nil, func(ev restful.Event) error {
+		if ev.Name != "r11n" {
+			return nil
+		}
+		if bar != nil {
+			bar.IncrBy(5)
+		}
+		response := dto.R11nResponse{}
+		if err := json.Unmarshal(ev.Data, &response); err != nil {
+			return errors.Wrapf(err, "decoding %s event", ev.Name)
+		}
+		var progressed bool
+		finished, progressed, deployErr = sd.update(p, response)
+		if progressed {
+			// Staged rollouts can take much longer than other deploys, so
+			// only give up if they stop making progress.
+			timeout.Reset(patience)
+		}
+		if finished || p.pastDeadline() {
+			cancel()
+		}
+		return nil
+	})
+	if finished {
+		return true, deployErr
+	}
+	if ctx.Err() != nil {
+		return true, p.gaveUp(pollAtempts)
+	}
+	return false, err
+}
+
+// pollDeployQueue polls the rectification at location until it finishes. It
+// gives up after pollAttempts polls without progress, or at its deadline.
+func (sd *Deploy) pollDeployQueue(location string, pollAtempts int, bar *mpb.Bar) error {
+	p := newDeployProgress(sd.serverURL(), location)
+	for stalled := 0; stalled < pollAtempts; stalled++ {
+		if bar != nil {
+			bar.IncrBy(5)
+		}
+		response := dto.R11nResponse{}
+		if _, err := sd.HTTPClient.Retrieve(p.location, nil, &response, nil); err != nil {
+			return errors.Wrapf(err, "Failed to deploy, duration: %s", timeTrack(p.start))
+		}
+
+		finished, progressed, err := sd.update(p, response)
+		if finished {
+			return err
+		}
+		if p.pastDeadline() {
+			break
+		}
+		if progressed {
+			// Staged rollouts can take much longer than other deploys, so
+			// only give up if they stop making progress.
+			stalled = 0
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	return p.gaveUp(pollAtempts)
 }
 
 func checkFinished(resolution sous.DiffResolution) bool {
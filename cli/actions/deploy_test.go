@@ -1,14 +1,20 @@
 package actions
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/nyarly/spies"
 	"github.com/opentable/sous/dto"
 	sous "github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
 	"github.com/opentable/sous/util/restful/restfultest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCheckFinished(t *testing.T) {
@@ -25,6 +31,15 @@ func TestCheckFinished(t *testing.T) {
 	assert.True(t, !checkFinished(sous.DiffResolution{}), "empty resolution should be false")
 }
 
+func TestNewDeployProgress(t *testing.T) {
+	assert.Equal(t, "https://sous.example.com/deploy-queue-item",
+		newDeployProgress("https://sous.example.com", "sous.example.com/deploy-queue-item").location)
+	assert.Equal(t, "http://sous.example.com/deploy-queue-item",
+		newDeployProgress("", "sous.example.com/deploy-queue-item").location)
+	assert.Equal(t, "https://sous.example.com/deploy-queue-item",
+		newDeployProgress("http://sous.example.com", "https://sous.example.com/deploy-queue-item").location)
+}
+
 func TestPollDeployQueue_success_created(t *testing.T) {
 	log, _ := logging.NewLogSinkSpy()
 	httpClient, ctrl := restfultest.NewHTTPClientSpy()
@@ -65,6 +80,137 @@ func TestPollDeployQueue_fail(t *testing.T) {
 	assert.Error(t, sd.pollDeployQueue(location, 10, nil))
 }
 
+func TestPollDeployQueue_deadline(t *testing.T) {
+	defer func(wait time.Duration) { maxDeployWait = wait }(maxDeployWait)
+	maxDeployWait = 0
+
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	createDeployResult(ctrl, 0, "created", sous.DeployStatusPending)
+	sd := &Deploy{
+		HTTPClient: httpClient,
+		LogSink:    log,
+	}
+
+	err := sd.pollDeployQueue("127.0.0.1:1234/deploy-queue-item", 10, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "within")
+	assert.Len(t, ctrl.CallsTo("Retrieve"), 1)
+}
+
+type streamingClient struct {
+	restful.HTTPClient
+	events []restful.Event
+}
+
+func (c *streamingClient) StreamEvents(ctx context.Context, urlPath string, qParms map[string]string, headers map[string]string, handle func(restful.Event) error) error {
+	for _, ev := range c.events {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := handle(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func r11nEvent(t *testing.T, response dto.R11nResponse) restful.Event {
+	b, err := json.Marshal(response)
+	require.NoError(t, err)
+	return restful.Event{Name: "r11n", Data: b}
+}
+
+func TestWaitDeployQueue_stream(t *testing.T) {
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	client := &streamingClient{
+		HTTPClient: httpClient,
+		events: []restful.Event{
+			r11nEvent(t, dto.R11nResponse{QueuePosition: 0}),
+			r11nEvent(t, dto.R11nResponse{
+				QueuePosition: -1,
+				Resolution: &sous.DiffResolution{
+					Desc:        sous.ModifyDiff,
+					DeployState: &sous.DeployState{Status: sous.DeployStatusActive},
+				},
+			}),
+		},
+	}
+	sd := &Deploy{
+		HTTPClient: client,
+		LogSink:    log,
+	}
+
+	assert.NoError(t, sd.waitDeployQueue("127.0.0.1:1234/deploy-queue-item", 1, nil))
+	assert.Len(t, ctrl.CallsTo("Retrieve"), 0)
+}
+
+func TestWaitDeployQueue_streamFailed(t *testing.T) {
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, _ := restfultest.NewHTTPClientSpy()
+	client := &streamingClient{
+		HTTPClient: httpClient,
+		events: []restful.Event{
+			r11nEvent(t, dto.R11nResponse{
+				QueuePosition: -1,
+				Resolution: &sous.DiffResolution{
+					Desc:  sous.ModifyDiff,
+					Error: sous.WrapResolveError(fmt.Errorf("boom")),
+				},
+			}),
+		},
+	}
+	sd := &Deploy{
+		HTTPClient: client,
+		LogSink:    log,
+	}
+
+	err := sd.waitDeployQueue("127.0.0.1:1234/deploy-queue-item", 1, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestWaitDeployQueue_notStreaming(t *testing.T) {
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	createDeployResult(ctrl, -1, "created", 2)
+	sd := &Deploy{
+		HTTPClient: httpClient,
+		LogSink:    log,
+	}
+
+	assert.NoError(t, sd.waitDeployQueue("127.0.0.1:1234/deploy-queue-item", 1, nil))
+	assert.Len(t, ctrl.CallsTo("Retrieve"), 1)
+}
+
+// A rollout which keeps making progress is still only followed until its
+// deadline.
+func TestWaitDeployQueue_streamDeadline(t *testing.T) {
+	defer func(wait time.Duration) { maxDeployWait = wait }(maxDeployWait)
+	maxDeployWait = 0
+
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, _ := restfultest.NewHTTPClientSpy()
+	client := &streamingClient{HTTPClient: httpClient}
+	for step := 1; step <= 3; step++ {
+		client.events = append(client.events, r11nEvent(t, dto.R11nResponse{
+			Rollout: &sous.RolloutProgress{Step: step, Steps: 3},
+		}))
+	}
+	sd := &Deploy{
+		HTTPClient: client,
+		LogSink:    log,
+	}
+
+	p := newDeployProgress("", "127.0.0.1:1234/deploy-queue-item")
+	finished, err := sd.streamDeployQueue(client, p, 10, nil)
+	assert.True(t, finished)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "within")
+	assert.Contains(t, p.lastRollout, "step 1/3", "followed past the deadline")
+}
+
 /*
 func (m *MyMockedHTTPClient) SetRZBody(body dto.R11nResponse) {
 	m.body = body
@@ -0,0 +1,42 @@
+package actions
+
+import (
+	"fmt"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+// DeploymentOperation is an Action that asks the server to change how a
+// single deployment runs, e.g. to scale or bounce it.
+type DeploymentOperation struct {
+	// Operation names the server resource performing the operation, e.g.
+	// "scale".
+	Operation string
+	// Query holds the parameters of the operation, besides the deployment ID.
+	Query              map[string]string
+	TargetDeploymentID sous.DeploymentID
+	HTTPClient         restful.HTTPClient
+	User               sous.User
+	LogSink            logging.LogSink
+}
+
+// Do implements Action on DeploymentOperation.
+func (op *DeploymentOperation) Do() error {
+	query := op.TargetDeploymentID.QueryMap()
+	for k, v := range op.Query {
+		query[k] = v
+	}
+	messages.ReportLogFieldsMessageToConsole(
+		fmt.Sprintf("Requesting %s of %s", op.Operation, op.TargetDeploymentID),
+		logging.ExtraDebug1Level,
+		op.LogSink,
+	)
+	if _, err := op.HTTPClient.Create("./"+op.Operation, query, nil, op.User.HTTPHeaders()); err != nil {
+		return errors.Wrapf(err, "%s of %s", op.Operation, op.TargetDeploymentID)
+	}
+	return nil
+}
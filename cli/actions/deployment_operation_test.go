@@ -0,0 +1,40 @@
+package actions
+
+import (
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful/restfultest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentOperation_Do(t *testing.T) {
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	ctrl.MatchMethod("Create", retrieving("./scale"), nil, restfultest.DummyUpdater(), nil)
+
+	op := &DeploymentOperation{
+		Operation: "scale",
+		Query:     map[string]string{"instances": "3"},
+		TargetDeploymentID: sous.DeploymentID{
+			ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/example/app"}},
+			Cluster:    "ci",
+		},
+		HTTPClient: httpClient,
+		User:       sous.User{Name: "Test User"},
+		LogSink:    log,
+	}
+	require.NoError(t, op.Do())
+
+	calls := ctrl.CallsTo("Create")
+	require.Len(t, calls, 1)
+	assert.Equal(t, map[string]string{
+		"repo":      "github.com/example/app",
+		"offset":    "",
+		"flavor":    "",
+		"cluster":   "ci",
+		"instances": "3",
+	}, calls[0].PassedArgs().Get(1))
+}
@@ -0,0 +1,84 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// JobRun is an Action that asks the server to run a job now.
+	JobRun struct {
+		TargetDeploymentID sous.DeploymentID
+		Options            sous.JobRunOptions
+		HTTPClient         restful.HTTPClient
+		User               sous.User
+		LogSink            logging.LogSink
+	}
+
+	// JobHistory is an Action that lists the recent runs of a job.
+	JobHistory struct {
+		TargetDeploymentID sous.DeploymentID
+		// Count is the most runs listed.
+		Count      int
+		HTTPClient restful.HTTPClient
+		OutWriter  io.Writer
+	}
+)
+
+// Do implements Action on JobRun.
+func (jr *JobRun) Do() error {
+	messages.ReportLogFieldsMessageToConsole(
+		fmt.Sprintf("Running %s as %s", jr.TargetDeploymentID, jr.Options.RunID),
+		logging.ExtraDebug1Level,
+		jr.LogSink,
+	)
+	opts := jr.Options
+	if _, err := jr.HTTPClient.Create("./job-run", jr.TargetDeploymentID.QueryMap(), &opts, jr.User.HTTPHeaders()); err != nil {
+		return errors.Wrapf(err, "running %s", jr.TargetDeploymentID)
+	}
+	return nil
+}
+
+// Do implements Action on JobHistory.
+func (jh *JobHistory) Do() error {
+	query := jh.TargetDeploymentID.QueryMap()
+	query["count"] = strconv.Itoa(jh.Count)
+	history := dto.JobHistoryResponse{}
+	if _, err := jh.HTTPClient.Retrieve("./job-history", query, &history, nil); err != nil {
+		return errors.Wrapf(err, "retrieving runs of %s", jh.TargetDeploymentID)
+	}
+	writeJobRuns(jh.OutWriter, history.Runs)
+	return nil
+}
+
+func writeJobRuns(out io.Writer, runs []sous.JobRun) {
+	w := &tabwriter.Writer{}
+	w.Init(out, 2, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STARTED\tENDED\tSTATE\tEXIT\tRUN\tTASK")
+	for _, r := range runs {
+		ended, exit, run := "-", "-", r.RunID
+		if !r.EndedAt.IsZero() {
+			ended = r.EndedAt.Local().Format(time.RFC3339)
+		}
+		if r.ExitCode != nil {
+			exit = strconv.Itoa(*r.ExitCode)
+		}
+		if run == "" {
+			run = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.StartedAt.Local().Format(time.RFC3339), ended, r.State, exit, run, r.TaskID)
+	}
+	w.Flush()
+}
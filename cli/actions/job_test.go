@@ -0,0 +1,77 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful/restfultest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jobDeploymentID() sous.DeploymentID {
+	return sous.DeploymentID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/example/job"}},
+		Cluster:    "ci",
+	}
+}
+
+func TestJobRun_Do(t *testing.T) {
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	ctrl.MatchMethod("Create", retrieving("./job-run"), sous.JobRunOptions{}, restfultest.DummyUpdater(), nil)
+
+	opts := sous.JobRunOptions{RunID: "run-1", Args: []string{"-v"}}
+	jr := &JobRun{
+		TargetDeploymentID: jobDeploymentID(),
+		Options:            opts,
+		HTTPClient:         httpClient,
+		User:               sous.User{Name: "Test User"},
+		LogSink:            log,
+	}
+	require.NoError(t, jr.Do())
+
+	calls := ctrl.CallsTo("Create")
+	require.Len(t, calls, 1)
+	assert.Equal(t, "ci", calls[0].PassedArgs().Get(1).(map[string]string)["cluster"])
+	assert.Equal(t, opts, jr.Options)
+}
+
+func TestJobHistory_Do(t *testing.T) {
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	exit := 1
+	started := time.Date(2018, 1, 2, 3, 4, 5, 0, time.Local)
+	ctrl.MatchMethod("Retrieve", retrieving("./job-history"), dto.JobHistoryResponse{Runs: []sous.JobRun{
+		{TaskID: "task-2", RunID: "run-1", State: "TASK_RUNNING", StartedAt: started},
+		{TaskID: "task-1", State: "TASK_FAILED", StartedAt: started, EndedAt: started, ExitCode: &exit},
+	}}, restfultest.DummyUpdater(), nil)
+
+	out := &bytes.Buffer{}
+	jh := &JobHistory{
+		TargetDeploymentID: jobDeploymentID(),
+		Count:              5,
+		HTTPClient:         httpClient,
+		OutWriter:          out,
+	}
+	require.NoError(t, jh.Do())
+
+	calls := ctrl.CallsTo("Retrieve")
+	require.Len(t, calls, 1)
+	assert.Equal(t, "5", calls[0].PassedArgs().Get(1).(map[string]string)["count"])
+
+	at := started.Format(time.RFC3339)
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		rows = append(rows, strings.Fields(line))
+	}
+	assert.Equal(t, [][]string{
+		{"STARTED", "ENDED", "STATE", "EXIT", "RUN", "TASK"},
+		{at, "-", "TASK_RUNNING", "-", "run-1", "task-2"},
+		{at, at, "TASK_FAILED", "1", "-", "task-1"},
+	}, rows)
+}
@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"context"
+	"io"
+
+	"github.com/opentable/sous/ext/singularity"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+// Logs is an Action that writes the logs of the tasks of a deployment.
+type Logs struct {
+	TargetDeploymentID sous.DeploymentID
+	StateReader        sous.StateReader
+	LogSink            logging.LogSink
+	OutWriter          io.Writer
+	Options            singularity.LogOptions
+}
+
+// Do implements Action on Logs.
+func (l *Logs) Do() error {
+	baseURL, reqID, err := l.singularityRequest()
+	if err != nil {
+		return err
+	}
+	lr := singularity.NewLogReader(baseURL, reqID, l.LogSink)
+	return lr.Stream(context.Background(), l.OutWriter, l.Options)
+}
+
+// singularityRequest returns the Singularity URL and request ID of the
+// target deployment.
+func (l *Logs) singularityRequest() (baseURL, reqID string, err error) {
+	did := l.TargetDeploymentID
+	state, err := l.StateReader.ReadState()
+	if err != nil {
+		return "", "", err
+	}
+	cluster, ok := state.Defs.Clusters[did.Cluster]
+	if !ok {
+		return "", "", errors.Errorf("cluster %q is not defined", did.Cluster)
+	}
+	if cluster.Kind != "" && cluster.Kind != sous.DefaultClusterKind {
+		return "", "", errors.Errorf("logs are only available from Singularity clusters, %q is a %s cluster", did.Cluster, cluster.Kind)
+	}
+	m, ok := state.Manifests.Get(did.ManifestID)
+	if !ok {
+		return "", "", errors.Errorf("no manifest %s", did.ManifestID)
+	}
+	spec, ok := m.Deployments[did.Cluster]
+	if !ok {
+		return "", "", errors.Errorf("no deployment %s", did)
+	}
+	reqID = m.WithDefaults(spec).SingularityRequestID
+	if reqID == "" {
+		if reqID, err = singularity.MakeRequestID(did); err != nil {
+			return "", "", err
+		}
+	}
+	return cluster.BaseURL, reqID, nil
+}
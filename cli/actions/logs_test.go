@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/ext/singularity"
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogs_singularityRequest(t *testing.T) {
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{
+		"cluster-1": &sous.Cluster{Name: "cluster-1", BaseURL: "http://singularity.example.com"},
+		"other":     &sous.Cluster{Name: "other", Kind: "other", BaseURL: "http://other.example.com"},
+	}
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/example/project"}}
+	state.Manifests.Add(&sous.Manifest{
+		Source: mid.Source,
+		Deployments: sous.DeploySpecs{
+			"cluster-1": {},
+			"other":     {},
+		},
+	})
+	logs := func(cluster string) *Logs {
+		return &Logs{
+			TargetDeploymentID: sous.DeploymentID{ManifestID: mid, Cluster: cluster},
+			StateReader:        &sous.DummyStateManager{State: state},
+		}
+	}
+
+	baseURL, reqID, err := logs("cluster-1").singularityRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "http://singularity.example.com", baseURL)
+	expected, err := singularity.MakeRequestID(logs("cluster-1").TargetDeploymentID)
+	require.NoError(t, err)
+	assert.Equal(t, expected, reqID)
+
+	spec := state.Manifests.Snapshot()[mid].Deployments["cluster-1"]
+	spec.SingularityRequestID = "custom-request-id"
+	state.Manifests.Snapshot()[mid].Deployments["cluster-1"] = spec
+	_, reqID, err = logs("cluster-1").singularityRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "custom-request-id", reqID)
+
+	_, _, err = logs("other").singularityRequest()
+	assert.Error(t, err)
+	_, _, err = logs("missing").singularityRequest()
+	assert.Error(t, err)
+}
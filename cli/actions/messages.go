@@ -106,7 +106,7 @@ func (msg updateMessage) WriteToConsole(console io.Writer) {
 	}
 	if msg.interval.Complete() {
 		version := msg.sid.Version.String()
-		numInstances := msg.manifest.Deployments[msg.did.Cluster].NumInstances
+		numInstances := msg.manifest.WithDefaults(msg.manifest.Deployments[msg.did.Cluster]).NumInstances
 
 		if _, err := fmt.Fprintf(console, "Updated global manifest: %d instances of version %s\n",
 			numInstances, version); err != nil {
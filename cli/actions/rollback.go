@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/server"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+// Rollback is used to redeploy the previous version of a Deployment whose
+// deploy succeeded, as recorded in the deployment history.
+type Rollback struct {
+	*Deploy
+}
+
+// Do implements Action on Rollback.
+func (r *Rollback) Do() error {
+	current, err := r.currentVersion()
+	if err != nil {
+		return err
+	}
+
+	history := dto.HistoryResponse{}
+	if _, err := r.HTTPClient.Retrieve("./history", r.TargetDeploymentID.QueryMap(), &history, nil); err != nil {
+		return errors.Wrapf(err, "retrieving deployment history of %s", r.TargetDeploymentID)
+	}
+
+	version, err := sous.PreviousGoodVersion(history.Outcomes, current)
+	if err != nil {
+		return errors.Wrapf(err, "cannot roll back %s", r.TargetDeploymentID)
+	}
+
+	messages.ReportLogFieldsMessageToConsole(
+		fmt.Sprintf("Rolling back %s from %s to %s", r.TargetDeploymentID, current, version),
+		logging.InformationLevel,
+		r.LogSink,
+	)
+	return r.deployVersion(version)
+}
+
+func (r *Rollback) currentVersion() (semv.Version, error) {
+	d := server.SingleDeploymentBody{}
+	if _, err := r.HTTPClient.Retrieve("./single-deployment", r.TargetDeploymentID.QueryMap(), &d, r.User.HTTPHeaders()); err != nil {
+		return semv.Version{}, errors.Wrapf(err, "retrieving current deployment %s", r.TargetDeploymentID)
+	}
+	if d.Deployment == nil {
+		return semv.Version{}, errors.Errorf("no deployment %s", r.TargetDeploymentID)
+	}
+	return d.Deployment.Version, nil
+}
@@ -0,0 +1,83 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/server"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/opentable/sous/util/restful/restfultest"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func retrieving(path string) func(mock.Arguments) bool {
+	return func(args mock.Arguments) bool {
+		return args.String(0) == path
+	}
+}
+
+// recordingUpdater records the bodies it is asked to update with.
+type recordingUpdater struct {
+	restful.UpdateDeleter
+	updated []restful.Comparable
+}
+
+func (u *recordingUpdater) Update(body restful.Comparable, _ map[string]string) (restful.UpdateDeleter, error) {
+	u.updated = append(u.updated, body)
+	return u, nil
+}
+
+func TestRollback_Do(t *testing.T) {
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	updater := &recordingUpdater{}
+
+	ctrl.MatchMethod("Retrieve", retrieving("./single-deployment"), server.SingleDeploymentBody{
+		Deployment: &sous.DeploySpec{Version: semv.MustParse("1.2.0")},
+	}, updater, nil)
+	ctrl.MatchMethod("Retrieve", retrieving("./history"), dto.HistoryResponse{
+		Changes: []sous.DeploymentChange{
+			{Kind: "modified", PriorVersion: "1.1.0", PostVersion: "1.2.0"},
+			{Kind: "modified", PriorVersion: "1.0.5", PostVersion: "1.1.0"},
+			{Kind: "modified", PriorVersion: "1.0.0", PostVersion: "1.0.5"},
+		},
+		Outcomes: []sous.DeployOutcome{
+			{Version: "1.2.0", Succeeded: true},
+			{Version: "1.1.0", Succeeded: false},
+			{Version: "1.0.5", Succeeded: true},
+		},
+	}, updater, nil)
+
+	r := &Rollback{Deploy: &Deploy{
+		HTTPClient: httpClient,
+		LogSink:    log,
+	}}
+	require.NoError(t, r.Do())
+
+	require.Len(t, updater.updated, 1)
+	body := updater.updated[0].(server.SingleDeploymentBody)
+	assert.Equal(t, "1.0.5", body.Deployment.Version.String())
+}
+
+func TestRollback_Do_noHistory(t *testing.T) {
+	log, _ := logging.NewLogSinkSpy()
+	httpClient, ctrl := restfultest.NewHTTPClientSpy()
+	updater := &recordingUpdater{}
+
+	ctrl.MatchMethod("Retrieve", retrieving("./single-deployment"), server.SingleDeploymentBody{
+		Deployment: &sous.DeploySpec{Version: semv.MustParse("1.2.0")},
+	}, updater, nil)
+	ctrl.MatchMethod("Retrieve", retrieving("./history"), dto.HistoryResponse{}, updater, nil)
+
+	r := &Rollback{Deploy: &Deploy{
+		HTTPClient: httpClient,
+		LogSink:    log,
+	}}
+	assert.Error(t, r.Do())
+	assert.Empty(t, updater.updated)
+}
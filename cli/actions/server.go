@@ -1,10 +1,14 @@
 package actions
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/opentable/sous/config"
 	"github.com/opentable/sous/ext/git"
@@ -27,6 +31,12 @@ type Server struct {
 	*config.Config
 	ServerHandler http.Handler
 	*sous.AutoResolver
+	// QueueSet is drained when the server shuts down.
+	QueueSet *sous.R11nQueueSet
+	// Leadership is resigned when the server shuts down.
+	Leadership *sous.Leadership
+	// DriftDetector, if not nil, is run until the server shuts down.
+	DriftDetector *sous.DriftDetector
 }
 
 // Do runs the server.
@@ -37,17 +47,67 @@ func (ss *Server) Do() error {
 
 	reportServerMessage("Starting scheduled GDM resolution.  Filtering the GDM to resolve on this server", ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
 
+	srv, err := server.NewServer(ss.ListenAddr, ss.ServerHandler, ss.Config)
+	if err != nil {
+		return err
+	}
+
+	var arDone sous.TriggerChannel
 	if ss.AutoResolver != nil {
-		ss.AutoResolver.Kickoff()
+		arDone = ss.AutoResolver.Kickoff()
 	} else {
 		reportServerMessage("Auto-resolver DISABLED", ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
 	}
 
+	if ss.DriftDetector != nil {
+		driftCtx, stopDrift := context.WithCancel(context.Background())
+		defer stopDrift()
+		go ss.DriftDetector.Run(driftCtx)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Run() }()
+
 	reportServerMessage("Sous Server Running", ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
 
-	fmt.Printf("Listening on http://%s", ss.ListenAddr)
+	fmt.Printf("Listening on %s://%s", srv.Scheme(), ss.ListenAddr)
 
-	return server.Run(ss.ListenAddr, ss.ServerHandler)
+	select {
+	case err := <-served:
+		return err
+	case sig := <-signals:
+		reportServerMessage(fmt.Sprintf("Received %s, shutting down", sig), ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
+	}
+	ss.shutdown(srv, arDone)
+	return <-served
+}
+
+// shutdown stops the server gracefully: it stops the auto-resolver and
+// accepting requests, then waits for queued rectifications to finish, up to
+// ShutdownTimeoutSeconds in all. Rectifications still pending after that are
+// resumed on restart, if the queue is stored in a database.
+func (ss *Server) shutdown(srv *server.Server, arDone sous.TriggerChannel) {
+	timeout := time.Duration(ss.Config.ShutdownTimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if arDone != nil {
+		close(arDone)
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		reportServerMessage(fmt.Sprintf("Error closing connections: %s", err), ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
+	}
+	if ss.QueueSet != nil {
+		if err := ss.QueueSet.Drain(ctx); err != nil {
+			reportServerMessage(fmt.Sprintf("Not all rectifications finished: %s", err), ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
+		}
+	}
+	ss.Leadership.Resign()
+	reportServerMessage("Sous Server stopped", ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
 }
 
 func ensureGDMExists(repo, localPath string, filterFlags config.DeployFilterFlags, listenAddress string, log logging.LogSink) error {
@@ -53,6 +53,24 @@ var (
 	DeployFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp + allFlagHelp + tagFlagHelp
 	// NewDeployFilterFlagsHelp is the text and config for deploy flags
 	NewDeployFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp + tagFlagHelp
+	// HistoryFilterFlagsHelp is the text and config for history flags
+	HistoryFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
+	// RollbackFilterFlagsHelp is the text and config for rollback flags
+	RollbackFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
+	// PlanFilterFlagsHelp is the text and config for plan flags
+	PlanFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
+	// DriftFilterFlagsHelp is the text and config for query drift flags
+	DriftFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
+	// LogsFilterFlagsHelp is the text and config for logs flags
+	LogsFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
+	// DeploymentOperationFilterFlagsHelp is the text and config for the flags
+	// of scale, pause, unpause and bounce
+	DeploymentOperationFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
+	// JobFilterFlagsHelp is the text and config for job run and job history
+	// flags
+	JobFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
+	// ScheduleFilterFlagsHelp is the text and config for query schedule flags
+	ScheduleFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp
 	// AddArtifactFlagsHelp is the text and config for add artifact flags
 	AddArtifactFlagsHelp = repoFlagHelp + offsetFlagHelp + tagFlagHelp
 )
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousBounce is the command description for `sous bounce`.
+type SousBounce struct {
+	SousGraph *graph.SousGraph
+
+	opts graph.DeploymentOperationOpts
+}
+
+func init() { TopLevelCommands["bounce"] = &SousBounce{} }
+
+const sousBounceHelp = `replaces the running instances of a deployment
+
+usage: sous bounce (options)
+
+sous bounce starts new instances of the deployment to the named cluster, and
+stops the old ones once the new ones are healthy. The deployment itself is not
+changed.
+`
+
+// Help returns the help string for this command.
+func (sb *SousBounce) Help() string { return sousBounceHelp }
+
+// AddFlags adds the flags for sous bounce.
+func (sb *SousBounce) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sb.opts.DFF, DeploymentOperationFilterFlagsHelp)
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sb *SousBounce) Execute(args []string) cmdr.Result {
+	sb.opts.Operation = "bounce"
+	return executeDeploymentOperation(sb.SousGraph, sb.opts, "Bouncing.")
+}
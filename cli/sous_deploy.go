@@ -3,12 +3,8 @@ package cli
 import (
 	"flag"
 
-	slack "github.com/ashwanthkumar/slack-go-webhook"
-	"github.com/opentable/sous/cli/actions"
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/util/cmdr"
-	"github.com/opentable/sous/util/logging"
-	"github.com/opentable/sous/util/logging/messages"
 )
 
 // SousDeploy is the command description for `sous deploy`.
@@ -51,78 +47,12 @@ func (sd *SousDeploy) Execute(args []string) cmdr.Result {
 	deploy, err := sd.SousGraph.GetDeploy(sd.opts)
 
 	if err != nil {
-		sd.slackMessage(deploy, err)
 		return cmdr.EnsureErrorResult(err)
 	}
 
-	err = deploy.Do()
-
-	sd.slackMessage(deploy, err)
-
-	if err != nil {
+	if err := deploy.Do(); err != nil {
 		return EnsureErrorResult(err)
 	}
 
 	return cmdr.Success("Done.")
 }
-
-func (sd *SousDeploy) slackMessage(action actions.Action, err error) {
-
-	var slackURL, slackChannel string
-	var additionalChannels map[string]string
-
-	d, ok := action.(*actions.Deploy)
-
-	if ok {
-		slackURL = d.Config.SlackHookURL
-		slackChannel = d.Config.SlackChannel
-		additionalChannels = d.Config.AdditionalSlackChannels
-	}
-
-	if additionalChannels == nil {
-		additionalChannels = make(map[string]string)
-	}
-
-	if len(slackURL) > 0 && len(slackChannel) > 0 {
-		additionalChannels[slackChannel] = slackURL
-	}
-
-	if len(additionalChannels) < 1 {
-		return
-	}
-
-	version, _ := d.ResolveFilter.TagVersion()
-
-	messages.ReportLogFieldsMessage("SlackMessage", logging.DebugLevel, d.LogSink, d.TargetDeploymentID.ManifestID, version)
-
-	color := "good"
-	attachment := slack.Attachment{Color: &color}
-	attachment.AddField(slack.Field{Title: "Build Author", Value: d.Config.User.Name})
-	attachment.AddField(slack.Field{Title: "Manifest ID", Value: d.TargetDeploymentID.String()})
-	attachment.AddField(slack.Field{Title: "Version", Value: version.String()})
-
-	if err != nil {
-		attachment.AddField(slack.Field{Title: "Status", Value: "FAILED"}).AddField(slack.Field{Title: "Error", Value: err.Error()})
-		color = "danger"
-	} else {
-		attachment.AddField(slack.Field{Title: "Status", Value: "SUCCESS"})
-	}
-
-	payload := slack.Payload{
-		Username:    "Sous Bot",
-		IconEmoji:   ":chefhat:",
-		Attachments: []slack.Attachment{attachment},
-	}
-
-	var errs []error
-	for k, v := range additionalChannels {
-		payload.Channel = k
-		newErrors := slack.Send(v, "", payload)
-		errs = append(errs, newErrors...)
-	}
-
-	if len(errs) > 0 {
-		messages.ReportLogFieldsMessage("Error sending slack message", logging.WarningLevel, d.LogSink, errs)
-	}
-
-}
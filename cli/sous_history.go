@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/dto"
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousHistory is the description of the `sous history` command.
+type SousHistory struct {
+	config.DeployFilterFlags `inject:"optional"`
+	graph.HTTPClient
+	flags struct {
+		limit int
+	}
+}
+
+func init() { TopLevelCommands["history"] = &SousHistory{} }
+
+const sousHistoryHelp = `list changes made to deployments, most recent first
+
+usage: sous history [-repo REPO [-offset OFFSET] [-flavor FLAVOR]] [-cluster CLUSTER] [-limit N]
+
+Each change records when it was made, by whom, and how the deployment
+changed. Only servers with a database record history.`
+
+// Help implements Command on SousHistory.
+func (*SousHistory) Help() string { return sousHistoryHelp }
+
+// AddFlags implements AddFlagger on SousHistory.
+func (sh *SousHistory) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sh.DeployFilterFlags, HistoryFilterFlagsHelp)
+	fs.IntVar(&sh.flags.limit, "limit", 20, "the maximum number of changes to list (0 for all)")
+}
+
+// RegisterOn adds options set by flags to the injection graph.
+func (sh *SousHistory) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&sh.DeployFilterFlags)
+}
+
+// Execute implements Executor on SousHistory.
+func (sh *SousHistory) Execute(args []string) cmdr.Result {
+	history := &dto.HistoryResponse{}
+	if _, err := sh.Retrieve("./history", historyQuery(sh.DeployFilterFlags, sh.flags.limit), history, nil); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	out := &bytes.Buffer{}
+	writeHistory(out, history.Changes)
+	return cmdr.SuccessData(out.Bytes())
+}
+
+func historyQuery(dff config.DeployFilterFlags, limit int) map[string]string {
+	query := map[string]string{"limit": strconv.Itoa(limit)}
+	if dff.Repo != "" {
+		query["repo"] = dff.Repo
+		query["offset"] = dff.Offset
+		query["flavor"] = dff.Flavor
+	}
+	if dff.Cluster != "" {
+		query["cluster"] = dff.Cluster
+	}
+	return query
+}
+
+func writeHistory(out io.Writer, changes []sous.DeploymentChange) {
+	w := &tabwriter.Writer{}
+	w.Init(out, 2, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TIME\tUSER\tDEPLOYMENT\tCHANGE\tVERSION\tDETAILS")
+	for _, c := range changes {
+		version := c.PostVersion
+		if c.Kind == "modified" && c.PriorVersion != c.PostVersion {
+			version = c.PriorVersion + " -> " + c.PostVersion
+		} else if c.Kind == "removed" {
+			version = c.PriorVersion
+		}
+		// Changes made to the GDM without Sous have no user.
+		user := c.User.String()
+		if user == "" {
+			user = "(unattributed)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.At.Local().Format(time.RFC3339), user, c.DeploymentID, c.Kind, version,
+			strings.Join(c.Diffs, "; "))
+	}
+	w.Flush()
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/config"
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryQuery(t *testing.T) {
+	dff := config.DeployFilterFlags{}
+	assert.Equal(t, map[string]string{"limit": "20"}, historyQuery(dff, 20))
+
+	dff.Repo = "github.com/example/project"
+	dff.Cluster = "cluster-1"
+	assert.Equal(t, map[string]string{
+		"limit":   "0",
+		"repo":    "github.com/example/project",
+		"offset":  "",
+		"flavor":  "",
+		"cluster": "cluster-1",
+	}, historyQuery(dff, 0))
+}
+
+func TestWriteHistory(t *testing.T) {
+	did := sous.DeploymentID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/example/project"}},
+		Cluster:    "cluster-1",
+	}
+	out := &bytes.Buffer{}
+	writeHistory(out, []sous.DeploymentChange{
+		{
+			DeploymentID: did,
+			At:           time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC),
+			User:         sous.User{Name: "Test User", Email: "test@example.com"},
+			Kind:         "modified",
+			PriorVersion: "1.0.0",
+			PostVersion:  "1.1.0",
+			Diffs:        sous.Differences{"source id; this: 1.0.0; other: 1.1.0", "number of instances; this: 1; other: 2"},
+		},
+		{
+			DeploymentID: did,
+			At:           time.Date(2018, 3, 2, 12, 0, 0, 0, time.UTC),
+			Kind:         "removed",
+			PriorVersion: "1.1.0",
+		},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[2], "(unattributed)")
+	assert.Contains(t, lines[1], "Test User <test@example.com>")
+	assert.Contains(t, lines[1], "cluster-1:github.com/example/project")
+	assert.Contains(t, lines[1], "1.0.0 -> 1.1.0")
+	assert.Contains(t, lines[1], "number of instances; this: 1; other: 2")
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousJob is the `sous job` command.
+type SousJob struct{}
+
+// JobSubcommands collects the subcommands of `sous job` as they're added.
+var JobSubcommands = cmdr.Commands{}
+
+func init() { TopLevelCommands["job"] = &SousJob{} }
+
+const sousJobHelp = `run jobs on demand and inspect their runs`
+
+// Subcommands implements Subcommander on SousJob.
+func (SousJob) Subcommands() cmdr.Commands {
+	return JobSubcommands
+}
+
+// Help implements Command for SousJob.
+func (*SousJob) Help() string { return sousJobHelp }
+
+// Execute implements Executor on SousJob.
+func (*SousJob) Execute(args []string) cmdr.Result {
+	err := cmdr.UsageErrorf("usage: sous job [options] <command>")
+	err.Tip = "try `sous help job` for a list of commands"
+	return err
+}
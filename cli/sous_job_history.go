@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousJobHistory is the command description for `sous job history`.
+type SousJobHistory struct {
+	SousGraph *graph.SousGraph
+
+	opts graph.JobActionOpts
+}
+
+func init() { JobSubcommands["history"] = &SousJobHistory{} }
+
+const sousJobHistoryHelp = `lists the recent runs of a job
+
+usage: sous job history (options)
+
+sous job history lists the most recent runs of the job deployed to the named
+cluster, whether scheduled or run on demand, most recently started first,
+with when each ended and its exit code.
+`
+
+// Help returns the help string for this command.
+func (sj *SousJobHistory) Help() string { return sousJobHistoryHelp }
+
+// AddFlags adds the flags for sous job history.
+func (sj *SousJobHistory) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sj.opts.DFF, JobFilterFlagsHelp)
+
+	fs.IntVar(&sj.opts.Count, "count", 10, "the most runs to list")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sj *SousJobHistory) Execute(args []string) cmdr.Result {
+	if sj.opts.Count < 1 {
+		return cmdr.UsageErrorf("-count must be at least 1")
+	}
+
+	history, err := sj.SousGraph.GetJobHistory(sj.opts, os.Stdout)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+
+	if err := history.Do(); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	return cmdr.Success()
+}
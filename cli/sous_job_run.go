@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SousJobRun is the command description for `sous job run`.
+type SousJobRun struct {
+	SousGraph *graph.SousGraph
+
+	opts graph.JobActionOpts
+}
+
+// envFlag is a flag.Value collecting repeated NAME=VALUE flags.
+type envFlag map[string]string
+
+func init() { JobSubcommands["run"] = &SousJobRun{} }
+
+const sousJobRunHelp = `runs a job now
+
+usage: sous job run (options) [-- ARGS...]
+
+sous job run starts a run of the scheduled, on-demand or once job deployed to
+the named cluster, as it is currently deployed. ARGS are appended to the
+command line of the run, and -env adds to its environment. The ID of the run
+is printed, and can be found in the output of sous job history.
+`
+
+// Help returns the help string for this command.
+func (sj *SousJobRun) Help() string { return sousJobRunHelp }
+
+// AddFlags adds the flags for sous job run.
+func (sj *SousJobRun) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sj.opts.DFF, JobFilterFlagsHelp)
+
+	sj.opts.Options.Env = map[string]string{}
+	fs.Var(envFlag(sj.opts.Options.Env), "env",
+		"NAME=VALUE to set in the environment of the run (repeatable)")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sj *SousJobRun) Execute(args []string) cmdr.Result {
+	sj.opts.Options.Args = args
+	sj.opts.Options.RunID = uuid.NewV4().String()
+
+	run, err := sj.SousGraph.GetJobRun(sj.opts)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+
+	if err := run.Do(); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	return cmdr.Successf("Started run %s.", sj.opts.Options.RunID)
+}
+
+// String implements flag.Value on envFlag.
+func (e envFlag) String() string {
+	vars := make([]string, 0, len(e))
+	for name, value := range e {
+		vars = append(vars, name+"="+value)
+	}
+	sort.Strings(vars)
+	return strings.Join(vars, " ")
+}
+
+// Set implements flag.Value on envFlag.
+func (e envFlag) Set(v string) error {
+	parts := strings.SplitN(v, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("%q is not NAME=VALUE", v)
+	}
+	e[parts[0]] = parts[1]
+	return nil
+}
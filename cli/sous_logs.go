@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousLogs is the command description for `sous logs`.
+type SousLogs struct {
+	SousGraph *graph.SousGraph
+
+	opts graph.LogsActionOpts
+}
+
+func init() { TopLevelCommands["logs"] = &SousLogs{} }
+
+const sousLogsHelp = `fetches the logs of the tasks of a deployment
+
+usage: sous logs (options)
+
+sous logs finds the Singularity request of the deployment to the named
+cluster, and writes the stdout and stderr of its tasks, each preceded by a
+header naming the task. By default the running tasks are shown, or the most
+recent task if none is running, e.g. one from a failed deploy.
+`
+
+// Help returns the help string for this command.
+func (sl *SousLogs) Help() string { return sousLogsHelp }
+
+// AddFlags adds the flags for sous logs.
+func (sl *SousLogs) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sl.opts.DFF, LogsFilterFlagsHelp)
+
+	fs.BoolVar(&sl.opts.Options.Follow, "follow", false,
+		"keep writing new output until interrupted")
+	fs.StringVar(&sl.opts.Options.TaskID, "task", "",
+		"show only the logs of the task with this ID")
+	fs.DurationVar(&sl.opts.Options.Since, "since", 0,
+		"show the logs of all tasks started within this duration, e.g. 2h")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sl *SousLogs) Execute(args []string) cmdr.Result {
+	logs, err := sl.SousGraph.GetLogs(sl.opts, os.Stdout)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+
+	if err := logs.Do(); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	return cmdr.Success()
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousPause is the command description for `sous pause`.
+type SousPause struct {
+	SousGraph *graph.SousGraph
+
+	opts graph.DeploymentOperationOpts
+}
+
+// SousUnpause is the command description for `sous unpause`.
+type SousUnpause struct {
+	SousGraph *graph.SousGraph
+
+	opts graph.DeploymentOperationOpts
+}
+
+func init() {
+	TopLevelCommands["pause"] = &SousPause{}
+	TopLevelCommands["unpause"] = &SousUnpause{}
+}
+
+const sousPauseHelp = `stops all instances of a deployment
+
+usage: sous pause (options)
+
+sous pause marks the deployment to the named cluster as paused in the GDM, and
+stops all of its instances. The deployment keeps its configuration, including
+NumInstances, and its instances are started again by sous unpause.
+`
+
+const sousUnpauseHelp = `restarts the instances of a paused deployment
+
+usage: sous unpause (options)
+
+sous unpause clears the paused mark of the deployment to the named cluster in
+the GDM, and starts NumInstances instances of it again.
+`
+
+// Help returns the help string for this command.
+func (sp *SousPause) Help() string { return sousPauseHelp }
+
+// AddFlags adds the flags for sous pause.
+func (sp *SousPause) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sp.opts.DFF, DeploymentOperationFilterFlagsHelp)
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sp *SousPause) Execute(args []string) cmdr.Result {
+	sp.opts.Operation = "pause"
+	return executeDeploymentOperation(sp.SousGraph, sp.opts, "Pausing.")
+}
+
+// Help returns the help string for this command.
+func (su *SousUnpause) Help() string { return sousUnpauseHelp }
+
+// AddFlags adds the flags for sous unpause.
+func (su *SousUnpause) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &su.opts.DFF, DeploymentOperationFilterFlagsHelp)
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (su *SousUnpause) Execute(args []string) cmdr.Result {
+	su.opts.Operation = "unpause"
+	return executeDeploymentOperation(su.SousGraph, su.opts, "Unpausing.")
+}
+
+// executeDeploymentOperation performs the operation described by opts, and
+// returns a Success with message if it succeeds.
+func executeDeploymentOperation(sg *graph.SousGraph, opts graph.DeploymentOperationOpts, message string) cmdr.Result {
+	op, err := sg.GetDeploymentOperation(opts)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+
+	if err := op.Do(); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	return cmdr.Success(message)
+}
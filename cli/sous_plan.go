@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/dto"
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousPlan is the description of the `sous plan` command.
+type SousPlan struct {
+	config.DeployFilterFlags `inject:"optional"`
+	graph.HTTPClient
+}
+
+func init() { TopLevelCommands["plan"] = &SousPlan{} }
+
+const sousPlanHelp = `show what rectification would change, without changing anything
+
+usage: sous plan [-repo REPO [-offset OFFSET] [-flavor FLAVOR]] [-cluster CLUSTER]
+
+The server compares the intended state of the selected deployments with what
+is running, resolves the artifacts that would be deployed, and lists each
+change field by field. Changes that could not be made, e.g. because no
+artifact exists for the intended version, are listed with the reason.
+Nothing is queued for rectification.`
+
+// Help implements Command on SousPlan.
+func (*SousPlan) Help() string { return sousPlanHelp }
+
+// AddFlags implements AddFlagger on SousPlan.
+func (sp *SousPlan) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sp.DeployFilterFlags, PlanFilterFlagsHelp)
+}
+
+// RegisterOn adds options set by flags to the injection graph.
+func (sp *SousPlan) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&sp.DeployFilterFlags)
+}
+
+// Execute implements Executor on SousPlan.
+func (sp *SousPlan) Execute(args []string) cmdr.Result {
+	plan := &dto.PlanResponse{}
+	if _, err := sp.Retrieve("./plan", deployFilterQuery(sp.DeployFilterFlags), plan, nil); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	out := &bytes.Buffer{}
+	writePlan(out, plan.Changes)
+	return cmdr.SuccessData(out.Bytes())
+}
+
+// deployFilterQuery returns the query that selects the deployments chosen by
+// dff from the /plan and /drift endpoints.
+func deployFilterQuery(dff config.DeployFilterFlags) map[string]string {
+	query := map[string]string{}
+	if dff.Repo != "" {
+		query["repo"] = dff.Repo
+		query["offset"] = dff.Offset
+		query["flavor"] = dff.Flavor
+	}
+	if dff.Cluster != "" {
+		query["cluster"] = dff.Cluster
+	}
+	return query
+}
+
+func writePlan(out io.Writer, changes []sous.PlannedChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "No changes.")
+		return
+	}
+	for _, c := range changes {
+		fmt.Fprintf(out, "%s: %s\n", c.DeploymentID, c.Action)
+		for _, d := range c.Diffs {
+			fmt.Fprintf(out, "  %s\n", d)
+		}
+		if c.Artifact != "" {
+			fmt.Fprintf(out, "  artifact: %s\n", c.Artifact)
+		}
+		if c.Error != "" {
+			fmt.Fprintf(out, "  cannot %s: %s\n", c.Action, c.Error)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/opentable/sous/config"
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployFilterQuery(t *testing.T) {
+	assert.Equal(t, map[string]string{}, deployFilterQuery(config.DeployFilterFlags{}))
+
+	dff := config.DeployFilterFlags{}
+	dff.Repo = "github.com/example/project"
+	dff.Cluster = "cluster-1"
+	assert.Equal(t, map[string]string{
+		"repo":    "github.com/example/project",
+		"offset":  "",
+		"flavor":  "",
+		"cluster": "cluster-1",
+	}, deployFilterQuery(dff))
+}
+
+func TestWritePlan(t *testing.T) {
+	did := sous.DeploymentID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/example/project"}},
+		Cluster:    "cluster-1",
+	}
+	out := &bytes.Buffer{}
+	writePlan(out, []sous.PlannedChange{
+		{
+			DeploymentID: did,
+			Kind:         "modified",
+			Action:       "update existing deployment",
+			Diffs: []sous.FieldDiff{
+				{Field: "Env.DEBUG", Post: "1"},
+				{Field: "Version", Prior: "1.0.0", Post: "1.1.0"},
+			},
+			Error: "no artifact for 1.1.0",
+		},
+	})
+
+	assert.Equal(t, "cluster-1:github.com/example/project: update existing deployment\n"+
+		"  Env.DEBUG: (unset) -> 1\n"+
+		"  Version: 1.0.0 -> 1.1.0\n"+
+		"  cannot update existing deployment: no artifact for 1.1.0\n", out.String())
+
+	out.Reset()
+	writePlan(out, nil)
+	assert.Equal(t, "No changes.\n", out.String())
+}
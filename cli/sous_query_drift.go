@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousQueryDrift is the description of the `sous query drift` command.
+type SousQueryDrift struct {
+	config.DeployFilterFlags `inject:"optional"`
+	graph.HTTPClient
+}
+
+func init() { QuerySubcommands["drift"] = &SousQueryDrift{} }
+
+const sousQueryDriftHelp = `list deployments that differ from the GDM in their clusters
+
+usage: sous query drift [-repo REPO [-offset OFFSET] [-flavor FLAVOR]] [-cluster CLUSTER]
+
+The server periodically compares the deployments running in its clusters with
+the GDM. This lists, as of the latest comparison, the deployments whose
+running configuration differs field by field, those running that are not in
+the GDM, and those in the GDM that are not running.`
+
+// Help implements Command on SousQueryDrift.
+func (*SousQueryDrift) Help() string { return sousQueryDriftHelp }
+
+// AddFlags implements AddFlagger on SousQueryDrift.
+func (sqd *SousQueryDrift) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sqd.DeployFilterFlags, DriftFilterFlagsHelp)
+}
+
+// RegisterOn adds options set by flags to the injection graph.
+func (sqd *SousQueryDrift) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&sqd.DeployFilterFlags)
+}
+
+// Execute implements Executor on SousQueryDrift.
+func (sqd *SousQueryDrift) Execute(args []string) cmdr.Result {
+	report := &sous.DriftReport{}
+	if _, err := sqd.Retrieve("./drift", deployFilterQuery(sqd.DeployFilterFlags), report, nil); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	out := &bytes.Buffer{}
+	writeDrift(out, report)
+	return cmdr.SuccessData(out.Bytes())
+}
+
+func writeDrift(out io.Writer, report *sous.DriftReport) {
+	fmt.Fprintf(out, "Compared at %s\n", report.At.Local().Format(time.RFC3339))
+	if len(report.Drifted)+len(report.Unknown)+len(report.Missing) == 0 {
+		fmt.Fprintln(out, "No drift.")
+		return
+	}
+	if len(report.Drifted) > 0 {
+		fmt.Fprintln(out, "Drifted:")
+		for _, d := range report.Drifted {
+			fmt.Fprintf(out, "  %s\n", d.DeploymentID)
+			for _, fd := range d.Diffs {
+				fmt.Fprintf(out, "    %s: running %s, intended %s\n", fd.Field, driftValue(fd.Prior), driftValue(fd.Post))
+			}
+		}
+	}
+	if len(report.Unknown) > 0 {
+		fmt.Fprintln(out, "Running but not in the GDM:")
+		for _, id := range report.Unknown {
+			fmt.Fprintf(out, "  %s\n", id)
+		}
+	}
+	if len(report.Missing) > 0 {
+		fmt.Fprintln(out, "In the GDM but not running:")
+		for _, id := range report.Missing {
+			fmt.Fprintf(out, "  %s\n", id)
+		}
+	}
+}
+
+func driftValue(v string) string {
+	if v == "" {
+		return "(unset)"
+	}
+	return v
+}
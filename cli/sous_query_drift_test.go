@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDrift(t *testing.T) {
+	did := func(repo string) sous.DeploymentID {
+		return sous.DeploymentID{
+			ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: repo}},
+			Cluster:    "cluster-1",
+		}
+	}
+	out := &bytes.Buffer{}
+	writeDrift(out, &sous.DriftReport{
+		At: time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC),
+		Drifted: []sous.DeploymentDrift{{
+			DeploymentID: did("github.com/example/scaled"),
+			Diffs:        []sous.FieldDiff{{Field: "NumInstances", Prior: "5", Post: "3"}},
+		}},
+		Unknown: []sous.DeploymentID{did("github.com/example/unknown")},
+		Missing: []sous.DeploymentID{did("github.com/example/missing")},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"Drifted:",
+		"  cluster-1:github.com/example/scaled",
+		"    NumInstances: running 5, intended 3",
+		"Running but not in the GDM:",
+		"  cluster-1:github.com/example/unknown",
+		"In the GDM but not running:",
+		"  cluster-1:github.com/example/missing",
+	}, lines[1:])
+
+	out.Reset()
+	writeDrift(out, &sous.DriftReport{})
+	assert.Contains(t, out.String(), "No drift.")
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/dto"
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousQueryQuota is the description of the `sous query quota` command.
+type SousQueryQuota struct {
+	graph.HTTPClient
+	flags struct {
+		owner, cluster string
+	}
+}
+
+func init() { QuerySubcommands["quota"] = &SousQueryQuota{} }
+
+const sousQueryQuotaHelp = `show the usage of resource quotas
+
+usage: sous query quota [-owner OWNER] [-cluster CLUSTER]
+
+Quotas are defined in the GDM's defs per owner, and optionally per cluster, on
+the total cpus, memory (in MB) and instances of that owner's deployments. This
+lists the used and limited amount of each; unlimited amounts are shown as '-'
+and exceeded ones are marked with '!'.`
+
+// Help implements Command on SousQueryQuota.
+func (*SousQueryQuota) Help() string { return sousQueryQuotaHelp }
+
+// AddFlags implements AddFlagger on SousQueryQuota.
+func (sqq *SousQueryQuota) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&sqq.flags.owner, "owner", "", "show only the quotas of this owner")
+	fs.StringVar(&sqq.flags.cluster, "cluster", "", "show only the quotas limited to this cluster")
+}
+
+// RegisterOn adds options set by flags to the injection graph.
+func (*SousQueryQuota) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&config.DeployFilterFlags{})
+}
+
+// Execute implements Executor on SousQueryQuota.
+func (sqq *SousQueryQuota) Execute(args []string) cmdr.Result {
+	query := map[string]string{}
+	if sqq.flags.owner != "" {
+		query["owner"] = sqq.flags.owner
+	}
+	if sqq.flags.cluster != "" {
+		query["cluster"] = sqq.flags.cluster
+	}
+	quotas := &dto.QuotaResponse{}
+	if _, err := sqq.Retrieve("./quota", query, quotas, nil); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	out := &bytes.Buffer{}
+	writeQuotaUsage(out, quotas.Usage)
+	return cmdr.SuccessData(out.Bytes())
+}
+
+func writeQuotaUsage(out io.Writer, usage []sous.QuotaUsage) {
+	if len(usage) == 0 {
+		fmt.Fprintln(out, "No quotas.")
+		return
+	}
+	w := &tabwriter.Writer{}
+	w.Init(out, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OWNER\tCLUSTER\tCPUS\tMEMORY\tINSTANCES")
+	for _, u := range usage {
+		q := u.Quota
+		cluster := q.Cluster
+		if cluster == "" {
+			cluster = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", q.Owner, cluster,
+			quotaCell(fmt.Sprintf("%g", u.Cpus), fmt.Sprintf("%g", q.Cpus), q.Cpus > 0 && u.Cpus > q.Cpus),
+			quotaCell(fmt.Sprintf("%g", u.Memory), fmt.Sprintf("%g", q.Memory), q.Memory > 0 && u.Memory > q.Memory),
+			quotaCell(fmt.Sprint(u.Instances), fmt.Sprint(q.Instances), q.Instances > 0 && u.Instances > q.Instances))
+	}
+	w.Flush()
+}
+
+// quotaCell formats used/limit, marking it if the limit is exceeded.
+func quotaCell(used, limit string, exceeded bool) string {
+	if limit == "0" {
+		limit = "-"
+	}
+	if exceeded {
+		return used + "/" + limit + "!"
+	}
+	return used + "/" + limit
+}
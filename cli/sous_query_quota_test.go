@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteQuotaUsage(t *testing.T) {
+	out := &bytes.Buffer{}
+	writeQuotaUsage(out, []sous.QuotaUsage{
+		{Quota: sous.Quota{Owner: "team-a", Cpus: 4, Instances: 10}, Cpus: 2.5, Memory: 1024, Instances: 12},
+		{Quota: sous.Quota{Owner: "team-b", Cluster: "cluster-1", Memory: 2048}, Memory: 512, Instances: 2},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"OWNER   CLUSTER    CPUS   MEMORY    INSTANCES",
+		"team-a  *          2.5/4  1024/-    12/10!",
+		"team-b  cluster-1  0/-    512/2048  2/-",
+	}, lines)
+
+	out.Reset()
+	writeQuotaUsage(out, nil)
+	assert.Equal(t, "No quotas.\n", out.String())
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousQuerySchedule is the description of the `sous query schedule` command.
+type SousQuerySchedule struct {
+	config.DeployFilterFlags `inject:"optional"`
+	*sous.ResolveFilter
+	StateManager *graph.ClientStateManager
+	flags        struct {
+		count int
+	}
+}
+
+func init() { QuerySubcommands["schedule"] = &SousQuerySchedule{} }
+
+const sousQueryScheduleHelp = `show when scheduled deployments will next run
+
+usage: sous query schedule [-repo REPO [-offset OFFSET] [-flavor FLAVOR]] [-cluster CLUSTER] [-count N]
+
+Lists the next N times each scheduled deployment in the GDM will run, in the
+time zone of its schedule.`
+
+// Help implements Command on SousQuerySchedule.
+func (*SousQuerySchedule) Help() string { return sousQueryScheduleHelp }
+
+// AddFlags implements AddFlagger on SousQuerySchedule.
+func (sqs *SousQuerySchedule) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sqs.DeployFilterFlags, ScheduleFilterFlagsHelp)
+	fs.IntVar(&sqs.flags.count, "count", 5, "the number of run times to show for each deployment")
+}
+
+// RegisterOn adds options set by flags to the injection graph.
+func (sqs *SousQuerySchedule) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&sqs.DeployFilterFlags)
+}
+
+// Execute implements Executor on SousQuerySchedule.
+func (sqs *SousQuerySchedule) Execute(args []string) cmdr.Result {
+	state, err := sqs.StateManager.ReadState()
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	deployments, err := state.Deployments()
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	scheduled := deployments.Filter(func(d *sous.Deployment) bool {
+		return d.Kind.IsScheduled() && sqs.ResolveFilter.FilterDeployment(d)
+	})
+
+	out := &bytes.Buffer{}
+	if err := writeSchedule(out, scheduled, time.Now(), sqs.flags.count); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return cmdr.SuccessData(out.Bytes())
+}
+
+func writeSchedule(out io.Writer, deployments sous.Deployments, now time.Time, count int) error {
+	snapshot := deployments.Snapshot()
+	if len(snapshot) == 0 {
+		fmt.Fprintln(out, "No scheduled deployments.")
+		return nil
+	}
+	ids := make([]sous.DeploymentID, 0, len(snapshot))
+	for id := range snapshot {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	for _, id := range ids {
+		d := snapshot[id]
+		tz := d.ScheduleTimeZone
+		if tz == "" {
+			tz = "UTC"
+		}
+		fmt.Fprintf(out, "%s (%q, %s)\n", id, d.Schedule, tz)
+		runs, err := d.NextRuns(now, count)
+		if err != nil {
+			return fmt.Errorf("%s: %s", id, err)
+		}
+		if d.IsPaused() {
+			fmt.Fprintln(out, "  paused")
+		}
+		for _, r := range runs {
+			fmt.Fprintf(out, "  %s\n", r.Format("2006-01-02 15:04 MST Mon"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSchedule(t *testing.T) {
+	deployment := func(cluster, schedule, tz string) *sous.Deployment {
+		return &sous.Deployment{
+			ClusterName: cluster,
+			SourceID:    sous.MustNewSourceID("github.com/user/repo", "", "1.0.0"),
+			Kind:        sous.ManifestKindScheduled,
+			DeployConfig: sous.DeployConfig{
+				Schedule:         schedule,
+				ScheduleTimeZone: tz,
+			},
+		}
+	}
+	ds := sous.NewDeployments(
+		deployment("cluster-2", "0 3 * * *", "America/Los_Angeles"),
+		deployment("cluster-1", "*/30 * * * *", ""),
+	)
+	now := time.Date(2018, 6, 1, 10, 10, 0, 0, time.UTC)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeSchedule(out, ds, now, 2))
+	assert.Equal(t, []string{
+		`cluster-1:github.com/user/repo ("*/30 * * * *", UTC)`,
+		"  2018-06-01 10:30 UTC Fri",
+		"  2018-06-01 11:00 UTC Fri",
+		`cluster-2:github.com/user/repo ("0 3 * * *", America/Los_Angeles)`,
+		"  2018-06-02 03:00 PDT Sat",
+		"  2018-06-03 03:00 PDT Sun",
+	}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+
+	out.Reset()
+	require.NoError(t, writeSchedule(out, sous.NewDeployments(), now, 2))
+	assert.Equal(t, "No scheduled deployments.\n", out.String())
+}
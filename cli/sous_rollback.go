@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousRollback is the command description for `sous rollback`.
+type SousRollback struct {
+	SousGraph *graph.SousGraph
+
+	opts graph.DeployActionOpts
+}
+
+func init() { TopLevelCommands["rollback"] = &SousRollback{} }
+
+const sousRollbackHelp = `redeploys the previous known-good version into a particular cluster
+
+usage: sous rollback (options)
+
+sous rollback finds the version deployed to the named cluster before the
+current one, according to the deployment history kept by the server, and
+deploys it. Versions which were automatically rolled back because their
+deploy failed are skipped.
+`
+
+// Help returns the help string for this command.
+func (sr *SousRollback) Help() string { return sousRollbackHelp }
+
+// AddFlags adds the flags for sous rollback.
+func (sr *SousRollback) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sr.opts.DFF, RollbackFilterFlagsHelp)
+
+	fs.BoolVar(&sr.opts.WaitStable, "wait-stable", true,
+		"wait for the deploy to complete before returning (otherwise, use --wait-stable=false)")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sr *SousRollback) Execute(args []string) cmdr.Result {
+	rollback, err := sr.SousGraph.GetRollback(sr.opts)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+
+	if err := rollback.Do(); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	return cmdr.Success("Done.")
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousScale is the command description for `sous scale`.
+type SousScale struct {
+	SousGraph *graph.SousGraph
+
+	opts      graph.DeploymentOperationOpts
+	instances int
+}
+
+func init() { TopLevelCommands["scale"] = &SousScale{} }
+
+const sousScaleHelp = `changes the number of instances of a deployment
+
+usage: sous scale -instances N (options)
+
+sous scale sets NumInstances of the deployment to the named cluster in the
+GDM, and deploys it. It is equivalent to editing the manifest, but changes
+nothing else. Use sous pause to stop all instances.
+`
+
+// Help returns the help string for this command.
+func (ss *SousScale) Help() string { return sousScaleHelp }
+
+// AddFlags adds the flags for sous scale.
+func (ss *SousScale) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &ss.opts.DFF, DeploymentOperationFilterFlagsHelp)
+
+	fs.IntVar(&ss.instances, "instances", 0, "the number of instances to run")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (ss *SousScale) Execute(args []string) cmdr.Result {
+	if ss.instances < 1 {
+		return cmdr.UsageErrorf("-instances must be at least 1")
+	}
+	ss.opts.Operation = "scale"
+	ss.opts.Query = map[string]string{"instances": strconv.Itoa(ss.instances)}
+	return executeDeploymentOperation(ss.SousGraph, ss.opts, fmt.Sprintf("Scaling to %d instances.", ss.instances))
+}
@@ -0,0 +1,27 @@
+package config
+
+type (
+	// AuthConfig configures authentication between Sous clients and servers.
+	// A server with neither TokensFile nor ClientCAFile set trusts the
+	// Sous-User-Name and Sous-User-Email headers, and does not authorize
+	// writes.
+	AuthConfig struct {
+		// Token is the bearer token this client sends to Sous servers.
+		Token string `env:"SOUS_AUTH_TOKEN"`
+		// TokensFile names the file of bearer tokens a server accepts. Each
+		// line holds a token, the email address of its user and optionally
+		// their name, separated by whitespace. Blank lines and lines
+		// starting with # are ignored.
+		TokensFile string `env:"SOUS_AUTH_TOKENS_FILE"`
+		// ClientCAFile names a PEM file of CA certificates. A server
+		// authenticates clients presenting a TLS certificate issued by one
+		// of them as the certificate's first email address, with its common
+		// name as their name.
+		ClientCAFile string `env:"SOUS_AUTH_CLIENT_CA_FILE"`
+	}
+)
+
+// Enabled returns true if a server configured with c authenticates clients.
+func (c AuthConfig) Enabled() bool {
+	return c.TokensFile != "" || c.ClientCAFile != ""
+}
@@ -8,6 +8,10 @@ import (
 	"path"
 
 	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/ext/kubernetes"
+	"github.com/opentable/sous/ext/notify"
+	"github.com/opentable/sous/ext/secrets"
+	"github.com/opentable/sous/ext/simulated"
 	"github.com/opentable/sous/ext/storage"
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/firsterr"
@@ -34,20 +38,47 @@ type (
 		// idea is to use it to transition to DB only and then change the behavior
 		// to be (unconditionally) DatabasePrimary=true.
 		DatabasePrimary bool `env:"SOUS_DATABASE_IS_PRIMARY"`
+		// R11nRetentionMinutes is how long the results of queued
+		// rectifications are kept in the database, so that clients can still
+		// retrieve them after a server restart.
+		R11nRetentionMinutes int `env:"SOUS_R11N_RETENTION_MINUTES"`
 		// SiblingURLs is a temporary measure for setting up a distributed cluster
 		// of sous servers. Each server must be configured with accessible URLs for
 		// all the servers in production, as named by cluster.
 		// (someday this should be replaced with a gossip protocol)
 		SiblingURLs map[string]string `env:"SOUS_SIBLING_URLS"`
+		// AdvertisedURL is the URL at which clients and other servers can
+		// reach this server. It identifies this server when it is elected
+		// leader of a cluster, and defaults to its host name.
+		AdvertisedURL string `env:"SOUS_ADVERTISED_URL"`
+		// TLSCertFile and TLSKeyFile name the PEM encoded certificate and
+		// key of a server. When they are set, the server serves HTTPS, and
+		// asks clients for certificates issued by Auth.ClientCAFile.
+		TLSCertFile string `env:"SOUS_TLS_CERT_FILE"`
+		TLSKeyFile  string `env:"SOUS_TLS_KEY_FILE"`
+		// ShutdownTimeoutSeconds is how long a server waits for queued
+		// rectifications to finish when it is asked to stop.
+		ShutdownTimeoutSeconds int `env:"SOUS_SHUTDOWN_TIMEOUT_SECONDS"`
+		// DriftIntervalSeconds is how often a server compares the deployments
+		// running in its clusters with the intended ones, to report drift.
+		// Zero disables drift detection.
+		DriftIntervalSeconds int `env:"SOUS_DRIFT_INTERVAL_SECONDS"`
 		// BuildStateDir is a directory where information about builds
 		// performed by this user on this machine are stored.
 		BuildStateDir string `env:"SOUS_BUILD_STATE_DIR"`
 		// Docker is the Docker configuration.
 		Docker docker.Config
+		// Kubernetes is the configuration for deploying to Kubernetes clusters.
+		Kubernetes kubernetes.Config
+		// Simulated is the configuration for simulated clusters, which keep
+		// their actual state on local disk.
+		Simulated simulated.Config
 		// Logging is the logging configuration.
 		Logging logging.Config
 		// User identifies the user of this client.
 		User sous.User
+		// Auth configures authentication between clients and servers.
+		Auth AuthConfig
 		// MaxHTTPConcurrencySingularity is the maximum number of concurrent
 		// requests that can be made to a single Singularity instance.
 		MaxHTTPConcurrencySingularity int `env:"MAX_HTTP_CONCURRENCY_SINGULARITY"`
@@ -59,6 +90,13 @@ type (
 		SlackChannel string `env:"SOUS_SLACK_CHANNEL"`
 		// AdditionalSlackChannels that should receive messages
 		AdditionalSlackChannels map[string]string `env:"SOUS_ADDITIONAL_SLACK_CHANNELS"`
+		// Notify configures the webhook and email notifications sent by a
+		// server. Where they, and Slack notifications, are sent is
+		// configured by Defs.Notifications.
+		Notify notify.Config
+		// Secrets configures the provider of the secrets referred to by
+		// "secret://path#key" values in the Env of deployments.
+		Secrets secrets.Config
 	}
 )
 
@@ -80,6 +118,14 @@ func (c Config) Validate() error {
 			return errors.Wrapf(err, "Config.Server")
 		}
 	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("Config.TLSCertFile and Config.TLSKeyFile must be set together")
+	}
+	if c.AdvertisedURL != "" {
+		if err := checkURL(c.AdvertisedURL); err != nil {
+			return errors.Wrapf(err, "Config.AdvertisedURL")
+		}
+	}
 	for n, url := range c.SiblingURLs {
 		if err := checkURL(url); err != nil {
 			return errors.Wrapf(err, "Config.SiblingURLs[%s]", n)
@@ -95,8 +141,13 @@ func (c Config) Validate() error {
 func DefaultConfig() Config {
 	return Config{
 		Docker:                        docker.DefaultConfig(),
+		Kubernetes:                    kubernetes.DefaultConfig(),
+		Simulated:                     simulated.DefaultConfig(),
 		MaxHTTPConcurrencySingularity: 10,
 		PollIntervalForClient:         600,
+		R11nRetentionMinutes:          24 * 60,
+		ShutdownTimeoutSeconds:        60,
+		DriftIntervalSeconds:          300,
 	}
 }
 
@@ -140,11 +191,21 @@ func (c *Config) FillDefaults() error {
 		func(e *error) {
 			*e = EnsureDirExists(c.StateLocation)
 		},
+		func(e *error) {
+			if c.Simulated.Dir == "" {
+				c.Simulated.Dir, *e = dataPath("simulated")
+			}
+		},
 	)
 }
 
 // defaultStateLocation returns the default state location.
 func (*Config) defaultStateLocation() (string, error) {
+	return dataPath("state")
+}
+
+// dataPath returns the path named name in the Sous data directory.
+func dataPath(name string) (string, error) {
 	dataRoot := os.Getenv("XDG_DATA_HOME")
 	if dataRoot == "" {
 		u, err := user.Current()
@@ -153,8 +214,7 @@ func (*Config) defaultStateLocation() (string, error) {
 		}
 		dataRoot = path.Join(u.HomeDir, ".local", "share")
 	}
-	stateLocation := path.Join(dataRoot, "sous", "state")
-	return stateLocation, nil
+	return path.Join(dataRoot, "sous", name), nil
 }
 
 // EnsureDirExists creates the named directory if it does not exist.
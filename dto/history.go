@@ -0,0 +1,12 @@
+package dto
+
+import sous "github.com/opentable/sous/lib"
+
+// HistoryResponse dto used by server to return deployment change history,
+// read by client.
+type HistoryResponse struct {
+	// Changes are the selected changes, most recent first.
+	Changes []sous.DeploymentChange
+	// Outcomes are the selected deploy outcomes, most recent first.
+	Outcomes []sous.DeployOutcome
+}
@@ -0,0 +1,10 @@
+package dto
+
+import sous "github.com/opentable/sous/lib"
+
+// JobHistoryResponse dto used by server to return the recent runs of a job,
+// read by client.
+type JobHistoryResponse struct {
+	// Runs are the runs of the job, most recently started first.
+	Runs []sous.JobRun
+}
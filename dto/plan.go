@@ -0,0 +1,10 @@
+package dto
+
+import sous "github.com/opentable/sous/lib"
+
+// PlanResponse dto used by server to return the changes that rectification
+// would make, read by client.
+type PlanResponse struct {
+	// Changes are the planned changes, ordered by DeploymentID.
+	Changes []sous.PlannedChange
+}
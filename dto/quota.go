@@ -0,0 +1,11 @@
+package dto
+
+import sous "github.com/opentable/sous/lib"
+
+// QuotaResponse dto used by server to return the usage of quotas, read by
+// client.
+type QuotaResponse struct {
+	// Usage is the usage of each selected quota, ordered by owner and
+	// cluster.
+	Usage []sous.QuotaUsage
+}
@@ -8,4 +8,6 @@ type R11nResponse struct {
 	// Pointer here is just to allow nil which is a clearer indication of
 	// "nothing to see here" than a JSON-marshalled zero value would be.
 	Resolution *sous.DiffResolution
+	// Rollout is the progress of a staged rollout, or nil if this is not one.
+	Rollout *sous.RolloutProgress `json:",omitempty"`
 }
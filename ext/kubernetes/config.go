@@ -0,0 +1,18 @@
+package kubernetes
+
+// Config is the configuration for Kubernetes deployers.
+type Config struct {
+	// Namespace is the namespace Sous manages objects in on every
+	// Kubernetes cluster.
+	Namespace string `env:"SOUS_KUBERNETES_NAMESPACE"`
+	// BearerToken, if set, is sent to the API server to authenticate Sous.
+	BearerToken string `env:"SOUS_KUBERNETES_TOKEN"`
+}
+
+// DefaultConfig builds a default configuration, which can be then overridden by
+// client code.
+func DefaultConfig() Config {
+	return Config{
+		Namespace: "default",
+	}
+}
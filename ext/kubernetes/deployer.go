@@ -0,0 +1,386 @@
+package kubernetes
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ClusterKind is the sous.Cluster Kind of clusters managed by this deployer.
+const ClusterKind = "kubernetes"
+
+type (
+	deployer struct {
+		namespace string
+		token     string
+		dryrun    bool
+		secrets   sous.SecretProvider
+		log       logging.LogSink
+	}
+
+	// DeployerOption is an option for configuring Kubernetes deployers.
+	DeployerOption func(*deployer)
+)
+
+// NewDeployer creates a new Kubernetes-based sous.Deployer.
+func NewDeployer(cfg Config, ls logging.LogSink, options ...DeployerOption) sous.Deployer {
+	d := &deployer{namespace: cfg.Namespace, token: cfg.BearerToken, log: ls}
+	if d.namespace == "" {
+		d.namespace = DefaultConfig().Namespace
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	return d
+}
+
+// OptNamespace overrides the configured namespace for this deployer.
+func OptNamespace(ns string) DeployerOption {
+	return func(d *deployer) { d.namespace = ns }
+}
+
+// OptDryRun makes the deployer read from Kubernetes as usual, but only log
+// the changes it would make.
+func OptDryRun() DeployerOption {
+	return func(d *deployer) { d.dryrun = true }
+}
+
+// OptSecretProvider sets the provider that resolves references to secrets in
+// the Env of deployments.
+func OptSecretProvider(p sous.SecretProvider) DeployerOption {
+	return func(d *deployer) { d.secrets = p }
+}
+
+func (r *deployer) buildClient(baseURL string) kubeClient {
+	client := newRESTClient(baseURL, r.namespace, r.token, r.log)
+	if r.dryrun {
+		return dryrunClient{kubeClient: client, log: r.log}
+	}
+	return client
+}
+
+// RunningDeployments collects the Sous-managed Deployments, CronJobs and Jobs
+// from each Kubernetes cluster and returns them as DeployStates.
+func (r *deployer) RunningDeployments(reg sous.Registry, clusters sous.Clusters) (sous.DeployStates, error) {
+	deps := sous.NewDeployStates()
+	urls := map[string]struct{}{}
+	for _, c := range clusters {
+		urls[c.BaseURL] = struct{}{}
+	}
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+	wg.Add(len(urls))
+	for url := range urls {
+		go func(url string) {
+			defer wg.Done()
+			states, err := r.clusterDeployments(reg, clusters, url)
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = errors.Wrapf(err, "getting deployments from %s", url)
+				}
+				errMu.Unlock()
+				return
+			}
+			for _, s := range states {
+				messages.ReportLogFieldsMessage("Adding deployment", logging.DebugLevel, r.log, s)
+				deps.Add(s)
+			}
+		}(url)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		logging.ReportError(r.log, firstErr)
+		return deps, firstErr
+	}
+	return deps, nil
+}
+
+func (r *deployer) clusterDeployments(reg sous.Registry, clusters sous.Clusters, url string) ([]*sous.DeployState, error) {
+	client := r.buildClient(url)
+	var workloads []workload
+
+	dl, err := client.ListDeployments(managedSelector)
+	if err != nil {
+		return nil, err
+	}
+	for i := range dl.Items {
+		workloads = append(workloads, deploymentWorkload(&dl.Items[i]))
+	}
+
+	cjl, err := client.ListCronJobs(managedSelector)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cjl.Items {
+		workloads = append(workloads, cronJobWorkload(&cjl.Items[i]))
+	}
+
+	jl, err := client.ListJobs(managedSelector)
+	if err != nil {
+		return nil, err
+	}
+	for i := range jl.Items {
+		workloads = append(workloads, jobWorkload(&jl.Items[i]))
+	}
+
+	var states []*sous.DeployState
+	for _, w := range workloads {
+		ds, err := buildDeployState(reg, clusters, url, w, r.log)
+		if err != nil {
+			if ignorableObject(err) {
+				messages.ReportLogFieldsMessage("Ignorable object.", logging.DebugLevel, r.log, w.meta.Name)
+			} else {
+				logging.ReportError(r.log, errors.Wrapf(err, "malformed"))
+			}
+			continue
+		}
+		ds.SchedulerURL = r.objectURL(url, w.kind, w.meta.Name)
+		states = append(states, ds)
+	}
+	return states, nil
+}
+
+func (r *deployer) objectURL(baseURL, kind, name string) string {
+	c := newRESTClient(baseURL, r.namespace, "", r.log)
+	switch kind {
+	default:
+		return ""
+	case objectKindDeployment:
+		return c.itemURL(deploymentsPath, name)
+	case objectKindCronJob:
+		return c.itemURL(cronJobsPath, name)
+	case objectKindJob:
+		return c.itemURL(jobsPath, name)
+	}
+}
+
+// Status implements sous.Deployer on deployer.
+func (r *deployer) Status(reg sous.Registry, clusters sous.Clusters, pair *sous.DeployablePair) (*sous.DeployState, error) {
+	name, err := objectName(pair.Post)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := objectKindFor(pair.Post.Deployment.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := pair.Post.Deployment.ClusterName
+	cluster, has := clusters[clusterName]
+	if !has {
+		return nil, errors.Errorf("No cluster found for %q. Known are: %q.", clusterName, clusters.Names())
+	}
+
+	if pair.UUID == uuid.Nil {
+		pair.UUID = uuid.NewV4()
+	}
+
+	client := r.buildClient(cluster.BaseURL)
+	var w workload
+	switch kind {
+	case objectKindDeployment:
+		d, err := client.GetDeployment(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting deployment")
+		}
+		w = deploymentWorkload(d)
+	case objectKindCronJob:
+		cj, err := client.GetCronJob(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting cronjob")
+		}
+		w = cronJobWorkload(cj)
+	case objectKindJob:
+		j, err := client.GetJob(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting job")
+		}
+		w = jobWorkload(j)
+	}
+
+	ds, err := buildDeployState(reg, clusters, cluster.BaseURL, w, r.log)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting object state")
+	}
+	ds.SchedulerURL = r.objectURL(cluster.BaseURL, kind, name)
+	return ds, nil
+}
+
+// Rectify invokes actions to ensure that the real world matches pair.Post,
+// given that it currently matches pair.Prior.
+func (r *deployer) Rectify(pair *sous.DeployablePair) sous.DiffResolution {
+	if pair.UUID == uuid.Nil {
+		pair.UUID = uuid.NewV4()
+	}
+
+	switch k := pair.Kind(); k {
+	default:
+		panic(fmt.Sprintf("unrecognised kind %q", k))
+	case sous.SameKind:
+		resolution := pair.SameResolution()
+		if pair.Post.Status == sous.DeployStatusFailed {
+			resolution.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+		}
+		return resolution
+	case sous.AddedKind:
+		result := sous.DiffResolution{DeploymentID: pair.ID()}
+		if err := r.rectifyCreate(pair); err != nil {
+			result.Desc = "not created"
+			result.Error = sous.WrapResolveError(&sous.CreateError{Deployment: pair.Post.Deployment.Clone(), Err: err})
+		} else {
+			result.Desc = sous.CreateDiff
+		}
+		messages.ReportLogFieldsMessage("Result of create", logging.InformationLevel, r.log, result)
+		return result
+	case sous.RemovedKind:
+		// As with Singularity, Sous does not delete objects for removed
+		// manifests; their owners are expected to clean up.
+		messages.ReportLogFieldsMessage("Rectify not deleting object", logging.WarningLevel, r.log, pair.ID())
+		return sous.DiffResolution{DeploymentID: pair.ID(), Desc: sous.DeleteDiff}
+	case sous.ModifiedKind:
+		result := sous.DiffResolution{DeploymentID: pair.ID()}
+		if err := r.rectifyModify(pair); err != nil {
+			dp := &sous.DeploymentPair{
+				Prior: pair.Prior.Deployment.Clone(),
+				Post:  pair.Post.Deployment.Clone(),
+			}
+			result.Error = sous.WrapResolveError(&sous.ChangeError{Deployments: dp, Err: err})
+			result.Desc = "not updated"
+		} else if pair.Prior.Status == sous.DeployStatusFailed || pair.Post.Status == sous.DeployStatusFailed {
+			result.Desc = sous.ModifyDiff
+			result.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+		} else {
+			result.Desc = sous.ModifyDiff
+		}
+		messages.ReportLogFieldsMessage("Result of modify", logging.InformationLevel, r.log, result)
+		return result
+	}
+}
+
+func rectifyRecover(d interface{}, f string, err *error, log logging.LogSink) {
+	if r := recover(); r != nil {
+		stack := string(debug.Stack())
+		messages.ReportLogFieldsMessage("Panic", logging.WarningLevel, log, d, f, r, stack)
+		*err = errors.Errorf("Panicked: %s; stack trace:\n%s", r, stack)
+	}
+}
+
+func (r *deployer) rectifyCreate(pair *sous.DeployablePair) (err error) {
+	defer rectifyRecover(pair, "rectifyCreate", &err, r.log)
+	name, err := objectName(pair.Post)
+	if err != nil {
+		return err
+	}
+	kind, err := objectKindFor(pair.Post.Deployment.Kind)
+	if err != nil {
+		return err
+	}
+	client := r.buildClient(pair.Post.Deployment.Cluster.BaseURL)
+
+	switch kind {
+	default:
+		return errors.Errorf("cannot create %s", kind)
+	case objectKindDeployment:
+		d, err := buildDeployment(pair.Post, name, r.namespace)
+		if err != nil {
+			return err
+		}
+		if err := resolveSecrets(&d.Spec.Template, r.secrets); err != nil {
+			return err
+		}
+		return client.CreateDeployment(d)
+	case objectKindCronJob:
+		cj, err := buildCronJob(pair.Post, name, r.namespace)
+		if err != nil {
+			return err
+		}
+		if err := resolveSecrets(&cj.Spec.JobTemplate.Spec.Template, r.secrets); err != nil {
+			return err
+		}
+		return client.CreateCronJob(cj)
+	case objectKindJob:
+		j, err := buildJob(pair.Post, name, r.namespace)
+		if err != nil {
+			return err
+		}
+		if err := resolveSecrets(&j.Spec.Template, r.secrets); err != nil {
+			return err
+		}
+		return client.CreateJob(j)
+	}
+}
+
+func (r *deployer) rectifyModify(pair *sous.DeployablePair) (err error) {
+	defer rectifyRecover(pair, "rectifyModify", &err, r.log)
+
+	data, ok := objectData(pair.ExecutorData)
+	if !ok {
+		return errors.Errorf("Modification record %#v doesn't contain Kubernetes compatible data: was %T\n\t%#v", pair.ID(), pair.ExecutorData, pair)
+	}
+	kind, err := objectKindFor(pair.Post.Deployment.Kind)
+	if err != nil {
+		return err
+	}
+	if kind != data.kind {
+		return errors.Errorf("cannot change %s %s into a %s; remove it from the cluster first", data.kind, data.name, kind)
+	}
+	name := data.name
+	client := r.buildClient(pair.Post.Deployment.Cluster.BaseURL)
+
+	switch kind {
+	default:
+		return errors.Errorf("cannot modify %s", kind)
+	case objectKindDeployment:
+		current, err := client.GetDeployment(name)
+		if err != nil {
+			return err
+		}
+		d, err := buildDeployment(pair.Post, name, r.namespace)
+		if err != nil {
+			return err
+		}
+		if err := resolveSecrets(&d.Spec.Template, r.secrets); err != nil {
+			return err
+		}
+		d.Metadata.ResourceVersion = current.Metadata.ResourceVersion
+		return client.UpdateDeployment(d)
+	case objectKindCronJob:
+		current, err := client.GetCronJob(name)
+		if err != nil {
+			return err
+		}
+		cj, err := buildCronJob(pair.Post, name, r.namespace)
+		if err != nil {
+			return err
+		}
+		if err := resolveSecrets(&cj.Spec.JobTemplate.Spec.Template, r.secrets); err != nil {
+			return err
+		}
+		cj.Metadata.ResourceVersion = current.Metadata.ResourceVersion
+		return client.UpdateCronJob(cj)
+	case objectKindJob:
+		// Job pod templates are immutable, so a modified one-off job is run
+		// afresh.
+		j, err := buildJob(pair.Post, name, r.namespace)
+		if err != nil {
+			return err
+		}
+		if err := resolveSecrets(&j.Spec.Template, r.secrets); err != nil {
+			return err
+		}
+		if err := client.DeleteJob(name); err != nil && !isNotFound(err) {
+			return err
+		}
+		return client.CreateJob(j)
+	}
+}
@@ -0,0 +1,377 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/opentable/sous/ext/docker"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labelRegistry is a sous.Registry which reports Sous docker labels for
+// images it has been told about.
+type labelRegistry struct {
+	*sous.DummyRegistry
+	labels map[string]map[string]string
+}
+
+func newLabelRegistry() *labelRegistry {
+	return &labelRegistry{
+		DummyRegistry: sous.NewDummyRegistry(),
+		labels:        map[string]map[string]string{},
+	}
+}
+
+func (r *labelRegistry) ImageLabels(image string) (map[string]string, error) {
+	return r.labels[image], nil
+}
+
+func (r *labelRegistry) artifactFor(sid sous.SourceID) *sous.BuildArtifact {
+	image := "docker.example.com/" + strings.Replace(sid.String(), ",", "_", -1)
+	r.labels[image] = map[string]string{
+		docker.DockerRepoLabel:     sid.Location.Repo,
+		docker.DockerPathLabel:     sid.Location.Dir,
+		docker.DockerVersionLabel:  sid.Version.String(),
+		docker.DockerRevisionLabel: "",
+	}
+	return &sous.BuildArtifact{DigestReference: image, Type: "docker"}
+}
+
+func testCluster(baseURL string) sous.Clusters {
+	return sous.Clusters{
+		"kube": &sous.Cluster{Name: "kube", Kind: ClusterKind, BaseURL: baseURL},
+	}
+}
+
+func testDeployment(clusters sous.Clusters, kind sous.ManifestKind) *sous.Deployment {
+	return &sous.Deployment{
+		SourceID:    sous.MustNewSourceID("github.com/opentable/example", "", "1.2.3"),
+		ClusterName: "kube",
+		Cluster:     clusters["kube"],
+		Kind:        kind,
+		Owners:      sous.OwnerSet{"sam@example.com": struct{}{}},
+		DeployConfig: sous.DeployConfig{
+			NumInstances: 2,
+			Env:          sous.Env{"GREETING": "hello"},
+			Metadata:     sous.Metadata{"team": "sous"},
+			Resources:    sous.Resources{"cpus": "0.1", "memory": "256", "ports": "2"},
+			Volumes:      sous.Volumes{{Host: "/srv/data", Container: "/data", Mode: sous.ReadOnly}},
+			Startup: sous.Startup{
+				Timeout:              300,
+				ConnectDelay:         10,
+				CheckReadyProtocol:   "HTTP",
+				CheckReadyURIPath:    "/health",
+				CheckReadyPortIndex:  1,
+				CheckReadyURITimeout: 5,
+				CheckReadyInterval:   3,
+				CheckReadyRetries:    20,
+			},
+		},
+	}
+}
+
+func setupDeployer(t *testing.T, options ...DeployerOption) (*fakeAPIServer, sous.Deployer, *labelRegistry, sous.Clusters) {
+	api := newFakeAPIServer()
+	ls, _ := logging.NewLogSinkSpy()
+	dep := NewDeployer(DefaultConfig(), ls, options...)
+	return api, dep, newLabelRegistry(), testCluster(api.URL)
+}
+
+func createPair(reg *labelRegistry, d *sous.Deployment) *sous.DeployablePair {
+	pair := &sous.DeployablePair{
+		Post: &sous.Deployable{Deployment: d, BuildArtifact: reg.artifactFor(d.SourceID)},
+	}
+	pair.SetID(d.ID())
+	return pair
+}
+
+func TestMakeObjectName(t *testing.T) {
+	label := regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	ids := []sous.DeploymentID{
+		{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/user/repo"}}, Cluster: "some-cluster"},
+		{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/user/repo", Dir: "some/dir"}}, Cluster: "some-cluster"},
+		{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/user/repo"}, Flavor: "Tasty_Flavor"}, Cluster: "some-cluster"},
+		{ManifestID: sous.ManifestID{Source: sous.SourceLocation{
+			Repo: "github.com/ihaveanincrediblylongname/andilikemyprojectstohaveincrediblylongnamestoo",
+			Dir:  "and/also/i/bury/my/services/super/deep/in/the/build/tree",
+		}, Flavor: "wellwehavetohaveaflavor"}, Cluster: "foo"},
+	}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		name, err := MakeObjectName(id)
+		require.NoError(t, err)
+		assert.True(t, label.MatchString(name), "%q is not a DNS label", name)
+		assert.True(t, len(name) <= maxObjectNameLen, "%q is too long", name)
+		assert.False(t, seen[name], "%q is duplicated", name)
+		seen[name] = true
+	}
+}
+
+func TestDeployer_CreateService(t *testing.T) {
+	api, dep, reg, clusters := setupDeployer(t)
+	defer api.Close()
+
+	d := testDeployment(clusters, sous.ManifestKindService)
+	res := dep.Rectify(createPair(reg, d))
+	require.Nil(t, res.Error)
+	assert.Equal(t, sous.CreateDiff, res.Desc)
+
+	name, err := MakeObjectName(d.ID())
+	require.NoError(t, err)
+	k := &Deployment{}
+	require.True(t, api.get(deploymentsPath, name, k))
+	assert.EqualValues(t, 2, *k.Spec.Replicas)
+	c := k.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "100m", c.Resources.Requests["cpu"])
+	assert.Equal(t, "256Mi", c.Resources.Limits["memory"])
+	assert.EqualValues(t, 8081, c.ReadinessProbe.HTTPGet.Port)
+	assert.Contains(t, c.Env, EnvVar{Name: "PORT1", Value: "8081"})
+
+	states, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	ds, ok := states.Get(d.ID())
+	require.True(t, ok)
+	assert.Equal(t, sous.DeployStatusPending, ds.Status)
+	different, diffs := d.Diff(&ds.Deployment)
+	assert.False(t, different, "%v", diffs)
+
+	k.Status = DeploymentStatus{ObservedGeneration: k.Metadata.Generation, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2}
+	api.put(deploymentsPath, name, k)
+	states, err = dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	ds, ok = states.Get(d.ID())
+	require.True(t, ok)
+	assert.Equal(t, sous.DeployStatusActive, ds.Status)
+}
+
+func TestDeployer_CreateScheduledAndOnDemand(t *testing.T) {
+	api, dep, reg, clusters := setupDeployer(t)
+	defer api.Close()
+
+	sched := testDeployment(clusters, sous.ManifestKindScheduled)
+	sched.Schedule = "*/5 * * * *"
+	sched.Startup = sous.Startup{SkipCheck: true}
+	require.Nil(t, dep.Rectify(createPair(reg, sched)).Error)
+
+	onDemand := testDeployment(clusters, sous.ManifestKindOnDemand)
+	onDemand.Flavor = "manual"
+	onDemand.Startup = sous.Startup{SkipCheck: true}
+	require.Nil(t, dep.Rectify(createPair(reg, onDemand)).Error)
+
+	name, _ := MakeObjectName(sched.ID())
+	cj := &CronJob{}
+	require.True(t, api.get(cronJobsPath, name, cj))
+	assert.Equal(t, "batch/v1", cj.APIVersion)
+	assert.Equal(t, "*/5 * * * *", cj.Spec.Schedule)
+	assert.False(t, *cj.Spec.Suspend)
+
+	name, _ = MakeObjectName(onDemand.ID())
+	require.True(t, api.get(cronJobsPath, name, cj))
+	assert.True(t, *cj.Spec.Suspend)
+
+	states, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	assert.Equal(t, 2, states.Len())
+	for _, d := range []*sous.Deployment{sched, onDemand} {
+		ds, ok := states.Get(d.ID())
+		require.True(t, ok)
+		different, diffs := d.Diff(&ds.Deployment)
+		assert.False(t, different, "%v", diffs)
+	}
+}
+
+func TestDeployer_CreateTCPAndCommandChecks(t *testing.T) {
+	api, dep, reg, clusters := setupDeployer(t)
+	defer api.Close()
+
+	tcp := testDeployment(clusters, sous.ManifestKindService)
+	tcp.Flavor = "tcp"
+	tcp.Startup.CheckReadyProtocol = "TCP"
+	command := testDeployment(clusters, sous.ManifestKindWorker)
+	command.Flavor = "command"
+	command.Startup.CheckReadyProtocol = "COMMAND"
+	command.Startup.CheckReadyCommand = []string{"grpc-health-probe", "-addr=:8081"}
+
+	probe := func(d *sous.Deployment) *Probe {
+		require.Nil(t, dep.Rectify(createPair(reg, d)).Error)
+		name, err := MakeObjectName(d.ID())
+		require.NoError(t, err)
+		k := &Deployment{}
+		require.True(t, api.get(deploymentsPath, name, k))
+		return k.Spec.Template.Spec.Containers[0].ReadinessProbe
+	}
+	p := probe(tcp)
+	assert.Nil(t, p.HTTPGet)
+	assert.EqualValues(t, 8081, p.TCPSocket.Port)
+	p = probe(command)
+	assert.Nil(t, p.HTTPGet)
+	assert.Equal(t, []string{"grpc-health-probe", "-addr=:8081"}, p.Exec.Command)
+
+	states, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	for _, d := range []*sous.Deployment{tcp, command} {
+		ds, ok := states.Get(d.ID())
+		require.True(t, ok)
+		different, diffs := d.Diff(&ds.Deployment)
+		assert.False(t, different, "%v", diffs)
+	}
+}
+
+func TestDeployer_Modify(t *testing.T) {
+	api, dep, reg, clusters := setupDeployer(t)
+	defer api.Close()
+
+	d := testDeployment(clusters, sous.ManifestKindService)
+	require.Nil(t, dep.Rectify(createPair(reg, d)).Error)
+
+	states, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	ds, ok := states.Get(d.ID())
+	require.True(t, ok)
+
+	post := d.Clone()
+	post.NumInstances = 5
+	pair := &sous.DeployablePair{
+		Prior:        &sous.Deployable{Deployment: &ds.Deployment, Status: ds.Status},
+		Post:         &sous.Deployable{Deployment: post, BuildArtifact: reg.artifactFor(post.SourceID)},
+		ExecutorData: ds.ExecutorData,
+	}
+	pair.SetID(d.ID())
+	res := dep.Rectify(pair)
+	require.Nil(t, res.Error)
+	assert.Equal(t, sous.ModifyDiff, res.Desc)
+
+	name, _ := MakeObjectName(d.ID())
+	k := &Deployment{}
+	require.True(t, api.get(deploymentsPath, name, k))
+	assert.EqualValues(t, 5, *k.Spec.Replicas)
+}
+
+func TestDeployer_Paused(t *testing.T) {
+	api, dep, reg, clusters := setupDeployer(t)
+	defer api.Close()
+
+	paused := true
+	service := testDeployment(clusters, sous.ManifestKindService)
+	service.Paused = &paused
+	sched := testDeployment(clusters, sous.ManifestKindScheduled)
+	sched.Flavor = "nightly"
+	sched.Schedule = "*/5 * * * *"
+	sched.Startup = sous.Startup{SkipCheck: true}
+	sched.Paused = &paused
+	require.Nil(t, dep.Rectify(createPair(reg, service)).Error)
+	require.Nil(t, dep.Rectify(createPair(reg, sched)).Error)
+
+	name, _ := MakeObjectName(service.ID())
+	k := &Deployment{}
+	require.True(t, api.get(deploymentsPath, name, k))
+	assert.EqualValues(t, 0, *k.Spec.Replicas)
+	name, _ = MakeObjectName(sched.ID())
+	cj := &CronJob{}
+	require.True(t, api.get(cronJobsPath, name, cj))
+	assert.True(t, *cj.Spec.Suspend)
+
+	states, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	for _, d := range []*sous.Deployment{service, sched} {
+		ds, ok := states.Get(d.ID())
+		require.True(t, ok)
+		assert.True(t, ds.IsPaused())
+		different, diffs := d.Diff(&ds.Deployment)
+		assert.False(t, different, "%v", diffs)
+	}
+
+	ds, _ := states.Get(service.ID())
+	post := service.Clone()
+	post.Paused = nil
+	pair := &sous.DeployablePair{
+		Prior:        &sous.Deployable{Deployment: &ds.Deployment, Status: ds.Status},
+		Post:         &sous.Deployable{Deployment: post, BuildArtifact: reg.artifactFor(post.SourceID)},
+		ExecutorData: ds.ExecutorData,
+	}
+	pair.SetID(service.ID())
+	require.Nil(t, dep.Rectify(pair).Error)
+	name, _ = MakeObjectName(service.ID())
+	k = &Deployment{}
+	require.True(t, api.get(deploymentsPath, name, k))
+	assert.EqualValues(t, 2, *k.Spec.Replicas)
+	assert.NotContains(t, k.Metadata.Annotations, PausedAnnotation)
+}
+
+func TestDeployer_StatusFailed(t *testing.T) {
+	api, dep, reg, clusters := setupDeployer(t)
+	defer api.Close()
+
+	d := testDeployment(clusters, sous.ManifestKindService)
+	pair := createPair(reg, d)
+	require.Nil(t, dep.Rectify(pair).Error)
+
+	name, _ := MakeObjectName(d.ID())
+	k := &Deployment{}
+	require.True(t, api.get(deploymentsPath, name, k))
+	k.Status.Conditions = []DeploymentCondition{{
+		Type:    "Progressing",
+		Status:  "False",
+		Reason:  progressDeadlineExceeded,
+		Message: "ReplicaSet has timed out progressing.",
+	}}
+	api.put(deploymentsPath, name, k)
+
+	ds, err := dep.Status(reg, clusters, pair)
+	require.NoError(t, err)
+	assert.Equal(t, sous.DeployStatusFailed, ds.Status)
+	assert.Contains(t, ds.ExecutorMessage, "timed out")
+	assert.Contains(t, ds.SchedulerURL, name)
+}
+
+type secretsProvider map[string]string
+
+func (p secretsProvider) Secret(ref sous.SecretRef) (string, error) {
+	return p[ref.String()], nil
+}
+
+func TestDeployer_CreateSecrets(t *testing.T) {
+	api, dep, reg, clusters := setupDeployer(t)
+	defer api.Close()
+
+	d := testDeployment(clusters, sous.ManifestKindService)
+	d.Env["PASSWORD"] = "secret://db/prod#password"
+	res := dep.Rectify(createPair(reg, d))
+	assert.NotNil(t, res.Error, "secrets cannot be resolved without a provider")
+
+	api, dep, reg, clusters = setupDeployer(t, OptSecretProvider(secretsProvider{"secret://db/prod#password": "hunter2"}))
+	defer api.Close()
+	d = testDeployment(clusters, sous.ManifestKindService)
+	d.Env["PASSWORD"] = "secret://db/prod#password"
+	require.Nil(t, dep.Rectify(createPair(reg, d)).Error)
+
+	name, err := MakeObjectName(d.ID())
+	require.NoError(t, err)
+	k := &Deployment{}
+	require.True(t, api.get(deploymentsPath, name, k))
+	assert.Contains(t, k.Spec.Template.Spec.Containers[0].Env, EnvVar{Name: "PASSWORD", Value: "hunter2"})
+	assert.Equal(t, "secret://db/prod#password", k.Metadata.Annotations[sous.SecretEnvLabel+"PASSWORD"])
+
+	states, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	ds, ok := states.Get(d.ID())
+	require.True(t, ok)
+	assert.Equal(t, "secret://db/prod#password", ds.Env["PASSWORD"])
+	different, diffs := d.Diff(&ds.Deployment)
+	assert.False(t, different, "%v", diffs)
+}
+
+func TestObjectData_storedJSON(t *testing.T) {
+	encoded, err := json.Marshal(&kubeObjectData{kind: objectKindDeployment, name: "example"})
+	require.NoError(t, err)
+	data, ok := objectData(json.RawMessage(encoded))
+	require.True(t, ok)
+	assert.Equal(t, kubeObjectData{kind: objectKindDeployment, name: "example"}, *data)
+	_, ok = objectData(json.RawMessage(`{}`))
+	assert.False(t, ok)
+}
@@ -0,0 +1,342 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opentable/sous/ext/docker"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/pkg/errors"
+)
+
+type (
+	// workload is the part of a Kubernetes object that a sous.Deployment is
+	// read back from, regardless of object kind.
+	workload struct {
+		kind      string
+		meta      ObjectMeta
+		template  PodTemplateSpec
+		instances int
+		schedule  string
+		timeZone  string
+		status    sous.DeployStatus
+		message   string
+	}
+
+	nonSousError struct {
+		name string
+	}
+
+	notThisClusterError struct {
+		foundClusterName        string
+		responsibleClusterNames []string
+	}
+
+	malformedObject struct {
+		message string
+	}
+)
+
+func (nse nonSousError) Error() string {
+	return fmt.Sprintf("%s is not a Sous Kubernetes object.", nse.name)
+}
+
+func (ntc notThisClusterError) Error() string {
+	return fmt.Sprintf("%s does not belong to this Sous server %#v.",
+		ntc.foundClusterName, ntc.responsibleClusterNames)
+}
+
+func (mo malformedObject) Error() string {
+	return mo.message
+}
+
+func ignorableObject(err error) bool {
+	switch errors.Cause(err).(type) {
+	case nonSousError, notThisClusterError:
+		return true
+	}
+	return false
+}
+
+// progressDeadlineExceeded is the reason Kubernetes gives on the Progressing
+// condition of a Deployment which has failed to roll out.
+const progressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// defaultBackoffLimit is the Kubernetes default for JobSpec.BackoffLimit.
+const defaultBackoffLimit = 6
+
+func deploymentWorkload(d *Deployment) workload {
+	w := workload{
+		kind:     objectKindDeployment,
+		meta:     d.Metadata,
+		template: d.Spec.Template,
+	}
+	if d.Spec.Replicas != nil {
+		w.instances = int(*d.Spec.Replicas)
+	}
+
+	st := d.Status
+	for _, c := range st.Conditions {
+		if c.Type == "Progressing" && c.Reason == progressDeadlineExceeded {
+			w.status = sous.DeployStatusFailed
+			w.message = fmt.Sprintf("Deploy failure: %q", c.Message)
+			return w
+		}
+	}
+	if st.ObservedGeneration >= d.Metadata.Generation &&
+		int(st.UpdatedReplicas) == w.instances &&
+		int(st.AvailableReplicas) == w.instances &&
+		int(st.Replicas) == w.instances {
+		w.status = sous.DeployStatusActive
+	} else {
+		w.status = sous.DeployStatusPending
+	}
+	return w
+}
+
+func cronJobWorkload(cj *CronJob) workload {
+	w := workload{
+		kind:     objectKindCronJob,
+		meta:     cj.Metadata,
+		template: cj.Spec.JobTemplate.Spec.Template,
+		schedule: cj.Spec.Schedule,
+		timeZone: cj.Spec.TimeZone,
+		// Once created, a CronJob is in the hands of the Kubernetes scheduler.
+		status: sous.DeployStatusActive,
+	}
+	if p := cj.Spec.JobTemplate.Spec.Parallelism; p != nil {
+		w.instances = int(*p)
+	}
+	return w
+}
+
+func jobWorkload(j *Job) workload {
+	w := workload{
+		kind:     objectKindJob,
+		meta:     j.Metadata,
+		template: j.Spec.Template,
+	}
+	if p := j.Spec.Parallelism; p != nil {
+		w.instances = int(*p)
+	}
+	limit := int32(defaultBackoffLimit)
+	if j.Spec.BackoffLimit != nil {
+		limit = *j.Spec.BackoffLimit
+	}
+	switch {
+	default:
+		w.status = sous.DeployStatusPending
+	case j.Status.Succeeded > 0:
+		w.status = sous.DeployStatusActive
+	case j.Status.Failed > limit:
+		w.status = sous.DeployStatusFailed
+		w.message = fmt.Sprintf("Deploy failure: job %s failed %d times", j.Metadata.Name, j.Status.Failed)
+	}
+	return w
+}
+
+// buildDeployState collects the data for a sous.DeployState from a
+// Kubernetes object read from the API server at baseURL.
+func buildDeployState(reg sous.ImageLabeller, clusters sous.Clusters, baseURL string, w workload, log logging.LogSink) (*sous.DeployState, error) {
+	messages.ReportLogFieldsMessage("Build deploy state", logging.ExtraDebug1Level, log, w.kind, w.meta.Name)
+	ann := w.meta.Annotations
+	clusterName, ok := ann[sous.ClusterNameLabel]
+	if !ok || w.meta.Labels[ManagedLabel] != "true" {
+		return nil, nonSousError{w.meta.Name}
+	}
+	if _, known := clusters[clusterName]; !known {
+		return nil, notThisClusterError{clusterName, clusters.Names()}
+	}
+	if len(w.template.Spec.Containers) != 1 {
+		return nil, malformedObject{fmt.Sprintf("%s %s has %d containers, expected 1", w.kind, w.meta.Name, len(w.template.Spec.Containers))}
+	}
+	container := w.template.Spec.Containers[0]
+
+	ds := &sous.DeployState{
+		Status:          w.status,
+		ExecutorMessage: w.message,
+		ExecutorData:    &kubeObjectData{kind: w.kind, name: w.meta.Name},
+	}
+	dep := &ds.Deployment
+	dep.ClusterName = clusterName
+	dep.Cluster = clusters[clusterName].Clone()
+	dep.Cluster.BaseURL = baseURL
+	dep.Flavor = ann[sous.FlavorLabel]
+	dep.Kind = sous.ManifestKind(ann[KindAnnotation])
+	if expected, err := objectKindFor(dep.Kind); err != nil || expected != w.kind {
+		return nil, malformedObject{fmt.Sprintf("%s %s has kind annotation %q", w.kind, w.meta.Name, dep.Kind)}
+	}
+	if dep.Kind == sous.ManifestKindScheduled {
+		dep.Schedule = w.schedule
+		dep.ScheduleTimeZone = w.timeZone
+	}
+
+	dep.Owners = sous.NewOwnerSet()
+	for _, o := range strings.Split(ann[OwnersAnnotation], ",") {
+		if o != "" {
+			dep.Owners.Add(o)
+		}
+	}
+
+	labels, err := reg.ImageLabels(container.Image)
+	if err != nil {
+		return nil, malformedObject{err.Error()}
+	}
+	dep.SourceID, err = docker.SourceIDFromLabels(labels)
+	if err != nil {
+		return nil, errors.Wrapf(malformedObject{err.Error()}, "for %s %s", w.kind, w.meta.Name)
+	}
+
+	dep.NumInstances = w.instances
+	if n, paused := ann[PausedAnnotation]; paused {
+		instances, err := strconv.Atoi(n)
+		if err != nil {
+			return nil, malformedObject{fmt.Sprintf("%s %s has bad %s annotation: %s", w.kind, w.meta.Name, PausedAnnotation, err)}
+		}
+		dep.NumInstances = instances
+		dep.Paused = &paused
+	}
+	if err := unpackContainer(&dep.DeployConfig, container, w.template.Spec.Volumes); err != nil {
+		return nil, errors.Wrapf(err, "for %s %s", w.kind, w.meta.Name)
+	}
+	// Variables resolved from secrets are restored to their references, so
+	// that their values are neither compared with the GDM nor logged.
+	for key, ref := range ann {
+		if name := strings.TrimPrefix(key, sous.SecretEnvLabel); name != key {
+			dep.DeployConfig.Env[name] = ref
+		}
+	}
+	if md := ann[MetadataAnnotation]; md != "" && md != "null" {
+		if err := json.Unmarshal([]byte(md), &dep.DeployConfig.Metadata); err != nil {
+			return nil, malformedObject{fmt.Sprintf("%s %s has bad %s annotation: %s", w.kind, w.meta.Name, MetadataAnnotation, err)}
+		}
+	}
+	if err := unpackStartup(&dep.DeployConfig.Startup, container.ReadinessProbe, ann[StartupAnnotation]); err != nil {
+		return nil, errors.Wrapf(err, "for %s %s", w.kind, w.meta.Name)
+	}
+	return ds, nil
+}
+
+func unpackContainer(dc *sous.DeployConfig, c Container, vols []Volume) error {
+	portEnv := map[string]string{}
+	for i, p := range c.Ports {
+		portEnv[fmt.Sprintf("PORT%d", i)] = fmt.Sprintf("%d", p.ContainerPort)
+	}
+
+	dc.Env = sous.Env{}
+	for _, e := range c.Env {
+		if portEnvName.MatchString(e.Name) && portEnv[e.Name] == e.Value {
+			continue
+		}
+		dc.Env[e.Name] = e.Value
+	}
+
+	cpus, err := parseCPU(c.Resources.Requests["cpu"])
+	if err != nil {
+		return malformedObject{err.Error()}
+	}
+	mem, err := parseMemoryMB(c.Resources.Requests["memory"])
+	if err != nil {
+		return malformedObject{err.Error()}
+	}
+	dc.Resources = sous.Resources{
+		"cpus":   fmt.Sprintf("%f", cpus),
+		"memory": fmt.Sprintf("%f", mem),
+		"ports":  fmt.Sprintf("%d", len(c.Ports)),
+	}
+
+	hostPaths := map[string]string{}
+	for _, v := range vols {
+		if v.HostPath != nil {
+			hostPaths[v.Name] = v.HostPath.Path
+		}
+	}
+	for _, m := range c.VolumeMounts {
+		host, ok := hostPaths[m.Name]
+		if !ok {
+			return malformedObject{fmt.Sprintf("volume mount %q is not a host path volume", m.Name)}
+		}
+		mode := sous.ReadWrite
+		if m.ReadOnly {
+			mode = sous.ReadOnly
+		}
+		dc.Volumes = append(dc.Volumes, &sous.Volume{Host: host, Container: m.MountPath, Mode: mode})
+	}
+	return nil
+}
+
+func unpackStartup(s *sous.Startup, probe *Probe, extrasJSON string) error {
+	if probe == nil || probe.HTTPGet == nil && probe.TCPSocket == nil && probe.Exec == nil {
+		s.SkipCheck = true
+		return nil
+	}
+	extras := startupExtras{}
+	if extrasJSON != "" {
+		if err := json.Unmarshal([]byte(extrasJSON), &extras); err != nil {
+			return malformedObject{fmt.Sprintf("bad %s annotation: %s", StartupAnnotation, err)}
+		}
+	}
+	s.Timeout = extras.Timeout
+	s.ConnectInterval = extras.ConnectInterval
+	s.CheckReadyFailureStatuses = extras.CheckReadyFailureStatuses
+
+	s.ConnectDelay = int(probe.InitialDelaySeconds)
+	switch {
+	case probe.HTTPGet != nil:
+		s.CheckReadyProtocol = probe.HTTPGet.Scheme
+		s.CheckReadyURIPath = probe.HTTPGet.Path
+		s.CheckReadyPortIndex = int(probe.HTTPGet.Port) - FirstContainerPort
+	case probe.TCPSocket != nil:
+		s.CheckReadyProtocol = "TCP"
+		s.CheckReadyURIPath = extras.CheckReadyURIPath
+		s.CheckReadyPortIndex = int(probe.TCPSocket.Port) - FirstContainerPort
+	default:
+		s.CheckReadyProtocol = "COMMAND"
+		s.CheckReadyURIPath = extras.CheckReadyURIPath
+		s.CheckReadyPortIndex = extras.CheckReadyPortIndex
+		s.CheckReadyCommand = probe.Exec.Command
+	}
+	s.CheckReadyURITimeout = int(probe.TimeoutSeconds)
+	s.CheckReadyInterval = int(probe.PeriodSeconds)
+	s.CheckReadyRetries = int(probe.FailureThreshold)
+	return nil
+}
+
+// parseCPU parses a Kubernetes CPU quantity into a number of CPUs.
+func parseCPU(q string) (float64, error) {
+	if strings.HasSuffix(q, "m") {
+		m, err := strconv.ParseFloat(strings.TrimSuffix(q, "m"), 64)
+		return m / 1000, err
+	}
+	return strconv.ParseFloat(q, 64)
+}
+
+var memorySuffixes = []struct {
+	suffix string
+	mb     float64
+}{
+	{"Ki", 1.0 / 1024},
+	{"Mi", 1},
+	{"Gi", 1024},
+	{"Ti", 1024 * 1024},
+	{"k", 1000.0 / (1024 * 1024)},
+	{"M", 1000.0 * 1000 / (1024 * 1024)},
+	{"G", 1000.0 * 1000 * 1000 / (1024 * 1024)},
+}
+
+// parseMemoryMB parses a Kubernetes memory quantity into megabytes (MiB, to
+// match Singularity's MemoryMb).
+func parseMemoryMB(q string) (float64, error) {
+	for _, s := range memorySuffixes {
+		if strings.HasSuffix(q, s.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(q, s.suffix), 64)
+			return n * s.mb, err
+		}
+	}
+	n, err := strconv.ParseFloat(q, 64)
+	return n / (1024 * 1024), err
+}
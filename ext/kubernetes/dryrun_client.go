@@ -0,0 +1,48 @@
+package kubernetes
+
+import (
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+)
+
+// dryrunClient is a kubeClient which passes queries through to a real client,
+// but only logs commands.
+type dryrunClient struct {
+	kubeClient
+	log logging.LogSink
+}
+
+func (c dryrunClient) report(verb, kind, name string) error {
+	messages.ReportLogFieldsMessage("Dry run: not sending "+verb+" "+kind, logging.InformationLevel, c.log, name)
+	return nil
+}
+
+// CreateDeployment implements kubeClient on dryrunClient.
+func (c dryrunClient) CreateDeployment(d *Deployment) error {
+	return c.report("create", objectKindDeployment, d.Metadata.Name)
+}
+
+// UpdateDeployment implements kubeClient on dryrunClient.
+func (c dryrunClient) UpdateDeployment(d *Deployment) error {
+	return c.report("update", objectKindDeployment, d.Metadata.Name)
+}
+
+// CreateCronJob implements kubeClient on dryrunClient.
+func (c dryrunClient) CreateCronJob(cj *CronJob) error {
+	return c.report("create", objectKindCronJob, cj.Metadata.Name)
+}
+
+// UpdateCronJob implements kubeClient on dryrunClient.
+func (c dryrunClient) UpdateCronJob(cj *CronJob) error {
+	return c.report("update", objectKindCronJob, cj.Metadata.Name)
+}
+
+// CreateJob implements kubeClient on dryrunClient.
+func (c dryrunClient) CreateJob(j *Job) error {
+	return c.report("create", objectKindJob, j.Metadata.Name)
+}
+
+// DeleteJob implements kubeClient on dryrunClient.
+func (c dryrunClient) DeleteJob(name string) error {
+	return c.report("delete", objectKindJob, name)
+}
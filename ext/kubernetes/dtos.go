@@ -0,0 +1,231 @@
+package kubernetes
+
+// The types in this file are a minimal subset of the Kubernetes API objects
+// (apps/v1 Deployment, batch/v1 CronJob and batch/v1 Job) - only the
+// fields Sous reads or writes are modelled. Unknown fields returned by the API
+// server are ignored when decoding.
+
+type (
+	// ObjectMeta is the metadata common to all Kubernetes objects.
+	ObjectMeta struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace,omitempty"`
+		Labels          map[string]string `json:"labels,omitempty"`
+		Annotations     map[string]string `json:"annotations,omitempty"`
+		ResourceVersion string            `json:"resourceVersion,omitempty"`
+		Generation      int64             `json:"generation,omitempty"`
+	}
+
+	// ListMeta is the metadata common to all Kubernetes lists.
+	ListMeta struct {
+		ResourceVersion string `json:"resourceVersion,omitempty"`
+	}
+
+	// LabelSelector selects objects by their labels.
+	LabelSelector struct {
+		MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	}
+
+	// Deployment is an apps/v1 Deployment.
+	Deployment struct {
+		APIVersion string           `json:"apiVersion"`
+		Kind       string           `json:"kind"`
+		Metadata   ObjectMeta       `json:"metadata"`
+		Spec       DeploymentSpec   `json:"spec"`
+		Status     DeploymentStatus `json:"status,omitempty"`
+	}
+
+	// DeploymentList is a list of Deployments.
+	DeploymentList struct {
+		Metadata ListMeta     `json:"metadata"`
+		Items    []Deployment `json:"items"`
+	}
+
+	// DeploymentSpec is the desired state of a Deployment.
+	DeploymentSpec struct {
+		Replicas                *int32          `json:"replicas,omitempty"`
+		Selector                *LabelSelector  `json:"selector,omitempty"`
+		Template                PodTemplateSpec `json:"template"`
+		ProgressDeadlineSeconds *int32          `json:"progressDeadlineSeconds,omitempty"`
+	}
+
+	// DeploymentStatus is the observed state of a Deployment.
+	DeploymentStatus struct {
+		ObservedGeneration int64                 `json:"observedGeneration,omitempty"`
+		Replicas           int32                 `json:"replicas,omitempty"`
+		UpdatedReplicas    int32                 `json:"updatedReplicas,omitempty"`
+		ReadyReplicas      int32                 `json:"readyReplicas,omitempty"`
+		AvailableReplicas  int32                 `json:"availableReplicas,omitempty"`
+		Conditions         []DeploymentCondition `json:"conditions,omitempty"`
+	}
+
+	// DeploymentCondition describes the state of a Deployment at some point.
+	DeploymentCondition struct {
+		Type    string `json:"type"`
+		Status  string `json:"status"`
+		Reason  string `json:"reason,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+
+	// CronJob is a batch/v1 CronJob.
+	CronJob struct {
+		APIVersion string        `json:"apiVersion"`
+		Kind       string        `json:"kind"`
+		Metadata   ObjectMeta    `json:"metadata"`
+		Spec       CronJobSpec   `json:"spec"`
+		Status     CronJobStatus `json:"status,omitempty"`
+	}
+
+	// CronJobList is a list of CronJobs.
+	CronJobList struct {
+		Metadata ListMeta  `json:"metadata"`
+		Items    []CronJob `json:"items"`
+	}
+
+	// CronJobSpec is the desired state of a CronJob.
+	CronJobSpec struct {
+		Schedule    string          `json:"schedule"`
+		TimeZone    string          `json:"timeZone,omitempty"`
+		Suspend     *bool           `json:"suspend,omitempty"`
+		JobTemplate JobTemplateSpec `json:"jobTemplate"`
+	}
+
+	// CronJobStatus is the observed state of a CronJob.
+	CronJobStatus struct {
+		LastScheduleTime string `json:"lastScheduleTime,omitempty"`
+	}
+
+	// JobTemplateSpec describes the Job a CronJob will create.
+	JobTemplateSpec struct {
+		Metadata ObjectMeta `json:"metadata,omitempty"`
+		Spec     JobSpec    `json:"spec"`
+	}
+
+	// Job is a batch/v1 Job.
+	Job struct {
+		APIVersion string     `json:"apiVersion"`
+		Kind       string     `json:"kind"`
+		Metadata   ObjectMeta `json:"metadata"`
+		Spec       JobSpec    `json:"spec"`
+		Status     JobStatus  `json:"status,omitempty"`
+	}
+
+	// JobList is a list of Jobs.
+	JobList struct {
+		Metadata ListMeta `json:"metadata"`
+		Items    []Job    `json:"items"`
+	}
+
+	// JobSpec is the desired state of a Job.
+	JobSpec struct {
+		Parallelism  *int32          `json:"parallelism,omitempty"`
+		BackoffLimit *int32          `json:"backoffLimit,omitempty"`
+		Suspend      *bool           `json:"suspend,omitempty"`
+		Template     PodTemplateSpec `json:"template"`
+	}
+
+	// JobStatus is the observed state of a Job.
+	JobStatus struct {
+		Active    int32 `json:"active,omitempty"`
+		Succeeded int32 `json:"succeeded,omitempty"`
+		Failed    int32 `json:"failed,omitempty"`
+	}
+
+	// PodTemplateSpec describes the pods created for a workload.
+	PodTemplateSpec struct {
+		Metadata ObjectMeta `json:"metadata,omitempty"`
+		Spec     PodSpec    `json:"spec"`
+	}
+
+	// PodSpec is the specification of a pod.
+	PodSpec struct {
+		Containers    []Container `json:"containers"`
+		Volumes       []Volume    `json:"volumes,omitempty"`
+		RestartPolicy string      `json:"restartPolicy,omitempty"`
+	}
+
+	// Container is a single container within a pod.
+	Container struct {
+		Name           string               `json:"name"`
+		Image          string               `json:"image"`
+		Env            []EnvVar             `json:"env,omitempty"`
+		Ports          []ContainerPort      `json:"ports,omitempty"`
+		Resources      ResourceRequirements `json:"resources,omitempty"`
+		VolumeMounts   []VolumeMount        `json:"volumeMounts,omitempty"`
+		ReadinessProbe *Probe               `json:"readinessProbe,omitempty"`
+	}
+
+	// EnvVar is a single environment variable.
+	EnvVar struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// ContainerPort is a port exposed by a container.
+	ContainerPort struct {
+		Name          string `json:"name,omitempty"`
+		ContainerPort int32  `json:"containerPort"`
+		Protocol      string `json:"protocol,omitempty"`
+	}
+
+	// ResourceRequirements describes the compute resources of a container.
+	ResourceRequirements struct {
+		Limits   map[string]string `json:"limits,omitempty"`
+		Requests map[string]string `json:"requests,omitempty"`
+	}
+
+	// Volume is a volume available to the containers of a pod.
+	Volume struct {
+		Name     string                `json:"name"`
+		HostPath *HostPathVolumeSource `json:"hostPath,omitempty"`
+	}
+
+	// HostPathVolumeSource maps a path on the node into a pod.
+	HostPathVolumeSource struct {
+		Path string `json:"path"`
+	}
+
+	// VolumeMount mounts a Volume into a container.
+	VolumeMount struct {
+		Name      string `json:"name"`
+		MountPath string `json:"mountPath"`
+		ReadOnly  bool   `json:"readOnly,omitempty"`
+	}
+
+	// Probe is a health check performed against a container.
+	Probe struct {
+		HTTPGet             *HTTPGetAction   `json:"httpGet,omitempty"`
+		TCPSocket           *TCPSocketAction `json:"tcpSocket,omitempty"`
+		Exec                *ExecAction      `json:"exec,omitempty"`
+		InitialDelaySeconds int32            `json:"initialDelaySeconds,omitempty"`
+		TimeoutSeconds      int32            `json:"timeoutSeconds,omitempty"`
+		PeriodSeconds       int32            `json:"periodSeconds,omitempty"`
+		FailureThreshold    int32            `json:"failureThreshold,omitempty"`
+	}
+
+	// HTTPGetAction is an HTTP GET health check.
+	HTTPGetAction struct {
+		Path   string `json:"path,omitempty"`
+		Port   int32  `json:"port"`
+		Scheme string `json:"scheme,omitempty"`
+	}
+
+	// TCPSocketAction is a health check that connects to a port.
+	TCPSocketAction struct {
+		Port int32 `json:"port"`
+	}
+
+	// ExecAction is a health check that runs a command in the container.
+	ExecAction struct {
+		Command []string `json:"command"`
+	}
+
+	// Status is the error body returned by the API server.
+	Status struct {
+		Kind    string `json:"kind"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+		Code    int    `json:"code"`
+	}
+)
@@ -0,0 +1,144 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fakeAPIServer is a minimal in-memory Kubernetes API server, which stores
+// objects as raw JSON keyed by collection path and name.
+type fakeAPIServer struct {
+	*httptest.Server
+	sync.Mutex
+	objects  map[string]map[string]json.RawMessage
+	requests []string
+	version  int
+}
+
+func newFakeAPIServer() *fakeAPIServer {
+	f := &fakeAPIServer{objects: map[string]map[string]json.RawMessage{}}
+	f.Server = httptest.NewServer(f)
+	return f
+}
+
+func (f *fakeAPIServer) split(path string) (collection, name string) {
+	for _, p := range []string{deploymentsPath, cronJobsPath, jobsPath} {
+		prefix := fmt.Sprintf(p, "default")
+		if path == prefix {
+			return prefix, ""
+		}
+		if strings.HasPrefix(path, prefix+"/") {
+			return prefix, strings.TrimPrefix(path, prefix+"/")
+		}
+	}
+	return "", ""
+}
+
+func (f *fakeAPIServer) status(rw http.ResponseWriter, code int, msg string) {
+	rw.WriteHeader(code)
+	json.NewEncoder(rw).Encode(Status{Kind: "Status", Status: "Failure", Message: msg, Code: code})
+}
+
+func (f *fakeAPIServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	f.requests = append(f.requests, req.Method+" "+req.URL.Path)
+
+	collection, name := f.split(req.URL.Path)
+	if collection == "" {
+		f.status(rw, 404, "unknown path "+req.URL.Path)
+		return
+	}
+	objs, ok := f.objects[collection]
+	if !ok {
+		objs = map[string]json.RawMessage{}
+		f.objects[collection] = objs
+	}
+
+	switch {
+	default:
+		f.status(rw, 405, req.Method)
+	case req.Method == "GET" && name == "":
+		names := []string{}
+		for n := range objs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		items := []json.RawMessage{}
+		for _, n := range names {
+			items = append(items, objs[n])
+		}
+		json.NewEncoder(rw).Encode(map[string]interface{}{"items": items})
+	case req.Method == "GET":
+		obj, ok := objs[name]
+		if !ok {
+			f.status(rw, 404, name+" not found")
+			return
+		}
+		rw.Write(obj)
+	case req.Method == "POST" || req.Method == "PUT":
+		body, _ := ioutil.ReadAll(req.Body)
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal(body, &obj); err != nil {
+			f.status(rw, 400, err.Error())
+			return
+		}
+		meta := obj["metadata"].(map[string]interface{})
+		objName := meta["name"].(string)
+		_, exists := objs[objName]
+		if req.Method == "POST" && exists {
+			f.status(rw, 409, objName+" already exists")
+			return
+		}
+		if req.Method == "PUT" && !exists {
+			f.status(rw, 404, objName+" not found")
+			return
+		}
+		f.version++
+		meta["resourceVersion"] = fmt.Sprintf("%d", f.version)
+		meta["generation"] = f.version
+		delete(obj, "status")
+		stored, _ := json.Marshal(obj)
+		objs[objName] = stored
+		rw.WriteHeader(201)
+		rw.Write(stored)
+	case req.Method == "DELETE":
+		if _, ok := objs[name]; !ok {
+			f.status(rw, 404, name+" not found")
+			return
+		}
+		delete(objs, name)
+		f.status(rw, 200, "deleted")
+	}
+}
+
+// get decodes the stored object at collection/name into into.
+func (f *fakeAPIServer) get(pathFmt, name string, into interface{}) bool {
+	f.Lock()
+	defer f.Unlock()
+	obj, ok := f.objects[fmt.Sprintf(pathFmt, "default")][name]
+	if !ok {
+		return false
+	}
+	json.Unmarshal(obj, into)
+	return true
+}
+
+// put replaces the stored object at collection/name, e.g. to simulate the
+// status reported by Kubernetes controllers.
+func (f *fakeAPIServer) put(pathFmt, name string, obj interface{}) {
+	f.Lock()
+	defer f.Unlock()
+	b, _ := json.Marshal(obj)
+	collection := fmt.Sprintf(pathFmt, "default")
+	if f.objects[collection] == nil {
+		f.objects[collection] = map[string]json.RawMessage{}
+	}
+	f.objects[collection][name] = b
+}
@@ -0,0 +1,210 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/pkg/errors"
+)
+
+type (
+	// kubeClient abstracts the queries and commands we send to a Kubernetes API
+	// server. All operations are scoped to a single namespace.
+	kubeClient interface {
+		ListDeployments(selector string) (*DeploymentList, error)
+		GetDeployment(name string) (*Deployment, error)
+		CreateDeployment(d *Deployment) error
+		UpdateDeployment(d *Deployment) error
+
+		ListCronJobs(selector string) (*CronJobList, error)
+		GetCronJob(name string) (*CronJob, error)
+		CreateCronJob(cj *CronJob) error
+		UpdateCronJob(cj *CronJob) error
+
+		ListJobs(selector string) (*JobList, error)
+		GetJob(name string) (*Job, error)
+		CreateJob(j *Job) error
+		DeleteJob(name string) error
+	}
+
+	// restClient is the HTTP implementation of kubeClient.
+	restClient struct {
+		baseURL    string
+		namespace  string
+		token      string
+		httpClient *http.Client
+		log        logging.LogSink
+	}
+
+	// apiError is returned when the API server responds with a non-2xx status.
+	apiError struct {
+		Method, URL string
+		StatusCode  int
+		Status      Status
+	}
+)
+
+const (
+	deploymentsPath = "/apis/apps/v1/namespaces/%s/deployments"
+	cronJobsPath    = "/apis/batch/v1/namespaces/%s/cronjobs"
+	jobsPath        = "/apis/batch/v1/namespaces/%s/jobs"
+)
+
+func (e *apiError) Error() string {
+	msg := e.Status.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, msg)
+}
+
+// isNotFound reports whether err is a 404 from the API server.
+func isNotFound(err error) bool {
+	ae, is := errors.Cause(err).(*apiError)
+	return is && ae.StatusCode == http.StatusNotFound
+}
+
+func newRESTClient(baseURL, namespace, token string, ls logging.LogSink) *restClient {
+	return &restClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		namespace:  namespace,
+		token:      token,
+		httpClient: http.DefaultClient,
+		log:        ls,
+	}
+}
+
+func (c *restClient) collectionURL(pathFmt, selector string) string {
+	u := c.baseURL + fmt.Sprintf(pathFmt, c.namespace)
+	if selector != "" {
+		u += "?" + url.Values{"labelSelector": []string{selector}}.Encode()
+	}
+	return u
+}
+
+func (c *restClient) itemURL(pathFmt, name string) string {
+	return c.baseURL + fmt.Sprintf(pathFmt, c.namespace) + "/" + url.PathEscape(name)
+}
+
+func (c *restClient) do(method, u string, body, into interface{}) error {
+	var rdr *bytes.Buffer
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrapf(err, "encoding %T", body)
+		}
+		rdr = bytes.NewBuffer(b)
+	} else {
+		rdr = &bytes.Buffer{}
+	}
+
+	req, err := http.NewRequest(method, u, rdr)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	messages.ReportLogFieldsMessage("Kubernetes API request", logging.ExtraDebug1Level, c.log, method, u)
+	rz, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s", method, u)
+	}
+	defer rz.Body.Close()
+
+	b, err := ioutil.ReadAll(rz.Body)
+	if err != nil {
+		return errors.Wrapf(err, "reading response to %s %s", method, u)
+	}
+
+	if rz.StatusCode < 200 || rz.StatusCode > 299 {
+		ae := &apiError{Method: method, URL: u, StatusCode: rz.StatusCode}
+		// The body may not be a Status, in which case we report the HTTP status.
+		json.Unmarshal(b, &ae.Status)
+		return ae
+	}
+
+	if into == nil || len(b) == 0 {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal(b, into), "decoding response to %s %s", method, u)
+}
+
+// ListDeployments implements kubeClient on restClient.
+func (c *restClient) ListDeployments(selector string) (*DeploymentList, error) {
+	l := &DeploymentList{}
+	return l, c.do("GET", c.collectionURL(deploymentsPath, selector), nil, l)
+}
+
+// GetDeployment implements kubeClient on restClient.
+func (c *restClient) GetDeployment(name string) (*Deployment, error) {
+	d := &Deployment{}
+	return d, c.do("GET", c.itemURL(deploymentsPath, name), nil, d)
+}
+
+// CreateDeployment implements kubeClient on restClient.
+func (c *restClient) CreateDeployment(d *Deployment) error {
+	return c.do("POST", c.collectionURL(deploymentsPath, ""), d, nil)
+}
+
+// UpdateDeployment implements kubeClient on restClient.
+func (c *restClient) UpdateDeployment(d *Deployment) error {
+	return c.do("PUT", c.itemURL(deploymentsPath, d.Metadata.Name), d, nil)
+}
+
+// ListCronJobs implements kubeClient on restClient.
+func (c *restClient) ListCronJobs(selector string) (*CronJobList, error) {
+	l := &CronJobList{}
+	return l, c.do("GET", c.collectionURL(cronJobsPath, selector), nil, l)
+}
+
+// GetCronJob implements kubeClient on restClient.
+func (c *restClient) GetCronJob(name string) (*CronJob, error) {
+	cj := &CronJob{}
+	return cj, c.do("GET", c.itemURL(cronJobsPath, name), nil, cj)
+}
+
+// CreateCronJob implements kubeClient on restClient.
+func (c *restClient) CreateCronJob(cj *CronJob) error {
+	return c.do("POST", c.collectionURL(cronJobsPath, ""), cj, nil)
+}
+
+// UpdateCronJob implements kubeClient on restClient.
+func (c *restClient) UpdateCronJob(cj *CronJob) error {
+	return c.do("PUT", c.itemURL(cronJobsPath, cj.Metadata.Name), cj, nil)
+}
+
+// ListJobs implements kubeClient on restClient.
+func (c *restClient) ListJobs(selector string) (*JobList, error) {
+	l := &JobList{}
+	return l, c.do("GET", c.collectionURL(jobsPath, selector), nil, l)
+}
+
+// GetJob implements kubeClient on restClient.
+func (c *restClient) GetJob(name string) (*Job, error) {
+	j := &Job{}
+	return j, c.do("GET", c.itemURL(jobsPath, name), nil, j)
+}
+
+// CreateJob implements kubeClient on restClient.
+func (c *restClient) CreateJob(j *Job) error {
+	return c.do("POST", c.collectionURL(jobsPath, ""), j, nil)
+}
+
+// DeleteJob implements kubeClient on restClient. Dependent pods are deleted
+// in the background.
+func (c *restClient) DeleteJob(name string) error {
+	return c.do("DELETE", c.itemURL(jobsPath, name)+"?propagationPolicy=Background", nil, nil)
+}
@@ -0,0 +1,416 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ManagedLabel marks Kubernetes objects (and their pods) as controlled by
+	// Sous. Only objects carrying this label are considered by the deployer.
+	ManagedLabel = "com.opentable.sous.managed"
+	// DeploymentLabel holds the object name on pods, and is used as the pod
+	// selector of Deployments.
+	DeploymentLabel = "com.opentable.sous.deployment"
+	// KindAnnotation records the sous.ManifestKind of a Kubernetes object.
+	KindAnnotation = "com.opentable.sous.kind"
+	// OwnersAnnotation records the comma-separated owners of a deployment.
+	OwnersAnnotation = "com.opentable.sous.owners"
+	// StartupAnnotation records the parts of sous.Startup which have no
+	// equivalent in a Kubernetes probe, as JSON.
+	StartupAnnotation = "com.opentable.sous.startup"
+	// MetadataAnnotation records the sous.Metadata of a deployment, as JSON.
+	MetadataAnnotation = "com.opentable.sous.metadata"
+	// PausedAnnotation marks the objects of paused deployments. Its value is
+	// the NumInstances the deployment runs once unpaused, since a paused
+	// Deployment has no replicas.
+	PausedAnnotation = "com.opentable.sous.paused"
+
+	// FirstContainerPort is the container port assigned to port index 0.
+	// Subsequent port indexes are assigned consecutive ports, and passed to the
+	// container as PORT0...PORTn, as Singularity does.
+	FirstContainerPort = 8080
+
+	// maxObjectNameLen is the length limit of CronJob names (52), which is the
+	// tightest of the object kinds we create.
+	maxObjectNameLen = 52
+
+	// onDemandSchedule is a schedule that never fires; on-demand deployments
+	// are represented as suspended CronJobs which are triggered manually.
+	onDemandSchedule = "0 0 31 2 *"
+
+	// objectKind* name the Kubernetes object kinds Sous creates.
+	objectKindDeployment = "Deployment"
+	objectKindCronJob    = "CronJob"
+	objectKindJob        = "Job"
+)
+
+var (
+	illegalNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+	portEnvName      = regexp.MustCompile(`^PORT[0-9]+$`)
+
+	managedSelector = ManagedLabel + "=true"
+)
+
+type (
+	// startupExtras holds the parts of sous.Startup without a Kubernetes
+	// equivalent, so that they round-trip through the StartupAnnotation.
+	startupExtras struct {
+		Timeout                   int   `json:",omitempty"`
+		ConnectInterval           int   `json:",omitempty"`
+		CheckReadyFailureStatuses []int `json:",omitempty"`
+		// CheckReadyURIPath and CheckReadyPortIndex are only recorded for
+		// checks whose probes do not include them.
+		CheckReadyURIPath   string `json:",omitempty"`
+		CheckReadyPortIndex int    `json:",omitempty"`
+	}
+
+	// kubeObjectData is the ExecutorData for Kubernetes deploy states.
+	kubeObjectData struct {
+		kind, name string
+	}
+)
+
+// storedObjectData is the JSON encoding of a kubeObjectData.
+type storedObjectData struct{ Kind, Name string }
+
+// MarshalJSON implements json.Marshaler on kubeObjectData, so that it is kept
+// when a queued rectification is stored.
+func (d *kubeObjectData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(storedObjectData{Kind: d.kind, Name: d.name})
+}
+
+// objectData returns the kubeObjectData in executorData, decoding it if it is
+// the JSON of a rectification restored from storage.
+func objectData(executorData interface{}) (*kubeObjectData, bool) {
+	switch data := executorData.(type) {
+	case *kubeObjectData:
+		return data, true
+	case json.RawMessage:
+		stored := storedObjectData{}
+		if err := json.Unmarshal(data, &stored); err != nil || stored.Name == "" {
+			return nil, false
+		}
+		return &kubeObjectData{kind: stored.Kind, name: stored.Name}, true
+	}
+	return nil, false
+}
+
+func sanitizeName(in string) string {
+	return strings.Trim(illegalNameChars.ReplaceAllString(strings.ToLower(in), "-"), "-")
+}
+
+// MakeObjectName creates a Kubernetes object name from a sous.DeploymentID.
+// Names are DNS-1123 labels short enough to be used for any object kind.
+func MakeObjectName(depID sous.DeploymentID) (string, error) {
+	sn, err := depID.ManifestID.Source.ShortName()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{sanitizeName(sn)}
+	for _, p := range []string{depID.ManifestID.Source.Dir, depID.ManifestID.Flavor, depID.Cluster} {
+		if s := sanitizeName(p); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	digest := fmt.Sprintf("%x", depID.Digest())[:8]
+
+	base := strings.Join(parts, "-")
+	if max := maxObjectNameLen - len(digest) - 1; len(base) > max {
+		base = strings.TrimRight(base[:max], "-")
+	}
+	return base + "-" + digest, nil
+}
+
+func objectName(d *sous.Deployable) (string, error) {
+	return MakeObjectName(d.ID())
+}
+
+// objectKindFor returns the kind of Kubernetes object used to run a
+// deployment of kind mk.
+func objectKindFor(mk sous.ManifestKind) (string, error) {
+	switch mk {
+	default:
+		return "", errors.Errorf("Unrecognized Sous manifest kind: %v", mk)
+	case sous.ManifestKindService, sous.ManifestKindWorker:
+		return objectKindDeployment, nil
+	case sous.ManifestKindScheduled, sous.ManifestKindOnDemand:
+		return objectKindCronJob, nil
+	case sous.ManifestKindOnce:
+		return objectKindJob, nil
+	}
+}
+
+func buildObjectMeta(d *sous.Deployable, name, namespace string) (ObjectMeta, error) {
+	startup := d.Deployment.DeployConfig.Startup
+	se := startupExtras{
+		Timeout:                   startup.Timeout,
+		ConnectInterval:           startup.ConnectInterval,
+		CheckReadyFailureStatuses: startup.CheckReadyFailureStatuses,
+	}
+	switch strings.ToUpper(startup.CheckReadyProtocol) {
+	case "TCP":
+		se.CheckReadyURIPath = startup.CheckReadyURIPath
+	case "COMMAND":
+		se.CheckReadyURIPath = startup.CheckReadyURIPath
+		se.CheckReadyPortIndex = startup.CheckReadyPortIndex
+	}
+	extras, err := json.Marshal(se)
+	if err != nil {
+		return ObjectMeta{}, err
+	}
+	metadata, err := json.Marshal(d.Deployment.DeployConfig.Metadata)
+	if err != nil {
+		return ObjectMeta{}, err
+	}
+	owners := d.Deployment.Owners.Slice()
+	sort.Strings(owners)
+
+	annotations := map[string]string{
+		sous.ClusterNameLabel: d.Deployment.ClusterName,
+		sous.FlavorLabel:      d.Deployment.Flavor,
+		KindAnnotation:        string(d.Deployment.Kind),
+		OwnersAnnotation:      strings.Join(owners, ","),
+		StartupAnnotation:     string(extras),
+		MetadataAnnotation:    string(metadata),
+	}
+	// Recording which variables are secrets lets their references be
+	// restored, rather than their values, when the object is read back.
+	for name, ref := range d.Deployment.DeployConfig.Env.SecretRefs() {
+		annotations[sous.SecretEnvLabel+name] = ref
+	}
+	if d.Deployment.IsPaused() {
+		annotations[PausedAnnotation] = strconv.Itoa(d.Deployment.NumInstances)
+	}
+
+	return ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels: map[string]string{
+			ManagedLabel:    "true",
+			DeploymentLabel: name,
+		},
+		Annotations: annotations,
+	}, nil
+}
+
+// mapResources produces Kubernetes resource quantities from sous.Resources.
+// Requests and limits are set equal so that pods get a guaranteed QoS.
+func mapResources(r sous.Resources) ResourceRequirements {
+	q := map[string]string{
+		"cpu":    fmt.Sprintf("%dm", int64(math.Ceil(r.Cpus()*1000))),
+		"memory": fmt.Sprintf("%dMi", int64(math.Ceil(r.Memory()))),
+	}
+	lim := map[string]string{}
+	for k, v := range q {
+		lim[k] = v
+	}
+	return ResourceRequirements{Requests: q, Limits: lim}
+}
+
+func buildPodTemplate(d *sous.Deployable, meta ObjectMeta) (PodTemplateSpec, error) {
+	if d.BuildArtifact == nil {
+		return PodTemplateSpec{}, &sous.MissingImageNameError{Cause: fmt.Errorf("Missing BuildArtifact on Deployable")}
+	}
+	dc := d.Deployment.DeployConfig
+
+	env := []EnvVar{}
+	for k, v := range dc.Env {
+		env = append(env, EnvVar{Name: k, Value: v})
+	}
+	ports := []ContainerPort{}
+	for i := 0; i < int(dc.Resources.Ports()); i++ {
+		p := int32(FirstContainerPort + i)
+		ports = append(ports, ContainerPort{Name: fmt.Sprintf("port%d", i), ContainerPort: p, Protocol: "TCP"})
+		env = append(env, EnvVar{Name: fmt.Sprintf("PORT%d", i), Value: fmt.Sprintf("%d", p)})
+	}
+	sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+
+	vols := []Volume{}
+	mounts := []VolumeMount{}
+	for i, v := range dc.Volumes {
+		if v == nil {
+			continue
+		}
+		vn := fmt.Sprintf("vol%d", i)
+		vols = append(vols, Volume{Name: vn, HostPath: &HostPathVolumeSource{Path: v.Host}})
+		mounts = append(mounts, VolumeMount{Name: vn, MountPath: v.Container, ReadOnly: v.Mode == sous.ReadOnly})
+	}
+
+	restart := "Always"
+	if d.Deployment.Kind != sous.ManifestKindService && d.Deployment.Kind != sous.ManifestKindWorker {
+		restart = "OnFailure"
+	}
+
+	return PodTemplateSpec{
+		Metadata: ObjectMeta{Labels: meta.Labels},
+		Spec: PodSpec{
+			Containers: []Container{{
+				Name:           meta.Name,
+				Image:          d.BuildArtifact.DigestReference,
+				Env:            env,
+				Ports:          ports,
+				Resources:      mapResources(dc.Resources),
+				VolumeMounts:   mounts,
+				ReadinessProbe: buildProbe(dc.Startup),
+			}},
+			Volumes:       vols,
+			RestartPolicy: restart,
+		},
+	}, nil
+}
+
+// resolveSecrets replaces the references to secrets in the Env of the
+// containers of tmpl with their values from p. It is only called just before
+// an object is sent, so that the values are never logged.
+func resolveSecrets(tmpl *PodTemplateSpec, p sous.SecretProvider) error {
+	for i := range tmpl.Spec.Containers {
+		c := &tmpl.Spec.Containers[i]
+		env := sous.Env{}
+		for _, e := range c.Env {
+			env[e.Name] = e.Value
+		}
+		resolved, err := env.ResolveSecrets(p)
+		if err != nil {
+			return err
+		}
+		for j := range c.Env {
+			c.Env[j].Value = resolved[c.Env[j].Name]
+		}
+	}
+	return nil
+}
+
+// buildProbe maps sous.Startup onto a readiness probe. It returns nil if
+// startup checks are skipped.
+func buildProbe(s sous.Startup) *Probe {
+	if s.SkipCheck {
+		return nil
+	}
+	p := &Probe{
+		InitialDelaySeconds: int32(s.ConnectDelay),
+		TimeoutSeconds:      int32(s.CheckReadyURITimeout),
+		PeriodSeconds:       int32(s.CheckReadyInterval),
+		FailureThreshold:    int32(s.CheckReadyRetries),
+	}
+	switch strings.ToUpper(s.CheckReadyProtocol) {
+	case "TCP":
+		p.TCPSocket = &TCPSocketAction{Port: int32(FirstContainerPort + s.CheckReadyPortIndex)}
+	case "COMMAND":
+		p.Exec = &ExecAction{Command: s.CheckReadyCommand}
+	default:
+		p.HTTPGet = &HTTPGetAction{
+			Path:   s.CheckReadyURIPath,
+			Port:   int32(FirstContainerPort + s.CheckReadyPortIndex),
+			Scheme: s.CheckReadyProtocol,
+		}
+	}
+	return p
+}
+
+func int32Ptr(i int) *int32 {
+	v := int32(i)
+	return &v
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func buildDeployment(d *sous.Deployable, name, namespace string) (*Deployment, error) {
+	meta, err := buildObjectMeta(d, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := buildPodTemplate(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	replicas := d.Deployment.NumInstances
+	if d.Deployment.IsPaused() {
+		replicas = 0
+	}
+	dep := &Deployment{
+		APIVersion: "apps/v1",
+		Kind:       objectKindDeployment,
+		Metadata:   meta,
+		Spec: DeploymentSpec{
+			Replicas: int32Ptr(replicas),
+			Selector: &LabelSelector{MatchLabels: map[string]string{DeploymentLabel: name}},
+			Template: tmpl,
+		},
+	}
+	if t := d.Deployment.Startup.Timeout; t > 0 && !d.Deployment.Startup.SkipCheck {
+		dep.Spec.ProgressDeadlineSeconds = int32Ptr(t)
+	}
+	return dep, nil
+}
+
+func buildJobSpec(d *sous.Deployable, meta ObjectMeta) (JobSpec, error) {
+	tmpl, err := buildPodTemplate(d, meta)
+	if err != nil {
+		return JobSpec{}, err
+	}
+	return JobSpec{
+		Parallelism: int32Ptr(d.Deployment.NumInstances),
+		Template:    tmpl,
+	}, nil
+}
+
+func buildCronJob(d *sous.Deployable, name, namespace string) (*CronJob, error) {
+	meta, err := buildObjectMeta(d, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := buildJobSpec(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	cj := &CronJob{
+		APIVersion: "batch/v1",
+		Kind:       objectKindCronJob,
+		Metadata:   meta,
+		Spec: CronJobSpec{
+			Schedule:    d.Deployment.Schedule,
+			TimeZone:    d.Deployment.ScheduleTimeZone,
+			Suspend:     boolPtr(false),
+			JobTemplate: JobTemplateSpec{Spec: spec},
+		},
+	}
+	if d.Deployment.Kind == sous.ManifestKindOnDemand {
+		cj.Spec.Schedule = onDemandSchedule
+		cj.Spec.Suspend = boolPtr(true)
+	}
+	if d.Deployment.IsPaused() {
+		cj.Spec.Suspend = boolPtr(true)
+	}
+	return cj, nil
+}
+
+func buildJob(d *sous.Deployable, name, namespace string) (*Job, error) {
+	meta, err := buildObjectMeta(d, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := buildJobSpec(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	if d.Deployment.IsPaused() {
+		spec.Suspend = boolPtr(true)
+	}
+	return &Job{
+		APIVersion: "batch/v1",
+		Kind:       objectKindJob,
+		Metadata:   meta,
+		Spec:       spec,
+	}, nil
+}
@@ -0,0 +1,19 @@
+package notify
+
+// Config is the configuration of the sinks a server sends notifications
+// through. Slack is configured by the Slack settings of the Sous config.
+type Config struct {
+	// WebhookSecret is the key used to sign the notifications posted to
+	// webhooks. Each is signed by its HMAC-SHA256, in hex, in the
+	// X-Sous-Signature header as "sha256=<signature>".
+	WebhookSecret string `env:"SOUS_NOTIFY_WEBHOOK_SECRET"`
+	// SMTPAddr is the host:port of the mail server used to send email
+	// notifications. Email notifications are not sent if it is empty.
+	SMTPAddr string `env:"SOUS_NOTIFY_SMTP_ADDR"`
+	// SMTPFrom is the address email notifications are sent from.
+	SMTPFrom string `env:"SOUS_NOTIFY_SMTP_FROM"`
+	// SMTPUsername and SMTPPassword authenticate with the mail server, if
+	// they are set.
+	SMTPUsername string `env:"SOUS_NOTIFY_SMTP_USERNAME"`
+	SMTPPassword string `env:"SOUS_NOTIFY_SMTP_PASSWORD"`
+}
@@ -0,0 +1,220 @@
+// Package notify delivers notifications about deployments to Slack, to
+// webhooks and by email.
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	slack "github.com/ashwanthkumar/slack-go-webhook"
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// SlackSink posts notifications to Slack channels through incoming
+	// webhooks. It implements sous.NotificationSink.
+	SlackSink struct {
+		// HookURL is the webhook used for channels without one in Hooks.
+		HookURL string
+		// Hooks maps channels to the webhooks used to post to them.
+		Hooks map[string]string
+		send  func(url, proxy string, payload slack.Payload) []error
+	}
+
+	// WebhookSink posts notifications as JSON to URLs, signed with Secret.
+	// It implements sous.NotificationSink.
+	WebhookSink struct {
+		Secret string
+		Client *http.Client
+	}
+
+	// EmailSink sends notifications by email. It implements
+	// sous.NotificationSink.
+	EmailSink struct {
+		// Addr is the host:port of the mail server.
+		Addr string
+		From string
+		// Auth authenticates with the mail server, if it is not nil.
+		Auth smtp.Auth
+		send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	}
+)
+
+// SignatureHeader is the header of the HMAC signature of a notification
+// posted to a webhook.
+const SignatureHeader = "X-Sous-Signature"
+
+// webhookTimeout limits how long a webhook can hold up notifications.
+const webhookTimeout = 10 * time.Second
+
+// headerNewlines replaces line breaks that would end an email header early.
+var headerNewlines = strings.NewReplacer("\r", " ", "\n", " ")
+
+// NewSinks returns the sinks configured by c, and the Slack sink using
+// hookURL for channel and additional, any of which are nil if they are not
+// configured.
+func NewSinks(c Config, hookURL, channel string, additional map[string]string) (slackSink, webhook, email sous.NotificationSink) {
+	hooks := map[string]string{}
+	for ch, url := range additional {
+		hooks[ch] = url
+	}
+	if channel != "" && hookURL != "" {
+		hooks[channel] = hookURL
+	}
+	if hookURL != "" || len(hooks) > 0 {
+		slackSink = &SlackSink{HookURL: hookURL, Hooks: hooks}
+	}
+	webhook = &WebhookSink{
+		Secret: c.WebhookSecret,
+		Client: &http.Client{Timeout: webhookTimeout},
+	}
+	if c.SMTPAddr != "" {
+		e := &EmailSink{Addr: c.SMTPAddr, From: c.SMTPFrom}
+		if c.SMTPUsername != "" {
+			host, _, _ := net.SplitHostPort(c.SMTPAddr)
+			e.Auth = smtp.PlainAuth("", c.SMTPUsername, c.SMTPPassword, host)
+		}
+		email = e
+	}
+	return slackSink, webhook, email
+}
+
+// Send implements sous.NotificationSink on SlackSink.
+func (s *SlackSink) Send(n sous.Notification, channels []string) error {
+	send := s.send
+	if send == nil {
+		send = slack.Send
+	}
+	color := "good"
+	status := "SUCCESS"
+	if n.Failed() {
+		color = "danger"
+		status = "FAILED"
+	}
+	attachment := slack.Attachment{Color: &color}
+	attachment.AddField(slack.Field{Title: "Deployment ID", Value: n.DeploymentID})
+	if n.Version != "" {
+		attachment.AddField(slack.Field{Title: "Version", Value: n.Version})
+	}
+	attachment.AddField(slack.Field{Title: "Resolution", Value: string(n.Resolution)})
+	attachment.AddField(slack.Field{Title: "Status", Value: status})
+	if n.Failed() {
+		attachment.AddField(slack.Field{Title: "Error", Value: n.Error})
+	}
+	payload := slack.Payload{
+		Username:    "Sous Bot",
+		IconEmoji:   ":chefhat:",
+		Text:        n.Summary(),
+		Attachments: []slack.Attachment{attachment},
+	}
+
+	var errs []error
+	for _, ch := range channels {
+		url := s.Hooks[ch]
+		if url == "" {
+			url = s.HookURL
+		}
+		if url == "" {
+			errs = append(errs, errors.Errorf("no Slack hook for %s", ch))
+			continue
+		}
+		payload.Channel = ch
+		for _, err := range send(url, "", payload) {
+			errs = append(errs, errors.Wrapf(err, "posting to %s", ch))
+		}
+	}
+	return combine(errs)
+}
+
+// Sign returns the signature of body with secret, as sent in the
+// SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send implements sous.NotificationSink on WebhookSink.
+func (w *WebhookSink) Send(n sous.Notification, urls []string) error {
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrapf(err, "encoding notification")
+	}
+	var errs []error
+	for _, url := range urls {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if w.Secret != "" {
+			req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode >= 300 {
+			errs = append(errs, errors.Errorf("POST %s: %s", url, res.Status))
+		}
+	}
+	return combine(errs)
+}
+
+// Send implements sous.NotificationSink on EmailSink.
+func (e *EmailSink) Send(n sous.Notification, to []string) error {
+	send := e.send
+	if send == nil {
+		send = smtp.SendMail
+	}
+	msg := &bytes.Buffer{}
+	fmt.Fprintf(msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(msg, "Subject: [sous] %s\r\n", headerNewlines.Replace(n.Summary()))
+	fmt.Fprintf(msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(msg, "%s\r\n\r\n", n.Summary())
+	fmt.Fprintf(msg, "Deployment: %s\r\n", n.DeploymentID)
+	if n.Version != "" {
+		fmt.Fprintf(msg, "Version:    %s\r\n", n.Version)
+	}
+	fmt.Fprintf(msg, "Resolution: %s\r\n", n.Resolution)
+	if len(n.Owners) > 0 {
+		fmt.Fprintf(msg, "Owners:     %s\r\n", strings.Join(n.Owners, ", "))
+	}
+	if n.Failed() {
+		fmt.Fprintf(msg, "Error:      %s\r\n", n.Error)
+	}
+	return errors.Wrapf(send(e.Addr, e.Auth, e.From, to, msg.Bytes()), "sending email")
+}
+
+// combine returns a single error describing errs, or nil if there are none.
+func combine(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.Errorf("%d errors: %s", len(errs), strings.Join(msgs, "; "))
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	slack "github.com/ashwanthkumar/slack-go-webhook"
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var failed = sous.Notification{
+	DeploymentID: "cluster-1:github.com/example/app",
+	ManifestID:   "github.com/example/app",
+	Cluster:      "cluster-1",
+	Version:      "1.2.3",
+	Owners:       []string{"team-a@example.com"},
+	Resolution:   sous.ModifyDiff,
+	Error:        "boom",
+}
+
+func TestWebhookSink(t *testing.T) {
+	var body []byte
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ = ioutil.ReadAll(req.Body)
+		signature = req.Header.Get(SignatureHeader)
+	}))
+	defer srv.Close()
+
+	w := &WebhookSink{Secret: "s3cret"}
+	require.NoError(t, w.Send(failed, []string{srv.URL}))
+
+	assert.Equal(t, Sign("s3cret", body), signature)
+	assert.True(t, strings.HasPrefix(signature, "sha256="))
+	got := sous.Notification{}
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, failed.DeploymentID, got.DeploymentID)
+	assert.Equal(t, "boom", got.Error)
+}
+
+func TestWebhookSink_errorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	w := &WebhookSink{}
+	assert.Error(t, w.Send(failed, []string{srv.URL}))
+}
+
+func TestSlackSink(t *testing.T) {
+	posted := map[string]string{}
+	s := &SlackSink{
+		HookURL: "https://hooks.slack.example.com/default",
+		Hooks:   map[string]string{"#team-a": "https://hooks.slack.example.com/team-a"},
+		send: func(url, proxy string, p slack.Payload) []error {
+			posted[p.Channel] = url
+			assert.Equal(t, "danger", *p.Attachments[0].Color)
+			return nil
+		},
+	}
+
+	require.NoError(t, s.Send(failed, []string{"#team-a", "#deploys"}))
+
+	assert.Equal(t, map[string]string{
+		"#team-a":  "https://hooks.slack.example.com/team-a",
+		"#deploys": "https://hooks.slack.example.com/default",
+	}, posted)
+}
+
+func TestEmailSink(t *testing.T) {
+	var msg string
+	var rcpts []string
+	e := &EmailSink{
+		Addr: "mail.example.com:25",
+		From: "sous@example.com",
+		send: func(addr string, a smtp.Auth, from string, to []string, m []byte) error {
+			rcpts = to
+			msg = string(m)
+			return nil
+		},
+	}
+
+	require.NoError(t, e.Send(failed, []string{"team-a@example.com"}))
+
+	assert.Equal(t, []string{"team-a@example.com"}, rcpts)
+	assert.Contains(t, msg, "Subject: [sous] Deployment of github.com/example/app version 1.2.3 to cluster-1 failed: boom\r\n")
+	assert.Contains(t, msg, "Error:      boom\r\n")
+}
+
+func TestEmailSink_subjectNewlines(t *testing.T) {
+	var msg string
+	e := &EmailSink{
+		send: func(addr string, a smtp.Auth, from string, to []string, m []byte) error {
+			msg = string(m)
+			return nil
+		},
+	}
+	n := failed
+	n.Error = "boom\r\nBcc: someone@example.com"
+
+	require.NoError(t, e.Send(n, []string{"team-a@example.com"}))
+
+	headers := strings.SplitN(msg, "\r\n\r\n", 2)[0]
+	assert.Contains(t, headers, "failed: boom  Bcc: someone@example.com")
+	assert.NotContains(t, headers, "\r\nBcc:")
+}
+
+func TestNewSinks(t *testing.T) {
+	s, w, e := NewSinks(Config{}, "", "", nil)
+	assert.Nil(t, s)
+	require.NotNil(t, w)
+	assert.Equal(t, webhookTimeout, w.(*WebhookSink).Client.Timeout)
+	assert.Nil(t, e)
+
+	s, _, e = NewSinks(Config{SMTPAddr: "mail.example.com:25"}, "https://hooks.slack.example.com/", "#deploys", nil)
+	require.NotNil(t, s)
+	assert.Equal(t, "https://hooks.slack.example.com/", s.(*SlackSink).Hooks["#deploys"])
+	assert.NotNil(t, e)
+}
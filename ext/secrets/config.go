@@ -0,0 +1,13 @@
+package secrets
+
+// Config is the configuration of the provider that resolves references to
+// secrets in the Env of deployments. Vault is used if VaultAddr is set,
+// otherwise Dir is used if it is set.
+type Config struct {
+	// Dir is the directory secret paths are relative to.
+	Dir string `env:"SOUS_SECRETS_DIR"`
+	// VaultAddr is the base URL of a Vault-compatible server.
+	VaultAddr string `env:"SOUS_VAULT_ADDR"`
+	// VaultToken authenticates with the Vault server.
+	VaultToken string `env:"SOUS_VAULT_TOKEN"`
+}
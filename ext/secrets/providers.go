@@ -0,0 +1,131 @@
+// Package secrets resolves references to secrets in the Env of deployments,
+// from files or from a Vault-compatible server.
+package secrets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// FileProvider resolves secrets from files under Dir. It implements
+	// sous.SecretProvider.
+	//
+	// A reference without a key is the content of the file at its path. If
+	// the path is a directory, the key names a file within it; otherwise the
+	// file holds a JSON object, and the key names one of its fields.
+	FileProvider struct {
+		Dir string
+	}
+
+	// VaultProvider resolves secrets from a Vault-compatible HTTP server. It
+	// implements sous.SecretProvider.
+	//
+	// The path of a reference is read from Addr/v1/<path>, and its key is a
+	// field of the secret's data. Secrets in version 2 key-value engines,
+	// whose data is nested, are supported.
+	VaultProvider struct {
+		Addr, Token string
+		Client      *http.Client
+	}
+)
+
+// NewProvider returns the provider configured by c, or nil if none is.
+func NewProvider(c Config) sous.SecretProvider {
+	switch {
+	case c.VaultAddr != "":
+		return &VaultProvider{Addr: c.VaultAddr, Token: c.VaultToken}
+	case c.Dir != "":
+		return &FileProvider{Dir: c.Dir}
+	}
+	return nil
+}
+
+// Secret implements sous.SecretProvider on FileProvider.
+func (p *FileProvider) Secret(ref sous.SecretRef) (string, error) {
+	path := filepath.Join(p.Dir, filepath.FromSlash(ref.Path))
+	if rel, err := filepath.Rel(p.Dir, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("secret path %q is outside %s", ref.Path, p.Dir)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", errors.Wrap(err, "reading secret")
+	}
+	if info.IsDir() {
+		if ref.Key == "" {
+			return "", errors.Errorf("secret %s is a directory, and needs a key", ref)
+		}
+		path = filepath.Join(path, filepath.Base(ref.Key))
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "reading secret")
+	}
+	if info.IsDir() || ref.Key == "" {
+		return strings.TrimSuffix(string(b), "\n"), nil
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", errors.Wrapf(err, "secret %s is not a JSON object", ref.Path)
+	}
+	return field(fields, ref)
+}
+
+// Secret implements sous.SecretProvider on VaultProvider.
+func (p *VaultProvider) Secret(ref sous.SecretRef) (string, error) {
+	if ref.Key == "" {
+		return "", errors.Errorf("secret %s needs a key to be read from Vault", ref)
+	}
+	url := strings.TrimSuffix(p.Addr, "/") + "/v1/" + strings.TrimPrefix(ref.Path, "/")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rz, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "reading secret from Vault")
+	}
+	defer rz.Body.Close()
+	if rz.StatusCode != http.StatusOK {
+		return "", errors.Errorf("reading secret %s from Vault: %s", ref.Path, rz.Status)
+	}
+	body := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(rz.Body).Decode(&body); err != nil {
+		return "", errors.Wrapf(err, "decoding secret %s from Vault", ref.Path)
+	}
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, v2 := data["metadata"]; v2 {
+			data = nested
+		}
+	}
+	return field(data, ref)
+}
+
+// field returns the field of fields named by ref's key, which must be a
+// string.
+func field(fields map[string]interface{}, ref sous.SecretRef) (string, error) {
+	v, ok := fields[ref.Key]
+	if !ok {
+		return "", errors.Errorf("secret %s has no key %q", ref.Path, ref.Key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errors.Errorf("secret %s key %q is not a string", ref.Path, ref.Key)
+	}
+	return s, nil
+}
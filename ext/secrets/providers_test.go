@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "db", "prod"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "db", "prod", "password"), []byte("hunter2\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "api.json"), []byte(`{"token":"abc","n":1}`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "plain"), []byte("whole"), 0600))
+
+	p := &FileProvider{Dir: dir}
+	for ref, want := range map[sous.SecretRef]string{
+		{Path: "db/prod", Key: "password"}: "hunter2",
+		{Path: "api.json", Key: "token"}:   "abc",
+		{Path: "plain"}:                    "whole",
+	} {
+		got, err := p.Secret(ref)
+		if assert.NoError(t, err, "%s", ref) {
+			assert.Equal(t, want, got, "%s", ref)
+		}
+	}
+	for _, ref := range []sous.SecretRef{
+		{Path: "db/prod"},
+		{Path: "api.json", Key: "missing"},
+		{Path: "api.json", Key: "n"},
+		{Path: "missing"},
+		{Path: "../outside"},
+	} {
+		_, err := p.Secret(ref)
+		assert.Error(t, err, "%s", ref)
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "tok" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch req.URL.Path {
+		case "/v1/secret/db":
+			rw.Write([]byte(`{"data":{"password":"hunter2"}}`))
+		case "/v1/kv/data/db":
+			rw.Write([]byte(`{"data":{"data":{"password":"v2"},"metadata":{"version":3}}}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewProvider(Config{VaultAddr: srv.URL + "/", VaultToken: "tok", Dir: "ignored"})
+	got, err := p.Secret(sous.SecretRef{Path: "secret/db", Key: "password"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hunter2", got)
+	}
+	got, err = p.Secret(sous.SecretRef{Path: "kv/data/db", Key: "password"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "v2", got)
+	}
+	_, err = p.Secret(sous.SecretRef{Path: "secret/missing", Key: "password"})
+	assert.Error(t, err)
+	_, err = p.Secret(sous.SecretRef{Path: "secret/db"})
+	assert.Error(t, err)
+
+	forbidden := &VaultProvider{Addr: srv.URL}
+	_, err = forbidden.Secret(sous.SecretRef{Path: "secret/db", Key: "password"})
+	assert.Error(t, err)
+}
+
+func TestNewProvider(t *testing.T) {
+	assert.Nil(t, NewProvider(Config{}))
+	assert.IsType(t, &FileProvider{}, NewProvider(Config{Dir: "/secrets"}))
+}
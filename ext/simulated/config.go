@@ -0,0 +1,27 @@
+package simulated
+
+// Config is the configuration for simulated deployers.
+type Config struct {
+	// Dir is the directory under which the actual state of each simulated
+	// cluster is kept, in a subdirectory named for the cluster.
+	Dir string `env:"SOUS_SIMULATED_DIR"`
+	// LatencyMillis is how long each call to Rectify takes.
+	LatencyMillis int `env:"SOUS_SIMULATED_LATENCY_MILLIS"`
+	// PendingMillis is how long a rectified deployment remains pending before
+	// it becomes active (or fails).
+	PendingMillis int `env:"SOUS_SIMULATED_PENDING_MILLIS"`
+	// RectifyFailurePercent is the percentage of calls to Rectify which fail
+	// outright, as if the scheduler had rejected the request.
+	RectifyFailurePercent int `env:"SOUS_SIMULATED_RECTIFY_FAILURE_PERCENT"`
+	// DeployFailurePercent is the percentage of accepted deployments which
+	// end up failed rather than active.
+	DeployFailurePercent int `env:"SOUS_SIMULATED_DEPLOY_FAILURE_PERCENT"`
+}
+
+// DefaultConfig builds a default configuration, which can be then overridden by
+// client code.
+func DefaultConfig() Config {
+	return Config{
+		PendingMillis: 5000,
+	}
+}
@@ -0,0 +1,226 @@
+package simulated
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ClusterKind is the sous.Cluster Kind of clusters simulated by this
+// deployer.
+const ClusterKind = "simulated"
+
+type (
+	// deployer simulates a scheduler, keeping the actual state of each
+	// cluster on local disk.
+	deployer struct {
+		Config
+		sync.Mutex
+		rand    *rand.Rand
+		now     func() time.Time
+		dryrun  bool
+		secrets sous.SecretProvider
+		log     logging.LogSink
+	}
+
+	// DeployerOption is an option for configuring simulated deployers.
+	DeployerOption func(*deployer)
+)
+
+// NewDeployer creates a new simulated sous.Deployer.
+func NewDeployer(cfg Config, ls logging.LogSink, options ...DeployerOption) sous.Deployer {
+	d := &deployer{
+		Config: cfg,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now,
+		log:    ls,
+	}
+	if d.Dir == "" {
+		d.Dir = filepath.Join(os.TempDir(), "sous-simulated")
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	return d
+}
+
+// OptClock replaces the deployer's source of the current time.
+func OptClock(now func() time.Time) DeployerOption {
+	return func(d *deployer) { d.now = now }
+}
+
+// OptSeed makes the deployer's failure injection deterministic.
+func OptSeed(seed int64) DeployerOption {
+	return func(d *deployer) { d.rand = rand.New(rand.NewSource(seed)) }
+}
+
+// OptDryRun makes the deployer read the simulated actual state as usual, but
+// only log the changes it would make.
+func OptDryRun() DeployerOption {
+	return func(d *deployer) { d.dryrun = true }
+}
+
+// OptSecretProvider sets the provider that resolves references to secrets in
+// the Env of deployments. The references are checked, but only they are
+// recorded, never the values.
+func OptSecretProvider(p sous.SecretProvider) DeployerOption {
+	return func(d *deployer) { d.secrets = p }
+}
+
+func (r *deployer) store(clusterName string) store {
+	return store{dir: filepath.Join(r.Dir, clusterName)}
+}
+
+// chance returns true percent% of the time.
+func (r *deployer) chance(percent int) bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.rand.Intn(100) < percent
+}
+
+// deployState computes the current DeployState of rec. A paused deployment
+// runs no instances, so it becomes active at once and never fails.
+func (r *deployer) deployState(rec *record, cluster *sous.Cluster) *sous.DeployState {
+	ds := &sous.DeployState{
+		Deployment:   *rec.Deployment,
+		Status:       sous.DeployStatusPending,
+		SchedulerURL: "file://" + r.store(cluster.Name).path(rec.Deployment.ID()),
+	}
+	ds.Deployment.Cluster = cluster
+	ds.Deployment.ClusterName = cluster.Name
+	if rec.Deployment.IsPaused() {
+		ds.Status = sous.DeployStatusActive
+		ds.ExecutorMessage = "Paused: no instances running"
+		return ds
+	}
+	if r.now().Before(rec.ReadyAt) {
+		ds.ExecutorMessage = fmt.Sprintf("Pending until %s", rec.ReadyAt.Format(time.RFC3339))
+		return ds
+	}
+	if rec.WillFail {
+		ds.Status = sous.DeployStatusFailed
+		ds.ExecutorMessage = "Deploy failure: simulated failure"
+		return ds
+	}
+	ds.Status = sous.DeployStatusActive
+	return ds
+}
+
+// RunningDeployments reads the simulated actual state of clusters.
+func (r *deployer) RunningDeployments(reg sous.Registry, clusters sous.Clusters) (sous.DeployStates, error) {
+	deps := sous.NewDeployStates()
+	for _, name := range clusters.Names() {
+		cluster := clusters[name]
+		records, err := r.store(name).list()
+		if err != nil {
+			return deps, errors.Wrapf(err, "reading simulated cluster %s", name)
+		}
+		for _, rec := range records {
+			ds := r.deployState(rec, cluster)
+			messages.ReportLogFieldsMessage("Adding deployment", logging.DebugLevel, r.log, ds)
+			deps.Add(ds)
+		}
+	}
+	return deps, nil
+}
+
+// Status implements sous.Deployer on deployer.
+func (r *deployer) Status(reg sous.Registry, clusters sous.Clusters, pair *sous.DeployablePair) (*sous.DeployState, error) {
+	clusterName := pair.Post.Deployment.ClusterName
+	cluster, has := clusters[clusterName]
+	if !has {
+		return nil, errors.Errorf("No cluster found for %q. Known are: %q.", clusterName, clusters.Names())
+	}
+	if pair.UUID == uuid.Nil {
+		pair.UUID = uuid.NewV4()
+	}
+	rec, err := r.store(clusterName).read(pair.ID())
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting simulated deployment")
+	}
+	return r.deployState(rec, cluster), nil
+}
+
+// Rectify records pair.Post as the actual state of its cluster, after the
+// configured latency, unless a failure is injected.
+func (r *deployer) Rectify(pair *sous.DeployablePair) sous.DiffResolution {
+	if pair.UUID == uuid.Nil {
+		pair.UUID = uuid.NewV4()
+	}
+
+	switch k := pair.Kind(); k {
+	default:
+		panic(fmt.Sprintf("unrecognised kind %q", k))
+	case sous.SameKind:
+		resolution := pair.SameResolution()
+		if pair.Post.Status == sous.DeployStatusFailed {
+			resolution.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+		}
+		return resolution
+	case sous.AddedKind:
+		result := sous.DiffResolution{DeploymentID: pair.ID()}
+		if err := r.rectifyWrite(pair); err != nil {
+			result.Desc = "not created"
+			result.Error = sous.WrapResolveError(&sous.CreateError{Deployment: pair.Post.Deployment.Clone(), Err: err})
+		} else {
+			result.Desc = sous.CreateDiff
+		}
+		messages.ReportLogFieldsMessage("Result of create", logging.InformationLevel, r.log, result)
+		return result
+	case sous.RemovedKind:
+		// As with Singularity, Sous does not delete removed deployments.
+		messages.ReportLogFieldsMessage("Rectify not deleting simulated deployment", logging.WarningLevel, r.log, pair.ID())
+		return sous.DiffResolution{DeploymentID: pair.ID(), Desc: sous.DeleteDiff}
+	case sous.ModifiedKind:
+		result := sous.DiffResolution{DeploymentID: pair.ID()}
+		if err := r.rectifyWrite(pair); err != nil {
+			dp := &sous.DeploymentPair{
+				Prior: pair.Prior.Deployment.Clone(),
+				Post:  pair.Post.Deployment.Clone(),
+			}
+			result.Error = sous.WrapResolveError(&sous.ChangeError{Deployments: dp, Err: err})
+			result.Desc = "not updated"
+		} else if pair.Prior.Status == sous.DeployStatusFailed || pair.Post.Status == sous.DeployStatusFailed {
+			result.Desc = sous.ModifyDiff
+			result.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+		} else {
+			result.Desc = sous.ModifyDiff
+		}
+		messages.ReportLogFieldsMessage("Result of modify", logging.InformationLevel, r.log, result)
+		return result
+	}
+}
+
+func (r *deployer) rectifyWrite(pair *sous.DeployablePair) error {
+	if r.dryrun {
+		messages.ReportLogFieldsMessage("Dry run: not writing simulated deployment", logging.InformationLevel, r.log, pair.ID())
+		return nil
+	}
+	if r.LatencyMillis > 0 {
+		time.Sleep(time.Duration(r.LatencyMillis) * time.Millisecond)
+	}
+	if r.chance(r.RectifyFailurePercent) {
+		return errors.Errorf("simulated failure rectifying %s", pair.ID())
+	}
+	if _, err := pair.Post.Deployment.Env.ResolveSecrets(r.secrets); err != nil {
+		return err
+	}
+	dep := pair.Post.Deployment.Clone()
+	now := r.now()
+	rec := &record{
+		Deployment: dep,
+		AcceptedAt: now,
+		ReadyAt:    now.Add(time.Duration(r.PendingMillis) * time.Millisecond),
+		WillFail:   r.chance(r.DeployFailurePercent),
+	}
+	return r.store(dep.ClusterName).write(rec)
+}
@@ -0,0 +1,180 @@
+package simulated
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func setupDeployer(t *testing.T, cfg Config, options ...DeployerOption) (sous.Deployer, sous.Clusters, *testClock, func()) {
+	dir, err := ioutil.TempDir("", "sous-simulated-test")
+	require.NoError(t, err)
+	cfg.Dir = dir
+	clock := &testClock{t: time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)}
+	ls, _ := logging.NewLogSinkSpy()
+	d := NewDeployer(cfg, ls, append([]DeployerOption{OptClock(clock.now), OptSeed(1)}, options...)...)
+	clusters := sous.Clusters{"sim": &sous.Cluster{Name: "sim", Kind: ClusterKind}}
+	return d, clusters, clock, func() { os.RemoveAll(dir) }
+}
+
+func testDeployment(clusters sous.Clusters) *sous.Deployment {
+	return &sous.Deployment{
+		SourceID:    sous.MustNewSourceID("github.com/opentable/example", "", "1.2.3"),
+		ClusterName: "sim",
+		Cluster:     clusters["sim"],
+		Kind:        sous.ManifestKindService,
+		Owners:      sous.OwnerSet{"sam@example.com": struct{}{}},
+		DeployConfig: sous.DeployConfig{
+			NumInstances: 2,
+			Env:          sous.Env{"GREETING": "hello"},
+			Resources:    sous.Resources{"cpus": "0.1", "memory": "256", "ports": "1"},
+		},
+	}
+}
+
+func pairFor(prior *sous.DeployState, post *sous.Deployment) *sous.DeployablePair {
+	pair := &sous.DeployablePair{Post: &sous.Deployable{Deployment: post}}
+	if prior != nil {
+		pair.Prior = &sous.Deployable{Deployment: &prior.Deployment, Status: prior.Status}
+	}
+	pair.SetID(post.ID())
+	return pair
+}
+
+func TestDeployer_Lifecycle(t *testing.T) {
+	d, clusters, clock, done := setupDeployer(t, Config{PendingMillis: 1000})
+	defer done()
+
+	dep := testDeployment(clusters)
+	res := d.Rectify(pairFor(nil, dep))
+	require.Nil(t, res.Error)
+	assert.Equal(t, sous.CreateDiff, res.Desc)
+
+	states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	ds, ok := states.Get(dep.ID())
+	require.True(t, ok)
+	assert.Equal(t, sous.DeployStatusPending, ds.Status)
+	different, diffs := dep.Diff(&ds.Deployment)
+	assert.False(t, different, "%v", diffs)
+
+	clock.t = clock.t.Add(2 * time.Second)
+	ds, err = d.Status(sous.NewDummyRegistry(), clusters, pairFor(nil, dep))
+	require.NoError(t, err)
+	assert.Equal(t, sous.DeployStatusActive, ds.Status)
+
+	post := dep.Clone()
+	post.NumInstances = 4
+	res = d.Rectify(pairFor(ds, post))
+	require.Nil(t, res.Error)
+	assert.Equal(t, sous.ModifyDiff, res.Desc)
+
+	states, err = d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	ds, ok = states.Get(dep.ID())
+	require.True(t, ok)
+	assert.Equal(t, 4, ds.NumInstances)
+	assert.Equal(t, sous.DeployStatusPending, ds.Status)
+}
+
+func TestDeployer_RectifyFailure(t *testing.T) {
+	d, clusters, _, done := setupDeployer(t, Config{RectifyFailurePercent: 100})
+	defer done()
+
+	res := d.Rectify(pairFor(nil, testDeployment(clusters)))
+	assert.NotNil(t, res.Error)
+
+	states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	assert.Equal(t, 0, states.Len())
+}
+
+func TestDeployer_DryRun(t *testing.T) {
+	d, clusters, _, done := setupDeployer(t, Config{}, OptDryRun())
+	defer done()
+
+	res := d.Rectify(pairFor(nil, testDeployment(clusters)))
+	require.Nil(t, res.Error)
+	assert.Equal(t, sous.CreateDiff, res.Desc)
+
+	states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	assert.Equal(t, 0, states.Len())
+}
+
+func TestDeployer_DeployFailure(t *testing.T) {
+	d, clusters, _, done := setupDeployer(t, Config{DeployFailurePercent: 100})
+	defer done()
+
+	dep := testDeployment(clusters)
+	require.Nil(t, d.Rectify(pairFor(nil, dep)).Error)
+
+	ds, err := d.Status(sous.NewDummyRegistry(), clusters, pairFor(nil, dep))
+	require.NoError(t, err)
+	assert.Equal(t, sous.DeployStatusFailed, ds.Status)
+}
+
+type secretsProvider map[string]string
+
+func (p secretsProvider) Secret(ref sous.SecretRef) (string, error) {
+	return p[ref.String()], nil
+}
+
+func TestDeployer_Secrets(t *testing.T) {
+	d, clusters, _, done := setupDeployer(t, Config{})
+	defer done()
+
+	dep := testDeployment(clusters)
+	dep.Env["PASSWORD"] = "secret://db/prod#password"
+	assert.NotNil(t, d.Rectify(pairFor(nil, dep)).Error, "secrets cannot be resolved without a provider")
+
+	d, clusters, _, done = setupDeployer(t, Config{}, OptSecretProvider(secretsProvider{"secret://db/prod#password": "hunter2"}))
+	defer done()
+
+	dep = testDeployment(clusters)
+	dep.Env["PASSWORD"] = "secret://db/prod#password"
+	require.Nil(t, d.Rectify(pairFor(nil, dep)).Error)
+
+	states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	ds, ok := states.Get(dep.ID())
+	require.True(t, ok)
+	assert.Equal(t, "secret://db/prod#password", ds.Env["PASSWORD"], "only the reference is recorded")
+}
+
+func TestDeployer_Paused(t *testing.T) {
+	d, clusters, _, done := setupDeployer(t, Config{PendingMillis: 1000, DeployFailurePercent: 100})
+	defer done()
+
+	paused := true
+	dep := testDeployment(clusters)
+	dep.Paused = &paused
+	require.Nil(t, d.Rectify(pairFor(nil, dep)).Error)
+
+	states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	ds, ok := states.Get(dep.ID())
+	require.True(t, ok)
+	assert.Equal(t, sous.DeployStatusActive, ds.Status)
+	assert.True(t, ds.IsPaused())
+	different, diffs := dep.Diff(&ds.Deployment)
+	assert.False(t, different, "%v", diffs)
+
+	post := dep.Clone()
+	post.Paused = nil
+	require.Nil(t, d.Rectify(pairFor(ds, post)).Error)
+	ds, err = d.Status(sous.NewDummyRegistry(), clusters, pairFor(nil, post))
+	require.NoError(t, err)
+	assert.False(t, ds.IsPaused())
+	assert.Equal(t, sous.DeployStatusPending, ds.Status)
+}
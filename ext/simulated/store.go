@@ -0,0 +1,105 @@
+package simulated
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// record is the on-disk form of a simulated deployment.
+	record struct {
+		Deployment *sous.Deployment
+		// AcceptedAt is when the deployment was last rectified.
+		AcceptedAt time.Time
+		// ReadyAt is when the deployment stops being pending.
+		ReadyAt time.Time
+		// WillFail is true if the deployment fails rather than becoming
+		// active at ReadyAt.
+		WillFail bool
+	}
+
+	// store keeps the records of one simulated cluster in a directory, one
+	// file per deployment.
+	store struct {
+		dir string
+	}
+)
+
+func (s store) path(did sous.DeploymentID) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%x.json", did.Digest()))
+}
+
+func (s store) read(did sous.DeploymentID) (*record, error) {
+	return readRecord(s.path(did))
+}
+
+func readRecord(path string) (*record, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &record{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	if r.Deployment == nil {
+		return nil, errors.Errorf("%s has no deployment", path)
+	}
+	return r, nil
+}
+
+func (s store) list() ([]*record, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []*record
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		r, err := readRecord(filepath.Join(s.dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// write replaces the record for r's deployment, via a temporary file so
+// that readers never see a partial record.
+func (s store) write(r *record) error {
+	if err := os.MkdirAll(s.dir, 0777); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(s.dir, ".record")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(r.Deployment.ID()))
+}
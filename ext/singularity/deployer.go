@@ -5,7 +5,6 @@ import (
 	"runtime/debug"
 	"strings"
 
-	"github.com/opentable/go-singularity"
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
 	"github.com/opentable/sous/util/logging/messages"
@@ -14,6 +13,9 @@ import (
 	"github.com/satori/go.uuid"
 )
 
+// ClusterKind is the sous.Cluster Kind of clusters managed by this deployer.
+const ClusterKind = "singularity"
+
 // Both of these values are (for reasons only known to the spirits)
 // _configurable_ in singularity. If you've done something silly like configure
 // them differently than their defaults, at the moment we wish you the best of
@@ -62,6 +64,28 @@ type (
 
 		// DeleteRequest instructs Singularity to delete a particular request
 		DeleteRequest(cluster, reqID, message string) error
+
+		// DeployStaged creates a new deploy on a particular request, which
+		// stops after stepInstances new instances until advanced.
+		DeployStaged(d sous.Deployable, reqID, depID string, stepInstances int) error
+
+		// AdvanceDeploy lets a staged deploy proceed to instances new instances.
+		AdvanceDeploy(cluster, reqID, depID string, instances int) error
+
+		// CancelDeploy cancels a pending deploy.
+		CancelDeploy(cluster, reqID, depID string) error
+
+		// PauseRequest stops the tasks of a request until it is unpaused.
+		PauseRequest(cluster, reqID, message string) error
+
+		// UnpauseRequest restarts the tasks of a paused request.
+		UnpauseRequest(cluster, reqID, message string) error
+
+		// BounceRequest replaces the running tasks of a request.
+		BounceRequest(cluster, reqID, message string) error
+
+		// RunRequest starts a task of the request of a job now.
+		RunRequest(cluster, reqID string, opts sous.JobRunOptions, message string) error
 	}
 
 	// DTOMap is shorthand for map[string]interface{}
@@ -146,34 +170,45 @@ func (r *deployer) Rectify(pair *sous.DeployablePair) sous.DiffResolution {
 		messages.ReportLogFieldsMessage("Result of delete", logging.InformationLevel, r.log, postID, version, result)
 		return result
 	case sous.ModifiedKind:
-		result := sous.DiffResolution{DeploymentID: pair.ID()}
 		messages.ReportLogFieldsMessageToConsole(fmt.Sprintf("Starting a ModifiedKind %s:%s by user %s", postID, version, user), logging.ExtraDebug1Level, r.log, pair)
-		if err := r.RectifySingleModification(pair); err != nil {
-			dp := &sous.DeploymentPair{
-				Prior: pair.Prior.Deployment.Clone(),
-				Post:  pair.Post.Deployment.Clone(),
-			}
-			result.Error = sous.WrapResolveError(&sous.ChangeError{Deployments: dp, Err: err})
-			result.Desc = "not updated"
-		} else if pair.Prior.Status == sous.DeployStatusFailed || pair.Post.Status == sous.DeployStatusFailed {
-			result.Desc = sous.ModifyDiff
-			result.Error = sous.WrapResolveError(&sous.FailedStatusError{})
-		} else {
-			result.Desc = sous.ModifyDiff
-		}
-		reportDiffResolutionMessage("Result of modify", result, logging.InformationLevel, r.log)
-		messages.ReportLogFieldsMessage("Result of modify", logging.InformationLevel, r.log, postID, version, result)
+		result, _ := r.rectifyModification(pair, 0)
 		return result
 	}
 }
 
+// rectifyModification rectifies a ModifiedKind pair. If stepInstances is
+// positive, any new deploy is staged, starting with stepInstances instances,
+// and staged is true.
+func (r *deployer) rectifyModification(pair *sous.DeployablePair, stepInstances int) (result sous.DiffResolution, staged bool) {
+	result = sous.DiffResolution{DeploymentID: pair.ID()}
+	staged, err := r.rectifySingleModification(pair, stepInstances)
+	if err != nil {
+		dp := &sous.DeploymentPair{
+			Prior: pair.Prior.Deployment.Clone(),
+			Post:  pair.Post.Deployment.Clone(),
+		}
+		result.Error = sous.WrapResolveError(&sous.ChangeError{Deployments: dp, Err: err})
+		result.Desc = "not updated"
+	} else if pair.Prior.Status == sous.DeployStatusFailed || pair.Post.Status == sous.DeployStatusFailed {
+		result.Desc = sous.ModifyDiff
+		result.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+	} else {
+		result.Desc = sous.ModifyDiff
+	}
+	postID := pair.Post.ID().String()
+	version := pair.Post.DeploySpec().Version.String()
+	reportDiffResolutionMessage("Result of modify", result, logging.InformationLevel, r.log)
+	messages.ReportLogFieldsMessage("Result of modify", logging.InformationLevel, r.log, postID, version, result)
+	return result, staged && result.Error == nil
+}
+
 func (r *deployer) SetSingularityFactory(fn func(string) singClient) {
 	r.singFac = fn
 }
 
 func (r *deployer) buildSingClient(url string) singClient {
 	if r.singFac == nil {
-		return singularity.NewClient(url, r.log)
+		return newSingularityClient(url, r.log)
 	}
 	return r.singFac(url)
 }
@@ -207,12 +242,18 @@ func (r *deployer) RectifySingleCreate(d *sous.DeployablePair) (err error) {
 	}
 	depID := computeDeployIDFromUUID(d.Post, d.UUID)
 
-	return r.Client.Deploy(*d.Post, reqID, depID)
+	if err := r.Client.Deploy(*d.Post, reqID, depID); err != nil {
+		return err
+	}
+	if d.Post.IsPaused() {
+		return r.Client.PauseRequest(d.Post.Cluster.BaseURL, reqID, pauseMessage(d.Post))
+	}
+	return nil
 }
 
 func (r *deployer) RectifySingleDelete(d *sous.DeployablePair) (err error) {
 	defer rectifyRecover(d, "RectifySingleDelete", &err, r.log)
-	data, ok := d.ExecutorData.(*singularityTaskData)
+	data, ok := taskData(d.ExecutorData)
 	if !ok {
 		return errors.Errorf("Delete record %#v doesn't contain Singularity compatible data: was %T\n\t%#v", d.ID(), data, d)
 	}
@@ -228,6 +269,11 @@ func (r *deployer) RectifySingleDelete(d *sous.DeployablePair) (err error) {
 }
 
 func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err error) {
+	_, err = r.rectifySingleModification(pair, 0)
+	return err
+}
+
+func (r *deployer) rectifySingleModification(pair *sous.DeployablePair, stepInstances int) (staged bool, err error) {
 	different, diffs := pair.Post.Deployment.Diff(pair.Prior.Deployment)
 	if different {
 		reportDeployerMessage("Rectifying modified diffs", pair, diffs, nil, nil, logging.InformationLevel, r.log)
@@ -237,9 +283,9 @@ func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err err
 
 	defer rectifyRecover(pair, "RectifySingleModification", &err, r.log)
 
-	data, ok := pair.ExecutorData.(*singularityTaskData)
+	data, ok := taskData(pair.ExecutorData)
 	if !ok {
-		return errors.Errorf("Modification record %#v doesn't contain Singularity compatible data: was %T\n\t%#v", pair.ID(), data, pair)
+		return false, errors.Errorf("Modification record %#v doesn't contain Singularity compatible data: was %T\n\t%#v", pair.ID(), data, pair)
 	}
 	currentReqID := data.requestID
 	desiredReqID := pair.Post.Deployment.DeployConfig.SingularityRequestID
@@ -255,25 +301,32 @@ func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err err
 		reportDeployerMessage(m, pair, diffs, data, nil, logging.WarningLevel, r.log)
 
 		if err := r.Client.PostRequest(*pair.Post, desiredReqID); err != nil {
-			return err
+			return false, err
 		}
 
 		reportDeployerMessage("Deploying", pair, diffs, data, nil, logging.DebugLevel, r.log)
 		depID := computeDeployIDFromUUID(pair.Post, pair.UUID)
 		if err := r.Client.Deploy(*pair.Post, desiredReqID, depID); err != nil {
-			return err
+			return false, err
 		}
 		// TODO: Remove the old request.
 		//m = fmt.Sprintf("Renamed to %q", desiredReqID)
 		//return r.Client.DeleteRequest(pair.Post.Deployment.Cluster.BaseURL, currentReqID, m)
-		return nil
+		return false, nil
 	}
 
 	reportDeployerMessage("Operating on request", pair, diffs, data, nil, logging.ExtraDebug1Level, r.log)
+	cluster := pair.Post.Deployment.Cluster.BaseURL
+	if pair.Prior.IsPaused() && !pair.Post.IsPaused() {
+		reportDeployerMessage("Unpausing request", pair, diffs, data, nil, logging.DebugLevel, r.log)
+		if err := r.Client.UnpauseRequest(cluster, desiredReqID, pauseMessage(pair.Post)); err != nil {
+			return false, err
+		}
+	}
 	if changesReq(pair) {
 		reportDeployerMessage("Updating request", pair, diffs, data, nil, logging.DebugLevel, r.log)
 		if err := r.Client.PostRequest(*pair.Post, desiredReqID); err != nil {
-			return err
+			return false, err
 		}
 	} else {
 		reportDeployerMessage("No change to Singularity request required", pair, diffs, data, nil, logging.DebugLevel, r.log)
@@ -282,14 +335,70 @@ func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err err
 	if changesDep(pair) {
 		reportDeployerMessage("Deploying", pair, diffs, data, nil, logging.DebugLevel, r.log)
 		depID := computeDeployIDFromUUID(pair.Post, pair.UUID)
-		if err := r.Client.Deploy(*pair.Post, desiredReqID, depID); err != nil {
-			return err
+		if stepInstances > 0 {
+			if err := r.Client.DeployStaged(*pair.Post, desiredReqID, depID, stepInstances); err != nil {
+				return false, err
+			}
+			staged = true
+		} else if err := r.Client.Deploy(*pair.Post, desiredReqID, depID); err != nil {
+			return false, err
 		}
 	} else {
 		reportDeployerMessage("No change to Singularity deployment required", pair, diffs, data, nil, logging.DebugLevel, r.log)
 	}
 
-	return nil
+	if !pair.Prior.IsPaused() && pair.Post.IsPaused() {
+		reportDeployerMessage("Pausing request", pair, diffs, data, nil, logging.DebugLevel, r.log)
+		if err := r.Client.PauseRequest(cluster, desiredReqID, pauseMessage(pair.Post)); err != nil {
+			return false, err
+		}
+	}
+
+	return staged, nil
+}
+
+// pauseMessage describes who paused or unpaused d.
+func pauseMessage(d *sous.Deployable) string {
+	if d.IsPaused() {
+		return fmt.Sprintf("paused by %s", d.Deployment.User)
+	}
+	return fmt.Sprintf("unpaused by %s", d.Deployment.User)
+}
+
+// Bounce implements sous.Bouncer on deployer, replacing the tasks of the
+// Singularity request of d.
+func (r *deployer) Bounce(d *sous.Deployment, user sous.User) error {
+	reqID, err := deploymentRequestID(d)
+	if err != nil {
+		return err
+	}
+	return r.Client.BounceRequest(d.Cluster.BaseURL, reqID, fmt.Sprintf("bounced by %s", user))
+}
+
+// RunJob implements sous.JobRunner on deployer.
+func (r *deployer) RunJob(d *sous.Deployment, opts sous.JobRunOptions, user sous.User) error {
+	reqID, err := deploymentRequestID(d)
+	if err != nil {
+		return err
+	}
+	return r.Client.RunRequest(d.Cluster.BaseURL, reqID, opts, fmt.Sprintf("run by %s", user))
+}
+
+// JobRuns implements sous.JobRunner on deployer.
+func (r *deployer) JobRuns(d *sous.Deployment, count int) ([]sous.JobRun, error) {
+	reqID, err := deploymentRequestID(d)
+	if err != nil {
+		return nil, err
+	}
+	return jobRuns(newSingularityClient(d.Cluster.BaseURL, r.log), reqID, count)
+}
+
+// deploymentRequestID returns the ID of the Singularity request of d.
+func deploymentRequestID(d *sous.Deployment) (string, error) {
+	if d.SingularityRequestID != "" {
+		return d.SingularityRequestID, nil
+	}
+	return MakeRequestID(d.ID())
 }
 
 // XXX for logging and other UI purposes, the best thing would be if the
@@ -299,7 +408,8 @@ func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err err
 // could report ("deploy required because of %v", diffs)
 
 func changesReq(pair *sous.DeployablePair) bool {
-	return (pair.Prior.Kind == sous.ManifestKindScheduled && pair.Prior.Schedule != pair.Post.Schedule) ||
+	return (pair.Prior.Kind == sous.ManifestKindScheduled && (pair.Prior.Schedule != pair.Post.Schedule ||
+		pair.Prior.ScheduleTimeZone != pair.Post.ScheduleTimeZone)) ||
 		pair.Prior.Kind != pair.Post.Kind ||
 		pair.Prior.NumInstances != pair.Post.NumInstances ||
 		!pair.Prior.Owners.Equal(pair.Post.Owners)
@@ -264,8 +264,8 @@ func matchedPair(t *testing.T, startDep *sous.Deployment) *sous.DeployablePair {
 	depID := "dummy-deploy"
 	dockerName := "dummy-docker-image"
 	// This happens in DiskStateManager on Read.
-	flaws := startDep.Validate()
-	require.Empty(t, flaws)
+	_, errs := sous.RepairAll(startDep.Validate())
+	require.Empty(t, errs)
 
 	deployable := sous.Deployable{
 		Deployment: startDep,
@@ -376,11 +376,41 @@ func TestScheduling(t *testing.T) {
 	assert.False(t, changesDep(pair), "Roundtrip of Deployment through Singularity DTOs reported as changing Deploy!")
 }
 
+func TestSchedulingTimeZone(t *testing.T) {
+	startDep := baseDeployment()
+	startDep.Kind = sous.ManifestKindScheduled
+	startDep.Schedule = "0 3 * * *"
+	startDep.ScheduleTimeZone = "America/Los_Angeles"
+	pair := matchedPair(t, startDep)
+
+	assert.Equal(t, "America/Los_Angeles", pair.Post.ScheduleTimeZone)
+	assert.False(t, changesReq(pair), "Roundtrip of Deployment through Singularity DTOs reported as changing Request!")
+
+	pair.Prior.ScheduleTimeZone = "Europe/London"
+	assert.True(t, changesReq(pair), "Updating schedule time zone reported as not changing Request!")
+}
+
+func TestTCPAndCommandStartupChecks(t *testing.T) {
+	for _, startup := range []sous.Startup{
+		{CheckReadyProtocol: "TCP", CheckReadyPortIndex: 1, ConnectDelay: 30},
+		{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"grpc-health-probe", "-addr=:9000"}, ConnectDelay: 30},
+	} {
+		depMap := map[string]interface{}{}
+		assert.Error(t, MapStartupIntoHealthcheckOptions(&depMap, startup), "%s check mapped to a Singularity deploy", startup.CheckReadyProtocol)
+
+		startup.SkipCheck = true
+		assert.NoError(t, MapStartupIntoHealthcheckOptions(&depMap, startup))
+		assert.Nil(t, depMap["Healthcheck"])
+	}
+}
+
 func TestSchedulingOnlyForScheduled(t *testing.T) {
 	startDep := baseDeployment()
 	startDep.Schedule = "* 3 * * *"
 	pair := matchedPair(t, startDep)
+	assert.Empty(t, startDep.Schedule, "Schedule of HTTP service not repaired away!")
 	pair.Prior.Schedule = "* 2 * * *"
+	pair.Post.Schedule = "* 3 * * *"
 
 	diff, diffs := pair.Prior.Deployment.Diff(pair.Post.Deployment)
 	assert.False(t, diff)
@@ -687,3 +717,63 @@ func TestOptMaxHTTPReqsPerServer(t *testing.T) {
 		t.Fatalf("got %d; want %d", deployer2.ReqsPerServer, x)
 	}
 }
+
+func TestPauseModification(t *testing.T) {
+	pair := func(priorPaused, postPaused bool) *sous.DeployablePair {
+		dpl := &sous.Deployment{
+			SourceID: sous.SourceID{
+				Location: sous.SourceLocation{
+					Repo: "fake.tld/org/project",
+				},
+				Version: semv.MustParse("0.0.1"),
+			},
+			DeployConfig: sous.DeployConfig{
+				NumInstances: 1,
+				Resources:    sous.Resources{},
+			},
+			ClusterName: "cluster",
+			Cluster: &sous.Cluster{
+				BaseURL: "cluster",
+			},
+			User: sous.User{Name: "Test User"},
+		}
+		prior, post := dpl.Clone(), dpl.Clone()
+		prior.Paused, post.Paused = &priorPaused, &postPaused
+		artifact := &sous.BuildArtifact{DigestReference: "build-artifact", Type: "docker"}
+		return &sous.DeployablePair{
+			ExecutorData: &singularityTaskData{requestID: "reqid"},
+			Prior:        &sous.Deployable{BuildArtifact: artifact, Deployment: prior, Status: sous.DeployStatusActive},
+			Post:         &sous.Deployable{BuildArtifact: artifact, Deployment: post, Status: sous.DeployStatusActive},
+		}
+	}
+
+	drc := sous.NewDummyRectificationClient()
+	deployer := NewDeployer(drc, logging.SilentLogSet())
+
+	rez := deployer.Rectify(pair(false, true))
+	assert.Equal(t, sous.ModifyDiff, rez.Desc)
+	assert.Zero(t, rez.Error)
+	assert.Equal(t, []string{"reqid"}, drc.Paused)
+	assert.Empty(t, drc.Unpaused)
+	assert.Len(t, drc.Deployed, 0)
+
+	rez = deployer.Rectify(pair(true, false))
+	assert.Zero(t, rez.Error)
+	assert.Equal(t, []string{"reqid"}, drc.Unpaused)
+	assert.Len(t, drc.Paused, 1)
+}
+
+func TestBounce(t *testing.T) {
+	drc := sous.NewDummyRectificationClient()
+	deployer := NewDeployer(drc, logging.SilentLogSet()).(*deployer)
+
+	d := &sous.Deployment{
+		SourceID:    sous.MustNewSourceID("fake.tld/org/project", "", "0.0.1"),
+		ClusterName: "cluster",
+		Cluster:     &sous.Cluster{BaseURL: "cluster"},
+	}
+	d.SingularityRequestID = "reqid"
+
+	require.NoError(t, deployer.Bounce(d, sous.User{Name: "Test User"}))
+	assert.Equal(t, []string{"reqid"}, drc.Bounced)
+}
@@ -394,11 +394,18 @@ func (db *deploymentBuilder) restoreFromMetadata() error {
 }
 
 func (db *deploymentBuilder) unpackDeployConfig() error {
-	db.Target.Env = db.deploy.Env
-	messages.ReportLogFieldsMessage("UnpackDeployConfig", logging.ExtraDebug1Level, db.log, db.reqID, db.deploy.Env)
-	if db.Target.Env == nil {
-		db.Target.Env = make(map[string]string)
+	db.Target.Env = make(sous.Env, len(db.deploy.Env))
+	for name, value := range db.deploy.Env {
+		db.Target.Env[name] = value
+	}
+	// Variables resolved from secrets are restored to their references, so
+	// that their values are neither compared with the GDM nor logged.
+	for label, ref := range db.deploy.Metadata {
+		if name := strings.TrimPrefix(label, sous.SecretEnvLabel); name != label {
+			db.Target.Env[name] = ref
+		}
 	}
+	messages.ReportLogFieldsMessage("UnpackDeployConfig", logging.ExtraDebug1Level, db.log, db.reqID, db.Target.Env)
 
 	singRez := db.deploy.Resources
 	if singRez == nil {
@@ -410,6 +417,10 @@ func (db *deploymentBuilder) unpackDeployConfig() error {
 	db.Target.Resources["ports"] = fmt.Sprintf("%d", singRez.NumPorts)
 
 	db.Target.NumInstances = int(db.request.Instances)
+	if db.req.ReqParent != nil && db.req.ReqParent.State == dtos.SingularityRequestParentRequestStatePAUSED {
+		paused := true
+		db.Target.Paused = &paused
+	}
 	db.Target.Owners = make(sous.OwnerSet)
 	for _, o := range db.request.Owners {
 		db.Target.Owners.Add(o)
@@ -485,6 +496,7 @@ func (db *deploymentBuilder) extractSchedule() error {
 			return fmt.Errorf("request is nil")
 		}
 		db.Target.DeployConfig.Schedule = db.request.Schedule
+		db.Target.DeployConfig.ScheduleTimeZone = db.request.ScheduleTimeZone
 	}
 	return nil
 }
@@ -458,3 +458,23 @@ func TestConstructDeployment(t *testing.T) {
 	}
 }
 */
+
+func TestUnpackDeployConfig_secrets(t *testing.T) {
+	db := &deploymentBuilder{
+		deploy: &dtos.SingularityDeploy{
+			Env: map[string]string{"PLAIN": "value", "PASSWORD": "hunter2"},
+			Metadata: map[string]string{
+				sous.SecretEnvLabel + "PASSWORD": "secret://db/prod#password",
+			},
+			Resources:     &dtos.Resources{},
+			ContainerInfo: &dtos.SingularityContainerInfo{},
+		},
+		request: &dtos.SingularityRequest{},
+		log:     logging.SilentLogSet(),
+	}
+	if err := db.unpackDeployConfig(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, sous.Env{"PLAIN": "value", "PASSWORD": "secret://db/prod#password"}, db.Target.Env)
+	assert.Equal(t, "hunter2", db.deploy.Env["PASSWORD"])
+}
@@ -0,0 +1,147 @@
+package singularity
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/opentable/go-singularity/dtos"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/swaggering"
+	"github.com/pkg/errors"
+)
+
+// runNowRequest is the body of a request to run a Singularity request now.
+// It is sent in place of dtos.SingularityRunNowRequest, which lacks the
+// envOverrides field.
+type runNowRequest struct {
+	RunID           string            `json:"runId,omitempty"`
+	CommandLineArgs []string          `json:"commandLineArgs,omitempty"`
+	EnvOverrides    map[string]string `json:"envOverrides,omitempty"`
+	Message         string            `json:"message,omitempty"`
+}
+
+// exitStatus matches the status messages of the various Mesos executors
+// reporting the exit code of a task.
+var exitStatus = regexp.MustCompile(`(?i)exit(?:ed)?(?: with)? (?:status|code) (-?\d+)`)
+
+func newRunNowRequest(opts sous.JobRunOptions, message string) *runNowRequest {
+	return &runNowRequest{
+		RunID:           opts.RunID,
+		CommandLineArgs: opts.Args,
+		EnvOverrides:    opts.Env,
+		Message:         "Sous: " + message,
+	}
+}
+
+// Populate implements swaggering.DTO on runNowRequest.
+func (r *runNowRequest) Populate(body io.ReadCloser) error {
+	defer body.Close()
+	return json.NewDecoder(body).Decode(r)
+}
+
+// Absorb implements swaggering.DTO on runNowRequest.
+func (r *runNowRequest) Absorb(other swaggering.DTO) error {
+	if like, ok := other.(*runNowRequest); ok {
+		*r = *like
+		return nil
+	}
+	return errors.Errorf("a runNowRequest cannot copy the values from %#v", other)
+}
+
+// FormatText implements swaggering.DTO on runNowRequest.
+func (r *runNowRequest) FormatText() string {
+	return r.FormatJSON()
+}
+
+// FormatJSON implements swaggering.DTO on runNowRequest.
+func (r *runNowRequest) FormatJSON() string {
+	bs, _ := json.Marshal(r)
+	return string(bs)
+}
+
+// jobRuns returns the count most recently started tasks of the request reqID,
+// with their exit codes.
+func jobRuns(client singClient, reqID string, count int) ([]sous.JobRun, error) {
+	active, inactive, err := requestTasks(client, reqID, int32(count))
+	if err != nil {
+		return nil, err
+	}
+	runs := []sous.JobRun{}
+	seen := map[string]bool{}
+	for _, h := range append(active, inactive...) {
+		if h.TaskId == nil || seen[h.TaskId.Id] {
+			continue
+		}
+		seen[h.TaskId.Id] = true
+		runs = append(runs, sous.JobRun{
+			TaskID:    h.TaskId.Id,
+			RunID:     h.RunId,
+			State:     string(h.LastTaskState),
+			StartedAt: fromMillis(h.TaskId.StartedAt),
+		})
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].TaskID < runs[j].TaskID
+	})
+	if len(runs) > count {
+		runs = runs[:count]
+	}
+
+	for i := range runs {
+		history, err := client.GetHistoryForTask(runs[i].TaskID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting history of task %s", runs[i].TaskID)
+		}
+		endRun(&runs[i], history)
+	}
+	return runs, nil
+}
+
+// endRun sets the end time and exit code of run from history, if it has
+// ended.
+func endRun(run *sous.JobRun, history *dtos.SingularityTaskHistory) {
+	if history == nil {
+		return
+	}
+	for _, u := range history.TaskUpdates {
+		if !taskEnded(u.TaskState) {
+			continue
+		}
+		run.State = string(u.TaskState)
+		run.EndedAt = fromMillis(u.Timestamp)
+		if m := exitStatus.FindStringSubmatch(u.StatusMessage); m != nil {
+			if code, err := strconv.Atoi(m[1]); err == nil {
+				run.ExitCode = &code
+			}
+		} else if u.TaskState == dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_FINISHED {
+			code := 0
+			run.ExitCode = &code
+		}
+	}
+}
+
+func taskEnded(state dtos.SingularityTaskHistoryUpdateExtendedTaskState) bool {
+	switch state {
+	default:
+		return false
+	case dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_FINISHED,
+		dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_FAILED,
+		dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_KILLED,
+		dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_LOST,
+		dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_LOST_WHILE_DOWN,
+		dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_ERROR:
+		return true
+	}
+}
+
+// fromMillis converts a Singularity timestamp to a time.Time.
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
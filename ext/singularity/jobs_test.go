@@ -0,0 +1,117 @@
+package singularity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/spies"
+	"github.com/opentable/go-singularity/dtos"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRuns(t *testing.T) {
+	millis := func(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+	now := time.Now().Truncate(time.Millisecond)
+	task := func(id, runID string, started time.Time) *dtos.SingularityTaskIdHistory {
+		return &dtos.SingularityTaskIdHistory{
+			RunId:  runID,
+			TaskId: &dtos.SingularityTaskId{Id: id, StartedAt: millis(started)},
+		}
+	}
+	update := func(state dtos.SingularityTaskHistoryUpdateExtendedTaskState, at time.Time, message string) *dtos.SingularityTaskHistoryUpdate {
+		return &dtos.SingularityTaskHistoryUpdate{TaskState: state, Timestamp: millis(at), StatusMessage: message}
+	}
+	forTask := func(id string) func(mock.Arguments) bool {
+		return func(args mock.Arguments) bool { return args.String(0) == id }
+	}
+
+	sing, ctrl := newSingClientSpy()
+	ctrl.MatchMethod("GetTaskHistoryForActiveRequest", spies.AnyArgs,
+		dtos.SingularityTaskIdHistoryList{task("running", "run-3", now.Add(-time.Minute))}, nil)
+	ctrl.MatchMethod("GetDeploys", spies.AnyArgs, dtos.SingularityDeployHistoryList{
+		{DeployMarker: &dtos.SingularityDeployMarker{DeployId: "dep1"}},
+	}, nil)
+	ctrl.MatchMethod("GetInactiveDeployTasks", spies.AnyArgs, dtos.SingularityTaskIdHistoryList{
+		task("failed", "", now.Add(-2*time.Hour)),
+		task("finished", "run-1", now.Add(-3*time.Hour)),
+		task("oldest", "", now.Add(-4*time.Hour)),
+	}, nil)
+	ctrl.MatchMethod("GetHistoryForTask", forTask("running"), &dtos.SingularityTaskHistory{
+		TaskUpdates: dtos.SingularityTaskHistoryUpdateList{
+			update(dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_RUNNING, now, ""),
+		},
+	}, nil)
+	ctrl.MatchMethod("GetHistoryForTask", forTask("failed"), &dtos.SingularityTaskHistory{
+		TaskUpdates: dtos.SingularityTaskHistoryUpdateList{
+			update(dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_RUNNING, now.Add(-2*time.Hour), ""),
+			update(dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_FAILED, now.Add(-time.Hour), "Command exited with status 3"),
+		},
+	}, nil)
+	ctrl.MatchMethod("GetHistoryForTask", forTask("finished"), &dtos.SingularityTaskHistory{
+		TaskUpdates: dtos.SingularityTaskHistoryUpdateList{
+			update(dtos.SingularityTaskHistoryUpdateExtendedTaskStateTASK_FINISHED, now.Add(-2*time.Hour), ""),
+		},
+	}, nil)
+
+	runs, err := jobRuns(sing, "request-id", 3)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+
+	assert.Equal(t, "running", runs[0].TaskID)
+	assert.Equal(t, "run-3", runs[0].RunID)
+	assert.True(t, runs[0].EndedAt.IsZero())
+	assert.Nil(t, runs[0].ExitCode)
+
+	assert.Equal(t, "failed", runs[1].TaskID)
+	assert.Equal(t, "TASK_FAILED", runs[1].State)
+	assert.True(t, runs[1].EndedAt.Equal(now.Add(-time.Hour)))
+	require.NotNil(t, runs[1].ExitCode)
+	assert.Equal(t, 3, *runs[1].ExitCode)
+
+	assert.Equal(t, sous.JobRun{
+		TaskID:    "finished",
+		RunID:     "run-1",
+		State:     "TASK_FINISHED",
+		StartedAt: runs[2].StartedAt,
+		EndedAt:   runs[2].EndedAt,
+		ExitCode:  runs[2].ExitCode,
+	}, runs[2])
+	require.NotNil(t, runs[2].ExitCode)
+	assert.Equal(t, 0, *runs[2].ExitCode)
+
+	// Only the listed runs have their histories read.
+	assert.Len(t, ctrl.CallsTo("GetHistoryForTask"), 3)
+}
+
+func TestRunJob(t *testing.T) {
+	drc := sous.NewDummyRectificationClient()
+	deployer := NewDeployer(drc, logging.SilentLogSet()).(*deployer)
+
+	d := &sous.Deployment{
+		SourceID:    sous.MustNewSourceID("fake.tld/org/project", "", "0.0.1"),
+		ClusterName: "cluster",
+		Cluster:     &sous.Cluster{BaseURL: "cluster"},
+	}
+	opts := sous.JobRunOptions{RunID: "run-1", Args: []string{"-v"}, Env: map[string]string{"A": "1"}}
+
+	require.NoError(t, deployer.RunJob(d, opts, sous.User{Name: "Test User"}))
+	assert.Equal(t, []sous.JobRunOptions{opts}, drc.Ran)
+}
+
+func TestNewRunNowRequest(t *testing.T) {
+	r := newRunNowRequest(sous.JobRunOptions{
+		RunID: "run-1",
+		Args:  []string{"-v"},
+		Env:   map[string]string{"A": "1"},
+	}, "run by Test User")
+	assert.JSONEq(t, `{
+		"runId": "run-1",
+		"commandLineArgs": ["-v"],
+		"envOverrides": {"A": "1"},
+		"message": "Sous: run by Test User"
+	}`, r.FormatJSON())
+}
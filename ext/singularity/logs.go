@@ -0,0 +1,220 @@
+package singularity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+const (
+	// recentDeployCount is the number of past deploys whose finished tasks
+	// are listed by LogReader.Tasks and jobRuns.
+	recentDeployCount = 3
+	// recentTaskCount is the number of finished tasks listed per deploy.
+	recentTaskCount = 5
+	// logChunkLength is the most bytes read from a sandbox file at once.
+	logChunkLength = 64 * 1024
+	// DefaultLogPollInterval is how often a followed log is read when
+	// LogOptions.PollInterval is not set.
+	DefaultLogPollInterval = 2 * time.Second
+)
+
+type (
+	// A LogReader reads the stdout and stderr of the tasks of a Singularity
+	// request from their sandboxes.
+	LogReader struct {
+		RequestID string
+		client    singClient
+	}
+
+	// A LogTask is a task of a Singularity request.
+	LogTask struct {
+		ID, Host, State string
+		StartedAt       time.Time
+		// Active is true if the task is running.
+		Active bool
+	}
+
+	// LogOptions select the tasks whose logs LogReader.Stream reads.
+	LogOptions struct {
+		// TaskID, if not empty, selects only that task.
+		TaskID string
+		// Since, if not zero, selects the tasks started within that long ago.
+		// Otherwise the active tasks are selected, or the most recent task if
+		// none is active.
+		Since time.Duration
+		// Follow keeps reading new output until the context is done.
+		Follow bool
+		// PollInterval is how often new output is read when following.
+		PollInterval time.Duration
+	}
+
+	logFile struct {
+		taskID, path string
+	}
+)
+
+// logPaths are the sandbox files read for each task.
+var logPaths = []string{"stdout", "stderr"}
+
+// NewLogReader returns a LogReader for the Singularity request reqID at
+// baseURL.
+func NewLogReader(baseURL, reqID string, ls logging.LogSink) *LogReader {
+	return &LogReader{
+		RequestID: reqID,
+		client:    newSingularityClient(baseURL, ls),
+	}
+}
+
+// Tasks returns the active tasks of the request, and the most recent finished
+// tasks of its last few deploys, most recently started first.
+func (lr *LogReader) Tasks() ([]LogTask, error) {
+	active, inactive, err := requestTasks(lr.client, lr.RequestID, recentTaskCount)
+	if err != nil {
+		return nil, err
+	}
+	tasks := map[string]LogTask{}
+	addTasks(tasks, active, true)
+	addTasks(tasks, inactive, false)
+
+	list := make([]LogTask, 0, len(tasks))
+	for _, t := range tasks {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].StartedAt.Equal(list[j].StartedAt) {
+			return list[i].StartedAt.After(list[j].StartedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// requestTasks returns the active tasks of the request reqID, and up to
+// perDeploy finished tasks of each of its last few deploys.
+func requestTasks(client singClient, reqID string, perDeploy int32) (active, inactive dtos.SingularityTaskIdHistoryList, err error) {
+	active, err = client.GetTaskHistoryForActiveRequest(reqID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "listing active tasks of %s", reqID)
+	}
+	deploys, err := client.GetDeploys(reqID, recentDeployCount, 1)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "listing deploys of %s", reqID)
+	}
+	for _, d := range deploys {
+		if d.DeployMarker == nil {
+			continue
+		}
+		tasks, err := client.GetInactiveDeployTasks(reqID, d.DeployMarker.DeployId, perDeploy, 1)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "listing tasks of deploy %s of %s", d.DeployMarker.DeployId, reqID)
+		}
+		inactive = append(inactive, tasks...)
+	}
+	return active, inactive, nil
+}
+
+func addTasks(tasks map[string]LogTask, hs dtos.SingularityTaskIdHistoryList, active bool) {
+	for _, h := range hs {
+		if h.TaskId == nil {
+			continue
+		}
+		if _, seen := tasks[h.TaskId.Id]; seen {
+			continue
+		}
+		tasks[h.TaskId.Id] = LogTask{
+			ID:        h.TaskId.Id,
+			Host:      h.TaskId.Host,
+			State:     string(h.LastTaskState),
+			StartedAt: fromMillis(h.TaskId.StartedAt),
+			Active:    active,
+		}
+	}
+}
+
+// selectTasks returns the tasks chosen by opts, as described by LogOptions.
+func (lr *LogReader) selectTasks(opts LogOptions) ([]LogTask, error) {
+	if opts.TaskID != "" {
+		return []LogTask{{ID: opts.TaskID}}, nil
+	}
+	tasks, err := lr.Tasks()
+	if err != nil {
+		return nil, err
+	}
+	var selected []LogTask
+	for _, t := range tasks {
+		if opts.Since > 0 && time.Since(t.StartedAt) <= opts.Since ||
+			opts.Since == 0 && t.Active {
+			selected = append(selected, t)
+		}
+	}
+	if len(selected) == 0 && opts.Since == 0 && len(tasks) > 0 {
+		selected = tasks[:1]
+	}
+	if len(selected) == 0 {
+		return nil, errors.Errorf("no tasks of %s were selected", lr.RequestID)
+	}
+	return selected, nil
+}
+
+// Stream writes the stdout and stderr of the tasks selected by opts to out,
+// each preceded by a header naming the task and file. If opts.Follow is true,
+// it keeps writing new output, including that of tasks started meanwhile,
+// until ctx is done.
+func (lr *LogReader) Stream(ctx context.Context, out io.Writer, opts LogOptions) error {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultLogPollInterval
+	}
+	offsets := map[logFile]int64{}
+	var last logFile
+	for {
+		tasks, err := lr.selectTasks(opts)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			for _, path := range logPaths {
+				f := logFile{taskID: t.ID, path: path}
+				if err := lr.readFile(out, f, offsets, &last); err != nil {
+					return err
+				}
+			}
+		}
+		if !opts.Follow {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// readFile writes the contents of f after offsets[f] to out, and advances
+// offsets[f] past them. If f is not last, the contents are preceded by a
+// header, and f becomes last.
+func (lr *LogReader) readFile(out io.Writer, f logFile, offsets map[logFile]int64, last *logFile) error {
+	for {
+		chunk, err := lr.client.Read(f.taskID, f.path, "", offsets[f], logChunkLength)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s of task %s", f.path, f.taskID)
+		}
+		if chunk == nil || len(chunk.Data) == 0 {
+			return nil
+		}
+		if *last != f {
+			fmt.Fprintf(out, "==> %s %s <==\n", f.taskID, f.path)
+			*last = f
+		}
+		io.WriteString(out, chunk.Data)
+		offsets[f] += int64(len(chunk.Data))
+	}
+}
@@ -0,0 +1,87 @@
+package singularity
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nyarly/spies"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func logReaderFixture(t *testing.T) (*LogReader, singClientSpyController) {
+	t.Helper()
+	task := func(id string, started time.Time) *dtos.SingularityTaskIdHistory {
+		return &dtos.SingularityTaskIdHistory{
+			TaskId: &dtos.SingularityTaskId{Id: id, StartedAt: started.UnixNano() / int64(time.Millisecond)},
+		}
+	}
+	now := time.Now()
+
+	sing, ctrl := newSingClientSpy()
+	ctrl.MatchMethod("GetTaskHistoryForActiveRequest", spies.AnyArgs,
+		dtos.SingularityTaskIdHistoryList{task("running", now.Add(-time.Minute))}, nil)
+	ctrl.MatchMethod("GetDeploys", spies.AnyArgs, dtos.SingularityDeployHistoryList{
+		{DeployMarker: &dtos.SingularityDeployMarker{DeployId: "dep2"}},
+		{DeployMarker: &dtos.SingularityDeployMarker{DeployId: "dep1"}},
+	}, nil)
+	ctrl.MatchMethod("GetInactiveDeployTasks", func(args mock.Arguments) bool { return args.String(1) == "dep2" },
+		dtos.SingularityTaskIdHistoryList{task("failed", now.Add(-time.Hour))}, nil)
+	ctrl.MatchMethod("GetInactiveDeployTasks", spies.AnyArgs,
+		dtos.SingularityTaskIdHistoryList{task("old", now.Add(-48*time.Hour))}, nil)
+	ctrl.MatchMethod("Read", func(args mock.Arguments) bool { return args.Get(3).(int64) == 0 },
+		&dtos.MesosFileChunkObject{Data: "output\n"}, nil)
+	ctrl.MatchMethod("Read", spies.AnyArgs, &dtos.MesosFileChunkObject{}, nil)
+
+	return &LogReader{RequestID: "request-id", client: sing}, ctrl
+}
+
+func TestLogReader_Tasks(t *testing.T) {
+	lr, _ := logReaderFixture(t)
+
+	tasks, err := lr.Tasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, "running", tasks[0].ID)
+	assert.True(t, tasks[0].Active)
+	assert.Equal(t, "failed", tasks[1].ID)
+	assert.False(t, tasks[1].Active)
+	assert.Equal(t, "old", tasks[2].ID)
+}
+
+func TestLogReader_selectTasks(t *testing.T) {
+	lr, _ := logReaderFixture(t)
+	ids := func(opts LogOptions) []string {
+		tasks, err := lr.selectTasks(opts)
+		require.NoError(t, err)
+		var ids []string
+		for _, t := range tasks {
+			ids = append(ids, t.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"running"}, ids(LogOptions{}))
+	assert.Equal(t, []string{"running", "failed"}, ids(LogOptions{Since: 2 * time.Hour}))
+	assert.Equal(t, []string{"other"}, ids(LogOptions{TaskID: "other"}))
+}
+
+func TestLogReader_Stream(t *testing.T) {
+	lr, ctrl := logReaderFixture(t)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, lr.Stream(context.Background(), out, LogOptions{Since: 2 * time.Hour}))
+	assert.Equal(t, "==> running stdout <==\noutput\n"+
+		"==> running stderr <==\noutput\n"+
+		"==> failed stdout <==\noutput\n"+
+		"==> failed stderr <==\noutput\n", out.String())
+
+	// Each file is read from where its output ended.
+	reads := ctrl.CallsTo("Read")
+	require.Len(t, reads, 8)
+	assert.Equal(t, int64(len("output\n")), reads[1].PassedArgs().Get(3))
+}
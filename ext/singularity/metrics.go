@@ -0,0 +1,56 @@
+package singularity
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	singularity "github.com/opentable/go-singularity"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/prometheus"
+	"github.com/opentable/swaggering"
+)
+
+type (
+	// instrumentedTransport records the latency of requests to a Singularity.
+	instrumentedTransport struct {
+		singularity string
+		next        http.RoundTripper
+	}
+)
+
+var requestDuration = prometheus.NewHistogramVec(
+	"sous_singularity_request_duration_seconds",
+	"How long HTTP requests to Singularity take.",
+	nil, "singularity", "method", "status")
+
+// RegisterMetrics registers the metrics of requests to Singularity with r.
+func RegisterMetrics(r *prometheus.Registry) {
+	r.Register(requestDuration)
+}
+
+// newSingularityClient returns a client of the Singularity at url whose
+// requests are instrumented.
+func newSingularityClient(url string, ls ...logging.LogSink) *singularity.Client {
+	c := singularity.NewClient(url, ls...)
+	if gc, ok := c.Requester.(*swaggering.GenericClient); ok {
+		gc.HTTP.Transport = &instrumentedTransport{singularity: url, next: gc.HTTP.Transport}
+	}
+	return c
+}
+
+// RoundTrip implements http.RoundTripper on instrumentedTransport.
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	start := time.Now()
+	rz, err := next.RoundTrip(req)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(rz.StatusCode)
+	}
+	requestDuration.Observe(time.Since(start).Seconds(), t.singularity, req.Method, status)
+	return rz, err
+}
@@ -0,0 +1,38 @@
+package singularity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &instrumentedTransport{singularity: srv.URL}}
+	rz, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	rz.Body.Close()
+
+	found := false
+	for _, s := range requestDuration.Collect().Samples {
+		if s.Suffix != "_count" {
+			continue
+		}
+		labels := map[string]string{}
+		for _, l := range s.Labels {
+			labels[l.Name] = l.Value
+		}
+		if labels["singularity"] == srv.URL && labels["method"] == "GET" && labels["status"] == "418" {
+			found = true
+			assert.Equal(t, 1.0, s.Value)
+		}
+	}
+	assert.True(t, found, "no sample recorded for %s", srv.URL)
+}
@@ -1,6 +1,7 @@
 package singularity
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -38,6 +39,7 @@ type (
 		singClients map[string]swaggering.Requester
 		sync.RWMutex
 		labeller sous.ImageLabeller
+		secrets  sous.SecretProvider
 		log      logging.LogSink
 	}
 
@@ -46,6 +48,31 @@ type (
 	}
 )
 
+// storedTaskData is the JSON encoding of a singularityTaskData.
+type storedTaskData struct{ RequestID string }
+
+// MarshalJSON implements json.Marshaler on singularityTaskData, so that it is
+// kept when a queued rectification is stored.
+func (d *singularityTaskData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(storedTaskData{RequestID: d.requestID})
+}
+
+// taskData returns the singularityTaskData in executorData, decoding it if it
+// is the JSON of a rectification restored from storage.
+func taskData(executorData interface{}) (*singularityTaskData, bool) {
+	switch data := executorData.(type) {
+	case *singularityTaskData:
+		return data, true
+	case json.RawMessage:
+		stored := storedTaskData{}
+		if err := json.Unmarshal(data, &stored); err != nil || stored.RequestID == "" {
+			return nil, false
+		}
+		return &singularityTaskData{requestID: stored.RequestID}, true
+	}
+	return nil, false
+}
+
 // NewRectiAgent returns a set-up RectiAgent
 func NewRectiAgent(l sous.ImageLabeller, ls logging.LogSink) *RectiAgent {
 	return &RectiAgent{
@@ -55,6 +82,12 @@ func NewRectiAgent(l sous.ImageLabeller, ls logging.LogSink) *RectiAgent {
 	}
 }
 
+// SetSecretProvider sets the provider that resolves references to secrets in
+// the Env of deployments, just before they are sent to Singularity.
+func (ra *RectiAgent) SetSecretProvider(p sous.SecretProvider) {
+	ra.secrets = p
+}
+
 // mapResources produces a dtoMap appropriate for building a Singularity
 // dto.Resources struct from
 func mapResources(r sous.Resources) dtoMap {
@@ -67,6 +100,16 @@ func mapResources(r sous.Resources) dtoMap {
 
 // Deploy sends requests to Singularity to make a deployment happen
 func (ra *RectiAgent) Deploy(d sous.Deployable, reqID, depID string) error {
+	return ra.deploy(d, reqID, depID, 0)
+}
+
+// DeployStaged sends requests to Singularity to start a deployment which
+// brings up only stepInstances new instances until AdvanceDeploy is called.
+func (ra *RectiAgent) DeployStaged(d sous.Deployable, reqID, depID string, stepInstances int) error {
+	return ra.deploy(d, reqID, depID, stepInstances)
+}
+
+func (ra *RectiAgent) deploy(d sous.Deployable, reqID, depID string, stepInstances int) error {
 	if d.BuildArtifact == nil {
 		return &sous.MissingImageNameError{Cause: fmt.Errorf("Missing BuildArtifact on Deployable")}
 	}
@@ -82,9 +125,23 @@ func (ra *RectiAgent) Deploy(d sous.Deployable, reqID, depID string) error {
 	if err != nil {
 		return err
 	}
+	if stepInstances > 0 {
+		if err := stageDeploy(depReq.Deploy, stepInstances); err != nil {
+			return err
+		}
+	}
 
 	messages.ReportLogFieldsMessage("Sending Deploy req to singularity Client", logging.DebugLevel, ra.log, depReq)
 
+	// Secrets are resolved only now, after the request has been logged, and
+	// are removed again before the request can be logged again.
+	refs := depReq.Deploy.Env
+	env, err := sous.Env(refs).ResolveSecrets(ra.secrets)
+	if err != nil {
+		return err
+	}
+	depReq.Deploy.Env = env
+
 	pathParamMap := map[string]interface{}{}
 
 	user := "sous"
@@ -99,6 +156,7 @@ func (ra *RectiAgent) Deploy(d sous.Deployable, reqID, depID string) error {
 	response := new(dtos.SingularityRequestParent)
 
 	err = ra.getSingularityRequester(clusterURI).DTORequest("singularity-deploy", response, "POST", "/api/deploys", pathParamMap, queryParamMap, depReq)
+	depReq.Deploy.Env = refs
 
 	if err != nil {
 		messages.ReportLogFieldsMessage("Singularity client returned following error", logging.WarningLevel, ra.log, depReq, reqID, err, response)
@@ -115,6 +173,11 @@ func buildDeployRequest(d sous.Deployable, reqID, depID string, metadata map[str
 
 	metadata[sous.ClusterNameLabel] = d.Deployment.ClusterName
 	metadata[sous.FlavorLabel] = d.Deployment.Flavor
+	// Recording which variables are secrets lets their references be
+	// restored, rather than their values, when the deploy is read back.
+	for name, ref := range e.SecretRefs() {
+		metadata[sous.SecretEnvLabel+name] = ref
+	}
 
 	dockerInfo, err := swaggering.LoadMap(&dtos.SingularityDockerInfo{}, dtoMap{
 		"Image":   dockerImage,
@@ -187,6 +250,38 @@ func buildDeployRequest(d sous.Deployable, reqID, depID string, metadata map[str
 	return depReq.(*dtos.SingularityDeployRequest), nil
 }
 
+// stageDeploy makes dep an incremental deploy which waits after its first
+// step of stepInstances instances to be advanced.
+func stageDeploy(dep *dtos.SingularityDeploy, stepInstances int) error {
+	if err := dep.SetField("DeployInstanceCountPerStep", int32(stepInstances)); err != nil {
+		return err
+	}
+	return dep.SetField("AutoAdvanceDeploySteps", false)
+}
+
+// AdvanceDeploy sends a request to Singularity to allow a pending deploy to
+// proceed to instances active instances.
+func (ra *RectiAgent) AdvanceDeploy(cluster, reqID, depID string, instances int) error {
+	messages.ReportLogFieldsMessage("Advancing deploy", logging.DebugLevel, ra.log, cluster, reqID, depID, instances)
+	req, err := swaggering.LoadMap(&dtos.SingularityUpdatePendingDeployRequest{}, dtoMap{
+		"RequestId":             reqID,
+		"DeployId":              depID,
+		"TargetActiveInstances": int32(instances),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ra.singularityClient(cluster).UpdatePendingDeploy(req.(*dtos.SingularityUpdatePendingDeployRequest))
+	return err
+}
+
+// CancelDeploy sends a request to Singularity to cancel a pending deploy.
+func (ra *RectiAgent) CancelDeploy(cluster, reqID, depID string) error {
+	messages.ReportLogFieldsMessage("Cancelling deploy", logging.DebugLevel, ra.log, cluster, reqID, depID)
+	_, err := ra.singularityClient(cluster).CancelDeploy(reqID, depID)
+	return err
+}
+
 // MapStartupIntoHealthcheckOptions updates the given dtoMap with fields for a
 // HealthcheckOptions struct if appropriate.
 // map[string]interface{} is used so that the function can be exported
@@ -195,6 +290,10 @@ func MapStartupIntoHealthcheckOptions(depMap *map[string]interface{}, startup so
 	if startup.SkipCheck {
 		return nil
 	}
+	switch protocol := strings.ToUpper(startup.CheckReadyProtocol); protocol {
+	case "TCP", "COMMAND":
+		return fmt.Errorf("Singularity cannot make %s startup checks, only HTTP and HTTPS", protocol)
+	}
 
 	hcMap := dtoMap{}
 
@@ -237,6 +336,9 @@ func singRequestFromDeployment(dep *sous.Deployment, reqID string, log logging.L
 	}
 	if reqType == dtos.SingularityRequestRequestTypeSCHEDULED {
 		reqFields["Schedule"] = dep.Schedule
+		if dep.ScheduleTimeZone != "" {
+			reqFields["ScheduleTimeZone"] = dep.ScheduleTimeZone
+		}
 
 		// until and unless someone asks
 		reqFields["ScheduleType"] = dtos.SingularityRequestScheduleTypeCRON
@@ -319,6 +421,60 @@ func (ra *RectiAgent) Scale(cluster, reqID string, instanceCount int, message st
 	return err
 }
 
+// PauseRequest sends a request to Singularity to pause a request, killing
+// its tasks.
+func (ra *RectiAgent) PauseRequest(cluster, reqID, message string) error {
+	messages.ReportLogFieldsMessage("Pausing", logging.DebugLevel, ra.log, cluster, reqID, message)
+	pr, err := swaggering.LoadMap(&dtos.SingularityPauseRequest{}, dtoMap{
+		"ActionId":  "SOUS_PAUSE_" + StripDeployID(uuid.NewV4().String()),
+		"KillTasks": true,
+		"Message":   "Sous: " + message,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ra.singularityClient(cluster).Pause(reqID, pr.(*dtos.SingularityPauseRequest))
+	return err
+}
+
+// UnpauseRequest sends a request to Singularity to unpause a request.
+func (ra *RectiAgent) UnpauseRequest(cluster, reqID, message string) error {
+	messages.ReportLogFieldsMessage("Unpausing", logging.DebugLevel, ra.log, cluster, reqID, message)
+	ur, err := swaggering.LoadMap(&dtos.SingularityUnpauseRequest{}, dtoMap{
+		"ActionId": "SOUS_UNPAUSE_" + StripDeployID(uuid.NewV4().String()),
+		"Message":  "Sous: " + message,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ra.singularityClient(cluster).Unpause(reqID, ur.(*dtos.SingularityUnpauseRequest))
+	return err
+}
+
+// BounceRequest sends a request to Singularity to replace the tasks of a
+// request, one at a time, so that it stays available.
+func (ra *RectiAgent) BounceRequest(cluster, reqID, message string) error {
+	messages.ReportLogFieldsMessage("Bouncing", logging.DebugLevel, ra.log, cluster, reqID, message)
+	br, err := swaggering.LoadMap(&dtos.SingularityBounceRequest{}, dtoMap{
+		"ActionId":    "SOUS_BOUNCE_" + StripDeployID(uuid.NewV4().String()),
+		"Incremental": true,
+		"Message":     "Sous: " + message,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ra.singularityClient(cluster).Bounce(reqID, br.(*dtos.SingularityBounceRequest))
+	return err
+}
+
+// RunRequest sends a request to Singularity to run a task of a request now.
+func (ra *RectiAgent) RunRequest(cluster, reqID string, opts sous.JobRunOptions, message string) error {
+	messages.ReportLogFieldsMessage("Running", logging.DebugLevel, ra.log, cluster, reqID, message)
+	pathParams := swaggering.UrlParams{"requestId": reqID}
+	return ra.singularityClient(cluster).DTORequest("singularity-scheduleimmediately", &dtos.SingularityRequestParent{},
+		"POST", "/api/requests/request/{requestId}/run", pathParams, swaggering.UrlParams{}, newRunNowRequest(opts, message))
+}
+
 //Deploy is actually not going to use traditional client, it will use Requester interface
 //Which on normal runs comes from Singularity, but testing gets injected (via the map) for testing
 //It also doesn't call the normal wrapper Client, hence the call straight to DTORequest
@@ -326,7 +482,7 @@ func (ra *RectiAgent) getSingularityRequester(url string) swaggering.Requester {
 	ra.RLock()
 	defer ra.RUnlock()
 	if _, ok := ra.singClients[url]; !ok {
-		c := singularity.NewClient(url)
+		c := newSingularityClient(url)
 		ra.singClients[url] = c
 	}
 
@@ -353,7 +509,7 @@ func (ra *RectiAgent) singularityClient(url string) *singularity.Client {
 	}
 	ra.Lock()
 	defer ra.Unlock()
-	cl = singularity.NewClient(url)
+	cl = newSingularityClient(url)
 	//cl.Debug = true
 	ra.singClients[url] = cl
 	return cl
@@ -2,6 +2,7 @@ package singularity
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"testing"
@@ -292,3 +293,63 @@ func (m *MySingularityClient) Request(resourceName string, method string, path s
 	args := m.Called(queryParams)
 	return ioutil.NopCloser(bytes.NewBufferString("")), args.Error(1)
 }
+
+type secretsProvider map[string]string
+
+func (p secretsProvider) Secret(ref sous.SecretRef) (string, error) {
+	return p[ref.String()], nil
+}
+
+// deployRecorder records the Env and Metadata of the deploy requested, as
+// they were when it was sent.
+type deployRecorder struct {
+	env, metadata map[string]string
+}
+
+func (r *deployRecorder) DTORequest(resourceName string, dto swaggering.DTO, method string, path string, pathParams swaggering.UrlParams, queryParams swaggering.UrlParams, body ...swaggering.DTO) error {
+	dep := body[0].(*dtos.SingularityDeployRequest).Deploy
+	r.env = sous.Env(dep.Env).Clone()
+	r.metadata = dep.Metadata
+	return nil
+}
+
+func (r *deployRecorder) Request(resourceName string, method string, path string, pathParams swaggering.UrlParams, queryParams swaggering.UrlParams, body ...swaggering.DTO) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewBufferString("")), nil
+}
+
+func TestDeploy_secrets(t *testing.T) {
+	d := sous.Deployable{
+		Deployment:    &sous.Deployment{Cluster: &sous.Cluster{BaseURL: "http://testcluster.com"}},
+		BuildArtifact: &sous.BuildArtifact{},
+	}
+	d.Env = sous.Env{"PLAIN": "value", "PASSWORD": "secret://db/prod#password"}
+
+	ra := NewRectiAgent(sous.NewDummyRegistry(), logging.SilentLogSet())
+	rec := &deployRecorder{}
+	ra.singClients[d.Deployment.Cluster.BaseURL] = rec
+
+	err := ra.Deploy(d, "fake-request-id", "fake-deploy-id")
+	assert.Error(t, err, "secrets cannot be resolved without a provider")
+	assert.Nil(t, rec.env)
+
+	ra.SetSecretProvider(secretsProvider{"secret://db/prod#password": "hunter2"})
+	if err := ra.Deploy(d, "fake-request-id", "fake-deploy-id"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"PLAIN": "value", "PASSWORD": "hunter2"}, rec.env)
+	assert.Equal(t, "secret://db/prod#password", rec.metadata[sous.SecretEnvLabel+"PASSWORD"])
+	assert.Equal(t, "secret://db/prod#password", d.Env["PASSWORD"])
+}
+
+func TestTaskData_storedJSON(t *testing.T) {
+	encoded, err := json.Marshal(&singularityTaskData{requestID: "req-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ok := taskData(json.RawMessage(encoded))
+	if assert.True(t, ok) {
+		assert.Equal(t, "req-1", data.requestID)
+	}
+	_, ok = taskData(json.RawMessage(`{}`))
+	assert.False(t, ok)
+}
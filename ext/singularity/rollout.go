@@ -0,0 +1,103 @@
+package singularity
+
+import (
+	"fmt"
+
+	"github.com/opentable/go-singularity/dtos"
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// BeginRollout implements sous.StagedDeployer on deployer. Only changes to
+// the deploy of an existing request are staged, as Singularity incremental
+// deploys; anything else is rectified as usual.
+func (r *deployer) BeginRollout(pair *sous.DeployablePair, instances int) (sous.DiffResolution, bool) {
+	if pair.Kind() != sous.ModifiedKind {
+		return r.Rectify(pair), false
+	}
+	if pair.UUID == uuid.Nil {
+		pair.UUID = uuid.NewV4()
+	}
+	return r.rectifyModification(pair, instances)
+}
+
+// AdvanceRollout implements sous.StagedDeployer on deployer.
+func (r *deployer) AdvanceRollout(pair *sous.DeployablePair, instances int) error {
+	cluster, reqID, depID, err := stagedDeployIDs(pair)
+	if err != nil {
+		return err
+	}
+	return r.Client.AdvanceDeploy(cluster, reqID, depID, instances)
+}
+
+// AbortRollout implements sous.StagedDeployer on deployer. Cancelling a
+// pending deploy leaves the previously active deploy in place.
+func (r *deployer) AbortRollout(pair *sous.DeployablePair) error {
+	cluster, reqID, depID, err := stagedDeployIDs(pair)
+	if err != nil {
+		return err
+	}
+	return r.Client.CancelDeploy(cluster, reqID, depID)
+}
+
+// RolloutStatus implements sous.StagedDeployer on deployer.
+func (r *deployer) RolloutStatus(reg sous.Registry, clusters sous.Clusters, pair *sous.DeployablePair) (*sous.RolloutStepStatus, error) {
+	cluster, reqID, depID, err := stagedDeployIDs(pair)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := r.buildSingClient(cluster).GetRequest(reqID, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting request %q", reqID)
+	}
+	return rolloutStepStatus(parent, depID, pair.Post.NumInstances), nil
+}
+
+func rolloutStepStatus(parent *dtos.SingularityRequestParent, depID string, numInstances int) *sous.RolloutStepStatus {
+	if parent.ActiveDeploy != nil && parent.ActiveDeploy.Id == depID {
+		return &sous.RolloutStepStatus{
+			Status:          sous.DeployStatusActive,
+			ActiveInstances: numInstances,
+			StepComplete:    true,
+		}
+	}
+	pds := parent.PendingDeployState
+	if pds == nil || pds.DeployMarker == nil || pds.DeployMarker.DeployId != depID {
+		return &sous.RolloutStepStatus{
+			Status:  sous.DeployStatusFailed,
+			Message: fmt.Sprintf("deploy %q is neither pending nor active", depID),
+		}
+	}
+	status := &sous.RolloutStepStatus{Status: sous.DeployStatusPending}
+	if pds.DeployProgress != nil {
+		status.ActiveInstances = int(pds.DeployProgress.CurrentActiveInstances)
+		status.StepComplete = pds.DeployProgress.StepComplete
+	}
+	switch pds.CurrentDeployState {
+	case dtos.SingularityPendingDeployDeployStateFAILED,
+		dtos.SingularityPendingDeployDeployStateFAILED_INTERNAL_STATE,
+		dtos.SingularityPendingDeployDeployStateOVERDUE,
+		dtos.SingularityPendingDeployDeployStateCANCELING,
+		dtos.SingularityPendingDeployDeployStateCANCELED:
+		status.Status = sous.DeployStatusFailed
+		status.Message = fmt.Sprintf("deploy %q is %s", depID, pds.CurrentDeployState)
+	}
+	return status
+}
+
+// stagedDeployIDs returns the cluster URL, request ID and deploy ID of the
+// deploy started by BeginRollout for pair.
+func stagedDeployIDs(pair *sous.DeployablePair) (cluster, reqID, depID string, err error) {
+	data, ok := taskData(pair.ExecutorData)
+	if !ok {
+		return "", "", "", errors.Errorf("Rollout record %#v doesn't contain Singularity compatible data: was %T", pair.ID(), pair.ExecutorData)
+	}
+	reqID = pair.Post.Deployment.DeployConfig.SingularityRequestID
+	if reqID == "" {
+		reqID = data.requestID
+	}
+	cluster = pair.Post.Deployment.Cluster.BaseURL
+	depID = computeDeployIDFromUUID(pair.Post, pair.UUID)
+	return cluster, reqID, depID, nil
+}
@@ -0,0 +1,44 @@
+package singularity
+
+import (
+	"testing"
+
+	"github.com/opentable/go-singularity/dtos"
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func pendingParent(depID string, state dtos.SingularityPendingDeployDeployState, active int32, complete bool) *dtos.SingularityRequestParent {
+	return &dtos.SingularityRequestParent{
+		ActiveDeploy: &dtos.SingularityDeploy{Id: "old"},
+		PendingDeployState: &dtos.SingularityPendingDeploy{
+			CurrentDeployState: state,
+			DeployMarker:       &dtos.SingularityDeployMarker{DeployId: depID},
+			DeployProgress: &dtos.SingularityDeployProgress{
+				CurrentActiveInstances: active,
+				StepComplete:           complete,
+			},
+		},
+	}
+}
+
+func TestRolloutStepStatus(t *testing.T) {
+	s := rolloutStepStatus(pendingParent("new", dtos.SingularityPendingDeployDeployStateWAITING, 1, false), "new", 4)
+	assert.Equal(t, sous.DeployStatusPending, s.Status)
+	assert.Equal(t, 1, s.ActiveInstances)
+	assert.False(t, s.StepComplete)
+
+	s = rolloutStepStatus(pendingParent("new", dtos.SingularityPendingDeployDeployStateWAITING, 2, true), "new", 4)
+	assert.Equal(t, 2, s.ActiveInstances)
+	assert.True(t, s.StepComplete)
+
+	s = rolloutStepStatus(pendingParent("new", dtos.SingularityPendingDeployDeployStateFAILED, 1, false), "new", 4)
+	assert.Equal(t, sous.DeployStatusFailed, s.Status)
+
+	s = rolloutStepStatus(&dtos.SingularityRequestParent{ActiveDeploy: &dtos.SingularityDeploy{Id: "new"}}, "new", 4)
+	assert.Equal(t, sous.DeployStatusActive, s.Status)
+	assert.Equal(t, 4, s.ActiveInstances)
+
+	s = rolloutStepStatus(&dtos.SingularityRequestParent{ActiveDeploy: &dtos.SingularityDeploy{Id: "old"}}, "new", 4)
+	assert.Equal(t, sous.DeployStatusFailed, s.Status)
+}
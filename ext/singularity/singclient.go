@@ -13,6 +13,10 @@ type (
 		GetDeploy(reqID, depID string) (*dtos.SingularityDeployHistory, error)
 		GetDeploys(reqID string, count int32, page int32) (dtos.SingularityDeployHistoryList, error)
 		GetPendingDeploys() (dtos.SingularityPendingDeployList, error)
+		GetTaskHistoryForActiveRequest(reqID string) (dtos.SingularityTaskIdHistoryList, error)
+		GetInactiveDeployTasks(reqID, depID string, count, page int32) (dtos.SingularityTaskIdHistoryList, error)
+		Read(taskID, path, grep string, offset, length int64) (*dtos.MesosFileChunkObject, error)
+		GetHistoryForTask(taskID string) (*dtos.SingularityTaskHistory, error)
 	}
 
 	singClientSpy struct {
@@ -54,6 +58,26 @@ func (spy singClientSpy) GetPendingDeploys() (dtos.SingularityPendingDeployList,
 	return res.Get(0).(dtos.SingularityPendingDeployList), res.Error(1)
 }
 
+func (spy singClientSpy) GetTaskHistoryForActiveRequest(reqID string) (dtos.SingularityTaskIdHistoryList, error) {
+	res := spy.spy.Called(reqID)
+	return res.Get(0).(dtos.SingularityTaskIdHistoryList), res.Error(1)
+}
+
+func (spy singClientSpy) GetInactiveDeployTasks(reqID, depID string, count, page int32) (dtos.SingularityTaskIdHistoryList, error) {
+	res := spy.spy.Called(reqID, depID, count, page)
+	return res.Get(0).(dtos.SingularityTaskIdHistoryList), res.Error(1)
+}
+
+func (spy singClientSpy) Read(taskID, path, grep string, offset, length int64) (*dtos.MesosFileChunkObject, error) {
+	res := spy.spy.Called(taskID, path, grep, offset, length)
+	return res.Get(0).(*dtos.MesosFileChunkObject), res.Error(1)
+}
+
+func (spy singClientSpy) GetHistoryForTask(taskID string) (*dtos.SingularityTaskHistory, error) {
+	res := spy.spy.Called(taskID)
+	return res.Get(0).(*dtos.SingularityTaskHistory), res.Error(1)
+}
+
 func (ctrl singClientSpyController) cannedRequest(answer *dtos.SingularityRequestParent) {
 	ctrl.MatchMethod("GetRequest", spies.AnyArgs, answer, nil)
 	ctrl.MatchMethod("GetRequests", spies.AnyArgs, dtos.SingularityRequestParentList{answer}, nil)
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"testing"
@@ -112,6 +113,98 @@ func TestReadState_empty(t *testing.T) {
 	}
 }
 
+func TestDiskStateManager_defaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-disk-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := exampleState()
+	m, _ := s.Manifests.Single(func(m *sous.Manifest) bool {
+		return m.Source.Repo == "github.com/user/project"
+	})
+	m.Defaults = sous.DeployConfig{
+		Env:          sous.Env{"DEBUG": "YES"},
+		NumInstances: 2,
+		Startup:      sous.Startup{CheckReadyURIPath: "/ready"},
+	}
+	for cluster, spec := range m.Deployments {
+		spec.Env = nil
+		m.Deployments[cluster] = spec
+	}
+	wantDeps, err := s.Deployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsm := NewDiskStateManager(dir, logging.SilentLogSet())
+	if err := dsm.WriteState(s, sous.User{}); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := dsm.ReadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := actual.Manifests.Get(m.ID())
+	if !ok {
+		t.Fatalf("manifest %q not read back", m.ID())
+	}
+	if different, diffs := m.Diff(got); different {
+		t.Errorf("manifest differs after round trip: %v", diffs)
+	}
+	gotDeps, err := actual.Deployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diff := range wantDeps.Diff(gotDeps).Collect() {
+		if diff.Kind() != sous.SameKind {
+			t.Errorf("deployment %q differs after round trip", diff.ID())
+		}
+	}
+}
+
+func TestDiskStateManager_startupCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-disk-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := exampleState()
+	m, _ := s.Manifests.Single(func(m *sous.Manifest) bool {
+		return m.Source.Repo == "github.com/user/project"
+	})
+	// Singularity clusters reject COMMAND checks.
+	for _, c := range s.Defs.Clusters {
+		c.Kind = "kubernetes"
+	}
+	for cluster, spec := range m.Deployments {
+		spec.Startup = sous.Startup{
+			CheckReadyProtocol: "COMMAND",
+			CheckReadyCommand:  []string{"grpc-health-probe", "-addr=:9000"},
+		}
+		m.Deployments[cluster] = spec
+	}
+
+	dsm := NewDiskStateManager(dir, logging.SilentLogSet())
+	if err := dsm.WriteState(s, sous.User{}); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := dsm.ReadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := actual.Manifests.Get(m.ID())
+	if !ok {
+		t.Fatalf("manifest %q not read back", m.ID())
+	}
+	if different, diffs := m.Diff(got); different {
+		t.Errorf("manifest differs after round trip: %v", diffs)
+	}
+}
+
 // exampleState produces a canonical state. If you pass one or more modify
 // funcs, each will be applied in order before the state is returned.
 // You can use this to test scenarios that differ slightly from canonical form.
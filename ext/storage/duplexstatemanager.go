@@ -9,7 +9,10 @@ import (
 )
 
 // A DuplexStateManager echoes StateManager operation to a primary StateManager,
-// but also ensures that writes occur to the secondary one.
+// but also ensures that successful writes to it are repeated to the secondary
+// one. States are only read from the primary, and each state read is written
+// to the secondary by no user, so that changes made to the primary directly
+// are recorded as unattributed.
 type DuplexStateManager struct {
 	primary, secondary sous.StateManager
 	log                logging.LogSink
@@ -26,11 +29,10 @@ func NewDuplexStateManager(primary, secondary sous.StateManager, log logging.Log
 
 // ReadState implements StateManager on DuplexStateManager
 func (dup *DuplexStateManager) ReadState() (*sous.State, error) {
-	user := sous.User{}
 	start := time.Now()
 	state, err := dup.primary.ReadState()
 	if err == nil {
-		if err := dup.secondary.WriteState(state, user); err != nil {
+		if err := dup.secondary.WriteState(state, sous.User{}); err != nil {
 			logging.ReportError(dup.log, errors.Wrapf(err, "writing to secondary StateManager"))
 		}
 	}
@@ -38,13 +40,18 @@ func (dup *DuplexStateManager) ReadState() (*sous.State, error) {
 	return state, err
 }
 
-// WriteState implements StateManager on DuplexStateManager
+// WriteState implements StateManager on DuplexStateManager. The state is
+// written to the secondary only once the primary has accepted it; failing to
+// write to the secondary is logged, but not returned.
 func (dup *DuplexStateManager) WriteState(state *sous.State, user sous.User) error {
 	start := time.Now()
+	err := dup.primary.WriteState(state, user)
+	reportWriting(dup.log, start, state, err)
+	if err != nil {
+		return err
+	}
 	if err := dup.secondary.WriteState(state, user); err != nil {
 		logging.ReportError(dup.log, errors.Wrapf(err, "writing to secondary StateManager"))
 	}
-	err := dup.primary.WriteState(state, user)
-	reportWriting(dup.log, start, state, err)
-	return err
+	return nil
 }
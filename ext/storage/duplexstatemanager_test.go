@@ -78,6 +78,31 @@ func TestDuplexReadState(t *testing.T) {
 	assertStatesEqual(t, expected, pstate)
 }
 
+// Changes made to the primary directly are recorded in the secondary's
+// history when they are read, with no user.
+func TestDuplexReadState_unattributedHistory(t *testing.T) {
+	s := exampleState()
+	PrepareTestGitRepo(t, s, "testdata/remote", "testdata/out")
+
+	db := sous.SetupDB(t)
+	defer sous.ReleaseDB(t)
+	log, _ := logging.NewLogSinkSpy()
+	gsm := NewGitStateManager(NewDiskStateManager("testdata/out", logging.SilentLogSet()), logging.SilentLogSet())
+	psm := NewPostgresStateManager(db, log)
+
+	dupsm := NewDuplexStateManager(gsm, psm, log)
+
+	_, err := dupsm.ReadState()
+	require.NoError(t, err)
+
+	changes, err := psm.ReadHistory(sous.HistoryFilter{})
+	require.NoError(t, err)
+	require.NotEmpty(t, changes)
+	for _, c := range changes {
+		require.Equal(t, sous.User{}, c.User, "%s", c.DeploymentID)
+	}
+}
+
 func assertStatesEqual(t *testing.T, oldState, newState *sous.State) {
 	t.Helper()
 
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/sqlgen"
+	"github.com/pkg/errors"
+)
+
+// leaderLockSpace is the first key of every advisory lock taken by a
+// PostgresLeaderElector, to keep them apart from any other advisory locks
+// taken in the same database.
+const leaderLockSpace = 0x50755 // "sous", more or less
+
+// A PostgresLeaderElector elects cluster leaders using postgres session
+// advisory locks. A server leads a cluster for as long as it holds that
+// cluster's lock on a dedicated connection, so if the server dies or loses
+// its connection, postgres releases the lock and another server's next
+// campaign wins it. It implements sous.LeaderElector.
+type PostgresLeaderElector struct {
+	db     *sql.DB
+	server string
+	log    logging.LogSink
+
+	sync.Mutex
+	held map[string]*sql.Conn
+}
+
+// NewPostgresLeaderElector creates a PostgresLeaderElector for the server
+// identified by server.
+func NewPostgresLeaderElector(db *sql.DB, server string, log logging.LogSink) *PostgresLeaderElector {
+	return &PostgresLeaderElector{
+		db:     db,
+		server: server,
+		log:    log,
+		held:   map[string]*sql.Conn{},
+	}
+}
+
+// Campaign implements sous.LeaderElector on PostgresLeaderElector.
+func (e *PostgresLeaderElector) Campaign(cluster string) (bool, error) {
+	e.Lock()
+	defer e.Unlock()
+	ctx := context.TODO()
+
+	if conn, held := e.held[cluster]; held {
+		if err := conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// The session holding the lock is gone, and the lock with it.
+		conn.Close()
+		delete(e.held, cluster)
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "connecting")
+	}
+	won, err := e.tryLock(ctx, conn, cluster)
+	if err != nil || !won {
+		conn.Close()
+		return false, err
+	}
+	e.held[cluster] = conn
+	logging.Deliver(e.log,
+		logging.SousGenericV1,
+		logging.GetCallerInfo(logging.NotHere()),
+		logging.WarningLevel,
+		logging.ConsoleAndMessage("Elected leader for cluster "+cluster),
+	)
+	return true, nil
+}
+
+// tryLock tries to take the lock for cluster on conn, and records this
+// server as its leader if it succeeds.
+func (e *PostgresLeaderElector) tryLock(ctx context.Context, conn *sql.Conn, cluster string) (bool, error) {
+	start := time.Now()
+	query := `select pg_try_advisory_lock($1, $2), pg_backend_pid()`
+	key := leaderLockKey(cluster)
+	var won bool
+	var pid int
+	err := conn.QueryRowContext(ctx, query, leaderLockSpace, int32(key)).Scan(&won, &pid)
+	sqlgen.ReportSelect(e.log, start, "cluster_leader", query, 1, err, leaderLockSpace, key)
+	if err != nil || !won {
+		return false, errors.Wrapf(err, "locking %q", cluster)
+	}
+	if err := e.recordLeader(ctx, conn, cluster, key, pid); err != nil {
+		e.unlock(ctx, conn, cluster)
+		return false, err
+	}
+	return true, nil
+}
+
+// recordLeader records this server as the leader of cluster, holding the
+// lock key on the session with backend pid.
+func (e *PostgresLeaderElector) recordLeader(ctx context.Context, conn *sql.Conn, cluster string, key uint32, pid int) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "opening transaction")
+	}
+	defer func(tx *sql.Tx) {
+		// ignoring error - since if the Tx is committed, we would expect an error on rollback
+		tx.Rollback()
+	}(tx)
+	if err := sqlgen.NewInserter(ctx, e.log, tx).Exec("cluster_leader", sqlgen.Upsert, sqlgen.SingleRow(func(row sqlgen.RowDef) {
+		row.CF("?", "cluster_name", cluster)
+		row.FD("?", "server", e.server)
+		row.FD("?", "elected_at", time.Now())
+		row.FD("?", "lock_key", int64(key))
+		row.FD("?", "backend_pid", pid)
+	})); err != nil {
+		return err
+	}
+	return errors.Wrapf(tx.Commit(), "committing transaction")
+}
+
+// unlock releases the lock for cluster held on conn. Closing conn only
+// returns it to the pool, so locks must be released explicitly.
+func (e *PostgresLeaderElector) unlock(ctx context.Context, conn *sql.Conn, cluster string) {
+	start := time.Now()
+	query := `select pg_advisory_unlock($1, $2)`
+	key := leaderLockKey(cluster)
+	_, err := conn.ExecContext(ctx, query, leaderLockSpace, int32(key))
+	sqlgen.ReportUpdate(e.log, start, "cluster_leader", query, 1, err, leaderLockSpace, key)
+}
+
+// Leaders implements sous.LeaderElector on PostgresLeaderElector. Only
+// leaders that still hold their cluster's lock are returned.
+func (e *PostgresLeaderElector) Leaders() ([]sous.Leader, error) {
+	start := time.Now()
+	query := `select cluster_name, server, elected_at
+from cluster_leader
+where exists (
+	select 1 from pg_locks l
+	where l.locktype = 'advisory' and l.granted
+	and l.classid::bigint = $1 and l.objid::bigint = cluster_leader.lock_key
+	and l.objsubid = 2 and l.pid = cluster_leader.backend_pid
+)
+order by cluster_name`
+
+	leaders := []sous.Leader{}
+	rows, err := e.db.QueryContext(context.TODO(), query, leaderLockSpace)
+	if err != nil {
+		sqlgen.ReportSelect(e.log, start, "cluster_leader", query, 0, err, leaderLockSpace)
+		return nil, errors.Wrapf(err, "reading leaders %q", query)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		l := sous.Leader{}
+		if err := rows.Scan(&l.ClusterName, &l.Server, &l.Since); err != nil {
+			sqlgen.ReportSelect(e.log, start, "cluster_leader", query, len(leaders), err, leaderLockSpace)
+			return nil, errors.Wrapf(err, "reading leaders %q", query)
+		}
+		leaders = append(leaders, l)
+	}
+	err = rows.Err()
+	sqlgen.ReportSelect(e.log, start, "cluster_leader", query, len(leaders), err, leaderLockSpace)
+	return leaders, errors.Wrapf(err, "reading leaders %q", query)
+}
+
+// Resign gives up leadership of every cluster this server leads.
+func (e *PostgresLeaderElector) Resign() {
+	e.Lock()
+	defer e.Unlock()
+	ctx := context.TODO()
+	for cluster, conn := range e.held {
+		e.unlock(ctx, conn, cluster)
+		conn.Close()
+		delete(e.held, cluster)
+	}
+}
+
+// leaderLockKey returns the second key of the advisory lock for cluster.
+// Postgres reports the key as an unsigned oid in pg_locks, so it is kept
+// unsigned here and converted when the lock is taken.
+func leaderLockKey(cluster string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(cluster))
+	return h.Sum32()
+}
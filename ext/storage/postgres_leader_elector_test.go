@@ -0,0 +1,55 @@
+// +build integration
+
+package storage
+
+import (
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresLeaderElector(t *testing.T) {
+	db := sous.SetupDB(t)
+	defer sous.ReleaseDB(t)
+	ls, _ := logging.NewLogSinkSpy()
+	left := NewPostgresLeaderElector(db, "https://left.example.com", ls)
+	right := NewPostgresLeaderElector(db, "https://right.example.com", ls)
+
+	won, err := left.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, won)
+
+	won, err = right.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.False(t, won, "only one server should lead a cluster")
+
+	won, err = right.Campaign("cluster-2")
+	require.NoError(t, err)
+	assert.True(t, won)
+
+	won, err = left.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, won, "the leader should stay leader")
+
+	leaders, err := right.Leaders()
+	require.NoError(t, err)
+	require.Len(t, leaders, 2)
+	assert.Equal(t, "cluster-1", leaders[0].ClusterName)
+	assert.Equal(t, "https://left.example.com", leaders[0].Server)
+	assert.Equal(t, "cluster-2", leaders[1].ClusterName)
+	assert.Equal(t, "https://right.example.com", leaders[1].Server)
+
+	left.Resign()
+
+	leaders, err = right.Leaders()
+	require.NoError(t, err)
+	require.Len(t, leaders, 1)
+	assert.Equal(t, "cluster-2", leaders[0].ClusterName)
+
+	won, err = right.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, won, "another server should take over after the leader resigns")
+}
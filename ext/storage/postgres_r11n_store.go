@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/sqlgen"
+	"github.com/pkg/errors"
+)
+
+// A PostgresR11nStore records queued rectifications in a postgres database,
+// so that they survive server restarts. It implements sous.R11nStore.
+type PostgresR11nStore struct {
+	db        *sql.DB
+	retention time.Duration
+	log       logging.LogSink
+}
+
+// NewPostgresR11nStore creates a new PostgresR11nStore, which keeps
+// resolved rectifications for retention.
+func NewPostgresR11nStore(db *sql.DB, retention time.Duration, log logging.LogSink) *PostgresR11nStore {
+	return &PostgresR11nStore{db: db, retention: retention, log: log}
+}
+
+// storedDeployable is the JSON encoding of a sous.Deployable in the
+// r11n_queue table.
+type storedDeployable struct {
+	Status        sous.DeployStatus
+	Deployment    *sous.Deployment
+	BuildArtifact *sous.BuildArtifact
+}
+
+// encodeDeployable returns the JSON encoding of d, or nil if d is nil.
+func encodeDeployable(d *sous.Deployable) (*string, error) {
+	if d == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(storedDeployable{Status: d.Status, Deployment: d.Deployment, BuildArtifact: d.BuildArtifact})
+	if err != nil {
+		return nil, errors.Wrapf(err, "encoding deployable")
+	}
+	e := string(b)
+	return &e, nil
+}
+
+// Queued implements sous.R11nStore on PostgresR11nStore.
+func (s *PostgresR11nStore) Queued(r sous.StoredR11n) error {
+	prior, err := encodeDeployable(r.Prior)
+	if err != nil {
+		return err
+	}
+	post, err := encodeDeployable(r.Post)
+	if err != nil {
+		return err
+	}
+	var executorData *string
+	if len(r.ExecutorData) != 0 {
+		e := string(r.ExecutorData)
+		executorData = &e
+	}
+
+	ctx := context.TODO()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "opening transaction")
+	}
+	defer func(tx *sql.Tx) {
+		// ignoring error - since if the Tx is committed, we would expect an error on rollback
+		tx.Rollback()
+	}(tx)
+
+	did := r.DeploymentID
+	if err := sqlgen.NewInserter(ctx, s.log, tx).Exec("r11n_queue", sqlgen.DoNothing, sqlgen.SingleRow(func(row sqlgen.RowDef) {
+		row.CF("?", "r11n_id", string(r.ID))
+		row.FD("?", "repo", did.ManifestID.Source.Repo)
+		row.FD("?", "dir", did.ManifestID.Source.Dir)
+		row.FD("?", "flavor", did.ManifestID.Flavor)
+		row.FD("?", "cluster_name", did.Cluster)
+		row.FD("?", "queued_at", r.QueuedAt)
+		row.FD("?", "prior_deployable", prior)
+		row.FD("?", "post_deployable", post)
+		row.FD("?", "executor_data", executorData)
+	})); err != nil {
+		return err
+	}
+	return errors.Wrapf(tx.Commit(), "committing transaction")
+}
+
+// Resolved implements sous.R11nStore on PostgresR11nStore. It also deletes
+// rectifications resolved longer ago than the retention period.
+func (s *PostgresR11nStore) Resolved(r sous.StoredR11n) error {
+	rez, err := json.Marshal(r.Resolution)
+	if err != nil {
+		return errors.Wrapf(err, "encoding resolution")
+	}
+
+	ctx := context.TODO()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "opening transaction")
+	}
+	defer func(tx *sql.Tx) {
+		// ignoring error - since if the Tx is committed, we would expect an error on rollback
+		tx.Rollback()
+	}(tx)
+
+	update := `update r11n_queue set resolved_at = $1, resolution = $2 where r11n_id = $3`
+	if err := s.exec(ctx, tx, update, r.ResolvedAt, string(rez), string(r.ID)); err != nil {
+		return err
+	}
+	expire := `delete from r11n_queue where resolved_at < $1`
+	if err := s.exec(ctx, tx, expire, r.ResolvedAt.Add(-s.retention)); err != nil {
+		return err
+	}
+	return errors.Wrapf(tx.Commit(), "committing transaction")
+}
+
+func (s *PostgresR11nStore) exec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) error {
+	start := time.Now()
+	res, err := tx.ExecContext(ctx, query, args...)
+	rows := int64(0)
+	if err == nil {
+		rows, err = res.RowsAffected()
+	}
+	sqlgen.ReportUpdate(s.log, start, "r11n_queue", query, int(rows), err, args...)
+	return errors.Wrapf(err, "executing %q", query)
+}
+
+// Load implements sous.R11nStore on PostgresR11nStore.
+func (s *PostgresR11nStore) Load(clusters []string) ([]sous.StoredR11n, error) {
+	start := time.Now()
+	query := `select
+	r11n_id, repo, dir, flavor, cluster_name,
+	queued_at, prior_deployable, post_deployable, executor_data,
+	resolved_at, resolution
+from r11n_queue
+where (resolved_at is null or resolved_at >= $1) and cluster_name = any($2)
+order by r11n_queue_seq`
+	since := time.Now().Add(-s.retention)
+	args := []interface{}{since, pq.Array(clusters)}
+
+	stored := []sous.StoredR11n{}
+	rows, err := s.db.QueryContext(context.TODO(), query, args...)
+	if err != nil {
+		sqlgen.ReportSelect(s.log, start, "r11n_queue", query, 0, err, args...)
+		return nil, errors.Wrapf(err, "loading %q", query)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r, err := scanStoredR11n(rows)
+		if err != nil {
+			sqlgen.ReportSelect(s.log, start, "r11n_queue", query, len(stored), err, args...)
+			return nil, errors.Wrapf(err, "loading %q", query)
+		}
+		stored = append(stored, r)
+	}
+	err = rows.Err()
+	sqlgen.ReportSelect(s.log, start, "r11n_queue", query, len(stored), err, args...)
+	return stored, errors.Wrapf(err, "loading %q", query)
+}
+
+// storedR11nColumns are the JSON encoded columns of a row of r11n_queue.
+type storedR11nColumns struct {
+	priorDeployable, postDeployable sql.NullString
+	executorData                    sql.NullString
+	resolution                      sql.NullString
+	resolvedAt                      pq.NullTime
+}
+
+func scanStoredR11n(rows *sql.Rows) (sous.StoredR11n, error) {
+	r := sous.StoredR11n{}
+	did := &r.DeploymentID
+	cols := storedR11nColumns{}
+	if err := rows.Scan(
+		&r.ID,
+		&did.ManifestID.Source.Repo,
+		&did.ManifestID.Source.Dir,
+		&did.ManifestID.Flavor,
+		&did.Cluster,
+		&r.QueuedAt,
+		&cols.priorDeployable,
+		&cols.postDeployable,
+		&cols.executorData,
+		&cols.resolvedAt,
+		&cols.resolution,
+	); err != nil {
+		return r, err
+	}
+	return r, decodeStoredR11n(&r, cols)
+}
+
+func decodeDeployable(col sql.NullString) (*sous.Deployable, error) {
+	if !col.Valid {
+		return nil, nil
+	}
+	stored := storedDeployable{}
+	if err := json.Unmarshal([]byte(col.String), &stored); err != nil {
+		return nil, err
+	}
+	return &sous.Deployable{Status: stored.Status, Deployment: stored.Deployment, BuildArtifact: stored.BuildArtifact}, nil
+}
+
+func decodeStoredR11n(r *sous.StoredR11n, cols storedR11nColumns) error {
+	var err error
+	if r.Prior, err = decodeDeployable(cols.priorDeployable); err != nil {
+		return errors.Wrapf(err, "decoding prior deployable of %s", r.ID)
+	}
+	if r.Post, err = decodeDeployable(cols.postDeployable); err != nil {
+		return errors.Wrapf(err, "decoding post deployable of %s", r.ID)
+	}
+	if cols.executorData.Valid {
+		r.ExecutorData = json.RawMessage(cols.executorData.String)
+	}
+	if cols.resolution.Valid {
+		r.Resolution = &sous.DiffResolution{}
+		if err := json.Unmarshal([]byte(cols.resolution.String), r.Resolution); err != nil {
+			return errors.Wrapf(err, "decoding resolution of %s", r.ID)
+		}
+		r.ResolvedAt = cols.resolvedAt.Time
+	}
+	return nil
+}
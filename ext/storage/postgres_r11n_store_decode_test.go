@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	sous "github.com/opentable/sous/lib"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeStoredR11n(t *testing.T) {
+	post := &sous.Deployment{
+		ClusterName: "cluster-1",
+		Cluster:     &sous.Cluster{Name: "cluster-1", BaseURL: "http://singularity.example.com"},
+		SourceID: sous.SourceID{
+			Location: sous.SourceLocation{Repo: "github.com/example/project"},
+			Version:  semv.MustParse("1.2.3"),
+		},
+		DeployConfig: sous.DeployConfig{NumInstances: 2},
+		Kind:         sous.ManifestKindService,
+	}
+	prior := post.Clone()
+	prior.NumInstances = 1
+	artifact := &sous.BuildArtifact{DigestReference: "docker.example.com/project@sha256:abc"}
+	rez := &sous.DiffResolution{Desc: sous.ModifyDiff, Error: sous.WrapResolveError(errors.New("boom"))}
+	priorJSON, err := encodeDeployable(&sous.Deployable{Status: sous.DeployStatusActive, Deployment: prior})
+	require.NoError(t, err)
+	postJSON, err := encodeDeployable(&sous.Deployable{Deployment: post, BuildArtifact: artifact})
+	require.NoError(t, err)
+	rezJSON, err := json.Marshal(rez)
+	require.NoError(t, err)
+	resolvedAt := time.Now()
+
+	r := sous.StoredR11n{ID: "id"}
+	require.NoError(t, decodeStoredR11n(&r, storedR11nColumns{
+		priorDeployable: sql.NullString{String: *priorJSON, Valid: true},
+		postDeployable:  sql.NullString{String: *postJSON, Valid: true},
+		executorData:    sql.NullString{String: `{"RequestID":"req"}`, Valid: true},
+		resolution:      sql.NullString{String: string(rezJSON), Valid: true},
+		resolvedAt:      pq.NullTime{Time: resolvedAt, Valid: true},
+	}))
+
+	require.NotNil(t, r.Prior)
+	assert.Equal(t, sous.DeployStatusActive, r.Prior.Status)
+	assert.Equal(t, 1, r.Prior.NumInstances)
+	require.NotNil(t, r.Post)
+	different, diffs := post.Diff(r.Post.Deployment)
+	assert.False(t, different, "%v", diffs)
+	assert.Equal(t, artifact, r.Post.BuildArtifact)
+	assert.Equal(t, json.RawMessage(`{"RequestID":"req"}`), r.ExecutorData)
+	require.NotNil(t, r.Resolution)
+	assert.Equal(t, sous.ModifyDiff, r.Resolution.Desc)
+	assert.Equal(t, "boom", r.Resolution.Error.Error())
+	assert.Equal(t, resolvedAt, r.ResolvedAt)
+
+	r = sous.StoredR11n{ID: "pending"}
+	require.NoError(t, decodeStoredR11n(&r, storedR11nColumns{}))
+	assert.Nil(t, r.Prior)
+	assert.Nil(t, r.Post)
+	assert.Nil(t, r.ExecutorData)
+	assert.Nil(t, r.Resolution)
+}
@@ -0,0 +1,59 @@
+// +build integration
+
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresR11nStore(t *testing.T) {
+	db := sous.SetupDB(t)
+	defer sous.ReleaseDB(t)
+	ls, _ := logging.NewLogSinkSpy()
+	store := NewPostgresR11nStore(db, time.Hour, ls)
+
+	did := sous.DeploymentID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/example/project"}},
+		Cluster:    "cluster-1",
+	}
+	post := &sous.Deployable{Deployment: &sous.Deployment{ClusterName: "cluster-1", DeployConfig: sous.DeployConfig{NumInstances: 2}}}
+	prior := &sous.Deployable{Status: sous.DeployStatusActive, Deployment: &sous.Deployment{ClusterName: "cluster-1", DeployConfig: sous.DeployConfig{NumInstances: 1}}}
+	otherCluster := did
+	otherCluster.Cluster = "cluster-2"
+	now := time.Now()
+
+	require.NoError(t, store.Queued(sous.StoredR11n{ID: "old", DeploymentID: did, Post: post, QueuedAt: now.Add(-3 * time.Hour)}))
+	require.NoError(t, store.Queued(sous.StoredR11n{ID: "done", DeploymentID: did, Post: post, QueuedAt: now.Add(-time.Minute)}))
+	require.NoError(t, store.Queued(sous.StoredR11n{ID: "pending", DeploymentID: did, Prior: prior, Post: post,
+		ExecutorData: json.RawMessage(`{"RequestID":"req"}`), QueuedAt: now}))
+	require.NoError(t, store.Queued(sous.StoredR11n{ID: "not-led", DeploymentID: otherCluster, Post: post, QueuedAt: now}))
+
+	require.NoError(t, store.Resolved(sous.StoredR11n{ID: "old", ResolvedAt: now.Add(-2 * time.Hour),
+		Resolution: &sous.DiffResolution{Desc: sous.ModifyDiff}}))
+	require.NoError(t, store.Resolved(sous.StoredR11n{ID: "done", ResolvedAt: now,
+		Resolution: &sous.DiffResolution{Desc: sous.ModifyDiff}}))
+
+	loaded, err := store.Load([]string{"cluster-1"})
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+
+	assert.Equal(t, sous.R11nID("done"), loaded[0].ID)
+	require.NotNil(t, loaded[0].Resolution)
+	assert.Equal(t, sous.ModifyDiff, loaded[0].Resolution.Desc)
+
+	assert.Equal(t, sous.R11nID("pending"), loaded[1].ID)
+	assert.Equal(t, did, loaded[1].DeploymentID)
+	assert.Nil(t, loaded[1].Resolution)
+	require.NotNil(t, loaded[1].Post)
+	assert.Equal(t, 2, loaded[1].Post.NumInstances)
+	require.NotNil(t, loaded[1].Prior)
+	assert.Equal(t, sous.DeployStatusActive, loaded[1].Prior.Status)
+	assert.JSONEq(t, `{"RequestID":"req"}`, string(loaded[1].ExecutorData))
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/sqlgen"
+	"github.com/pkg/errors"
+)
+
+// ReadHistory implements sous.HistoryReader on PostgresStateManager.
+func (m PostgresStateManager) ReadHistory(filter sous.HistoryFilter) ([]sous.DeploymentChange, error) {
+	start := time.Now()
+	ctx := context.TODO()
+
+	query, args := historyQuery(filter)
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		sqlgen.ReportSelect(m.log, start, "deployment_history", query, 0, err, args...)
+		return nil, errors.Wrapf(err, "reading history %q", query)
+	}
+	defer rows.Close()
+
+	changes := []sous.DeploymentChange{}
+	for rows.Next() {
+		c := sous.DeploymentChange{}
+		did := &c.DeploymentID
+		var diffs []string
+		if err := rows.Scan(
+			&c.At,
+			&c.User.Name,
+			&c.User.Email,
+			&did.ManifestID.Source.Repo,
+			&did.ManifestID.Source.Dir,
+			&did.ManifestID.Flavor,
+			&did.Cluster,
+			&c.Kind,
+			&c.PriorVersion,
+			&c.PostVersion,
+			pq.Array(&diffs),
+		); err != nil {
+			sqlgen.ReportSelect(m.log, start, "deployment_history", query, len(changes), err, args...)
+			return nil, errors.Wrapf(err, "reading history %q", query)
+		}
+		c.Diffs = sous.Differences(diffs)
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		sqlgen.ReportSelect(m.log, start, "deployment_history", query, len(changes), err, args...)
+		return nil, errors.Wrapf(err, "reading history %q", query)
+	}
+	sqlgen.ReportSelect(m.log, start, "deployment_history", query, len(changes), nil, args...)
+	return changes, nil
+}
+
+// ReadOutcomes implements sous.HistoryReader on PostgresStateManager.
+func (m PostgresStateManager) ReadOutcomes(filter sous.HistoryFilter) ([]sous.DeployOutcome, error) {
+	start := time.Now()
+	ctx := context.TODO()
+
+	query, args := outcomesQuery(filter)
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		sqlgen.ReportSelect(m.log, start, "deploy_outcomes", query, 0, err, args...)
+		return nil, errors.Wrapf(err, "reading outcomes %q", query)
+	}
+	defer rows.Close()
+
+	outcomes := []sous.DeployOutcome{}
+	for rows.Next() {
+		o := sous.DeployOutcome{}
+		did := &o.DeploymentID
+		if err := rows.Scan(
+			&o.At,
+			&did.ManifestID.Source.Repo,
+			&did.ManifestID.Source.Dir,
+			&did.ManifestID.Flavor,
+			&did.Cluster,
+			&o.Version,
+			&o.Succeeded,
+		); err != nil {
+			sqlgen.ReportSelect(m.log, start, "deploy_outcomes", query, len(outcomes), err, args...)
+			return nil, errors.Wrapf(err, "reading outcomes %q", query)
+		}
+		outcomes = append(outcomes, o)
+	}
+	if err := rows.Err(); err != nil {
+		sqlgen.ReportSelect(m.log, start, "deploy_outcomes", query, len(outcomes), err, args...)
+		return nil, errors.Wrapf(err, "reading outcomes %q", query)
+	}
+	sqlgen.ReportSelect(m.log, start, "deploy_outcomes", query, len(outcomes), nil, args...)
+	return outcomes, nil
+}
+
+// RecordOutcome implements sous.OutcomeRecorder on PostgresStateManager.
+func (m PostgresStateManager) RecordOutcome(o sous.DeployOutcome) error {
+	ctx := context.TODO()
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "opening transaction")
+	}
+	defer func(tx *sql.Tx) {
+		// ignoring error - since if the Tx is committed, we would expect an error on rollback
+		tx.Rollback()
+	}(tx)
+
+	did := o.DeploymentID
+	if err := sqlgen.NewInserter(ctx, m.log, tx).Exec("deploy_outcomes", "", sqlgen.SingleRow(func(row sqlgen.RowDef) {
+		row.FD("?", "observed_at", o.At)
+		row.FD("?", "repo", did.ManifestID.Source.Repo)
+		row.FD("?", "dir", did.ManifestID.Source.Dir)
+		row.FD("?", "flavor", did.ManifestID.Flavor)
+		row.FD("?", "cluster_name", did.Cluster)
+		row.FD("?", "version", o.Version)
+		row.FD("?", "succeeded", o.Succeeded)
+	})); err != nil {
+		return err
+	}
+	return errors.Wrapf(tx.Commit(), "committing transaction")
+}
+
+func historyQuery(filter sous.HistoryFilter) (string, []interface{}) {
+	return filteredQuery(`select
+	changed_at, user_name, user_email,
+	repo, dir, flavor, cluster_name,
+	change_kind, prior_version, post_version, diffs
+from deployment_history`, "deployment_history_id", filter)
+}
+
+func outcomesQuery(filter sous.HistoryFilter) (string, []interface{}) {
+	return filteredQuery(`select
+	observed_at,
+	repo, dir, flavor, cluster_name,
+	version, succeeded
+from deploy_outcomes`, "deploy_outcome_id", filter)
+}
+
+// filteredQuery adds the conditions and limit of filter to query, ordering
+// its rows by idColumn, most recent first.
+func filteredQuery(query, idColumn string, filter sous.HistoryFilter) (string, []interface{}) {
+	conds := []string{}
+	args := []interface{}{}
+	where := func(col string, val interface{}) {
+		args = append(args, val)
+		conds = append(conds, fmt.Sprintf("%s = $%d", col, len(args)))
+	}
+	if mid := filter.ManifestID; mid != nil {
+		where("repo", mid.Source.Repo)
+		where("dir", mid.Source.Dir)
+		where("flavor", mid.Flavor)
+	}
+	if filter.Cluster != "" {
+		where("cluster_name", filter.Cluster)
+	}
+
+	if len(conds) > 0 {
+		query += "\nwhere " + strings.Join(conds, " and ")
+	}
+	query += "\norder by " + idColumn + " desc"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf("\nlimit $%d", len(args))
+	}
+	return query, args
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryQuery(t *testing.T) {
+	query, args := historyQuery(sous.HistoryFilter{})
+	assert.NotContains(t, query, "where")
+	assert.Empty(t, args)
+
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/example/project", Dir: "sub"}, Flavor: "vanilla"}
+	query, args = historyQuery(sous.HistoryFilter{ManifestID: &mid, Cluster: "cluster-1", Limit: 5})
+	assert.Contains(t, query, "where repo = $1 and dir = $2 and flavor = $3 and cluster_name = $4")
+	assert.Contains(t, query, "limit $5")
+	assert.Equal(t, []interface{}{"github.com/example/project", "sub", "vanilla", "cluster-1", 5}, args)
+}
+
+func TestOutcomesQuery(t *testing.T) {
+	query, args := outcomesQuery(sous.HistoryFilter{Cluster: "cluster-1"})
+	assert.Contains(t, query, "from deploy_outcomes\nwhere cluster_name = $1")
+	assert.Contains(t, query, "order by deploy_outcome_id desc")
+	assert.Equal(t, []interface{}{"cluster-1"}, args)
+}
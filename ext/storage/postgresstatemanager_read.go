@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
@@ -121,7 +122,7 @@ func loadClusters(context context.Context, log logging.LogSink, tx *sql.Tx, stat
 			clusters.cluster_id, clusters.name, clusters.kind, "base_url",
 			"crdef_skip", "crdef_connect_delay", "crdef_timeout", "crdef_connect_interval",
 			"crdef_proto", "crdef_path", "crdef_port_index", "crdef_failure_statuses",
-			"crdef_uri_timeout", "crdef_interval", "crdef_retries",
+			"crdef_uri_timeout", "crdef_interval", "crdef_retries", "crdef_command",
 			advisories.names
 		from
 			clusters
@@ -132,11 +133,12 @@ func loadClusters(context context.Context, log logging.LogSink, tx *sql.Tx, stat
 			c := new(sous.Cluster)
 			qnames := make(pq.StringArray, 10)
 			failStates := make(pq.Int64Array, 10)
+			command := pq.StringArray{}
 			if err := rows.Scan(
 				&cid, &c.Name, &c.Kind, &c.BaseURL,
 				&c.Startup.SkipCheck, &c.Startup.ConnectDelay, &c.Startup.Timeout, &c.Startup.ConnectInterval,
 				&c.Startup.CheckReadyProtocol, &c.Startup.CheckReadyURIPath, &c.Startup.CheckReadyPortIndex, &failStates,
-				&c.Startup.CheckReadyURITimeout, &c.Startup.CheckReadyInterval, &c.Startup.CheckReadyRetries,
+				&c.Startup.CheckReadyURITimeout, &c.Startup.CheckReadyInterval, &c.Startup.CheckReadyRetries, &command,
 				&qnames,
 			); err != nil {
 				return errors.Wrapf(err, "loadClusters")
@@ -147,6 +149,9 @@ func loadClusters(context context.Context, log logging.LogSink, tx *sql.Tx, stat
 			for _, s := range failStates {
 				c.Startup.CheckReadyFailureStatuses = append(c.Startup.CheckReadyFailureStatuses, int(s))
 			}
+			if len(command) > 0 {
+				c.Startup.CheckReadyCommand = command
+			}
 			clusters[cid] = c
 			return nil
 		}); err != nil {
@@ -167,12 +172,12 @@ func loadManifests(context context.Context, log logging.LogSink, tx *sql.Tx, sta
 		// specifically, every possible combination of env/resource/volume/metadata
 		// results in its own row. Maybe that could be reduced?
 		`select
-			"repo", "dir", "flavor", components.kind,
-			"versionstring", "num_instances", "schedule_string",
+			"repo", "dir", "flavor", components.kind, components.defaults,
+			"versionstring", "num_instances", "schedule_string", "schedule_time_zone",
 			coalesce("singularity_deployment_bindings"."singularity_request_id", ''),
 			"cr_skip", "cr_connect_delay", "cr_timeout", "cr_connect_interval",
 			"cr_proto", "cr_path", "cr_port_index", "cr_failure_statuses",
-			"cr_uri_timeout", "cr_interval", "cr_retries",
+			"cr_uri_timeout", "cr_interval", "cr_retries", "cr_command",
 			clusters.name,
 			"host", "container", "mode",
 			envs.key, envs.value,
@@ -209,7 +214,8 @@ func loadManifests(context context.Context, log logging.LogSink, tx *sql.Tx, sta
 				},
 			}
 			var versionString,
-				clusterName string
+				clusterName,
+				defaults string
 
 			var envKey, envValue,
 				resName, resValue,
@@ -219,13 +225,14 @@ func loadManifests(context context.Context, log logging.LogSink, tx *sql.Tx, sta
 			var ownerEmail sql.NullString
 
 			failStates := make(pq.Int64Array, 0)
+			command := pq.StringArray{}
 
 			if err := rows.Scan(
-				&m.Source.Repo, &m.Source.Dir, &m.Flavor, &m.Kind,
-				&versionString, &ds.NumInstances, &ds.Schedule, &ds.DeployConfig.SingularityRequestID,
+				&m.Source.Repo, &m.Source.Dir, &m.Flavor, &m.Kind, &defaults,
+				&versionString, &ds.NumInstances, &ds.Schedule, &ds.ScheduleTimeZone, &ds.DeployConfig.SingularityRequestID,
 				&ds.Startup.SkipCheck, &ds.Startup.ConnectDelay, &ds.Startup.Timeout, &ds.Startup.ConnectInterval,
 				&ds.Startup.CheckReadyProtocol, &ds.Startup.CheckReadyURIPath, &ds.Startup.CheckReadyPortIndex, &failStates,
-				&ds.Startup.CheckReadyURITimeout, &ds.Startup.CheckReadyInterval, &ds.Startup.CheckReadyRetries,
+				&ds.Startup.CheckReadyURITimeout, &ds.Startup.CheckReadyInterval, &ds.Startup.CheckReadyRetries, &command,
 				&clusterName,
 				&volHost, &volContainer, &volMode,
 				&envKey, &envValue,
@@ -238,6 +245,11 @@ func loadManifests(context context.Context, log logging.LogSink, tx *sql.Tx, sta
 			if newM, has := state.Manifests.Get(m.ID()); has {
 				m = newM
 			} else {
+				if defaults != "" {
+					if err := json.Unmarshal([]byte(defaults), &m.Defaults); err != nil {
+						return errors.Wrapf(err, "loadManifests decoding defaults of %s", m.ID())
+					}
+				}
 				state.Manifests.Add(m)
 			}
 			set := sous.NewOwnerSet(m.Owners...)
@@ -259,6 +271,9 @@ func loadManifests(context context.Context, log logging.LogSink, tx *sql.Tx, sta
 				for _, s := range failStates {
 					ds.Startup.CheckReadyFailureStatuses = append(ds.Startup.CheckReadyFailureStatuses, int(s))
 				}
+				if len(command) > 0 {
+					ds.Startup.CheckReadyCommand = command
+				}
 			}
 			if envKey.Valid && envValue.Valid {
 				ds.Env[envKey.String] = envValue.String
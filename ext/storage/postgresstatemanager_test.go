@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	sous "github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
+	"github.com/samsalisbury/semv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	// It's a SQL db driver. This is how you do that.
@@ -162,6 +164,163 @@ func TestPostgresStateManagerWriteState_success(t *testing.T) {
 	}
 }
 
+// Manifests are stored as the deployments they describe, so their Defaults
+// are read back merged into each DeploySpec, describing the same deployments.
+func TestPostgresStateManagerWriteState_defaults(t *testing.T) {
+	suite := SetupTest(t, "postgresstatemanagerwritestate_defaults")
+	defer sous.ReleaseDB(t)
+
+	s := exampleState()
+	m, _ := s.Manifests.Single(func(m *sous.Manifest) bool {
+		return m.Source.Repo == "github.com/user/project"
+	})
+	m.Defaults = sous.DeployConfig{
+		Env:          sous.Env{"DEFAULTED": "YES"},
+		NumInstances: 2,
+	}
+
+	suite.require.NoError(suite.manager.WriteState(s, testUser))
+	readState, err := suite.manager.ReadState()
+	suite.require.NoError(err)
+
+	written, err := s.Deployments()
+	suite.require.NoError(err)
+	read, err := readState.Deployments()
+	suite.require.NoError(err)
+	for _, p := range written.Diff(read).Collect() {
+		if p.Kind() != sous.SameKind {
+			t.Errorf("deployment %q differs after round trip: %v", p.ID(), p.Diffs())
+		}
+	}
+
+	readM, ok := readState.Manifests.Get(m.ID())
+	suite.require.True(ok)
+	if _, diffs := m.Defaults.Diff(readM.Defaults); len(diffs) != 0 {
+		t.Errorf("defaults differ after round trip: %v", diffs)
+	}
+
+	// Clearing the defaults must be stored too.
+	m.Defaults = sous.DeployConfig{}
+	suite.require.NoError(suite.manager.WriteState(s, testUser))
+	readState, err = suite.manager.ReadState()
+	suite.require.NoError(err)
+	readM, ok = readState.Manifests.Get(m.ID())
+	suite.require.True(ok)
+	if different, diffs := readM.Defaults.Diff(sous.DeployConfig{}); different {
+		t.Errorf("cleared defaults were read back: %v", diffs)
+	}
+}
+
+func TestPostgresStateManagerWriteState_startupCommand(t *testing.T) {
+	suite := SetupTest(t, "postgresstatemanagerwritestate_startupcommand")
+	defer sous.ReleaseDB(t)
+
+	s := exampleState()
+	m, _ := s.Manifests.Single(func(m *sous.Manifest) bool {
+		return m.Source.Repo == "github.com/user/project"
+	})
+	// Singularity clusters reject COMMAND checks.
+	for _, c := range s.Defs.Clusters {
+		c.Kind = "kubernetes"
+	}
+	for cluster, spec := range m.Deployments {
+		spec.Startup.CheckReadyProtocol = "COMMAND"
+		spec.Startup.CheckReadyCommand = []string{"grpc-health-probe", "-addr=:9000"}
+		m.Deployments[cluster] = spec
+	}
+
+	suite.require.NoError(suite.manager.WriteState(s, testUser))
+	readState, err := suite.manager.ReadState()
+	suite.require.NoError(err)
+
+	got, ok := readState.Manifests.Get(m.ID())
+	suite.require.True(ok)
+	for cluster, spec := range got.Deployments {
+		suite.Equal([]string{"grpc-health-probe", "-addr=:9000"}, spec.Startup.CheckReadyCommand, cluster)
+	}
+}
+
+func TestPostgresStateManagerWriteState_scheduleTimeZone(t *testing.T) {
+	suite := SetupTest(t, "postgresstatemanagerwritestate_scheduletimezone")
+	defer sous.ReleaseDB(t)
+
+	s := exampleState()
+	m, _ := s.Manifests.Single(func(m *sous.Manifest) bool {
+		return m.Source.Repo == "github.com/user/project"
+	})
+	m.Kind = sous.ManifestKindScheduled
+	for cluster, spec := range m.Deployments {
+		spec.Schedule = "0 3 * * *"
+		spec.ScheduleTimeZone = "America/Los_Angeles"
+		m.Deployments[cluster] = spec
+	}
+
+	suite.require.NoError(suite.manager.WriteState(s, testUser))
+	readState, err := suite.manager.ReadState()
+	suite.require.NoError(err)
+
+	got, ok := readState.Manifests.Get(m.ID())
+	suite.require.True(ok)
+	for cluster, spec := range got.Deployments {
+		suite.Equal("0 3 * * *", spec.Schedule, cluster)
+		suite.Equal("America/Los_Angeles", spec.ScheduleTimeZone, cluster)
+	}
+}
+
+func TestPostgresStateManagerWriteState_history(t *testing.T) {
+	suite := SetupTest(t, "postgresstatemanagerwritestate_history")
+	defer sous.ReleaseDB(t)
+
+	s := exampleState()
+	suite.require.NoError(suite.manager.WriteState(s, testUser))
+	suite.Equal(int64(4), suite.pluckSQL("select count(*) from deployment_history"))
+
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/opentable/sous"}}
+	m, ok := s.Manifests.Get(mid)
+	suite.require.True(ok)
+	spec := m.Deployments["cluster-1"]
+	spec.Version = semv.MustParse("1.0.1")
+	m.Deployments["cluster-1"] = spec
+	s.Manifests.Set(mid, m)
+	suite.require.NoError(suite.manager.WriteState(s, testUser))
+
+	changes, err := suite.manager.ReadHistory(sous.HistoryFilter{ManifestID: &mid, Cluster: "cluster-1"})
+	suite.require.NoError(err)
+	suite.require.Len(changes, 2)
+	suite.Equal("modified", changes[0].Kind)
+	suite.Equal("1.0.0-rc.1+deadbeef", changes[0].PriorVersion)
+	suite.Equal("1.0.1", changes[0].PostVersion)
+	suite.Equal(testUser, changes[0].User)
+	suite.NotEmpty(changes[0].Diffs)
+	suite.Equal("added", changes[1].Kind)
+
+	changes, err = suite.manager.ReadHistory(sous.HistoryFilter{Limit: 1})
+	suite.require.NoError(err)
+	suite.Len(changes, 1)
+}
+
+func TestPostgresStateManager_outcomes(t *testing.T) {
+	suite := SetupTest(t, "postgresstatemanager_outcomes")
+	defer sous.ReleaseDB(t)
+
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/opentable/sous"}}
+	did := sous.DeploymentID{ManifestID: mid, Cluster: "cluster-1"}
+	at := time.Now()
+	suite.require.NoError(suite.manager.RecordOutcome(sous.DeployOutcome{DeploymentID: did, At: at, Version: "1.0.0", Succeeded: true}))
+	suite.require.NoError(suite.manager.RecordOutcome(sous.DeployOutcome{DeploymentID: did, At: at, Version: "1.1.0"}))
+	other := sous.DeploymentID{ManifestID: mid, Cluster: "cluster-2"}
+	suite.require.NoError(suite.manager.RecordOutcome(sous.DeployOutcome{DeploymentID: other, At: at, Version: "1.1.0", Succeeded: true}))
+
+	outcomes, err := suite.manager.ReadOutcomes(sous.HistoryFilter{ManifestID: &mid, Cluster: "cluster-1"})
+	suite.require.NoError(err)
+	suite.require.Len(outcomes, 2)
+	suite.Equal("1.1.0", outcomes[0].Version)
+	suite.False(outcomes[0].Succeeded)
+	suite.Equal("1.0.0", outcomes[1].Version)
+	suite.True(outcomes[1].Succeeded)
+	suite.Equal(did, outcomes[1].DeploymentID)
+}
+
 func assertSameClusters(t *testing.T, old *sous.State, new *sous.State) {
 	t.Helper()
 	ocs := old.Defs.Clusters
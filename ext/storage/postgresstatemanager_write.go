@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
@@ -26,7 +27,7 @@ func (m PostgresStateManager) WriteState(state *sous.State, user sous.User) erro
 		tx.Rollback()
 	}(tx)
 
-	if err := storeManifests(context, m.log, state, tx); err != nil {
+	if err := storeManifests(context, m.log, state, user, start, tx); err != nil {
 		reportWriting(m.log, start, state, errors.Wrapf(err, "storing state"))
 		return err
 	}
@@ -39,7 +40,7 @@ func (m PostgresStateManager) WriteState(state *sous.State, user sous.User) erro
 	return nil
 }
 
-func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State, tx *sql.Tx) error {
+func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State, user sous.User, at time.Time, tx *sql.Tx) error {
 
 	newDeps, err := state.Deployments()
 	if err != nil {
@@ -59,8 +60,12 @@ func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State,
 	updates := sous.NewDeployments()
 	deletes := sous.NewDeployments()
 	alldeps := sous.NewDeployments()
+	changes := []*sous.DeploymentChange{}
 
 	for _, diff := range diffs {
+		if change := sous.NewDeploymentChange(diff, user, at); change != nil {
+			changes = append(changes, change)
+		}
 		switch diff.Kind() {
 		default: //do nothing for Same
 		case sous.AddedKind, sous.ModifiedKind:
@@ -94,6 +99,26 @@ func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State,
 		return err
 	}
 
+	// Deployments are stored with their manifest's Defaults already applied,
+	// so the Defaults themselves are kept with the manifest's component.
+	defaults, err := manifestDefaults(state.Manifests)
+	if err != nil {
+		return err
+	}
+	if err := ins.Exec("components", componentDefaultsConflict, func(fields sqlgen.FieldSet) {
+		for _, m := range state.Manifests.Snapshot() {
+			fields.Row(func(r sqlgen.RowDef) {
+				r.CF("?", "repo", m.Source.Repo)
+				r.CF("?", "dir", m.Source.Dir)
+				r.CF("?", "flavor", m.Flavor)
+				r.CF("?", "kind", m.Kind)
+				r.FD("?", "defaults", defaults[m.ID()])
+			})
+		}
+	}); err != nil {
+		return err
+	}
+
 	if err := ins.Exec("clusters", sqlgen.Upsert,
 		deploymentsFieldSetter(alldeps, func(fields sqlgen.FieldSet, dep *sous.Deployment) {
 			c := dep.Cluster
@@ -168,6 +193,7 @@ func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State,
 				r.FD("?", "versionstring", dep.SourceID.Version.String())
 				r.FD("?", "num_instances", dep.NumInstances)
 				r.FD("?", "schedule_string", dep.Schedule)
+				r.FD("?", "schedule_time_zone", dep.ScheduleTimeZone)
 				r.FD("?", "lifecycle", "active")
 				startupFields(r, "cr", s)
 			})
@@ -187,6 +213,7 @@ func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State,
 				r.FD("?", "versionstring", dep.SourceID.Version.String())
 				r.FD("?", "num_instances", dep.NumInstances)
 				r.FD("?", "schedule_string", dep.Schedule)
+				r.FD("?", "schedule_time_zone", dep.ScheduleTimeZone)
 				r.FD("?", "lifecycle", "decommisioned")
 				startupFields(r, "cr", s)
 			})
@@ -256,6 +283,29 @@ func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State,
 		return err
 	}
 
+	// deployment_history is append-only: every change is recorded, even if an
+	// identical change was recorded before.
+	if err := ins.Exec("deployment_history", "", func(fields sqlgen.FieldSet) {
+		for _, c := range changes {
+			did := c.DeploymentID
+			fields.Row(func(row sqlgen.RowDef) {
+				row.FD("?", "changed_at", c.At)
+				row.FD("?", "user_name", c.User.Name)
+				row.FD("?", "user_email", c.User.Email)
+				row.FD("?", "repo", did.ManifestID.Source.Repo)
+				row.FD("?", "dir", did.ManifestID.Source.Dir)
+				row.FD("?", "flavor", did.ManifestID.Flavor)
+				row.FD("?", "cluster_name", did.Cluster)
+				row.FD("?", "change_kind", c.Kind)
+				row.FD("?", "prior_version", c.PriorVersion)
+				row.FD("?", "post_version", c.PostVersion)
+				row.FD("?", "diffs", pq.Array([]string(c.Diffs)))
+			})
+		}
+	}); err != nil {
+		return err
+	}
+
 	if err := ins.Exec("volumes", sqlgen.DoNothing,
 		deploymentsFieldSetter(updates, func(fields sqlgen.FieldSet, dep *sous.Deployment) {
 			for _, volume := range dep.Volumes {
@@ -334,6 +384,33 @@ func startupFields(r sqlgen.RowDef, prefix string, s sous.Startup) {
 	r.FD("?", prefix+"_interval", s.CheckReadyInterval)
 	r.FD("?", prefix+"_retries", s.CheckReadyRetries)
 	r.FD("?", prefix+"_failure_statuses", pq.Array(statuses))
+	command := s.CheckReadyCommand
+	if command == nil {
+		command = []string{}
+	}
+	r.FD("?", prefix+"_command", pq.Array(command))
+}
+
+// componentDefaultsConflict replaces the stored Defaults of components. (The
+// parenthesised form of sqlgen.Upsert cannot update a single column.)
+const componentDefaultsConflict = `on conflict ("repo","dir","flavor","kind") do update set "defaults" = excluded."defaults"`
+
+// manifestDefaults returns the JSON encoding of the Defaults of each of ms,
+// or "" for those without any.
+func manifestDefaults(ms sous.Manifests) (map[sous.ManifestID]string, error) {
+	defaults := map[sous.ManifestID]string{}
+	for id, m := range ms.Snapshot() {
+		if different, _ := m.Defaults.Diff(sous.DeployConfig{}); !different {
+			defaults[id] = ""
+			continue
+		}
+		b, err := json.Marshal(m.Defaults)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding defaults of %s", id)
+		}
+		defaults[id] = string(b)
+	}
+	return defaults, nil
 }
 
 func deploymentsFieldSetter(ds sous.Deployments, eachDep func(sqlgen.FieldSet, *sous.Deployment)) func(sqlgen.FieldSet) {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/opentable/sous/cli/actions"
 	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/ext/singularity"
 	sous "github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/cmdr"
 	"github.com/opentable/sous/util/logging"
@@ -291,6 +292,135 @@ func (di *SousGraph) GetDeploy(opts DeployActionOpts) (actions.Action, error) {
 	}, nil
 }
 
+// LogsActionOpts are options for GetLogs.
+type LogsActionOpts struct {
+	DFF     config.DeployFilterFlags
+	Options singularity.LogOptions
+}
+
+// GetLogs constructs a Logs Action.
+func (di *SousGraph) GetLogs(opts LogsActionOpts, out io.Writer) (actions.Action, error) {
+	di.guardedAdd("Dryrun", DryrunNeither)
+	di.guardedAdd("DeployFilterFlags", &opts.DFF)
+
+	scoop := struct {
+		DeploymentID     TargetDeploymentID
+		HTTPStateManager *sous.HTTPStateManager
+		LogSink          LogSink
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+
+	did := sous.DeploymentID(scoop.DeploymentID)
+	return &actions.Logs{
+		TargetDeploymentID: did,
+		StateReader:        scoop.HTTPStateManager,
+		LogSink:            scoop.LogSink.LogSink.Child("logs", did),
+		OutWriter:          out,
+		Options:            opts.Options,
+	}, nil
+}
+
+// DeploymentOperationOpts are options for GetDeploymentOperation.
+type DeploymentOperationOpts struct {
+	DFF config.DeployFilterFlags
+	// Operation names the server resource performing the operation, e.g.
+	// "scale".
+	Operation string
+	// Query holds the parameters of the operation, besides the deployment ID.
+	Query map[string]string
+}
+
+// GetDeploymentOperation constructs a DeploymentOperation Action.
+func (di *SousGraph) GetDeploymentOperation(opts DeploymentOperationOpts) (actions.Action, error) {
+	di.guardedAdd("Dryrun", DryrunNeither)
+	di.guardedAdd("DeployFilterFlags", &opts.DFF)
+
+	scoop := struct {
+		HTTP         *ClusterSpecificHTTPClient
+		DeploymentID TargetDeploymentID
+		User         sous.User
+		LogSink      LogSink
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+
+	did := sous.DeploymentID(scoop.DeploymentID)
+	return &actions.DeploymentOperation{
+		Operation:          opts.Operation,
+		Query:              opts.Query,
+		TargetDeploymentID: did,
+		HTTPClient:         scoop.HTTP.HTTPClient,
+		User:               scoop.User,
+		LogSink:            scoop.LogSink.LogSink.Child(opts.Operation, did),
+	}, nil
+}
+
+// JobActionOpts are options for GetJobRun and GetJobHistory.
+type JobActionOpts struct {
+	DFF     config.DeployFilterFlags
+	Options sous.JobRunOptions
+	// Count is the most runs listed by GetJobHistory.
+	Count int
+}
+
+// GetJobRun constructs a JobRun Action.
+func (di *SousGraph) GetJobRun(opts JobActionOpts) (actions.Action, error) {
+	di.guardedAdd("Dryrun", DryrunNeither)
+	di.guardedAdd("DeployFilterFlags", &opts.DFF)
+
+	scoop := struct {
+		HTTP         *ClusterSpecificHTTPClient
+		DeploymentID TargetDeploymentID
+		User         sous.User
+		LogSink      LogSink
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+
+	did := sous.DeploymentID(scoop.DeploymentID)
+	return &actions.JobRun{
+		TargetDeploymentID: did,
+		Options:            opts.Options,
+		HTTPClient:         scoop.HTTP.HTTPClient,
+		User:               scoop.User,
+		LogSink:            scoop.LogSink.LogSink.Child("job-run", did),
+	}, nil
+}
+
+// GetJobHistory constructs a JobHistory Action.
+func (di *SousGraph) GetJobHistory(opts JobActionOpts, out io.Writer) (actions.Action, error) {
+	di.guardedAdd("Dryrun", DryrunNeither)
+	di.guardedAdd("DeployFilterFlags", &opts.DFF)
+
+	scoop := struct {
+		HTTP         *ClusterSpecificHTTPClient
+		DeploymentID TargetDeploymentID
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+
+	return &actions.JobHistory{
+		TargetDeploymentID: sous.DeploymentID(scoop.DeploymentID),
+		Count:              opts.Count,
+		HTTPClient:         scoop.HTTP.HTTPClient,
+		OutWriter:          out,
+	}, nil
+}
+
+// GetRollback constructs a Rollback Action.
+func (di *SousGraph) GetRollback(opts DeployActionOpts) (actions.Action, error) {
+	deploy, err := di.GetDeploy(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &actions.Rollback{Deploy: deploy.(*actions.Deploy)}, nil
+}
+
 // GetRectify produces a rectify Action.
 func (di *SousGraph) GetRectify(dryrun string, dff config.DeployFilterFlags) (actions.Action, error) {
 	di.guardedAdd("Dryrun", DryrunOption(dryrun))
@@ -358,6 +488,9 @@ func (di *SousGraph) GetServer(
 		LogSink       LogSink
 		Config        *config.Config
 		ServerHandler ServerHandler
+		QueueSet      *sous.R11nQueueSet
+		Leadership    ServerLeadership
+		DriftDetector ServerDriftDetector
 	}{}
 
 	if err := di.Inject(&scoop); err != nil {
@@ -383,5 +516,8 @@ func (di *SousGraph) GetServer(
 		Config:            scoop.Config,
 		ServerHandler:     scoop.ServerHandler.Handler,
 		AutoResolver:      arScoop.AutoResolver,
+		QueueSet:          scoop.QueueSet,
+		Leadership:        scoop.Leadership.Leadership,
+		DriftDetector:     scoop.DriftDetector.DriftDetector,
 	}, nil
 }
@@ -13,6 +13,9 @@ import (
 	"github.com/opentable/sous/ext/docker"
 	"github.com/opentable/sous/ext/git"
 	"github.com/opentable/sous/ext/github"
+	"github.com/opentable/sous/ext/kubernetes"
+	"github.com/opentable/sous/ext/secrets"
+	"github.com/opentable/sous/ext/simulated"
 	"github.com/opentable/sous/ext/singularity"
 	"github.com/opentable/sous/ext/storage"
 	"github.com/opentable/sous/lib"
@@ -314,6 +317,11 @@ func AddInternals(graph adder) {
 		newHTTPClientBundle,
 		newClusterSpecificHTTPClient,
 		NewR11nQueueSet,
+		newServerLeadership,
+		newServerAuthenticator,
+		newNotifier,
+		newServerDriftDetector,
+		newMetricsRegistry,
 	)
 }
 
@@ -325,8 +333,11 @@ func newResolver(filter *sous.ResolveFilter, d sous.Deployer, r sous.Registry, l
 	return sous.NewResolver(d, r, filter, ls.Child("resolver"), qs)
 }
 
-func newAutoResolver(rez *sous.Resolver, sr *ServerStateManager, ls LogSink) *sous.AutoResolver {
-	return sous.NewAutoResolver(rez, sr, ls.Child("autoresolver"))
+func newAutoResolver(rez *sous.Resolver, sr *ServerStateManager, sl ServerLeadership, n *sous.Notifier, ls LogSink) *sous.AutoResolver {
+	ar := sous.NewAutoResolver(rez, sr, ls.Child("autoresolver"))
+	ar.Leadership = sl.Leadership
+	ar.Notifier = n
+	return ar
 }
 
 func newSourceHostChooser() sous.SourceHostChooser {
@@ -543,26 +554,53 @@ func newRegistry(graph *SousGraph, nc lazyNameCache, dryrun DryrunOption, c Loca
 }
 
 func newDeployer(dryrun DryrunOption, nc lazyNameCache, ls LogSink, c LocalSousConfig) (sous.Deployer, error) {
-	// Eventually, based on configuration, we may make different decisions here.
+	// Each kind of cluster is handled by its own deployer; the registry
+	// dispatches to them by Cluster.Kind.
+	reg := sous.NewDeployerRegistry(ls.Child("deployer-registry"))
 	if dryrun == DryrunBoth || dryrun == DryrunScheduler || c.Server != "" {
+		reg.Register(simulated.ClusterKind, simulated.NewDeployer(
+			c.Simulated,
+			ls.Child("simulated-deployer"),
+			simulated.OptDryRun(),
+		))
 		drc := sous.NewDummyRectificationClient()
 		drc.SetLogger(ls.Child("rectify"))
-		return singularity.NewDeployer(
+		reg.Register(singularity.ClusterKind, singularity.NewDeployer(
 			drc,
 			ls.Child("singularity-deployer"),
 			singularity.OptMaxHTTPReqsPerServer(c.MaxHTTPConcurrencySingularity),
-		), nil
+		))
+		reg.Register(kubernetes.ClusterKind, kubernetes.NewDeployer(
+			c.Kubernetes,
+			ls.Child("kubernetes-deployer"),
+			kubernetes.OptDryRun(),
+		))
+		return reg, nil
 	}
 	// We need the real name cache.
 	labeller, err := nc()
 	if err != nil {
 		return nil, err
 	}
-	return singularity.NewDeployer(
-		singularity.NewRectiAgent(labeller, ls),
+	sp := secrets.NewProvider(c.Secrets)
+	ra := singularity.NewRectiAgent(labeller, ls)
+	ra.SetSecretProvider(sp)
+	reg.Register(singularity.ClusterKind, singularity.NewDeployer(
+		ra,
 		ls,
 		singularity.OptMaxHTTPReqsPerServer(c.MaxHTTPConcurrencySingularity),
-	), nil
+	))
+	reg.Register(kubernetes.ClusterKind, kubernetes.NewDeployer(
+		c.Kubernetes,
+		ls.Child("kubernetes-deployer"),
+		kubernetes.OptSecretProvider(sp),
+	))
+	reg.Register(simulated.ClusterKind, simulated.NewDeployer(
+		c.Simulated,
+		ls.Child("simulated-deployer"),
+		simulated.OptSecretProvider(sp),
+	))
+	return reg, nil
 }
 
 func newServerHandler(g *SousGraph, Registry sous.Registry, ComponentLocator server.ComponentLocator, metrics MetricsHandler, log LogSink) ServerHandler {
@@ -587,10 +625,10 @@ func newServerListData(c HTTPClient) (ServerListData, error) {
 	return serverList, err
 }
 
-func newHTTPClientBundle(serverList ServerListData, tid sous.TraceID, log LogSink) (ClientBundle, error) {
+func newHTTPClientBundle(serverList ServerListData, c LocalSousConfig, tid sous.TraceID, log LogSink) (ClientBundle, error) {
 	bundle := ClientBundle{}
 	for _, s := range serverList.Servers {
-		client, err := restful.NewClient(s.URL, log.Child(s.ClusterName+".http-client"), map[string]string{"OT-RequestId": string(tid)})
+		client, err := restful.NewClient(s.URL, log.Child(s.ClusterName+".http-client"), clientHeaders(c, tid))
 		if err != nil {
 			return nil, err
 		}
@@ -625,16 +663,26 @@ func newHTTPClient(c LocalSousConfig, user sous.User, tid sous.TraceID, log LogS
 		return HTTPClient{}, errors.New("no server configured")
 	}
 	messages.ReportLogFieldsMessageToConsole(fmt.Sprintf("Using server %s", c.Server), logging.ExtraDebug1Level, log)
-	cl, err := restful.NewClient(c.Server, log.Child("http-client"), map[string]string{"OT-RequestId": string(tid)})
+	cl, err := restful.NewClient(c.Server, log.Child("http-client"), clientHeaders(c, tid))
 	return HTTPClient{HTTPClient: cl}, err
 }
 
+// clientHeaders returns the headers sent with every request to a Sous
+// server, including the configured bearer token.
+func clientHeaders(c LocalSousConfig, tid sous.TraceID) map[string]string {
+	headers := map[string]string{"OT-RequestId": string(tid)}
+	if c.Auth.Token != "" {
+		headers["Authorization"] = "Bearer " + c.Auth.Token
+	}
+	return headers
+}
+
 func newInMemoryClient(srvr ServerHandler, log LogSink) (HTTPClient, error) {
 	cl, err := restful.NewInMemoryClient(srvr.Handler, log.Child("local-http"))
 	return HTTPClient{HTTPClient: cl}, err
 }
 
-func newServerStateManager(c LocalSousConfig, log LogSink, gm gitStateManager, dm DistStateManager) (*ServerStateManager, error) {
+func newServerStateManager(c LocalSousConfig, log LogSink, mdb MaybeDatabase, gm gitStateManager, dm DistStateManager) (*ServerStateManager, error) {
 	var primary, secondary sous.StateManager
 	var perr error
 	primary = gm.StateManager
@@ -646,6 +694,16 @@ func newServerStateManager(c LocalSousConfig, log LogSink, gm gitStateManager, d
 
 	//Temorarily adding logger as secondary (TODO://Fix distributed state manager and timeouts associated with updates)
 	secondary = storage.NewLogOnlyStateManager(log.Child("secondary"))
+	// With a local database, writes are also recorded there, along with the
+	// deployment change history. States read from git are recorded too, so
+	// that changes pushed to it directly appear in the history unattributed.
+	if mdb.Err == nil {
+		secondary = storage.NewDuplexStateManager(
+			secondary,
+			storage.NewPostgresStateManager(mdb.Db, log.Child("secondary-db")),
+			log.Child("secondary"),
+		)
+	}
 
 	duplex := storage.NewDuplexStateManager(primary, secondary, log.Child("duplex-state"))
 	return &ServerStateManager{StateManager: duplex}, nil
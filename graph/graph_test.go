@@ -147,6 +147,7 @@ func TestComponentLocatorInjection(t *testing.T) {
 	assert.NotNil(t, locator.StateManager)
 	assert.NotNil(t, locator.ResolveFilter)
 	assert.NotNil(t, locator.AutoResolver)
+	assert.NotNil(t, locator.Metrics)
 	assert.Equal(t, locator.Version.Format("M.m.p"), "2.3.7")
 }
 
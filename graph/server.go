@@ -2,10 +2,17 @@ package graph
 
 import (
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/opentable/sous/ext/notify"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/ext/storage"
 	sous "github.com/opentable/sous/lib"
 	"github.com/opentable/sous/server"
 	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/prometheus"
+	"github.com/pkg/errors"
 	"github.com/samsalisbury/semv"
 )
 
@@ -19,6 +26,12 @@ func newServerComponentLocator(
 	v semv.Version,
 	qs *sous.R11nQueueSet,
 	ar *sous.AutoResolver,
+	mdb MaybeDatabase,
+	sl ServerLeadership,
+	sa ServerAuthenticator,
+	dd ServerDriftDetector,
+	d sous.Deployer,
+	mr *prometheus.Registry,
 ) server.ComponentLocator {
 
 	logging.Deliver(ls, logging.SousGenericV1, logging.DebugLevel, logging.GetCallerInfo(),
@@ -32,6 +45,14 @@ func newServerComponentLocator(
 	case sous.DeploymentManager:
 		dm = ldm
 	}
+	var hr sous.HistoryReader
+	if mdb.Err == nil {
+		hr = storage.NewPostgresStateManager(mdb.Db, ls.Child("history"))
+	}
+	bouncer, _ := d.(sous.Bouncer)
+	jobRunner, _ := d.(sous.JobRunner)
+	deployers, _ := d.(*sous.DeployerRegistry)
+
 	return server.ComponentLocator{
 
 		LogSink:           ls.LogSink,
@@ -44,17 +65,127 @@ func newServerComponentLocator(
 		Version:           v,
 		QueueSet:          qs,
 		AutoResolver:      ar,
+		HistoryReader:     hr,
+		Leadership:        sl.Leadership,
+		Authenticator:     sa.Authenticator,
+		DriftDetector:     dd.DriftDetector,
+		Bouncer:           bouncer,
+		JobRunner:         jobRunner,
+		Deployers:         deployers,
+		Metrics:           mr,
 	}
 
 }
 
 // NewR11nQueueSet returns a new queue set configured to start processing r11ns
 // immediately.
-func NewR11nQueueSet(d sous.Deployer, r sous.Registry, rf *sous.ResolveFilter, sm *ServerStateManager) *sous.R11nQueueSet {
+// When there is a database, queued r11ns are stored in it, and those to
+// clusters this server leads still pending from before a restart are
+// resumed; the outcomes of deploys are recorded there too.
+// Rectifications of deployments to clusters this server does not lead are
+// refused, and the resolutions of the rest are sent to n.
+func NewR11nQueueSet(d sous.Deployer, r sous.Registry, rf *sous.ResolveFilter, sm *ServerStateManager, c LocalSousConfig, mdb MaybeDatabase, sl ServerLeadership, n *sous.Notifier, ls LogSink) *sous.R11nQueueSet {
 	sr := sm.StateManager
-	return sous.NewR11nQueueSet(sous.R11nQueueStartWithHandler(
+	rb := sous.NewRollbacker(sm.StateManager, ls.Child("rollback"))
+	var ol *sous.OutcomeLog
+	if mdb.Err == nil {
+		ol = sous.NewOutcomeLog(storage.NewPostgresStateManager(mdb.Db, ls.Child("outcomes")), ls.Child("outcomes"))
+	}
+	opts := []sous.R11nQueueOpt{sous.R11nQueueStartWithHandler(
 		func(qr *sous.QueuedR11n) sous.DiffResolution {
+			if rez, refused := sl.Guard(&qr.Rectification.Pair); refused {
+				return rez
+			}
 			qr.Rectification.Begin(d, r, rf, sr)
-			return qr.Rectification.Wait()
-		}))
+			rez := qr.Rectification.Wait()
+			ol.HandleResolution(&qr.Rectification.Pair, rez)
+			rez = rb.HandleResolution(&qr.Rectification.Pair, rez)
+			go n.Notify(rez)
+			return rez
+		})}
+	if mdb.Err != nil {
+		return sous.NewR11nQueueSet(opts...)
+	}
+
+	retention := time.Duration(c.R11nRetentionMinutes) * time.Minute
+	store := storage.NewPostgresR11nStore(mdb.Db, retention, ls.Child("r11n-store"))
+	qs := sous.NewR11nQueueSet(append(opts, sous.R11nQueueStore(store, ls.Child("r11n-queue")))...)
+	// Only rectifications to clusters this server leads are resumed; those
+	// to other clusters are left to their leaders.
+	if state, err := sr.ReadState(); err != nil {
+		logging.ReportError(ls, errors.Wrapf(err, "reading state to restore queued rectifications"))
+	} else if err := qs.Restore(store, sl.Led(state.Defs.Clusters).Names(), ls.Child("r11n-queue")); err != nil {
+		logging.ReportError(ls, err)
+	}
+	return qs
+}
+
+// ServerLeadership wraps the *sous.Leadership of a server, which is nil
+// when there is no database to hold elections in.
+type ServerLeadership struct {
+	*sous.Leadership
+}
+
+func newServerLeadership(c LocalSousConfig, mdb MaybeDatabase, ls LogSink) ServerLeadership {
+	if mdb.Err != nil {
+		return ServerLeadership{}
+	}
+	id := c.AdvertisedURL
+	if id == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			logging.ReportError(ls, err)
+		}
+		id = host
+	}
+	e := storage.NewPostgresLeaderElector(mdb.Db, id, ls.Child("leader-election"))
+	return ServerLeadership{sous.NewLeadership(id, e, ls.Child("leadership"))}
+}
+
+// newNotifier returns the Notifier that sends notifications through the
+// sinks configured by c, routed by the Defs read from sm.
+func newNotifier(c LocalSousConfig, sm *ServerStateManager, ls LogSink) *sous.Notifier {
+	n := sous.NewNotifier(sm.StateManager, ls.Child("notifier"))
+	n.Slack, n.Webhook, n.Email = notify.NewSinks(c.Notify, c.SlackHookURL, c.SlackChannel, c.AdditionalSlackChannels)
+	return n
+}
+
+// ServerDriftDetector wraps the *sous.DriftDetector of a server, which is
+// nil when drift detection is disabled.
+type ServerDriftDetector struct {
+	*sous.DriftDetector
+}
+
+func newServerDriftDetector(d sous.Deployer, r sous.Registry, rf *sous.ResolveFilter, sm *ServerStateManager, c LocalSousConfig, ls LogSink) ServerDriftDetector {
+	if c.DriftIntervalSeconds <= 0 {
+		return ServerDriftDetector{}
+	}
+	interval := time.Duration(c.DriftIntervalSeconds) * time.Second
+	dd := sous.NewDriftDetector(d, r, sm, rf, interval, ls.Child("drift"))
+	return ServerDriftDetector{dd}
+}
+
+// newMetricsRegistry returns the Registry of the metrics served at /metrics:
+// those of rectifications, resolve cycles and requests to Singularity, the
+// depths of the queues in qs, and the drift found by dd, if it is enabled.
+func newMetricsRegistry(qs *sous.R11nQueueSet, dd ServerDriftDetector) *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	sous.RegisterMetrics(r)
+	singularity.RegisterMetrics(r)
+	r.Register(qs)
+	if dd.DriftDetector != nil {
+		r.Register(dd)
+	}
+	return r
+}
+
+// ServerAuthenticator wraps the server.Authenticator of a server, which is
+// nil when authentication is not configured.
+type ServerAuthenticator struct {
+	server.Authenticator
+}
+
+func newServerAuthenticator(c LocalSousConfig) (ServerAuthenticator, error) {
+	a, err := server.NewAuthenticator(c.Auth)
+	return ServerAuthenticator{a}, err
 }
@@ -466,7 +466,7 @@ func TestMissingImage(t *testing.T) {
 	rf := &sous.ResolveFilter{}
 	sr := sous.NewDummyStateManager()
 	sr.State = &stateOne
-	qs := graph.NewR11nQueueSet(suite.deployer, suite.nameCache, rf, &graph.ServerStateManager{sr})
+	qs := graph.NewR11nQueueSet(suite.deployer, suite.nameCache, rf, &graph.ServerStateManager{sr}, graph.LocalSousConfig{}, graph.MaybeDatabase{Err: fmt.Errorf("no database")}, graph.ServerLeadership{}, nil, graph.LogSink{suite.ls})
 	r := sous.NewResolver(suite.deployer, suite.nameCache, rf, suite.ls, qs)
 
 	deploymentsOne, err := stateOne.Deployments()
@@ -528,7 +528,7 @@ func TestResolve(t *testing.T) {
 	rf := &sous.ResolveFilter{}
 	sr := sous.NewDummyStateManager()
 	sr.State = &stateOneTwo
-	qs := graph.NewR11nQueueSet(suite.deployer, suite.nameCache, rf, &graph.ServerStateManager{sr})
+	qs := graph.NewR11nQueueSet(suite.deployer, suite.nameCache, rf, &graph.ServerStateManager{sr}, graph.LocalSousConfig{}, graph.MaybeDatabase{Err: fmt.Errorf("no database")}, graph.ServerLeadership{}, nil, graph.LogSink{suite.ls})
 	r := sous.NewResolver(suite.deployer, suite.nameCache, rf, logsink, qs)
 
 	suite.T().Log("Begining OneTwo")
@@ -601,7 +601,7 @@ func TestResolve(t *testing.T) {
 		rf := &sous.ResolveFilter{}
 		sr := sous.NewDummyStateManager()
 		sr.State = &stateOneTwo
-		qs := graph.NewR11nQueueSet(suite.deployer, suite.nameCache, rf, &graph.ServerStateManager{sr})
+		qs := graph.NewR11nQueueSet(suite.deployer, suite.nameCache, rf, &graph.ServerStateManager{sr}, graph.LocalSousConfig{}, graph.MaybeDatabase{Err: fmt.Errorf("no database")}, graph.ServerLeadership{}, nil, graph.LogSink{suite.ls})
 		r := sous.NewResolver(deployer, suite.nameCache, rf, logging.SilentLogSet(), qs)
 
 		err := r.Begin(deploymentsTwoThree, clusterDefs.Clusters).Wait()
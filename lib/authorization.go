@@ -0,0 +1,105 @@
+package sous
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An UnauthorizedError is returned when a user may not make a change.
+type UnauthorizedError struct {
+	User User
+	// ManifestID is the manifest the user may not change, or nil if the
+	// change needs an admin.
+	ManifestID *ManifestID
+}
+
+func (e *UnauthorizedError) Error() string {
+	if e.ManifestID == nil {
+		return fmt.Sprintf("%s is not a Sous admin", e.User)
+	}
+	return fmt.Sprintf("%s is neither an owner of %q nor a Sous admin", e.User, *e.ManifestID)
+}
+
+// Identifies returns true if id is u's email address or name. Case is
+// ignored, and empty ids identify no one.
+func (u User) Identifies(id string) bool {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return false
+	}
+	return strings.EqualFold(id, u.Email) || strings.EqualFold(id, u.Name)
+}
+
+// IsAdmin returns true if u is one of the Admins in d.
+func (d Defs) IsAdmin(u User) bool {
+	for _, a := range d.Admins {
+		if u.Identifies(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOwner returns true if u is one of the Owners of m.
+func (m *Manifest) IsOwner(u User) bool {
+	for _, o := range m.Owners {
+		if u.Identifies(o) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizeAdmin returns an UnauthorizedError unless u is an admin in d.
+func AuthorizeAdmin(d Defs, u User) error {
+	if d.IsAdmin(u) {
+		return nil
+	}
+	return &UnauthorizedError{User: u}
+}
+
+// AuthorizeManifest returns an UnauthorizedError unless u may change the
+// manifest prior into post. Either may be nil, for additions and removals.
+// Admins may change any manifest. Otherwise, u must own prior, or post if
+// it is being added; owners cannot be added by someone who is not one
+// already.
+func AuthorizeManifest(d Defs, prior, post *Manifest, u User) error {
+	if d.IsAdmin(u) {
+		return nil
+	}
+	m := prior
+	if m == nil {
+		m = post
+	}
+	if m == nil || m.IsOwner(u) {
+		return nil
+	}
+	mid := m.ID()
+	return &UnauthorizedError{User: u, ManifestID: &mid}
+}
+
+// AuthorizeManifests returns an UnauthorizedError unless u may make every
+// change from prior to post, as AuthorizeManifest. Admins are defined by d.
+func AuthorizeManifests(d Defs, prior, post Manifests, u User) error {
+	if d.IsAdmin(u) {
+		return nil
+	}
+	ids := map[ManifestID]struct{}{}
+	for _, mid := range prior.Keys() {
+		ids[mid] = struct{}{}
+	}
+	for _, mid := range post.Keys() {
+		ids[mid] = struct{}{}
+	}
+	for mid := range ids {
+		before, _ := prior.Get(mid)
+		after, _ := post.Get(mid)
+		if before != nil && after != nil && before.Equal(after) {
+			continue
+		}
+		if err := AuthorizeManifest(d, before, after, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_Identifies(t *testing.T) {
+	u := User{Name: "Judson Lester", Email: "jlester@example.com"}
+	assert.True(t, u.Identifies("jlester@example.com"))
+	assert.True(t, u.Identifies("JLester@Example.com"))
+	assert.True(t, u.Identifies("judson lester"))
+	assert.False(t, u.Identifies("sam"))
+	assert.False(t, User{}.Identifies(""))
+}
+
+func TestAuthorizeManifest(t *testing.T) {
+	defs := Defs{Admins: []string{"admin@example.com"}}
+	owned := &Manifest{Source: SourceLocation{Repo: "github.com/example/project"}, Owners: []string{"owner@example.com"}}
+	grabbed := owned.Clone()
+	grabbed.Owners = append(grabbed.Owners, "intruder@example.com")
+
+	owner := User{Email: "owner@example.com"}
+	admin := User{Email: "admin@example.com"}
+	intruder := User{Email: "intruder@example.com"}
+
+	assert.NoError(t, AuthorizeManifest(defs, owned, owned, owner))
+	assert.NoError(t, AuthorizeManifest(defs, owned, nil, owner))
+	assert.NoError(t, AuthorizeManifest(defs, owned, owned, admin))
+	assert.NoError(t, AuthorizeManifest(defs, nil, grabbed, intruder), "anyone may add a manifest they own")
+
+	err := AuthorizeManifest(defs, owned, grabbed, intruder)
+	if assert.IsType(t, &UnauthorizedError{}, err) {
+		assert.Contains(t, err.Error(), `neither an owner of "github.com/example/project"`)
+	}
+}
+
+func TestAuthorizeManifests(t *testing.T) {
+	defs := Defs{Admins: []string{"admin@example.com"}}
+	mine := &Manifest{Source: SourceLocation{Repo: "github.com/example/mine"}, Owners: []string{"me@example.com"}}
+	theirs := &Manifest{Source: SourceLocation{Repo: "github.com/example/theirs"}, Owners: []string{"them@example.com"}}
+	me := User{Email: "me@example.com"}
+
+	prior := NewManifests(mine, theirs)
+
+	changedMine := mine.Clone()
+	changedMine.Kind = ManifestKindService
+	assert.NoError(t, AuthorizeManifests(defs, prior, NewManifests(changedMine, theirs), me))
+
+	changedTheirs := theirs.Clone()
+	changedTheirs.Kind = ManifestKindService
+	assert.Error(t, AuthorizeManifests(defs, prior, NewManifests(mine, changedTheirs), me))
+	assert.Error(t, AuthorizeManifests(defs, prior, NewManifests(mine), me), "removing another's manifest")
+	assert.NoError(t, AuthorizeManifests(defs, prior, NewManifests(mine), User{Email: "admin@example.com"}))
+}
+
+func TestAuthorizeAdmin(t *testing.T) {
+	defs := Defs{Admins: []string{"admin@example.com"}}
+	assert.NoError(t, AuthorizeAdmin(defs, User{Email: "admin@example.com"}))
+	assert.EqualError(t, AuthorizeAdmin(defs, User{Name: "Sam", Email: "sam@example.com"}), "Sam <sam@example.com> is not a Sous admin")
+}
@@ -27,6 +27,12 @@ type (
 		GDM Deployments
 		*Resolver
 		logging.LogSink
+		// Leadership, if not nil, limits resolution to the clusters this
+		// server leads.
+		Leadership *Leadership
+		// Notifier, if not nil, is notified of the deployments that fail
+		// before they can be rectified.
+		Notifier  *Notifier
 		listeners []autoResolveListener
 		sync.RWMutex
 		stableStatus, liveStatus *ResolveStatus
@@ -177,8 +183,15 @@ func (ar *AutoResolver) resolveOnce(ac announceChannel) {
 		return
 	}
 
+	clusters := ar.Leadership.Led(ar.Resolver.FilteredClusters(state.Defs.Clusters))
+	intended := ar.GDM.Filter(func(d *Deployment) bool {
+		_, led := clusters[d.ClusterName]
+		return led
+	})
+
 	ar.write(func() {
-		ar.currentRecorder = ar.Resolver.Begin(ar.GDM, state.Defs.Clusters)
+		ar.currentRecorder = ar.Resolver.Begin(intended, clusters)
+		ar.Notifier.Watch(ar.currentRecorder)
 	})
 	defer ar.write(func() {
 		ar.currentRecorder = nil
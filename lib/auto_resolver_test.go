@@ -100,3 +100,32 @@ func TestResolveLoop(t *testing.T) {
 		t.Error("Should have announced a result")
 	}
 }
+
+func TestAutoResolver_Leadership(t *testing.T) {
+	state := NewState()
+	state.Defs.Clusters = Clusters{
+		"cluster-1": {Name: "cluster-1"},
+		"cluster-2": {Name: "cluster-2"},
+	}
+	state.Manifests.Add(&Manifest{
+		Source: SourceLocation{Repo: "github.com/opentable/example"},
+		Kind:   ManifestKindService,
+		Deployments: DeploySpecs{
+			"cluster-1": {DeployConfig: DeployConfig{NumInstances: 1}},
+			"cluster-2": {DeployConfig: DeployConfig{NumInstances: 1}},
+		},
+	})
+	ls := logging.SilentLogSet()
+	ar := NewAutoResolver(dummyResolver(), &DummyStateManager{State: state}, ls)
+	ar.Leadership = NewLeadership("self", &fakeElector{leads: map[string]bool{"cluster-1": true}}, ls)
+
+	tc := make(TriggerChannel, 1)
+	ac := make(announceChannel, 1)
+	tc.trigger()
+	ar.resolveLoop(tc, make(TriggerChannel), ac)
+
+	stable, _ := ar.Statuses()
+	if assert.NotNil(t, stable) && assert.Len(t, stable.Intended, 1) {
+		assert.Equal(t, "cluster-1", stable.Intended[0].ClusterName)
+	}
+}
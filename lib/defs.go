@@ -2,6 +2,8 @@ package sous
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/opentable/sous/util/restful"
 )
@@ -45,6 +47,9 @@ func (ds *Defs) Diff(o *Defs) []string {
 	vs = append(vs, prefixed("resource ", ds.Resources.Diff(o.Resources))...)
 	vs = append(vs, prefixed("metadata ", ds.Metadata.Diff(o.Metadata))...)
 	vs = append(vs, prefixed("envdefs ", ds.EnvVars.Diff(o.EnvVars))...)
+	if !reflect.DeepEqual(ds.Quotas, o.Quotas) {
+		vs = append(vs, "quotas differ")
+	}
 
 	return vs
 }
@@ -89,6 +94,12 @@ func (ed EnvDef) Diff(o EnvDef) []string {
 	if ed.Type != o.Type {
 		vs = append(vs, "types differ")
 	}
+	if strings.Join(ed.Values, ",") != strings.Join(o.Values, ",") {
+		vs = append(vs, "values differ")
+	}
+	if ed.Required != o.Required {
+		vs = append(vs, "required differs")
+	}
 	return vs
 }
 
@@ -188,3 +199,28 @@ func (c *Cluster) Diff(oc *Cluster) []string {
 
 	return vs
 }
+
+// Validate implements Flawed on Defs. It reports undefined clusters, invalid
+// EnvDefs, cluster Envs that do not match them, and invalid quotas. Whether
+// each cluster's Kind has a Deployer depends on the deployers in use; see
+// DeployerRegistry.ValidateDefs.
+func (ds *Defs) Validate() []Flaw {
+	var flaws []Flaw
+	for _, name := range ds.Clusters.Names() {
+		c := ds.Clusters[name]
+		if c == nil {
+			flaws = append(flaws, FatalFlaw("cluster %q has no definition", name))
+		}
+	}
+	flaws = append(flaws, ds.validateEnvDefs()...)
+	flaws = append(flaws, ds.Quotas.Validate()...)
+	for _, q := range ds.Quotas {
+		if _, ok := ds.Clusters[q.Cluster]; q.Cluster != "" && !ok {
+			flaws = append(flaws, FatalFlaw("quota for %s: cluster %q is not defined", q, q.Cluster))
+		}
+	}
+	for _, f := range flaws {
+		f.AddContext("defs", ds)
+	}
+	return flaws
+}
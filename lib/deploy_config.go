@@ -2,8 +2,10 @@ package sous
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/opentable/sous/util/cron"
 	"github.com/pkg/errors"
 )
 
@@ -33,6 +35,18 @@ type (
 		Startup Startup `yaml:",omitempty"`
 		// Schedule is a cronjob-format schedule for jobs.
 		Schedule string
+		// ScheduleTimeZone is the name of the time zone, e.g.
+		// "America/Los_Angeles", in which Schedule is interpreted. If unset,
+		// Schedule is in UTC.
+		ScheduleTimeZone string `yaml:",omitempty"`
+		// Rollout describes how changes to this deployment are rolled out.
+		// If unset, all instances are replaced at once.
+		Rollout Rollout `yaml:",omitempty"`
+		// Paused deployments keep their configuration, including
+		// NumInstances, but have no running instances until unpaused. If it
+		// is nil, it is inherited from the manifest's Defaults, and
+		// otherwise the deployment is not paused.
+		Paused *bool `yaml:",omitempty"`
 
 		// SingularityRequestID is the ID of the request representing this
 		// deployment in a Singularity scheduler.
@@ -78,6 +92,12 @@ func (dc *DeployConfig) Validate() []Flaw {
 
 	flaws = append(flaws, dc.Startup.Validate()...)
 
+	flaws = append(flaws, dc.validateSchedule()...)
+
+	flaws = append(flaws, dc.Rollout.Validate()...)
+
+	flaws = append(flaws, dc.Env.validateSecretRefs()...)
+
 	for _, f := range flaws {
 		f.AddContext("deploy config", dc)
 	}
@@ -85,6 +105,50 @@ func (dc *DeployConfig) Validate() []Flaw {
 	return flaws
 }
 
+// validateSchedule checks that Schedule, if set, is a cron schedule that
+// runs, and that ScheduleTimeZone names a known time zone.
+func (dc *DeployConfig) validateSchedule() []Flaw {
+	var flaws []Flaw
+	if dc.Schedule != "" {
+		if s, err := cron.Parse(dc.Schedule); err != nil {
+			flaws = append(flaws, FatalFlaw("Schedule %q is invalid: %s", dc.Schedule, err))
+		} else if s.Next(time.Now()).IsZero() {
+			flaws = append(flaws, FatalFlaw("Schedule %q never runs", dc.Schedule))
+		}
+	}
+	if dc.ScheduleTimeZone != "" {
+		if _, err := time.LoadLocation(dc.ScheduleTimeZone); err != nil {
+			flaws = append(flaws, FatalFlaw("ScheduleTimeZone %q is not a known time zone", dc.ScheduleTimeZone))
+		}
+	}
+	return flaws
+}
+
+// NextRuns returns the next n times after after that dc.Schedule runs at, in
+// ScheduleTimeZone.
+func (dc DeployConfig) NextRuns(after time.Time, n int) ([]time.Time, error) {
+	if dc.Schedule == "" {
+		return nil, errors.Errorf("no schedule")
+	}
+	s, err := cron.Parse(dc.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(dc.ScheduleTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	var runs []time.Time
+	t := after.In(loc)
+	for len(runs) < n {
+		if t = s.Next(t); t.IsZero() {
+			break
+		}
+		runs = append(runs, t)
+	}
+	return runs, nil
+}
+
 // AddContext simply discards all context - NilVolumeFlaw doesn't need it.
 func (nvf *NilVolumeFlaw) AddContext(string, interface{}) {
 }
@@ -148,6 +212,9 @@ func (dc *DeployConfig) Diff(o DeployConfig) (bool, []string) {
 			diffs = append(diffs, fmt.Sprintf("volumes; this: %v; other: %v", dc.Volumes, o.Volumes))
 		}
 	}
+	if dc.IsPaused() != o.IsPaused() {
+		diffs = append(diffs, fmt.Sprintf("paused; this: %t; other: %t", dc.IsPaused(), o.IsPaused()))
+	}
 	if dc.SingularityRequestID != o.SingularityRequestID {
 		diffs = append(diffs, fmt.Sprintf("SingularityRequestID; this: %q; other %q",
 			dc.SingularityRequestID, o.SingularityRequestID))
@@ -183,9 +250,19 @@ func (dc DeployConfig) Clone() DeployConfig {
 	dc.Resources = dc.Resources.Clone()
 	dc.Metadata = dc.Metadata.Clone()
 	dc.Volumes = dc.Volumes.Clone()
+	dc.Rollout = dc.Rollout.Clone()
+	if dc.Paused != nil {
+		paused := *dc.Paused
+		dc.Paused = &paused
+	}
 	return dc
 }
 
+// IsPaused returns true if dc is explicitly paused.
+func (dc DeployConfig) IsPaused() bool {
+	return dc.Paused != nil && *dc.Paused
+}
+
 // Equal compares Envs
 func (e Env) Equal(o Env) bool {
 	if len(e) != len(o) {
@@ -250,6 +327,25 @@ func flattenDeployConfigs(dcs []DeployConfig) DeployConfig {
 			break
 		}
 	}
+	for _, c := range dcs {
+		if c.ScheduleTimeZone != "" {
+			dc.ScheduleTimeZone = c.ScheduleTimeZone
+			break
+		}
+	}
+	for _, c := range dcs {
+		if !c.Rollout.IsZero() {
+			dc.Rollout = c.Rollout.Clone()
+			break
+		}
+	}
+	for _, c := range dcs {
+		if c.Paused != nil {
+			paused := *c.Paused
+			dc.Paused = &paused
+			break
+		}
+	}
 	for _, c := range dcs {
 		for n, v := range c.Resources {
 			if _, set := dc.Resources[n]; !set {
@@ -267,8 +363,53 @@ func flattenDeployConfigs(dcs []DeployConfig) DeployConfig {
 			}
 		}
 
-		dc.Startup = c.Startup
-		dc.SingularityRequestID = c.SingularityRequestID
+		// Startup fields are inherited where they are zero, so a zero value
+		// cannot override an inherited one.
+		dc.Startup = c.Startup.MergeDefaults(dc.Startup)
+	}
+	for _, c := range dcs {
+		if c.SingularityRequestID != "" {
+			dc.SingularityRequestID = c.SingularityRequestID
+			break
+		}
+	}
+	return dc
+}
+
+// unmergeDefaults returns a copy of dc without the configuration it would
+// inherit from defaults, so that flattening it with defaults reproduces dc.
+// Fields that were set in old, the configuration dc replaces, are kept even
+// if they match defaults.
+func (dc DeployConfig) unmergeDefaults(defaults, old DeployConfig) DeployConfig {
+	dc = dc.Clone()
+	if defaults.NumInstances != 0 && dc.NumInstances == defaults.NumInstances && old.NumInstances == 0 {
+		dc.NumInstances = 0
+	}
+	if len(defaults.Volumes) != 0 && dc.Volumes.Equal(defaults.Volumes) && len(old.Volumes) == 0 {
+		dc.Volumes = nil
+	}
+	if defaults.Schedule != "" && dc.Schedule == defaults.Schedule && old.Schedule == "" {
+		dc.Schedule = ""
+	}
+	if defaults.ScheduleTimeZone != "" && dc.ScheduleTimeZone == defaults.ScheduleTimeZone && old.ScheduleTimeZone == "" {
+		dc.ScheduleTimeZone = ""
+	}
+	if !defaults.Rollout.IsZero() && len(dc.Rollout.Diff(defaults.Rollout)) == 0 && old.Rollout.IsZero() {
+		dc.Rollout = Rollout{}
+	}
+	if defaults.Paused != nil && dc.Paused != nil && *dc.Paused == *defaults.Paused && old.Paused == nil {
+		dc.Paused = nil
+	}
+	unmergeMap := func(m, defaults, old map[string]string) {
+		for k, v := range defaults {
+			if _, set := old[k]; !set && m[k] == v {
+				delete(m, k)
+			}
+		}
 	}
+	unmergeMap(dc.Resources, defaults.Resources, old.Resources)
+	unmergeMap(dc.Env, defaults.Env, old.Env)
+	unmergeMap(dc.Metadata, defaults.Metadata, old.Metadata)
+	dc.Startup = defaults.Startup.UnmergeDefaults(dc.Startup, old.Startup)
 	return dc
 }
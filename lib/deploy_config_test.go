@@ -2,8 +2,10 @@ package sous
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeployConfig_Validate_Repair(t *testing.T) {
@@ -30,6 +32,44 @@ func TestDeployConfig_Validate_Repair(t *testing.T) {
 	assert.Len(t, dc.Volumes, 1)
 }
 
+func TestDeployConfig_Validate_schedule(t *testing.T) {
+	flawCount := func(schedule, tz string) int {
+		dc := DeployConfig{
+			Resources:        Resources{"cpus": "0.1", "memory": "32", "ports": "1"},
+			Startup:          Startup{SkipCheck: true},
+			Schedule:         schedule,
+			ScheduleTimeZone: tz,
+		}
+		return len(dc.Validate())
+	}
+	assert.Equal(t, 0, flawCount("", ""))
+	assert.Equal(t, 0, flawCount("*/15 9-17 * * mon-fri", "America/Los_Angeles"))
+	assert.Equal(t, 0, flawCount("@daily", ""))
+	assert.Equal(t, 1, flawCount("* * *", ""))
+	assert.Equal(t, 1, flawCount("60 * * * *", ""))
+	assert.Equal(t, 1, flawCount("0 0 30 2 *", ""), "schedules that never run are flawed")
+	assert.Equal(t, 1, flawCount("0 0 * * *", "Mars/Olympus_Mons"))
+}
+
+func TestDeployConfig_NextRuns(t *testing.T) {
+	dc := DeployConfig{Schedule: "30 9 * * mon", ScheduleTimeZone: "America/New_York"}
+	after := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	runs, err := dc.NextRuns(after, 2)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "2018-06-04T09:30:00-04:00", runs[0].Format(time.RFC3339))
+	assert.Equal(t, "2018-06-11T09:30:00-04:00", runs[1].Format(time.RFC3339))
+
+	dc.ScheduleTimeZone = ""
+	runs, err = dc.NextRuns(after, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2018-06-04T09:30:00Z", runs[0].Format(time.RFC3339))
+
+	_, err = DeployConfig{}.NextRuns(after, 1)
+	assert.Error(t, err)
+}
+
 // TODO: Add a more complete test for this Diff method.
 // This one just tests the new SingularityRequestID field.
 func TestDeployConfig_Diff_singularityRequestID(t *testing.T) {
@@ -82,9 +82,10 @@ func (spec DeploySpec) Diff(other DeploySpec) (bool, []string) {
 		diff("version; this: %q; other: %q", spec.Version, other.Version)
 	}
 	_, configDiffs := spec.DeployConfig.Diff(other.DeployConfig)
-	for _, d := range configDiffs {
-		diff(d)
-	}
+	diffs = append(diffs, configDiffs...)
+	// Rollout is not part of DeployConfig.Diff, as it is not part of what is
+	// deployed, but it is part of the spec.
+	diffs = append(diffs, spec.Rollout.Diff(other.Rollout)...)
 	return len(diffs) != 0, diffs
 }
 
@@ -11,6 +11,13 @@ type (
 		Status(Registry, Clusters, *DeployablePair) (*DeployState, error)
 	}
 
+	// A Bouncer is a Deployer that can restart the running instances of a
+	// deployment without changing it.
+	Bouncer interface {
+		// Bounce replaces the running instances of d, on behalf of user.
+		Bounce(d *Deployment, user User) error
+	}
+
 	// DeployerSpy is a noop deployer.
 	DeployerSpy struct {
 		*spies.Spy
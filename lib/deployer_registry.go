@@ -0,0 +1,165 @@
+package sous
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+// DefaultClusterKind is the Kind assumed for clusters which do not specify
+// one, for compatibility with states written before Kind was significant.
+const DefaultClusterKind = "singularity"
+
+type (
+	// DeployerRegistry is a Deployer which dispatches to other Deployers
+	// according to the Kind of the clusters involved. This allows a single
+	// Sous server to manage clusters of different kinds.
+	DeployerRegistry struct {
+		sync.RWMutex
+		backends map[string]Deployer
+		log      logging.LogSink
+	}
+)
+
+func kindOrDefault(kind string) string {
+	if kind == "" {
+		return DefaultClusterKind
+	}
+	return kind
+}
+
+// NewDeployerRegistry creates an empty DeployerRegistry.
+func NewDeployerRegistry(ls logging.LogSink) *DeployerRegistry {
+	return &DeployerRegistry{
+		backends: map[string]Deployer{},
+		log:      ls,
+	}
+}
+
+// Register adds d as the Deployer for clusters of the given kind.
+func (dr *DeployerRegistry) Register(kind string, d Deployer) {
+	dr.Lock()
+	defer dr.Unlock()
+	dr.backends[kind] = d
+}
+
+// Kinds returns the sorted cluster kinds this registry has Deployers for.
+func (dr *DeployerRegistry) Kinds() []string {
+	dr.RLock()
+	defer dr.RUnlock()
+	kinds := make([]string, 0, len(dr.backends))
+	for k := range dr.backends {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ValidateDefs reports the clusters in defs whose Kind has no Deployer in this
+// registry.
+func (dr *DeployerRegistry) ValidateDefs(defs Defs) []Flaw {
+	var flaws []Flaw
+	for _, name := range defs.Clusters.Names() {
+		c := defs.Clusters[name]
+		if c == nil {
+			continue
+		}
+		if _, err := dr.backend(c.Kind); err != nil {
+			flaws = append(flaws, FatalFlaw("cluster %q has kind %q, which has no registered deployer (known kinds: %q)",
+				name, c.Kind, dr.Kinds()))
+		}
+	}
+	return flaws
+}
+
+func (dr *DeployerRegistry) backend(kind string) (Deployer, error) {
+	dr.RLock()
+	d, has := dr.backends[kindOrDefault(kind)]
+	dr.RUnlock()
+	if !has {
+		return nil, errors.Errorf("no deployer registered for cluster kind %q (known kinds: %q)", kind, dr.Kinds())
+	}
+	return d, nil
+}
+
+func (dr *DeployerRegistry) pairBackend(pair *DeployablePair) (Deployer, error) {
+	dep := pair.Post
+	if dep == nil {
+		dep = pair.Prior
+	}
+	if dep == nil || dep.Deployment == nil || dep.Deployment.Cluster == nil {
+		return nil, errors.Errorf("cannot determine cluster kind of %q", pair.ID())
+	}
+	return dr.backend(dep.Deployment.Cluster.Kind)
+}
+
+// RunningDeployments implements Deployer on DeployerRegistry. It partitions
+// clusters by Kind, and collects the DeployStates from each backend.
+func (dr *DeployerRegistry) RunningDeployments(reg Registry, from Clusters) (DeployStates, error) {
+	byKind := map[string]Clusters{}
+	for name, c := range from {
+		kind := kindOrDefault(c.Kind)
+		if byKind[kind] == nil {
+			byKind[kind] = Clusters{}
+		}
+		byKind[kind][name] = c
+	}
+
+	deps := NewDeployStates()
+	for kind, clusters := range byKind {
+		d, err := dr.backend(kind)
+		if err != nil {
+			return deps, err
+		}
+		states, err := d.RunningDeployments(reg, clusters)
+		if err != nil {
+			return deps, errors.Wrapf(err, "%s clusters", kind)
+		}
+		if id, ok := deps.AddAll(states); !ok {
+			return deps, errors.Errorf("deployment %q reported by more than one deployer", id)
+		}
+	}
+	return deps, nil
+}
+
+// Rectify implements Deployer on DeployerRegistry.
+func (dr *DeployerRegistry) Rectify(pair *DeployablePair) DiffResolution {
+	d, err := dr.pairBackend(pair)
+	if err != nil {
+		logging.ReportError(dr.log, err)
+		return DiffResolution{
+			DeploymentID: pair.ID(),
+			Desc:         "not rectified",
+			Error:        WrapResolveError(err),
+		}
+	}
+	return d.Rectify(pair)
+}
+
+// Status implements Deployer on DeployerRegistry.
+func (dr *DeployerRegistry) Status(reg Registry, clusters Clusters, pair *DeployablePair) (*DeployState, error) {
+	d, err := dr.pairBackend(pair)
+	if err != nil {
+		return nil, err
+	}
+	return d.Status(reg, clusters, pair)
+}
+
+// Bounce implements Bouncer on DeployerRegistry, using the backend for the
+// Kind of the cluster of d.
+func (dr *DeployerRegistry) Bounce(d *Deployment, user User) error {
+	if d.Cluster == nil {
+		return errors.Errorf("cannot determine cluster kind of %q", d.ID())
+	}
+	backend, err := dr.backend(d.Cluster.Kind)
+	if err != nil {
+		return err
+	}
+	b, ok := backend.(Bouncer)
+	if !ok {
+		return errors.Errorf("deployments to %s clusters cannot be bounced", kindOrDefault(d.Cluster.Kind))
+	}
+	return b.Bounce(d, user)
+}
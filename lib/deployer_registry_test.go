@@ -0,0 +1,78 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/spies"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployerRegistry(t *testing.T) {
+	sing, singCtrl := NewDeployerSpy()
+	kube, kubeCtrl := NewDeployerSpy()
+
+	singStates := NewDeployStates(&DeployState{Deployment: Deployment{ClusterName: "sing"}})
+	kubeStates := NewDeployStates(&DeployState{Deployment: Deployment{ClusterName: "kube"}})
+	singCtrl.MatchMethod("RunningDeployments", spies.AnyArgs, singStates, nil)
+	kubeCtrl.MatchMethod("RunningDeployments", spies.AnyArgs, kubeStates, nil)
+	singCtrl.MatchMethod("Rectify", spies.AnyArgs, DiffResolution{Desc: CreateDiff})
+	kubeCtrl.MatchMethod("Rectify", spies.AnyArgs, DiffResolution{Desc: ModifyDiff})
+
+	ls, _ := logging.NewLogSinkSpy()
+	dr := NewDeployerRegistry(ls)
+	dr.Register("singularity", sing)
+	dr.Register("kubernetes", kube)
+	assert.Equal(t, []string{"kubernetes", "singularity"}, dr.Kinds())
+
+	clusters := Clusters{
+		"legacy": {Name: "legacy"},
+		"sing":   {Name: "sing", Kind: "singularity"},
+		"kube":   {Name: "kube", Kind: "kubernetes"},
+	}
+
+	states, err := dr.RunningDeployments(NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	assert.Equal(t, 2, states.Len())
+
+	singCalls := singCtrl.CallsTo("RunningDeployments")
+	require.Len(t, singCalls, 1)
+	assert.Len(t, singCalls[0].PassedArgs().Get(1).(Clusters), 2)
+	kubeCalls := kubeCtrl.CallsTo("RunningDeployments")
+	require.Len(t, kubeCalls, 1)
+	assert.Equal(t, []string{"kube"}, kubeCalls[0].PassedArgs().Get(1).(Clusters).Names())
+
+	pair := func(c *Cluster) *DeployablePair {
+		return &DeployablePair{Post: &Deployable{Deployment: &Deployment{Cluster: c}}}
+	}
+	assert.Equal(t, CreateDiff, dr.Rectify(pair(clusters["legacy"])).Desc)
+	assert.Equal(t, ModifyDiff, dr.Rectify(pair(clusters["kube"])).Desc)
+
+	res := dr.Rectify(pair(&Cluster{Name: "other", Kind: "unheard-of"}))
+	assert.NotNil(t, res.Error)
+	_, err = dr.Status(NewDummyRegistry(), clusters, pair(&Cluster{Kind: "unheard-of"}))
+	assert.Error(t, err)
+}
+
+func TestDeployerRegistry_ValidateDefs(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	dr := NewDeployerRegistry(ls)
+	dr.Register(DefaultClusterKind, NewDummyDeployer())
+
+	defs := Defs{
+		Clusters: Clusters{
+			"legacy":  {Name: "legacy"},
+			"sing":    {Name: "sing", Kind: DefaultClusterKind},
+			"mystery": {Name: "mystery", Kind: "mystery-scheduler"},
+		},
+	}
+
+	flaws := dr.ValidateDefs(defs)
+	if assert.Len(t, flaws, 1) {
+		assert.Contains(t, flaws[0].(GenericFlaw).Desc, `"mystery-scheduler"`)
+	}
+
+	dr.Register("mystery-scheduler", NewDummyDeployer())
+	assert.Len(t, dr.ValidateDefs(defs), 0)
+}
@@ -38,6 +38,8 @@ type (
 		Kind ManifestKind
 		// User
 		User User
+		// AutoRollback is copied from the manifest; see Manifest.AutoRollback.
+		AutoRollback bool
 	}
 )
 
@@ -98,6 +100,23 @@ func (d *Deployment) Validate() []Flaw {
 		flaws = append(flaws, d.Kind.Validate()...)
 	}
 
+	switch {
+	case d.Kind.IsScheduled() && d.Schedule == "":
+		flaws = append(flaws, FatalFlaw("deployment %q of kind %q has no Schedule", d.ID(), d.Kind))
+	case !d.Kind.IsScheduled() && d.Schedule != "":
+		flaws = append(flaws, NewFlaw(
+			fmt.Sprintf("deployment %q of kind %q must not have a Schedule, has %q", d.ID(), d.Kind, d.Schedule),
+			func() error { d.Schedule = ""; return nil },
+		))
+	}
+
+	if d.Cluster != nil && kindOrDefault(d.Cluster.Kind) == "singularity" && !d.Startup.SkipCheck {
+		switch protocol := strings.ToUpper(d.Startup.CheckReadyProtocol); protocol {
+		case "TCP", "COMMAND":
+			flaws = append(flaws, FatalFlaw("deployment %q has a %s startup check, but Singularity clusters only make HTTP and HTTPS checks", d.ID(), protocol))
+		}
+	}
+
 	cf := d.DeployConfig.Validate()
 	flaws = append(flaws, cf...)
 
@@ -219,6 +238,9 @@ func (d *Deployment) Diff(o *Deployment) (bool, Differences) {
 		if d.Schedule != o.Schedule {
 			diff("schedule; this: %q, other: %q", d.Schedule, o.Schedule)
 		}
+		if d.ScheduleTimeZone != o.ScheduleTimeZone {
+			diff("schedule time zone; this: %q, other: %q", d.ScheduleTimeZone, o.ScheduleTimeZone)
+		}
 	}
 
 	if len(d.Owners) != len(o.Owners) {
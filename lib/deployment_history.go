@@ -0,0 +1,190 @@
+package sous
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+type (
+	// A DeploymentChange records one change to the intended state of a
+	// deployment, as made by a single WriteState.
+	DeploymentChange struct {
+		DeploymentID DeploymentID
+		// At is when the change was written.
+		At time.Time
+		// User is who made the change.
+		User User
+		// Kind is the kind of change: "added", "removed" or "modified".
+		Kind string
+		// PriorVersion and PostVersion are the intended versions before and
+		// after the change. PriorVersion is empty for additions, and
+		// PostVersion is empty for removals.
+		PriorVersion, PostVersion string
+		// Diffs describes each field that changed.
+		Diffs Differences
+	}
+
+	// A DeployOutcome records whether a deploy of a version of a deployment
+	// became active or failed.
+	DeployOutcome struct {
+		DeploymentID DeploymentID
+		// At is when the outcome was observed.
+		At time.Time
+		// Version is the version that was deployed.
+		Version string
+		// Succeeded is true if Version became active, and false if its deploy
+		// failed.
+		Succeeded bool
+	}
+
+	// An OutcomeRecorder records DeployOutcomes.
+	OutcomeRecorder interface {
+		RecordOutcome(DeployOutcome) error
+	}
+
+	// An OutcomeLog records the outcomes of rectifications through an
+	// OutcomeRecorder, skipping those which repeat the last outcome recorded
+	// for the same deployment. A nil *OutcomeLog records nothing.
+	OutcomeLog struct {
+		recorder OutcomeRecorder
+		sync.Mutex
+		last map[DeploymentID]DeployOutcome
+		log  logging.LogSink
+	}
+
+	// A HistoryFilter selects DeploymentChanges and DeployOutcomes from a
+	// HistoryReader.
+	HistoryFilter struct {
+		// ManifestID, if not nil, selects changes to deployments of that
+		// manifest.
+		ManifestID *ManifestID
+		// Cluster, if not empty, selects changes to deployments to that
+		// cluster.
+		Cluster string
+		// Limit is the maximum number of changes to return, most recent
+		// first. Zero means no limit.
+		Limit int
+	}
+
+	// A HistoryReader reads the deployment change history.
+	HistoryReader interface {
+		// ReadHistory returns the changes selected by filter, most recent
+		// first.
+		ReadHistory(filter HistoryFilter) ([]DeploymentChange, error)
+		// ReadOutcomes returns the deploy outcomes selected by filter, most
+		// recent first.
+		ReadOutcomes(filter HistoryFilter) ([]DeployOutcome, error)
+	}
+)
+
+// NewDeploymentChange returns the DeploymentChange made by user at time at,
+// as described by pair. It returns nil if pair describes no change.
+func NewDeploymentChange(pair *DeployablePair, user User, at time.Time) *DeploymentChange {
+	change := &DeploymentChange{
+		DeploymentID: pair.ID(),
+		At:           at,
+		User:         user,
+		Kind:         pair.Kind().String(),
+		Diffs:        Differences{},
+	}
+	switch pair.Kind() {
+	default:
+		return nil
+	case AddedKind:
+		change.PostVersion = pair.Post.SourceID.Version.String()
+	case RemovedKind:
+		change.PriorVersion = pair.Prior.SourceID.Version.String()
+	case ModifiedKind:
+		change.PriorVersion = pair.Prior.SourceID.Version.String()
+		change.PostVersion = pair.Post.SourceID.Version.String()
+		change.Diffs = pair.Diffs()
+	}
+	return change
+}
+
+// NewDeployOutcome returns the outcome of rectifying pair shown by rez, its
+// resolution, as observed at time at. It returns nil if rez shows neither that
+// the intended version became active nor that its deploy failed.
+func NewDeployOutcome(pair *DeployablePair, rez DiffResolution, at time.Time) *DeployOutcome {
+	if pair.Post == nil {
+		return nil
+	}
+	version := pair.Post.SourceID.Version
+	outcome := &DeployOutcome{
+		DeploymentID: pair.ID(),
+		At:           at,
+		Version:      version.String(),
+	}
+	if _, failed := deployFailure(rez); failed {
+		return outcome
+	}
+	ds := rez.DeployState
+	if rez.Error != nil || ds == nil || ds.Status != DeployStatusActive || !ds.SourceID.Version.Equals(version) {
+		return nil
+	}
+	outcome.Succeeded = true
+	return outcome
+}
+
+// NewOutcomeLog creates an OutcomeLog which records outcomes with r.
+func NewOutcomeLog(r OutcomeRecorder, ls logging.LogSink) *OutcomeLog {
+	return &OutcomeLog{
+		recorder: r,
+		last:     map[DeploymentID]DeployOutcome{},
+		log:      ls,
+	}
+}
+
+// HandleResolution records the outcome of rectifying pair shown by rez, if
+// there is one, unless it repeats the last outcome recorded for pair.
+func (ol *OutcomeLog) HandleResolution(pair *DeployablePair, rez DiffResolution) {
+	if ol == nil {
+		return
+	}
+	outcome := NewDeployOutcome(pair, rez, time.Now())
+	if outcome == nil {
+		return
+	}
+	ol.Lock()
+	defer ol.Unlock()
+	last, has := ol.last[outcome.DeploymentID]
+	if has && last.Version == outcome.Version && last.Succeeded == outcome.Succeeded {
+		return
+	}
+	if err := ol.recorder.RecordOutcome(*outcome); err != nil {
+		logging.ReportError(ol.log, errors.Wrapf(err, "recording outcome of %s", outcome.DeploymentID))
+		return
+	}
+	ol.last[outcome.DeploymentID] = *outcome
+}
+
+// Match returns true if did is selected by f.
+func (f HistoryFilter) Match(did DeploymentID) bool {
+	if f.ManifestID != nil && did.ManifestID != *f.ManifestID {
+		return false
+	}
+	return f.Cluster == "" || did.Cluster == f.Cluster
+}
+
+// PreviousGoodVersion returns the most recent version of a deployment, other
+// than current, whose deploy succeeded according to outcomes, which must be
+// most recent first.
+func PreviousGoodVersion(outcomes []DeployOutcome, current semv.Version) (semv.Version, error) {
+	for _, o := range outcomes {
+		if !o.Succeeded {
+			continue
+		}
+		v, err := semv.Parse(o.Version)
+		if err != nil {
+			return semv.Version{}, errors.Wrapf(err, "outcome at %s", o.At)
+		}
+		if !v.Equals(current) {
+			return v, nil
+		}
+	}
+	return semv.Version{}, errors.Errorf("no version before %s succeeded according to the deployment history", current)
+}
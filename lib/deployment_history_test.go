@@ -0,0 +1,141 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeploymentChange(t *testing.T) {
+	user := User{Name: "Test User", Email: "test@example.com"}
+	at := time.Now()
+	deployable := func(version string, instances int) *Deployable {
+		return &Deployable{Deployment: &Deployment{
+			ClusterName:  "cluster-1",
+			SourceID:     SourceID{Location: SourceLocation{Repo: "github.com/example/project"}, Version: semv.MustParse(version)},
+			DeployConfig: DeployConfig{NumInstances: instances},
+		}}
+	}
+
+	pair := &DeployablePair{Prior: deployable("1.0.0", 1), Post: deployable("1.1.0", 2)}
+	c := NewDeploymentChange(pair, user, at)
+	require.NotNil(t, c)
+	assert.Equal(t, "modified", c.Kind)
+	assert.Equal(t, "1.0.0", c.PriorVersion)
+	assert.Equal(t, "1.1.0", c.PostVersion)
+	assert.Equal(t, user, c.User)
+	assert.Len(t, c.Diffs, 2)
+
+	c = NewDeploymentChange(&DeployablePair{Post: deployable("1.0.0", 1)}, user, at)
+	require.NotNil(t, c)
+	assert.Equal(t, "added", c.Kind)
+	assert.Equal(t, "", c.PriorVersion)
+	assert.Empty(t, c.Diffs)
+
+	same := &DeployablePair{Prior: deployable("1.0.0", 1), Post: deployable("1.0.0", 1)}
+	assert.Nil(t, NewDeploymentChange(same, user, at))
+}
+
+func TestHistoryFilter_Match(t *testing.T) {
+	mid := ManifestID{Source: SourceLocation{Repo: "github.com/example/project"}}
+	did := DeploymentID{ManifestID: mid, Cluster: "cluster-1"}
+
+	assert.True(t, HistoryFilter{}.Match(did))
+	assert.True(t, HistoryFilter{ManifestID: &mid}.Match(did))
+	assert.True(t, HistoryFilter{ManifestID: &mid, Cluster: "cluster-1"}.Match(did))
+	assert.False(t, HistoryFilter{Cluster: "cluster-2"}.Match(did))
+	other := ManifestID{Source: SourceLocation{Repo: "github.com/example/other"}}
+	assert.False(t, HistoryFilter{ManifestID: &other}.Match(did))
+}
+
+func TestPreviousGoodVersion(t *testing.T) {
+	outcome := func(version string, succeeded bool) DeployOutcome {
+		return DeployOutcome{Version: version, Succeeded: succeeded}
+	}
+	// Most recent first: 1.0.0 ok, 1.1.0 ok, 1.2.0 failed, 1.1.0 ok, 1.3.0 ok
+	outcomes := []DeployOutcome{
+		outcome("1.3.0", true),
+		outcome("1.1.0", true),
+		outcome("1.2.0", false),
+		outcome("1.1.0", true),
+		outcome("1.0.0", true),
+	}
+
+	v, err := PreviousGoodVersion(outcomes, semv.MustParse("1.3.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", v.String())
+
+	// A version whose deploy failed is never chosen, even if it was the
+	// last one intended.
+	v, err = PreviousGoodVersion(outcomes[2:], semv.MustParse("1.3.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", v.String())
+
+	v, err = PreviousGoodVersion(outcomes[3:], semv.MustParse("1.1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", v.String())
+
+	_, err = PreviousGoodVersion(outcomes[4:], semv.MustParse("1.0.0"))
+	assert.Error(t, err)
+}
+
+func outcomePair(version string) *DeployablePair {
+	return &DeployablePair{Post: &Deployable{Deployment: &Deployment{
+		ClusterName: "cluster-1",
+		SourceID:    SourceID{Location: SourceLocation{Repo: "github.com/example/project"}, Version: semv.MustParse(version)},
+	}}}
+}
+
+func outcomeResolution(version string, status DeployStatus) DiffResolution {
+	return DiffResolution{DeployState: &DeployState{
+		Deployment: Deployment{SourceID: SourceID{Version: semv.MustParse(version)}},
+		Status:     status,
+	}}
+}
+
+func TestNewDeployOutcome(t *testing.T) {
+	at := time.Now()
+
+	o := NewDeployOutcome(outcomePair("1.1.0"), outcomeResolution("1.1.0", DeployStatusActive), at)
+	require.NotNil(t, o)
+	assert.True(t, o.Succeeded)
+	assert.Equal(t, "1.1.0", o.Version)
+	assert.Equal(t, at, o.At)
+
+	o = NewDeployOutcome(outcomePair("1.1.0"), outcomeResolution("1.1.0", DeployStatusFailed), at)
+	require.NotNil(t, o)
+	assert.False(t, o.Succeeded)
+
+	assert.Nil(t, NewDeployOutcome(outcomePair("1.1.0"), outcomeResolution("1.1.0", DeployStatusPending), at))
+	assert.Nil(t, NewDeployOutcome(outcomePair("1.1.0"), outcomeResolution("1.0.0", DeployStatusActive), at))
+	assert.Nil(t, NewDeployOutcome(outcomePair("1.1.0"), DiffResolution{}, at))
+}
+
+type outcomeRecorderStub []DeployOutcome
+
+func (r *outcomeRecorderStub) RecordOutcome(o DeployOutcome) error {
+	*r = append(*r, o)
+	return nil
+}
+
+func TestOutcomeLog(t *testing.T) {
+	recorded := &outcomeRecorderStub{}
+	ls, _ := logging.NewLogSinkSpy()
+	ol := NewOutcomeLog(recorded, ls)
+
+	ol.HandleResolution(outcomePair("1.1.0"), outcomeResolution("1.1.0", DeployStatusPending))
+	ol.HandleResolution(outcomePair("1.1.0"), outcomeResolution("1.1.0", DeployStatusActive))
+	ol.HandleResolution(outcomePair("1.1.0"), outcomeResolution("1.1.0", DeployStatusActive))
+	ol.HandleResolution(outcomePair("1.2.0"), outcomeResolution("1.2.0", DeployStatusFailed))
+	require.Len(t, *recorded, 2)
+	assert.True(t, (*recorded)[0].Succeeded)
+	assert.Equal(t, "1.2.0", (*recorded)[1].Version)
+	assert.False(t, (*recorded)[1].Succeeded)
+
+	var none *OutcomeLog
+	none.HandleResolution(outcomePair("1.1.0"), outcomeResolution("1.1.0", DeployStatusActive))
+}
@@ -1,7 +1,9 @@
 package sous
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/opentable/sous/util/allfields"
@@ -31,6 +33,7 @@ func TestDeploymentDiffAnalysis(t *testing.T) {
 		"Deployment.Cluster.Startup.CheckReadyInterval",
 		"Deployment.Cluster.Startup.ConnectDelay",
 		"Deployment.Cluster.Startup.CheckReadyPortIndex",
+		"Deployment.Cluster.Startup.CheckReadyCommand",
 		// SourceID.Location is incorporated into the value of ID(),
 		// is is compared directly - Repo and Dir are compared implicitly thereby
 		"Deployment.SourceID.Location.Repo",
@@ -38,6 +41,20 @@ func TestDeploymentDiffAnalysis(t *testing.T) {
 		"Deployment.User",
 		"Deployment.User.Name",
 		"Deployment.User.Email",
+		// AutoRollback is a policy for handling failures, not part of what is
+		// running.
+		"Deployment.AutoRollback",
+		// Rollout describes how changes are applied, not what is running.
+		"Deployment.Rollout",
+		"Deployment.Rollout.CanaryInstances",
+		"Deployment.Rollout.StepPercents",
+		"Deployment.Rollout.SoakSeconds",
+		"Deployment.Rollout.StepTimeoutSeconds",
+		"Deployment.DeployConfig.Rollout",
+		"Deployment.DeployConfig.Rollout.CanaryInstances",
+		"Deployment.DeployConfig.Rollout.StepPercents",
+		"Deployment.DeployConfig.Rollout.SoakSeconds",
+		"Deployment.DeployConfig.Rollout.StepTimeoutSeconds",
 		/*
 			"Deployment.Owners",
 			"Deployment.DeployConfig.Args",
@@ -156,7 +173,7 @@ func TestDeployment_String(t *testing.T) {
 			in: &Deployment{},
 			// Current value of want reflects current reality.
 			// I think we can do better than this representation...
-			want: ",0.0.0 \"\" @ <unknown> #0 {false 0 0 0   0 <nil> 0 0 0} map[] : map[] []",
+			want: ",0.0.0 \"\" @ <unknown> #0 {false 0 0 0   0 <nil> 0 0 0 <nil>} map[] : map[] []",
 		},
 	}
 	for name, tc := range testCases {
@@ -176,3 +193,59 @@ func TestDeployment_String(t *testing.T) {
 		})
 	}
 }
+
+func TestDeployment_Validate_schedule(t *testing.T) {
+	flawCount := func(kind ManifestKind, schedule string) int {
+		d := &Deployment{
+			Kind:        kind,
+			ClusterName: "cluster",
+			DeployConfig: DeployConfig{
+				Resources: Resources{"cpus": "0.1", "memory": "32", "ports": "1"},
+				Startup:   Startup{SkipCheck: true},
+				Schedule:  schedule,
+			},
+		}
+		return len(d.Validate())
+	}
+	assert.Equal(t, 0, flawCount(ManifestKindScheduled, "0 * * * *"))
+	assert.Equal(t, 0, flawCount(ScheduledJob, "0 * * * *"))
+	assert.Equal(t, 1, flawCount(ManifestKindScheduled, ""))
+	assert.Equal(t, 1, flawCount(ScheduledJob, ""))
+	assert.Equal(t, 0, flawCount(ManifestKindService, ""))
+	assert.Equal(t, 1, flawCount(ManifestKindService, "0 * * * *"))
+	assert.Equal(t, 1, flawCount(ManifestKindWorker, "0 * * * *"))
+
+	d := &Deployment{
+		Kind:         ManifestKindService,
+		DeployConfig: DeployConfig{Schedule: "0 * * * *"},
+	}
+	for _, f := range d.Validate() {
+		if strings.Contains(fmt.Sprint(f), "must not have a Schedule") {
+			assert.NoError(t, f.Repair())
+		}
+	}
+	assert.Equal(t, "", d.Schedule)
+}
+
+func TestDeployment_Validate_startupOnSingularity(t *testing.T) {
+	flawCount := func(kind string, startup Startup) int {
+		d := &Deployment{
+			Kind:        ManifestKindService,
+			ClusterName: "cluster",
+			Cluster:     &Cluster{Kind: kind},
+			DeployConfig: DeployConfig{
+				Resources: Resources{"cpus": "0.1", "memory": "32", "ports": "1"},
+				Startup:   startup,
+			},
+		}
+		return len(d.Validate())
+	}
+	tcp := Startup{CheckReadyProtocol: "TCP", CheckReadyPortIndex: 0}
+	command := Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"/bin/check"}}
+	assert.Equal(t, 1, flawCount("singularity", tcp))
+	assert.Equal(t, 1, flawCount("", command))
+	assert.Equal(t, 0, flawCount("kubernetes", tcp))
+	assert.Equal(t, 0, flawCount("kubernetes", command))
+	assert.Equal(t, 0, flawCount("singularity", Startup{CheckReadyProtocol: "HTTP", CheckReadyURIPath: "/health"}))
+	assert.Equal(t, 0, flawCount("singularity", Startup{SkipCheck: true, CheckReadyProtocol: "TCP"}))
+}
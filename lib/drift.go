@@ -0,0 +1,233 @@
+package sous
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/prometheus"
+)
+
+type (
+	// A DriftReport lists the differences between the intended deployments
+	// and those running in their clusters, found at a point in time.
+	DriftReport struct {
+		// At is when the running deployments were compared.
+		At time.Time
+		// Drifted are the deployments whose running configuration differs
+		// from the intended one.
+		Drifted []DeploymentDrift
+		// Unknown are the running deployments that are not intended.
+		Unknown []DeploymentID
+		// Missing are the intended deployments that are not running.
+		Missing []DeploymentID
+	}
+
+	// A DeploymentDrift is a running deployment that differs from its
+	// intended configuration.
+	DeploymentDrift struct {
+		DeploymentID DeploymentID
+		// Diffs lists each field that differs. Prior values are those
+		// running, and Post values those intended.
+		Diffs []FieldDiff
+	}
+
+	// A DriftDetector periodically compares the running deployments with the
+	// intended ones, to find changes made to deployments other than by Sous.
+	DriftDetector struct {
+		Deployer    Deployer
+		Registry    Registry
+		StateReader StateReader
+		// ResolveFilter selects the clusters and deployments compared.
+		*ResolveFilter
+		// Interval is how long to wait between comparisons.
+		Interval time.Duration
+		ls       logging.LogSink
+		sync.RWMutex
+		report *DriftReport
+	}
+)
+
+// NewDriftDetector returns a DriftDetector that compares the deployments read
+// from sr, that are selected by rf, with those d reports as running, every
+// interval.
+func NewDriftDetector(d Deployer, r Registry, sr StateReader, rf *ResolveFilter, interval time.Duration, ls logging.LogSink) *DriftDetector {
+	return &DriftDetector{
+		Deployer:      d,
+		Registry:      r,
+		StateReader:   sr,
+		ResolveFilter: rf,
+		Interval:      interval,
+		ls:            ls,
+	}
+}
+
+// Run compares the running and intended deployments every Interval, until ctx
+// is done. Errors are logged, and leave the last report in place.
+func (dd *DriftDetector) Run(ctx context.Context) {
+	for {
+		if _, err := dd.Detect(); err != nil {
+			logging.ReportError(dd.ls, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(dd.Interval):
+		}
+	}
+}
+
+// Report returns the most recent DriftReport, or nil if none has been made.
+func (dd *DriftDetector) Report() *DriftReport {
+	dd.RLock()
+	defer dd.RUnlock()
+	return dd.report
+}
+
+// Detect compares the running and intended deployments once, and records and
+// returns the resulting report.
+func (dd *DriftDetector) Detect() (*DriftReport, error) {
+	state, err := dd.StateReader.ReadState()
+	if err != nil {
+		return nil, err
+	}
+	intended, err := state.Deployments()
+	if err != nil {
+		return nil, err
+	}
+	clusters := dd.FilteredClusters(state.Defs.Clusters)
+	actual, err := dd.Deployer.RunningDeployments(dd.Registry, clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	report := driftReport(
+		intended.Filter(dd.FilterDeployment),
+		actual.Filter(dd.FilterDeployStates),
+		clusters)
+	dd.Lock()
+	dd.report = report
+	dd.Unlock()
+	return report, nil
+}
+
+// driftReport reports how actual differs from intended, in clusters.
+func driftReport(intended Deployments, actual DeployStates, clusters Clusters) *DriftReport {
+	report := &DriftReport{
+		At:      time.Now(),
+		Drifted: []DeploymentDrift{},
+		Unknown: []DeploymentID{},
+		Missing: []DeploymentID{},
+	}
+	running := actual.Snapshot()
+	for id, want := range intended.Snapshot() {
+		if _, ok := clusters[id.Cluster]; !ok {
+			continue
+		}
+		have, ok := running[id]
+		if !ok {
+			// Deployments with no instances or no version are never created.
+			if want.NumInstances != 0 && want.SourceID.Version.String() != "0.0.0" {
+				report.Missing = append(report.Missing, id)
+			}
+			continue
+		}
+		// Status is left out: deployments that are still being rectified
+		// have not drifted.
+		diffs := FieldDiffs(&Deployable{Deployment: &have.Deployment}, &Deployable{Deployment: want})
+		if len(diffs) > 0 {
+			report.Drifted = append(report.Drifted, DeploymentDrift{DeploymentID: id, Diffs: diffs})
+		}
+	}
+	for id := range running {
+		if _, ok := intended.Get(id); !ok {
+			report.Unknown = append(report.Unknown, id)
+		}
+	}
+	report.sort()
+	return report
+}
+
+func (r *DriftReport) sort() {
+	sort.Slice(r.Drifted, func(i, j int) bool {
+		return r.Drifted[i].DeploymentID.String() < r.Drifted[j].DeploymentID.String()
+	})
+	sortIDs := func(ids []DeploymentID) {
+		sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	}
+	sortIDs(r.Unknown)
+	sortIDs(r.Missing)
+}
+
+// Filter returns a copy of r with only the deployments selected by rf.
+func (r *DriftReport) Filter(rf *ResolveFilter) *DriftReport {
+	match := func(id DeploymentID) bool {
+		return rf.FilterManifestID(id.ManifestID) && rf.FilterClusterName(id.Cluster)
+	}
+	filtered := &DriftReport{
+		At:      r.At,
+		Drifted: []DeploymentDrift{},
+		Unknown: []DeploymentID{},
+		Missing: []DeploymentID{},
+	}
+	for _, d := range r.Drifted {
+		if match(d.DeploymentID) {
+			filtered.Drifted = append(filtered.Drifted, d)
+		}
+	}
+	for _, id := range r.Unknown {
+		if match(id) {
+			filtered.Unknown = append(filtered.Unknown, id)
+		}
+	}
+	for _, id := range r.Missing {
+		if match(id) {
+			filtered.Missing = append(filtered.Missing, id)
+		}
+	}
+	return filtered
+}
+
+// Collect implements prometheus.Collector on DriftDetector, reporting the
+// number of drifted, unknown and missing deployments in each cluster, as of
+// the last report.
+func (dd *DriftDetector) Collect() prometheus.Family {
+	f := prometheus.Family{
+		Name: "sous_drift_deployments",
+		Help: "The number of deployments that differ from their intended state, by cluster and kind of drift.",
+		Type: "gauge",
+	}
+	report := dd.Report()
+	if report == nil {
+		return f
+	}
+	counts := map[[2]string]int{}
+	for _, d := range report.Drifted {
+		counts[[2]string{d.DeploymentID.Cluster, "drifted"}]++
+	}
+	for _, id := range report.Unknown {
+		counts[[2]string{id.Cluster, "unknown"}]++
+	}
+	for _, id := range report.Missing {
+		counts[[2]string{id.Cluster, "missing"}]++
+	}
+	keys := make([][2]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		f.Samples = append(f.Samples, prometheus.Sample{
+			Labels: []prometheus.Label{
+				{Name: "cluster", Value: k[0]},
+				{Name: "kind", Value: k[1]},
+			},
+			Value: float64(counts[k]),
+		})
+	}
+	return f
+}
@@ -0,0 +1,92 @@
+package sous
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nyarly/spies"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func driftFixture(t *testing.T) (*DummyStateManager, DeployStates, map[string]DeploymentID) {
+	sm := NewDummyStateManager()
+	sm.State = DefaultStateFixture()
+	intended, err := sm.State.Deployments()
+	require.NoError(t, err)
+
+	ids := map[string]DeploymentID{}
+	actual := NewDeployStates()
+	for id, d := range intended.Snapshot() {
+		ds := &DeployState{Deployment: *d.Clone(), Status: DeployStatusActive}
+		switch {
+		case id.Cluster == "cluster0" && id.ManifestID.Source.Repo == "github.com/user0/repo0":
+			ds.NumInstances = 5
+			ds.Status = DeployStatusPending
+			ids["drifted"] = id
+		case id.Cluster == "cluster1" && id.ManifestID.Source.Repo == "github.com/user1/repo1":
+			ids["missing"] = id
+			continue
+		}
+		actual.Add(ds)
+	}
+	unknown := *intended.Snapshot()[ids["missing"]].Clone()
+	unknown.Flavor = "unknown"
+	actual.Add(&DeployState{Deployment: unknown, Status: DeployStatusActive})
+	ids["unknown"] = unknown.ID()
+	return sm, actual, ids
+}
+
+func TestDriftDetector_Detect(t *testing.T) {
+	sm, actual, ids := driftFixture(t)
+	deployer, ctrl := NewDeployerSpy()
+	ctrl.MatchMethod("RunningDeployments", spies.AnyArgs, actual, nil)
+	dd := NewDriftDetector(deployer, NewDummyRegistry(), sm, &ResolveFilter{}, time.Minute, logging.SilentLogSet())
+
+	assert.Nil(t, dd.Report())
+	report, err := dd.Detect()
+	require.NoError(t, err)
+	assert.Equal(t, report, dd.Report())
+
+	assert.Equal(t, []DeploymentDrift{{
+		DeploymentID: ids["drifted"],
+		Diffs:        []FieldDiff{{Field: "NumInstances", Prior: "5", Post: "3"}},
+	}}, report.Drifted)
+	assert.Equal(t, []DeploymentID{ids["unknown"]}, report.Unknown)
+	assert.Equal(t, []DeploymentID{ids["missing"]}, report.Missing)
+
+	family := dd.Collect()
+	assert.Equal(t, "sous_drift_deployments", family.Name)
+	require.Len(t, family.Samples, 3)
+	assert.Equal(t, "cluster0", family.Samples[0].Labels[0].Value)
+	assert.Equal(t, "drifted", family.Samples[0].Labels[1].Value)
+	assert.Equal(t, float64(1), family.Samples[0].Value)
+
+	cluster1 := NewResolveFieldMatcher("cluster1")
+	filtered := report.Filter(&ResolveFilter{Cluster: cluster1})
+	assert.Empty(t, filtered.Drifted)
+	assert.Len(t, filtered.Unknown, 1)
+	assert.Len(t, filtered.Missing, 1)
+}
+
+func TestDriftDetector_Run(t *testing.T) {
+	sm, actual, _ := driftFixture(t)
+	deployer, ctrl := NewDeployerSpy()
+	ctrl.MatchMethod("RunningDeployments", spies.AnyArgs, actual, nil)
+	dd := NewDriftDetector(deployer, NewDummyRegistry(), sm, &ResolveFilter{}, time.Millisecond, logging.SilentLogSet())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dd.Run(ctx)
+		close(done)
+	}()
+	for dd.Report() == nil || len(ctrl.CallsTo("RunningDeployments")) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	assert.Len(t, dd.Report().Drifted, 1)
+}
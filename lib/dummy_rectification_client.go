@@ -13,6 +13,15 @@ type (
 		Created  []Deployable
 		Deployed []Deployable
 		Deleted  []dummyDelete
+		// Advanced records the instance counts passed to AdvanceDeploy.
+		Advanced []int
+		// Cancelled records the deploy IDs passed to CancelDeploy.
+		Cancelled []string
+		// Paused, Unpaused and Bounced record the request IDs passed to
+		// PauseRequest, UnpauseRequest and BounceRequest.
+		Paused, Unpaused, Bounced []string
+		// Ran records the options passed to RunRequest.
+		Ran []JobRunOptions
 	}
 
 	dummyDelete struct {
@@ -58,3 +67,52 @@ func (drc *DummyRectificationClient) DeleteRequest(
 	drc.Deleted = append(drc.Deleted, dummyDelete{cluster, reqid, message})
 	return nil
 }
+
+// DeployStaged implements part of the RectificationClient interface
+func (drc *DummyRectificationClient) DeployStaged(d Deployable, reqID, depID string, stepInstances int) error {
+	drc.logf("Deploying instance %#v in steps of %d", d, stepInstances)
+	drc.Deployed = append(drc.Deployed, d)
+	return nil
+}
+
+// AdvanceDeploy implements part of the RectificationClient interface
+func (drc *DummyRectificationClient) AdvanceDeploy(cluster, reqID, depID string, instances int) error {
+	drc.logf("Advancing deploy %s %s %s to %d", cluster, reqID, depID, instances)
+	drc.Advanced = append(drc.Advanced, instances)
+	return nil
+}
+
+// CancelDeploy implements part of the RectificationClient interface
+func (drc *DummyRectificationClient) CancelDeploy(cluster, reqID, depID string) error {
+	drc.logf("Cancelling deploy %s %s %s", cluster, reqID, depID)
+	drc.Cancelled = append(drc.Cancelled, depID)
+	return nil
+}
+
+// PauseRequest implements part of the RectificationClient interface
+func (drc *DummyRectificationClient) PauseRequest(cluster, reqID, message string) error {
+	drc.logf("Pausing application %s %s %s", cluster, reqID, message)
+	drc.Paused = append(drc.Paused, reqID)
+	return nil
+}
+
+// UnpauseRequest implements part of the RectificationClient interface
+func (drc *DummyRectificationClient) UnpauseRequest(cluster, reqID, message string) error {
+	drc.logf("Unpausing application %s %s %s", cluster, reqID, message)
+	drc.Unpaused = append(drc.Unpaused, reqID)
+	return nil
+}
+
+// BounceRequest implements part of the RectificationClient interface
+func (drc *DummyRectificationClient) BounceRequest(cluster, reqID, message string) error {
+	drc.logf("Bouncing application %s %s %s", cluster, reqID, message)
+	drc.Bounced = append(drc.Bounced, reqID)
+	return nil
+}
+
+// RunRequest implements part of the RectificationClient interface
+func (drc *DummyRectificationClient) RunRequest(cluster, reqID string, opts JobRunOptions, message string) error {
+	drc.logf("Running application %s %s %s", cluster, reqID, message)
+	drc.Ran = append(drc.Ran, opts)
+	return nil
+}
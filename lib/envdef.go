@@ -0,0 +1,229 @@
+package sous
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The types of environment variables defined by EnvDefs. The empty VarType
+// is the same as VarTypeString.
+const (
+	VarTypeString     = VarType("string")
+	VarTypeInt        = VarType("int")
+	VarTypeBool       = VarType("bool")
+	VarTypeURL        = VarType("url")
+	VarTypeDuration   = VarType("duration")
+	VarTypeMemorySize = VarType("memory_size")
+	VarTypeEnum       = VarType("enum")
+)
+
+// The scopes of environment variables defined by EnvDefs, which determine
+// where they may be set. The empty scope allows them to be set anywhere.
+const (
+	// ScopeCluster variables may only be set in Cluster.Env.
+	ScopeCluster = "cluster"
+	// ScopeDeployment variables may only be set in the Env of deployments
+	// in manifests.
+	ScopeDeployment = "deployment"
+)
+
+var memorySizeRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?\s*([KMGTkmgt]i?[Bb]?|[Bb])?$`)
+
+// Validate returns an error if vt is not a known VarType.
+func (vt VarType) Validate() error {
+	switch vt {
+	case "", VarTypeString, VarTypeInt, VarTypeBool, VarTypeURL, VarTypeDuration, VarTypeMemorySize, VarTypeEnum:
+		return nil
+	}
+	return errors.Errorf("unknown type %q", vt)
+}
+
+// Check returns an error if value is not of vt. Values of VarTypeEnum must
+// be one of values.
+func (vt VarType) Check(value string, values []string) error {
+	var err error
+	switch vt {
+	case VarTypeInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case VarTypeBool:
+		_, err = strconv.ParseBool(value)
+	case VarTypeURL:
+		var u *url.URL
+		if u, err = url.Parse(value); err == nil && (u.Scheme == "" || u.Host == "") {
+			err = errors.New("missing scheme or host")
+		}
+	case VarTypeDuration:
+		_, err = time.ParseDuration(value)
+	case VarTypeMemorySize:
+		if !memorySizeRE.MatchString(value) {
+			err = errors.New(`want a size like "512", "512M" or "2GiB"`)
+		}
+	case VarTypeEnum:
+		for _, v := range values {
+			if v == value {
+				return nil
+			}
+		}
+		err = errors.Errorf("want one of %q", values)
+	}
+	if err != nil {
+		return errors.Errorf("%q is not a valid %s: %s", value, vt, unwrapStrconv(err))
+	}
+	return nil
+}
+
+// unwrapStrconv drops the repetition of the function and value from the
+// errors returned by strconv.
+func unwrapStrconv(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
+
+// Get returns the definition of the variable called name, and true, or false
+// if it is not defined.
+func (evs EnvDefs) Get(name string) (EnvDef, bool) {
+	for _, ed := range evs {
+		if ed.Name == name {
+			return ed, true
+		}
+	}
+	return EnvDef{}, false
+}
+
+// Validate returns flaws for definitions of unknown types or scopes, and for
+// enums without values.
+func (ed EnvDef) Validate() []Flaw {
+	var flaws []Flaw
+	if err := ed.Type.Validate(); err != nil {
+		flaws = append(flaws, FatalFlaw("env var %s: %s", ed.Name, err))
+	}
+	if ed.Type == VarTypeEnum && len(ed.Values) == 0 {
+		flaws = append(flaws, FatalFlaw("env var %s: enum has no Values", ed.Name))
+	}
+	switch ed.Scope {
+	case "", ScopeCluster, ScopeDeployment:
+	default:
+		flaws = append(flaws, FatalFlaw("env var %s: unknown scope %q", ed.Name, ed.Scope))
+	}
+	return flaws
+}
+
+// Check returns an error if value is not of the type of ed. References to
+// secrets are not checked, since their values are not known until they are
+// deployed.
+func (ed EnvDef) Check(value string) error {
+	if IsSecretRef(value) {
+		return nil
+	}
+	if err := ed.Type.Check(value, ed.Values); err != nil {
+		return errors.Wrapf(err, "env var %s", ed.Name)
+	}
+	return nil
+}
+
+// checkEnv returns a flaw for each variable in env with a definition whose
+// value is of the wrong type, or which may not be set in scope.
+func (evs EnvDefs) checkEnv(env map[string]string, where, scope string) []Flaw {
+	var flaws []Flaw
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ed, defined := evs.Get(name)
+		if !defined {
+			continue
+		}
+		if ed.Scope != "" && ed.Scope != scope {
+			flaws = append(flaws, FatalFlaw("%s: env var %s has %s scope, so cannot be set here", where, name, ed.Scope))
+		}
+		if err := ed.Check(env[name]); err != nil {
+			flaws = append(flaws, FatalFlaw("%s: %s", where, err))
+		}
+	}
+	return flaws
+}
+
+// validateEnvDefs returns flaws for each invalid EnvDef, and for each
+// cluster whose Env does not match them.
+func (ds *Defs) validateEnvDefs() []Flaw {
+	var flaws []Flaw
+	for _, ed := range ds.EnvVars {
+		flaws = append(flaws, ed.Validate()...)
+	}
+	for _, name := range ds.Clusters.Names() {
+		c := ds.Clusters[name]
+		if c == nil {
+			continue
+		}
+		env := make(map[string]string, len(c.Env))
+		for n, v := range c.Env {
+			env[n] = string(v)
+		}
+		flaws = append(flaws, ds.EnvVars.checkEnv(env, "cluster "+name, ScopeCluster)...)
+	}
+	return flaws
+}
+
+// ValidateEnv returns flaws for the Env of m's defaults and of each of its
+// deployments: variables with values of the wrong type, set outside of their
+// scope, or required but not set by the deployment, the defaults, or the
+// cluster.
+func (ds *Defs) ValidateEnv(m *Manifest) []Flaw {
+	flaws := ds.EnvVars.checkEnv(m.Defaults.Env, "defaults of "+m.ID().String(), ScopeDeployment)
+	for _, cn := range m.Deployments.ClusterNames() {
+		spec := m.Deployments[cn]
+		where := "deployment " + m.ID().String() + " in " + cn
+		flaws = append(flaws, ds.EnvVars.checkEnv(spec.Env, where, ScopeDeployment)...)
+
+		var clusterEnv EnvDefaults
+		if c, ok := ds.Clusters[cn]; ok && c != nil {
+			clusterEnv = c.Env
+		}
+		var missing []string
+		for _, ed := range ds.EnvVars {
+			if !ed.Required {
+				continue
+			}
+			if _, set := spec.Env[ed.Name]; set {
+				continue
+			}
+			if _, set := m.Defaults.Env[ed.Name]; set {
+				continue
+			}
+			if _, set := clusterEnv[ed.Name]; set {
+				continue
+			}
+			missing = append(missing, ed.Name)
+		}
+		if len(missing) > 0 {
+			flaws = append(flaws, FatalFlaw("%s: required env vars not set: %s", where, strings.Join(missing, ", ")))
+		}
+	}
+	for _, f := range flaws {
+		f.AddContext("manifest", m)
+	}
+	return flaws
+}
+
+// ValidateJobEnv returns flaws for the Env a run of the job did is started
+// with: variables with values of the wrong type or set outside of their
+// scope, as ValidateEnv reports for manifests, and malformed references to
+// secrets.
+func (ds *Defs) ValidateJobEnv(did DeploymentID, env Env) []Flaw {
+	flaws := ds.EnvVars.checkEnv(env, "run of "+did.String(), ScopeDeployment)
+	flaws = append(flaws, env.validateSecretRefs()...)
+	for _, f := range flaws {
+		f.AddContext("deployment", did)
+	}
+	return flaws
+}
@@ -0,0 +1,141 @@
+package sous
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVarType_Check(t *testing.T) {
+	enum := []string{"debug", "info"}
+	good := map[VarType][]string{
+		"":                {"anything"},
+		VarTypeString:     {""},
+		VarTypeInt:        {"0", "-12", "8080"},
+		VarTypeBool:       {"true", "false", "1", "0"},
+		VarTypeURL:        {"http://example.com", "postgres://db:5432/x"},
+		VarTypeDuration:   {"1s", "1h30m"},
+		VarTypeMemorySize: {"512", "512M", "2GiB", "1.5G", "100kb"},
+		VarTypeEnum:       {"debug", "info"},
+	}
+	bad := map[VarType][]string{
+		VarTypeInt:        {"", "1.5", "eight"},
+		VarTypeBool:       {"yes", ""},
+		VarTypeURL:        {"example.com", "/path"},
+		VarTypeDuration:   {"5", "soon"},
+		VarTypeMemorySize: {"big", "512X", "-1"},
+		VarTypeEnum:       {"warn", ""},
+	}
+	for vt, values := range good {
+		for _, v := range values {
+			if err := vt.Check(v, enum); err != nil {
+				t.Errorf("%s.Check(%q): %s", vt, v, err)
+			}
+		}
+	}
+	for vt, values := range bad {
+		for _, v := range values {
+			if err := vt.Check(v, enum); err == nil {
+				t.Errorf("%s.Check(%q) did not return an error", vt, v)
+			}
+		}
+	}
+}
+
+func TestDefs_Validate_envDefs(t *testing.T) {
+	defs := Defs{
+		EnvVars: EnvDefs{
+			{Name: "A", Type: "float"},
+			{Name: "B", Type: VarTypeEnum},
+			{Name: "C", Scope: "global"},
+			{Name: "REGION", Scope: ScopeCluster},
+			{Name: "PORT", Type: VarTypeInt, Scope: ScopeDeployment},
+		},
+		Clusters: Clusters{
+			"one": {Name: "one", Kind: "singularity", Env: EnvDefaults{"REGION": "us", "PORT": "80"}},
+		},
+	}
+	flaws := defs.Validate()
+	if len(flaws) != 4 {
+		t.Errorf("got %d flaws, want 4: %v", len(flaws), flaws)
+	}
+}
+
+func TestDefs_ValidateEnv(t *testing.T) {
+	defs := Defs{
+		EnvVars: EnvDefs{
+			{Name: "REGION", Scope: ScopeCluster},
+			{Name: "WORKERS", Type: VarTypeInt},
+			{Name: "LEVEL", Type: VarTypeEnum, Values: []string{"debug", "info"}},
+			{Name: "OWNER", Required: true},
+			{Name: "DSN", Type: VarTypeURL, Required: true},
+		},
+		Clusters: Clusters{
+			"one": {Name: "one", Env: EnvDefaults{"REGION": "us", "OWNER": "team"}},
+			"two": {Name: "two"},
+		},
+	}
+	m := &Manifest{
+		Source: SourceLocation{Repo: "github.com/example/app"},
+		Deployments: DeploySpecs{
+			"one": {DeployConfig: DeployConfig{Env: Env{
+				"WORKERS": "4",
+				"LEVEL":   "info",
+				"DSN":     "secret://db#dsn",
+				"OTHER":   "not defined",
+			}}},
+		},
+	}
+	if flaws := defs.ValidateEnv(m); len(flaws) != 0 {
+		t.Fatalf("got flaws for a valid manifest: %v", flaws)
+	}
+
+	m.Deployments["two"] = DeploySpec{DeployConfig: DeployConfig{Env: Env{
+		"REGION":  "eu",
+		"WORKERS": "lots",
+		"LEVEL":   "trace",
+		"DSN":     "http://db",
+	}}}
+	flaws := defs.ValidateEnv(m)
+	var descs []string
+	for _, f := range flaws {
+		descs = append(descs, f.(GenericFlaw).Desc)
+	}
+	all := strings.Join(descs, "\n")
+	for _, want := range []string{"REGION has cluster scope", "\"lots\" is not a valid int", "\"trace\" is not a valid enum", "required env vars not set: OWNER"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("flaws do not mention %q:\n%s", want, all)
+		}
+	}
+	if len(flaws) != 4 {
+		t.Errorf("got %d flaws, want 4:\n%s", len(flaws), all)
+	}
+}
+
+func TestDefs_ValidateJobEnv(t *testing.T) {
+	defs := Defs{
+		EnvVars: EnvDefs{
+			{Name: "REGION", Scope: ScopeCluster},
+			{Name: "WORKERS", Type: VarTypeInt},
+			{Name: "OWNER", Required: true},
+		},
+	}
+	did := DeploymentID{ManifestID: ManifestID{Source: SourceLocation{Repo: "github.com/example/app"}}, Cluster: "one"}
+	if flaws := defs.ValidateJobEnv(did, Env{"WORKERS": "4", "DSN": "secret://db#dsn"}); len(flaws) != 0 {
+		t.Fatalf("got flaws for a valid env: %v", flaws)
+	}
+
+	flaws := defs.ValidateJobEnv(did, Env{"REGION": "eu", "WORKERS": "lots", "DSN": "secret://"})
+	var descs []string
+	for _, f := range flaws {
+		descs = append(descs, f.(GenericFlaw).Desc)
+	}
+	all := strings.Join(descs, "\n")
+	for _, want := range []string{"REGION has cluster scope", "\"lots\" is not a valid int", "env DSN"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("flaws do not mention %q:\n%s", want, all)
+		}
+	}
+	if len(flaws) != 3 {
+		t.Errorf("got %d flaws, want 3:\n%s", len(flaws), all)
+	}
+}
@@ -0,0 +1,85 @@
+package sous
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A JobRunner is a Deployer that can run jobs on demand, and report
+	// their past runs.
+	JobRunner interface {
+		// RunJob starts a run of the job deployed as d, on behalf of user.
+		RunJob(d *Deployment, opts JobRunOptions, user User) error
+		// JobRuns returns the count most recent runs of the job deployed as d,
+		// most recently started first.
+		JobRuns(d *Deployment, count int) ([]JobRun, error)
+	}
+
+	// JobRunOptions describe a run of a job.
+	JobRunOptions struct {
+		// RunID identifies the run, so that it can be found in the runs of the
+		// job.
+		RunID string
+		// Args are appended to the command line of the job.
+		Args []string `json:",omitempty"`
+		// Env is added to the environment of the job, overriding the Env of
+		// the deployment.
+		Env map[string]string `json:",omitempty"`
+	}
+
+	// A JobRun is a past or current run of a job.
+	JobRun struct {
+		TaskID string
+		// RunID is the RunID of the JobRunOptions the job was run with, if it
+		// was run on demand.
+		RunID string `json:",omitempty"`
+		// State is the last state of the run reported by the scheduler.
+		State     string
+		StartedAt time.Time
+		// EndedAt is zero if the run has not ended.
+		EndedAt time.Time
+		// ExitCode is nil if the run has not ended, or its exit code is not
+		// known.
+		ExitCode *int `json:",omitempty"`
+	}
+)
+
+// RunJob implements JobRunner on DeployerRegistry, using the backend for the
+// Kind of the cluster of d.
+func (dr *DeployerRegistry) RunJob(d *Deployment, opts JobRunOptions, user User) error {
+	jr, err := dr.jobRunner(d)
+	if err != nil {
+		return err
+	}
+	return jr.RunJob(d, opts, user)
+}
+
+// JobRuns implements JobRunner on DeployerRegistry, using the backend for the
+// Kind of the cluster of d.
+func (dr *DeployerRegistry) JobRuns(d *Deployment, count int) ([]JobRun, error) {
+	jr, err := dr.jobRunner(d)
+	if err != nil {
+		return nil, err
+	}
+	return jr.JobRuns(d, count)
+}
+
+func (dr *DeployerRegistry) jobRunner(d *Deployment) (JobRunner, error) {
+	if !d.Kind.IsJob() {
+		return nil, errors.Errorf("%q is a %s, not a job", d.ID(), d.Kind)
+	}
+	if d.Cluster == nil {
+		return nil, errors.Errorf("cannot determine cluster kind of %q", d.ID())
+	}
+	backend, err := dr.backend(d.Cluster.Kind)
+	if err != nil {
+		return nil, err
+	}
+	jr, ok := backend.(JobRunner)
+	if !ok {
+		return nil, errors.Errorf("jobs on %s clusters cannot be run by Sous", kindOrDefault(d.Cluster.Kind))
+	}
+	return jr, nil
+}
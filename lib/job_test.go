@@ -0,0 +1,49 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jobDeployerSpy struct {
+	Deployer
+	ran []JobRunOptions
+}
+
+func (j *jobDeployerSpy) RunJob(d *Deployment, opts JobRunOptions, user User) error {
+	j.ran = append(j.ran, opts)
+	return nil
+}
+
+func (j *jobDeployerSpy) JobRuns(d *Deployment, count int) ([]JobRun, error) {
+	return make([]JobRun, count), nil
+}
+
+func TestDeployerRegistry_RunJob(t *testing.T) {
+	sing, _ := NewDeployerSpy()
+	kube, _ := NewDeployerSpy()
+	jobs := &jobDeployerSpy{Deployer: sing}
+
+	ls, _ := logging.NewLogSinkSpy()
+	dr := NewDeployerRegistry(ls)
+	dr.Register("singularity", jobs)
+	dr.Register("kubernetes", kube)
+
+	deployment := func(kind ManifestKind, clusterKind string) *Deployment {
+		return &Deployment{Kind: kind, Cluster: &Cluster{Kind: clusterKind}}
+	}
+
+	require.NoError(t, dr.RunJob(deployment(ManifestKindOnDemand, ""), JobRunOptions{RunID: "run-1"}, User{}))
+	assert.Equal(t, []JobRunOptions{{RunID: "run-1"}}, jobs.ran)
+	runs, err := dr.JobRuns(deployment(ManifestKindScheduled, "singularity"), 2)
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+
+	assert.Error(t, dr.RunJob(deployment(ManifestKindService, ""), JobRunOptions{}, User{}))
+	_, err = dr.JobRuns(deployment(ManifestKindOnce, "kubernetes"), 1)
+	assert.Error(t, err)
+	assert.Len(t, jobs.ran, 1)
+}
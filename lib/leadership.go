@@ -0,0 +1,121 @@
+package sous
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A LeaderElector elects, for each cluster, a single server to run the
+	// AutoResolver and rectifications for deployments to that cluster.
+	LeaderElector interface {
+		// Campaign tries to make this server the leader for cluster. It
+		// returns true if this server is the leader, whether it has just
+		// been elected or was already.
+		Campaign(cluster string) (bool, error)
+		// Leaders returns the current leader of each cluster that has one.
+		Leaders() ([]Leader, error)
+		// Resign gives up leadership of every cluster this server leads.
+		Resign()
+	}
+
+	// A Leader records which server leads a cluster.
+	Leader struct {
+		ClusterName string
+		// Server identifies the leading server, by its URL if it has one.
+		Server string
+		// Since is when the server became the leader.
+		Since time.Time
+	}
+
+	// Leadership tracks which clusters this server leads. A nil *Leadership
+	// leads every cluster, so that servers without an elector behave as they
+	// always have.
+	Leadership struct {
+		// Server identifies this server, as recorded in its Leaders.
+		Server  string
+		elector LeaderElector
+		log     logging.LogSink
+	}
+
+	// NotLeaderError is returned when a server is asked to rectify a
+	// deployment to a cluster it does not lead.
+	NotLeaderError struct {
+		Cluster string
+	}
+)
+
+// NewLeadership creates a Leadership for the server identified by server,
+// which campaigns for leadership using e.
+func NewLeadership(server string, e LeaderElector, ls logging.LogSink) *Leadership {
+	return &Leadership{Server: server, elector: e, log: ls}
+}
+
+func (e *NotLeaderError) Error() string {
+	return fmt.Sprintf("this server is not the leader for cluster %q", e.Cluster)
+}
+
+// Leads returns true if this server leads cluster, campaigning for it if it
+// does not yet. Errors campaigning are reported, and count as not leading.
+func (l *Leadership) Leads(cluster string) bool {
+	if l == nil {
+		return true
+	}
+	leads, err := l.elector.Campaign(cluster)
+	if err != nil {
+		logging.ReportError(l.log, errors.Wrapf(err, "campaigning for leadership of %q", cluster))
+		return false
+	}
+	return leads
+}
+
+// Led returns the clusters in cs that this server leads, campaigning for any
+// that it does not yet.
+func (l *Leadership) Led(cs Clusters) Clusters {
+	if l == nil {
+		return cs
+	}
+	led := Clusters{}
+	for name, c := range cs {
+		if l.Leads(name) {
+			led[name] = c
+		}
+	}
+	return led
+}
+
+// Leaders returns the current leader of each cluster, or nil if there is no
+// elector.
+func (l *Leadership) Leaders() ([]Leader, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return l.elector.Leaders()
+}
+
+// Resign gives up leadership of every cluster this server leads, so that
+// other servers can take over without waiting for this one to disappear.
+func (l *Leadership) Resign() {
+	if l == nil {
+		return
+	}
+	l.elector.Resign()
+}
+
+// Guard returns the resolution of a rectification of pair that was refused
+// because this server does not lead its cluster, and true; or false if this
+// server does lead it.
+func (l *Leadership) Guard(pair *DeployablePair) (DiffResolution, bool) {
+	cluster := pair.ID().Cluster
+	if l.Leads(cluster) {
+		return DiffResolution{}, false
+	}
+	return DiffResolution{
+		DeploymentID: pair.ID(),
+		Desc:         pair.Kind().ExpectedResolutionType(),
+		Error:        WrapResolveError(&NotLeaderError{Cluster: cluster}),
+	}, true
+}
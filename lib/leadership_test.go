@@ -0,0 +1,82 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeElector struct {
+	leads map[string]bool
+	err   error
+}
+
+func (e *fakeElector) Campaign(cluster string) (bool, error) {
+	return e.leads[cluster], e.err
+}
+
+func (e *fakeElector) Leaders() ([]Leader, error) {
+	leaders := []Leader{}
+	for c, leads := range e.leads {
+		if leads {
+			leaders = append(leaders, Leader{ClusterName: c, Server: "self"})
+		}
+	}
+	return leaders, e.err
+}
+
+func (e *fakeElector) Resign() {
+	e.leads = nil
+}
+
+func TestLeadership_nil(t *testing.T) {
+	var l *Leadership
+	assert.True(t, l.Leads("cluster-1"))
+	cs := Clusters{"cluster-1": {}, "cluster-2": {}}
+	assert.Equal(t, cs, l.Led(cs))
+	leaders, err := l.Leaders()
+	assert.NoError(t, err)
+	assert.Nil(t, leaders)
+}
+
+func TestLeadership_Led(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	l := NewLeadership("self", &fakeElector{leads: map[string]bool{"cluster-1": true}}, ls)
+
+	led := l.Led(Clusters{"cluster-1": {}, "cluster-2": {}})
+
+	assert.Len(t, led, 1)
+	assert.Contains(t, led, "cluster-1")
+}
+
+func TestLeadership_Leads_error(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	l := NewLeadership("self", &fakeElector{
+		leads: map[string]bool{"cluster-1": true},
+		err:   errors.New("no database"),
+	}, ls)
+
+	assert.False(t, l.Leads("cluster-1"))
+}
+
+func TestLeadership_Guard(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	l := NewLeadership("self", &fakeElector{leads: map[string]bool{"cluster-1": true}}, ls)
+	pair := func(cluster string) *DeployablePair {
+		p := &DeployablePair{Post: &Deployable{Deployment: &Deployment{ClusterName: cluster}}}
+		p.SetID(p.Post.ID())
+		return p
+	}
+
+	_, refused := l.Guard(pair("cluster-1"))
+	assert.False(t, refused)
+
+	rez, refused := l.Guard(pair("cluster-2"))
+	assert.True(t, refused)
+	assert.Equal(t, CreateDiff, rez.Desc)
+	if assert.NotNil(t, rez.Error) {
+		assert.Contains(t, rez.Error.Error(), `not the leader for cluster "cluster-2"`)
+	}
+}
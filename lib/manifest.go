@@ -29,8 +29,21 @@ type (
 		Owners []string
 		// Kind is the kind of software that SourceRepo represents.
 		Kind ManifestKind `validate:"nonzero"`
+		// Defaults is the configuration inherited by each of Deployments. A
+		// DeploySpec overrides it field by field: each Resources, Env and
+		// Metadata entry, and each Startup field, it sets is overridden, and
+		// it is inherited wherever the DeploySpec leaves a field zero. As with
+		// the Startup of a Cluster, a DeploySpec cannot override a Startup
+		// field with its zero value: SkipCheck true, or a non-zero Timeout,
+		// in Defaults applies to every deployment, so set such fields in each
+		// DeploySpec that needs them instead.
+		Defaults DeployConfig `yaml:",omitempty"`
 		// Deployments is a map of cluster names to DeploymentSpecs
 		Deployments DeploySpecs `validate:"keys=nonempty,values=nonzero"`
+		// AutoRollback, if true, makes Sous set the version of a deployment
+		// back to the last version that became active when a deploy of a new
+		// version fails.
+		AutoRollback bool `yaml:",omitempty"`
 	}
 )
 
@@ -59,9 +72,20 @@ func (m Manifest) Clone() (c *Manifest) {
 	}
 	c.Owners = owners
 	c.Deployments = deployments
+	// Manifests without Defaults are left with a zero DeployConfig, rather
+	// than one with empty maps.
+	if different, _ := m.Defaults.Diff(DeployConfig{}); different {
+		c.Defaults = m.Defaults.Clone()
+	}
 	return
 }
 
+// WithDefaults returns spec with the configuration it inherits from
+// m.Defaults filled in. Cluster defaults are not applied.
+func (m *Manifest) WithDefaults(spec DeploySpec) DeploySpec {
+	return flattenDeploySpecs([]DeploySpec{spec, {DeployConfig: m.Defaults}})
+}
+
 // FileLocation returns the path that the manifest should be saved to.
 func (m *Manifest) FileLocation() string {
 	return filepath.Join(string(m.Source.Repo), string(m.Source.Dir))
@@ -82,6 +106,9 @@ func (m *Manifest) Diff(o *Manifest) (bool, []string) {
 	if m.Kind != o.Kind {
 		diff("kind; this: %q; other: %q", m.Kind, o.Kind)
 	}
+	if m.AutoRollback != o.AutoRollback {
+		diff("auto rollback; this: %t; other: %t", m.AutoRollback, o.AutoRollback)
+	}
 	if len(m.Owners) != len(o.Owners) {
 		diff("number of owners; this: %d; other: %d", len(m.Owners), len(o.Owners))
 	} else {
@@ -90,6 +117,11 @@ func (m *Manifest) Diff(o *Manifest) (bool, []string) {
 		_, ods := here.Diff(there)
 		diffs = append(diffs, ods...)
 	}
+	if _, defaultsDiffs := m.Defaults.Diff(o.Defaults); len(defaultsDiffs) != 0 {
+		for _, d := range defaultsDiffs {
+			diff("defaults: %s", d)
+		}
+	}
 	if len(m.Deployments) != len(o.Deployments) {
 		diff("number of deployments; this: %d; other: %d", len(m.Deployments), len(o.Deployments))
 	} else {
@@ -132,6 +164,13 @@ func (m *Manifest) Validate() []Flaw {
 		flaws = append(flaws, m.Kind.Validate()...)
 	}
 
+	if m.Kind != "" && !m.Kind.IsScheduled() && m.hasSchedule() {
+		flaws = append(flaws, NewFlaw(
+			fmt.Sprintf("manifest %q of kind %q must not have a Schedule", m.ID(), m.Kind),
+			func() error { m.clearSchedules(); return nil },
+		))
+	}
+
 	/*
 		Cannot validate Deployments without defs...
 		In other words, we need (part of) the State context to do that.
@@ -147,6 +186,29 @@ func (m *Manifest) Validate() []Flaw {
 	return flaws
 }
 
+// hasSchedule returns true if m's Defaults or any of its Deployments has a
+// Schedule.
+func (m *Manifest) hasSchedule() bool {
+	if m.Defaults.Schedule != "" {
+		return true
+	}
+	for _, spec := range m.Deployments {
+		if spec.Schedule != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// clearSchedules removes the Schedule from m's Defaults and Deployments.
+func (m *Manifest) clearSchedules() {
+	m.Defaults.Schedule = ""
+	for cn, spec := range m.Deployments {
+		spec.Schedule = ""
+		m.Deployments[cn] = spec
+	}
+}
+
 // Repair implements Flawed for State
 func (m *Manifest) Repair(fs []Flaw) error {
 	return errors.Errorf("Can't do nuffin with flaws yet")
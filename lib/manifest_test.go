@@ -130,6 +130,29 @@ var manifestTests = []struct {
 			},
 		},
 	},
+	{
+		TestName: "schedule on a service",
+		OriginalManifest: &Manifest{
+			Source:   SourceLocation{Repo: "github.com/example/app"},
+			Kind:     ManifestKindService,
+			Defaults: DeployConfig{Schedule: "0 * * * *"},
+			Deployments: DeploySpecs{
+				"some-cluster": DeploySpec{
+					DeployConfig: DeployConfig{Schedule: "0 3 * * *", NumInstances: 3},
+				},
+			},
+		},
+		FixedManifest: &Manifest{
+			Source: SourceLocation{Repo: "github.com/example/app"},
+			Kind:   ManifestKindService,
+			Deployments: DeploySpecs{
+				"some-cluster": DeploySpec{
+					DeployConfig: DeployConfig{NumInstances: 3},
+				},
+			},
+		},
+		FlawDesc: `manifest "github.com/example/app" of kind "http-service" must not have a Schedule`,
+	},
 	{
 		// NOTE: This one is valid, hence no FlawDesc.
 		TestName: "valid",
@@ -43,3 +43,20 @@ func (mk ManifestKind) Validate() []Flaw {
 		return nil
 	}
 }
+
+// IsJob returns true if deployments of mk run to completion, rather than
+// running continuously. Jobs can be run on demand.
+func (mk ManifestKind) IsJob() bool {
+	switch mk {
+	default:
+		return false
+	case ManifestKindOnDemand, ManifestKindScheduled, ManifestKindOnce, ScheduledJob:
+		return true
+	}
+}
+
+// IsScheduled returns true if deployments of mk are run by their scheduler
+// according to their Schedule.
+func (mk ManifestKind) IsScheduled() bool {
+	return mk == ManifestKindScheduled || mk == ScheduledJob
+}
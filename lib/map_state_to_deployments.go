@@ -65,6 +65,9 @@ func (ds Deployments) PutbackManifests(defs Defs, olds Manifests, log logging.Lo
 			m = &Manifest{Deployments: DeploySpecs{}}
 			m.Owners = d.Owners.Slice()
 			m.SetID(mid)
+			if was {
+				m.Defaults = old.Defaults.Clone()
+			}
 		}
 		spec := DeploySpec{
 			Version:      d.SourceID.Version,
@@ -79,6 +82,11 @@ func (ds Deployments) PutbackManifests(defs Defs, olds Manifests, log logging.Lo
 			if !ok {
 				continue
 			}
+			// Variables with manifest defaults are inherited from those
+			// rather than the cluster.
+			if _, inherited := m.Defaults.Env[k]; inherited {
+				continue
+			}
 			if string(clusterVal) == v {
 				messages.ReportLogFieldsMessage("Redundant environment definition", logging.DebugLevel, log, k, v)
 				if was && hadSpec {
@@ -94,8 +102,10 @@ func (ds Deployments) PutbackManifests(defs Defs, olds Manifests, log logging.Lo
 				}
 			}
 		}
+		spec.DeployConfig = spec.DeployConfig.unmergeDefaults(m.Defaults, oldSpec.DeployConfig)
 		m.Deployments[d.ClusterName] = spec
 		m.Kind = d.Kind
+		m.AutoRollback = d.AutoRollback
 
 		ms.Set(mid, m)
 	}
@@ -151,6 +161,7 @@ func (ds Deployments) RawManifests(defs Defs, log logging.LogSink) (Manifests, e
 		}
 		m.Deployments[d.ClusterName] = spec
 		m.Kind = d.Kind
+		m.AutoRollback = d.AutoRollback
 
 		ms.Set(mid, m)
 	}
@@ -171,7 +182,7 @@ func (ds Deployments) RawManifests(defs Defs, log logging.LogSink) (Manifests, e
 // and configuration).
 func DeploymentsFromManifest(defs Defs, m *Manifest) (Deployments, error) {
 	ds := NewDeployments()
-	var inherit []DeploySpec
+	inherit := []DeploySpec{{DeployConfig: m.Defaults}}
 
 	for clusterName, spec := range m.Deployments {
 		cluster, ok := defs.Clusters[clusterName]
@@ -210,6 +221,7 @@ func BuildDeployment(m *Manifest, nick string, cluster *Cluster, spec DeploySpec
 		Owners:       ownMap,
 		Kind:         m.Kind,
 		SourceID:     m.Source.SourceID(ds.Version),
+		AutoRollback: m.AutoRollback,
 	}, nil
 }
 
@@ -605,3 +605,140 @@ func compareManifests(t *testing.T, expectedManifests, actualManifests Manifests
 }
 
 func jsonDump(v interface{}) string { b, _ := json.MarshalIndent(v, "", "  "); return string(b) }
+
+func makeTestManifestWithDefaults() *Manifest {
+	return &Manifest{
+		Source: project2,
+		Owners: []string{"owner2"},
+		Kind:   ManifestKindService,
+		Defaults: DeployConfig{
+			Resources:    Resources{"cpus": "1", "mem": "1024"},
+			Env:          Env{"LEVEL": "info", "CLUSTER_LONG_NAME": "Everywhere"},
+			NumInstances: 2,
+			Startup:      Startup{CheckReadyURIPath: "/health", Timeout: 30},
+		},
+		Deployments: DeploySpecs{
+			"cluster-1": {
+				Version: semv.MustParse("1.0.0"),
+			},
+			"cluster-2": {
+				Version: semv.MustParse("2.0.0"),
+				DeployConfig: DeployConfig{
+					Resources:    Resources{"mem": "2048"},
+					Env:          Env{"LEVEL": "debug"},
+					NumInstances: 5,
+					Startup:      Startup{Timeout: 60},
+				},
+			},
+		},
+	}
+}
+
+func TestDeploymentsFromManifest_defaults(t *testing.T) {
+	m := makeTestManifestWithDefaults()
+	ds, err := DeploymentsFromManifest(makeTestDefs(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	one, ok := ds.Get(DeploymentID{ManifestID: m.ID(), Cluster: "cluster-1"})
+	if !ok {
+		t.Fatal("no deployment to cluster-1")
+	}
+	assert.Equal(t, Resources{"cpus": "1", "mem": "1024"}, one.Resources)
+	assert.Equal(t, Env{"LEVEL": "info", "CLUSTER_LONG_NAME": "Everywhere"}, one.Env)
+	assert.Equal(t, 2, one.NumInstances)
+	assert.Equal(t, "/health", one.Startup.CheckReadyURIPath)
+	assert.Equal(t, 30, one.Startup.Timeout)
+
+	two, ok := ds.Get(DeploymentID{ManifestID: m.ID(), Cluster: "cluster-2"})
+	if !ok {
+		t.Fatal("no deployment to cluster-2")
+	}
+	assert.Equal(t, Resources{"cpus": "1", "mem": "2048"}, two.Resources)
+	assert.Equal(t, "debug", two.Env["LEVEL"])
+	assert.Equal(t, 5, two.NumInstances)
+	assert.Equal(t, "/health", two.Startup.CheckReadyURIPath)
+	assert.Equal(t, 60, two.Startup.Timeout)
+}
+
+// A zero Startup field of a DeploySpec inherits the value in Defaults, so it
+// cannot turn off a check the Defaults turn on.
+func TestDeploymentsFromManifest_defaultsStartupZeroValues(t *testing.T) {
+	m := makeTestManifestWithDefaults()
+	m.Defaults.Startup.SkipCheck = true
+	spec := m.Deployments["cluster-1"]
+	spec.Startup.SkipCheck = false
+	spec.Startup.Timeout = 0
+	m.Deployments["cluster-1"] = spec
+
+	ds, err := DeploymentsFromManifest(makeTestDefs(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	one, ok := ds.Get(DeploymentID{ManifestID: m.ID(), Cluster: "cluster-1"})
+	if !ok {
+		t.Fatal("no deployment to cluster-1")
+	}
+	assert.True(t, one.Startup.SkipCheck)
+	assert.Equal(t, 30, one.Startup.Timeout)
+}
+
+func TestDeployments_PutbackManifests_defaults(t *testing.T) {
+	defs := makeTestDefs()
+	ls, _ := logging.NewLogSinkSpy()
+	m := makeTestManifestWithDefaults()
+	olds := NewManifests(m)
+
+	ds, err := olds.Deployments(defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, err := ds.PutbackManifests(defs, olds, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareManifests(t, olds, ms)
+
+	// Changing the version of one deployment leaves its inherited
+	// configuration inherited.
+	did := DeploymentID{ManifestID: m.ID(), Cluster: "cluster-1"}
+	d, _ := ds.Get(did)
+	d.SourceID.Version = semv.MustParse("1.1.0")
+	ms, err = ds.PutbackManifests(defs, olds, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ms.Get(m.ID())
+	spec := got.Deployments["cluster-1"]
+	assert.Equal(t, "1.1.0", spec.Version.String())
+	assert.Empty(t, spec.Resources)
+	assert.Empty(t, spec.Env)
+	assert.Zero(t, spec.NumInstances)
+	assert.Equal(t, Startup{}, spec.Startup)
+	assert.Equal(t, m.Defaults.Env, got.Defaults.Env)
+
+	roundTripped, err := ms.Deployments(defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareDeployments(t, ds, roundTripped)
+}
+
+func TestDeploymentsFromManifest_defaultsPaused(t *testing.T) {
+	paused, unpaused := true, false
+	m := makeTestManifestWithDefaults()
+	m.Defaults.Paused = &paused
+	spec := m.Deployments["cluster-2"]
+	spec.Paused = &unpaused
+	m.Deployments["cluster-2"] = spec
+
+	ds, err := DeploymentsFromManifest(makeTestDefs(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	one, _ := ds.Get(DeploymentID{ManifestID: m.ID(), Cluster: "cluster-1"})
+	assert.True(t, one.IsPaused(), "cluster-1 inherits Paused")
+	two, _ := ds.Get(DeploymentID{ManifestID: m.ID(), Cluster: "cluster-2"})
+	assert.False(t, two.IsPaused(), "cluster-2 explicitly unpauses")
+}
@@ -0,0 +1,69 @@
+package sous
+
+import (
+	"time"
+
+	"github.com/opentable/sous/util/prometheus"
+)
+
+var (
+	rectificationDuration = prometheus.NewHistogramVec(
+		"sous_rectification_duration_seconds",
+		"How long rectifications take, from starting to being resolved.",
+		nil, "cluster", "manifest", "resolution", "outcome")
+
+	resolvePhaseDuration = prometheus.NewHistogramVec(
+		"sous_resolve_phase_duration_seconds",
+		"How long each phase of a resolve cycle takes.",
+		nil, "phase", "outcome")
+)
+
+// RegisterMetrics registers the metrics of rectifications and resolve cycles
+// with r.
+func RegisterMetrics(r *prometheus.Registry) {
+	r.Register(rectificationDuration)
+	r.Register(resolvePhaseDuration)
+}
+
+// outcome returns the outcome label of a metric of something that failed
+// with err.
+func outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// observeRectification records the duration of r, which started at start.
+func observeRectification(r *Rectification, start time.Time) {
+	r.RLock()
+	rez := r.Resolution
+	r.RUnlock()
+	var err error
+	if rez.Error != nil {
+		err = rez.Error
+	}
+	id := r.Pair.ID()
+	rectificationDuration.Observe(time.Since(start).Seconds(),
+		id.Cluster, id.ManifestID.String(), string(rez.Desc), outcome(err))
+}
+
+// Collect implements prometheus.Collector on R11nQueueSet, reporting the
+// number of rectifications queued or in progress for each deployment.
+func (rqs *R11nQueueSet) Collect() prometheus.Family {
+	f := prometheus.Family{
+		Name: "sous_r11n_queue_depth",
+		Help: "The number of rectifications queued or in progress for each deployment.",
+		Type: "gauge",
+	}
+	for id, q := range rqs.Queues() {
+		f.Samples = append(f.Samples, prometheus.Sample{
+			Labels: []prometheus.Label{
+				{Name: "cluster", Value: id.Cluster},
+				{Name: "manifest", Value: id.ManifestID.String()},
+			},
+			Value: float64(q.pending()),
+		})
+	}
+	return f
+}
@@ -0,0 +1,66 @@
+package sous
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/prometheus"
+)
+
+// sampleValue returns the value of the sample in f with suffix and labels.
+func sampleValue(f prometheus.Family, suffix string, labels map[string]string) (float64, bool) {
+samples:
+	for _, s := range f.Samples {
+		if s.Suffix != suffix || len(s.Labels) < len(labels) {
+			continue
+		}
+		for _, l := range s.Labels {
+			if want, ok := labels[l.Name]; ok && want != l.Value {
+				continue samples
+			}
+		}
+		return s.Value, true
+	}
+	return 0, false
+}
+
+func TestR11nQueueSet_Collect(t *testing.T) {
+	rqs := NewR11nQueueSet()
+	rqs.Push(makeTestR11nWithRepo("one"))
+	rqs.Push(makeTestR11nWithRepo("one"))
+	rqs.Push(makeTestR11nWithRepo("two"))
+
+	f := rqs.Collect()
+	if f.Name != "sous_r11n_queue_depth" || f.Type != "gauge" {
+		t.Fatalf("got family %q of type %q", f.Name, f.Type)
+	}
+	for repo, want := range map[string]float64{"one": 2, "two": 1} {
+		got, ok := sampleValue(f, "", map[string]string{"manifest": repo})
+		if !ok {
+			t.Errorf("no queue depth for %q", repo)
+		}
+		if got != want {
+			t.Errorf("got queue depth %v for %q; want %v", got, repo, want)
+		}
+	}
+}
+
+func TestResolveRecorder_performPhase_metrics(t *testing.T) {
+	rr := NewResolveRecorder(NewDeployments(), logging.SilentLogSet(), func(rr *ResolveRecorder) {
+		rr.performPhase("metrics test ok", func() error { return nil })
+		rr.performPhase("metrics test failing", func() error { return errors.New("bad") })
+	})
+	rr.Wait()
+
+	f := resolvePhaseDuration.Collect()
+	for phase, outcome := range map[string]string{
+		"metrics test ok":      "success",
+		"metrics test failing": "failure",
+	} {
+		got, ok := sampleValue(f, "_count", map[string]string{"phase": phase, "outcome": outcome})
+		if !ok || got != 1 {
+			t.Errorf("got %v observations of %q with outcome %q; want 1", got, phase, outcome)
+		}
+	}
+}
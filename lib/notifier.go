@@ -0,0 +1,268 @@
+package sous
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A NotificationRoute routes notifications about the deployments of some
+	// manifests to some destinations. A route with neither Manifests nor
+	// Owners routes the deployments of every manifest.
+	NotificationRoute struct {
+		// Manifests lists the IDs of the manifests whose deployments are
+		// routed.
+		Manifests []string `yaml:",omitempty"`
+		// Owners lists the owners whose manifests' deployments are routed.
+		Owners []string `yaml:",omitempty"`
+		// FailuresOnly routes only failed deployments.
+		FailuresOnly bool `yaml:",omitempty"`
+		// Slack lists the Slack channels to notify.
+		Slack []string `yaml:",omitempty"`
+		// Webhooks lists the URLs to post notifications to.
+		Webhooks []string `yaml:",omitempty"`
+		// Email lists the addresses to send notifications to.
+		Email []string `yaml:",omitempty"`
+	}
+
+	// NotificationRoutes is a list of NotificationRoute.
+	NotificationRoutes []NotificationRoute
+
+	// A Notification describes a change made to a deployment, or a failure to
+	// make one.
+	Notification struct {
+		DeploymentID string
+		ManifestID   string
+		Cluster      string
+		// Version is the intended version of the deployment, if there is one.
+		Version    string `json:",omitempty"`
+		Owners     []string
+		Resolution ResolutionType
+		// Error is the reason the deployment failed, if it did.
+		Error string `json:",omitempty"`
+		Time  time.Time
+	}
+
+	// A NotificationSink delivers notifications to destinations of one kind.
+	NotificationSink interface {
+		// Send delivers n to each destination in to.
+		Send(n Notification, to []string) error
+	}
+
+	// A Notifier sends notifications about deployments to the destinations
+	// that Defs.Notifications routes them to. A nil *Notifier sends nothing.
+	Notifier struct {
+		StateReader StateReader
+		// Slack, Webhook and Email deliver to the destinations in the
+		// corresponding fields of NotificationRoute. Destinations of a kind
+		// with a nil sink are reported and skipped.
+		Slack, Webhook, Email NotificationSink
+		log                   logging.LogSink
+
+		sync.Mutex
+		// failing records the last failure notified for each deployment,
+		// so that repeated attempts failing the same way are notified once.
+		failing map[DeploymentID]string
+	}
+)
+
+// NewNotifier creates a Notifier which routes notifications using the Defs
+// read from sr. Its sinks must be set before it is used.
+func NewNotifier(sr StateReader, ls logging.LogSink) *Notifier {
+	return &Notifier{
+		StateReader: sr,
+		log:         ls,
+		failing:     map[DeploymentID]string{},
+	}
+}
+
+// Failed returns true if n describes a failed deployment.
+func (n Notification) Failed() bool {
+	return n.Error != ""
+}
+
+// Summary returns a one line description of n.
+func (n Notification) Summary() string {
+	version := ""
+	if n.Version != "" {
+		version = " version " + n.Version
+	}
+	if n.Failed() {
+		return fmt.Sprintf("Deployment of %s%s to %s failed: %s", n.ManifestID, version, n.Cluster, n.Error)
+	}
+	return fmt.Sprintf("Deployment of %s%s to %s %s", n.ManifestID, version, n.Cluster, n.Resolution)
+}
+
+// Matches returns true if r routes notifications about deployments of the
+// manifest mid, owned by owners.
+func (r NotificationRoute) Matches(mid ManifestID, owners []string) bool {
+	if len(r.Manifests) == 0 && len(r.Owners) == 0 {
+		return true
+	}
+	for _, m := range r.Manifests {
+		if m == mid.String() {
+			return true
+		}
+	}
+	for _, ro := range r.Owners {
+		for _, o := range owners {
+			if strings.EqualFold(strings.TrimSpace(ro), strings.TrimSpace(o)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Clone returns a deep copy of rs.
+func (rs NotificationRoutes) Clone() NotificationRoutes {
+	if rs == nil {
+		return nil
+	}
+	c := make(NotificationRoutes, len(rs))
+	for i, r := range rs {
+		c[i] = NotificationRoute{
+			Manifests:    append([]string(nil), r.Manifests...),
+			Owners:       append([]string(nil), r.Owners...),
+			FailuresOnly: r.FailuresOnly,
+			Slack:        append([]string(nil), r.Slack...),
+			Webhooks:     append([]string(nil), r.Webhooks...),
+			Email:        append([]string(nil), r.Email...),
+		}
+	}
+	return c
+}
+
+// Route returns the destinations that rs routes n to, as a route that
+// combines the destinations of every matching route without duplicates.
+func (rs NotificationRoutes) Route(n Notification) NotificationRoute {
+	to := NotificationRoute{}
+	mid, err := ParseManifestID(n.ManifestID)
+	if err != nil {
+		return to
+	}
+	for _, r := range rs {
+		if (r.FailuresOnly && !n.Failed()) || !r.Matches(mid, n.Owners) {
+			continue
+		}
+		to.Slack = appendNew(to.Slack, r.Slack...)
+		to.Webhooks = appendNew(to.Webhooks, r.Webhooks...)
+		to.Email = appendNew(to.Email, r.Email...)
+	}
+	return to
+}
+
+func appendNew(to []string, ss ...string) []string {
+outer:
+	for _, s := range ss {
+		for _, t := range to {
+			if s == t {
+				continue outer
+			}
+		}
+		to = append(to, s)
+	}
+	return to
+}
+
+// Notify sends a notification about rez to the destinations it is routed to.
+// Only changes to deployments and failures are notified, and a deployment
+// that fails again in the same way as last time is not notified again.
+func (n *Notifier) Notify(rez DiffResolution) {
+	if n == nil || !n.notable(rez) {
+		return
+	}
+	state, err := n.StateReader.ReadState()
+	if err != nil {
+		logging.ReportError(n.log, errors.Wrapf(err, "reading notification routes"))
+		return
+	}
+	note := NewNotification(state, rez)
+	to := state.Defs.Notifications.Route(note)
+	n.send("Slack", n.Slack, note, to.Slack)
+	n.send("webhook", n.Webhook, note, to.Webhooks)
+	n.send("email", n.Email, note, to.Email)
+}
+
+func (n *Notifier) send(kind string, sink NotificationSink, note Notification, to []string) {
+	if len(to) == 0 {
+		return
+	}
+	if sink == nil {
+		logging.ReportError(n.log, errors.Errorf("no %s notifier configured, not notifying %s", kind, strings.Join(to, ", ")))
+		return
+	}
+	if err := sink.Send(note, to); err != nil {
+		logging.ReportError(n.log, errors.Wrapf(err, "sending %s notification for %s", kind, note.DeploymentID))
+	}
+}
+
+// notable returns true if rez should be notified, and records the failure
+// it describes if there is one.
+func (n *Notifier) notable(rez DiffResolution) bool {
+	n.Lock()
+	defer n.Unlock()
+	if rez.Error != nil {
+		msg := rez.Error.Error()
+		if n.failing[rez.DeploymentID] == msg {
+			return false
+		}
+		n.failing[rez.DeploymentID] = msg
+		return true
+	}
+	delete(n.failing, rez.DeploymentID)
+	switch rez.Desc {
+	default:
+		return false
+	case CreateDiff, ModifyDiff, DeleteDiff:
+		return true
+	}
+}
+
+// Watch notifies the failures logged by rr that stopped deployments from
+// being rectified at all. The resolutions of rectifications are notified by
+// the handler of the queue they are pushed to, so rr's are ignored.
+func (n *Notifier) Watch(rr *ResolveRecorder) {
+	if n == nil {
+		return
+	}
+	rr.Subscribe(func(rez DiffResolution) {
+		if rez.Error == nil {
+			return
+		}
+		switch rez.Desc {
+		case CreateDiff, ModifyDiff, DeleteDiff, StableDiff, ComingDiff:
+			return
+		}
+		go n.Notify(rez)
+	})
+}
+
+// NewNotification returns the notification of rez, with details of the
+// deployment taken from state.
+func NewNotification(state *State, rez DiffResolution) Notification {
+	note := Notification{
+		DeploymentID: rez.DeploymentID.String(),
+		ManifestID:   rez.ManifestID.String(),
+		Cluster:      rez.Cluster,
+		Resolution:   rez.Desc,
+		Time:         time.Now(),
+	}
+	if rez.Error != nil {
+		note.Error = rez.Error.Error()
+	}
+	m, ok := state.Manifests.Get(rez.ManifestID)
+	if !ok {
+		return note
+	}
+	note.Owners = append([]string{}, m.Owners...)
+	if spec, ok := m.Deployments[rez.Cluster]; ok && rez.Desc != DeleteDiff {
+		note.Version = spec.Version.String()
+	}
+	return note
+}
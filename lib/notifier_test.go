@@ -0,0 +1,145 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentNotification struct {
+	Notification
+	to []string
+}
+
+type fakeSink struct {
+	sent []sentNotification
+}
+
+func (s *fakeSink) Send(n Notification, to []string) error {
+	s.sent = append(s.sent, sentNotification{n, to})
+	return nil
+}
+
+func notifierFixture(t *testing.T, routes ...NotificationRoute) (*Notifier, *fakeSink, *fakeSink) {
+	state := NewState()
+	state.Defs.Notifications = routes
+	state.Manifests.Add(&Manifest{
+		Source: SourceLocation{Repo: "github.com/example/app"},
+		Owners: []string{"team-a@example.com"},
+		Deployments: DeploySpecs{
+			"cluster-1": {Version: semv.MustParse("1.2.3")},
+		},
+	})
+	state.Manifests.Add(&Manifest{
+		Source: SourceLocation{Repo: "github.com/example/other"},
+		Owners: []string{"team-b@example.com"},
+	})
+	slack, webhook := &fakeSink{}, &fakeSink{}
+	n := NewNotifier(&DummyStateManager{State: state}, logging.SilentLogSet())
+	n.Slack = slack
+	n.Webhook = webhook
+	return n, slack, webhook
+}
+
+func appRez(desc ResolutionType, err error) DiffResolution {
+	rez := DiffResolution{
+		DeploymentID: DeploymentID{
+			ManifestID: ManifestID{Source: SourceLocation{Repo: "github.com/example/app"}},
+			Cluster:    "cluster-1",
+		},
+		Desc: desc,
+	}
+	if err != nil {
+		rez.Error = WrapResolveError(err)
+	}
+	return rez
+}
+
+func TestNotifier_routes(t *testing.T) {
+	n, slack, webhook := notifierFixture(t,
+		NotificationRoute{Manifests: []string{"github.com/example/app"}, Slack: []string{"#app"}},
+		NotificationRoute{Owners: []string{"TEAM-A@example.com"}, Slack: []string{"#team-a", "#app"}},
+		NotificationRoute{Owners: []string{"team-b@example.com"}, Slack: []string{"#team-b"}},
+		NotificationRoute{FailuresOnly: true, Webhooks: []string{"https://hooks.example.com/"}},
+	)
+
+	n.Notify(appRez(ModifyDiff, nil))
+
+	require.Len(t, slack.sent, 1)
+	assert.Equal(t, []string{"#app", "#team-a"}, slack.sent[0].to)
+	assert.Equal(t, "1.2.3", slack.sent[0].Version)
+	assert.Equal(t, []string{"team-a@example.com"}, slack.sent[0].Owners)
+	assert.False(t, slack.sent[0].Failed())
+	assert.Empty(t, webhook.sent)
+
+	n.Notify(appRez(ModifyDiff, errors.New("boom")))
+
+	require.Len(t, webhook.sent, 1)
+	assert.Equal(t, []string{"https://hooks.example.com/"}, webhook.sent[0].to)
+	assert.Equal(t, "boom", webhook.sent[0].Error)
+}
+
+func TestNotifier_unchanged(t *testing.T) {
+	n, slack, _ := notifierFixture(t, NotificationRoute{Slack: []string{"#all"}})
+
+	n.Notify(appRez(StableDiff, nil))
+	n.Notify(appRez(ComingDiff, nil))
+
+	assert.Empty(t, slack.sent)
+}
+
+func TestNotifier_repeatedFailures(t *testing.T) {
+	n, slack, _ := notifierFixture(t, NotificationRoute{Slack: []string{"#all"}})
+
+	n.Notify(appRez(ModifyDiff, errors.New("boom")))
+	n.Notify(appRez(ModifyDiff, errors.New("boom")))
+	assert.Len(t, slack.sent, 1)
+
+	n.Notify(appRez(ModifyDiff, errors.New("bang")))
+	assert.Len(t, slack.sent, 2)
+
+	n.Notify(appRez(ModifyDiff, nil))
+	n.Notify(appRez(ModifyDiff, errors.New("bang")))
+	assert.Len(t, slack.sent, 4)
+}
+
+func TestNotifier_nil(t *testing.T) {
+	var n *Notifier
+	n.Notify(appRez(ModifyDiff, nil))
+	n.Watch(nil)
+}
+
+func TestNotificationRoute_Matches(t *testing.T) {
+	mid := MustParseManifestID("github.com/example/app~canary")
+	owners := []string{"someone@example.com"}
+
+	assert.True(t, NotificationRoute{}.Matches(mid, owners))
+	assert.True(t, NotificationRoute{Manifests: []string{"github.com/example/app~canary"}}.Matches(mid, owners))
+	assert.False(t, NotificationRoute{Manifests: []string{"github.com/example/app"}}.Matches(mid, owners))
+	assert.True(t, NotificationRoute{Owners: []string{" Someone@Example.com"}}.Matches(mid, owners))
+	assert.False(t, NotificationRoute{Owners: []string{"someone-else@example.com"}}.Matches(mid, owners))
+}
+
+func TestResolveRecorder_Subscribe(t *testing.T) {
+	logged := make(chan struct{})
+	rr := NewResolveRecorder(NewDeployments(), logging.SilentLogSet(), func(rr *ResolveRecorder) {
+		rr.Log <- appRez(CreateDiff, nil)
+		<-logged
+		rr.Log <- appRez(ModifyDiff, nil)
+	})
+	for len(rr.CurrentStatus().Log) == 0 {
+	}
+
+	got := make(chan ResolutionType, 2)
+	rr.Subscribe(func(rez DiffResolution) {
+		got <- rez.Desc
+	})
+	close(logged)
+
+	assert.Equal(t, CreateDiff, <-got)
+	assert.Equal(t, ModifyDiff, <-got)
+}
@@ -0,0 +1,204 @@
+package sous
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// A PlannedChange is a change that rectification would make to bring a
+	// deployment to its intended state.
+	PlannedChange struct {
+		DeploymentID DeploymentID
+		// Kind is the kind of change: "added", "removed" or "modified".
+		Kind string
+		// Action describes what rectification would do, e.g. "update existing
+		// deployment".
+		Action string
+		// Diffs lists each field of the deployment that would change.
+		Diffs []FieldDiff
+		// Artifact names the build artifact that would be deployed. It is
+		// empty for removals, for deployments with no instances, and when
+		// the artifact cannot be resolved.
+		Artifact string `json:",omitempty"`
+		// Error, if not empty, is why rectification could not make the
+		// change, e.g. because there is no artifact for the intended version.
+		Error string `json:",omitempty"`
+	}
+
+	// A FieldDiff is the actual and intended value of one field of a
+	// deployment. Prior is empty if the field is not yet set, and Post is
+	// empty if it would be unset.
+	FieldDiff struct {
+		Field, Prior, Post string
+	}
+)
+
+// Plan runs the phases of resolution that precede rectification, for the
+// deployments in intended and the clusters selected by r's ResolveFilter. It
+// returns the change that rectification would make to each deployment,
+// ordered by DeploymentID. Nothing is rectified or queued.
+func (r *Resolver) Plan(intended Deployments, clusters Clusters) ([]PlannedChange, error) {
+	intended = intended.Filter(r.FilterDeployment)
+	clusters = r.FilteredClusters(clusters)
+
+	actual, err := r.Deployer.RunningDeployments(r.Registry, clusters)
+	if err != nil {
+		return nil, err
+	}
+	actual = actual.Filter(r.FilterDeployStates)
+
+	changes := []PlannedChange{}
+	for p := range actual.Diff(intended).Pairs {
+		// As in queueDiffs, unchanged deployments and new deployments with no
+		// instances or no version are not rectified.
+		if p.Kind() == SameKind {
+			continue
+		}
+		if p.Kind() == AddedKind && (p.Post.NumInstances == 0 ||
+			p.Post.DeploySpec().Version.String() == "0.0.0") {
+			continue
+		}
+		changes = append(changes, r.planChange(p))
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].DeploymentID.String() < changes[j].DeploymentID.String()
+	})
+	return changes, nil
+}
+
+// planChange resolves the artifact for p, as rectification would, and
+// describes the change it would make.
+func (r *Resolver) planChange(p *DeployablePair) PlannedChange {
+	change := PlannedChange{
+		DeploymentID: p.ID(),
+		Kind:         p.Kind().String(),
+		Action:       p.Kind().ResolveVerb(),
+		Diffs:        FieldDiffs(p.Prior, p.Post),
+	}
+
+	resolved, rez := HandlePairsByRegistry(r.Registry, p, r.ls)
+	if rez != nil && rez.Error != nil {
+		change.Error = rez.Error.Error()
+	}
+	if resolved != nil && resolved.Post != nil && resolved.Post.BuildArtifact != nil {
+		art := resolved.Post.BuildArtifact
+		change.Artifact = art.DigestReference
+		if change.Artifact == "" {
+			change.Artifact = art.VersionName
+		}
+	}
+	return change
+}
+
+// FieldDiffs returns the fields that differ between prior and post, either of
+// which may be nil, ordered by field name. Fields are named like the YAML of
+// a manifest, with map entries named like "Env.NAME".
+func FieldDiffs(prior, post *Deployable) []FieldDiff {
+	pf, qf := deployableFields(prior), deployableFields(post)
+	if prior != nil && post != nil {
+		// Resources are compared as quantities, so that e.g. "1" and "1.0"
+		// cpus are the same.
+		if prior.Resources.Equal(post.Resources) {
+			dropPrefix(pf, "Resources.")
+			dropPrefix(qf, "Resources.")
+		}
+	}
+
+	names := map[string]struct{}{}
+	for n := range pf {
+		names[n] = struct{}{}
+	}
+	for n := range qf {
+		names[n] = struct{}{}
+	}
+	diffs := []FieldDiff{}
+	for n := range names {
+		if pf[n] != qf[n] {
+			diffs = append(diffs, FieldDiff{Field: n, Prior: pf[n], Post: qf[n]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Field < diffs[j].Field })
+	return diffs
+}
+
+func (fd FieldDiff) String() string {
+	prior, post := fd.Prior, fd.Post
+	if prior == "" {
+		prior = "(unset)"
+	}
+	if post == "" {
+		post = "(unset)"
+	}
+	return fmt.Sprintf("%s: %s -> %s", fd.Field, prior, post)
+}
+
+// deployableFields flattens the fields of d that rectification compares into
+// a map from field name to value. Fields without a value are omitted.
+func deployableFields(d *Deployable) map[string]string {
+	fields := map[string]string{}
+	if d == nil || d.Deployment == nil {
+		return fields
+	}
+	set := func(name, value string) {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	setMap := func(prefix string, m map[string]string) {
+		for k, v := range m {
+			set(prefix+"."+k, v)
+		}
+	}
+
+	set("Status", d.Status.String())
+	set("Version", d.SourceID.Version.String())
+	set("Kind", string(d.Kind))
+	owners := d.Owners.Slice()
+	sort.Strings(owners)
+	set("Owners", strings.Join(owners, ", "))
+
+	dc := d.DeployConfig
+	set("NumInstances", strconv.Itoa(dc.NumInstances))
+	if dc.IsPaused() {
+		set("Paused", "true")
+	}
+	// Schedule is only significant for scheduled jobs.
+	if d.Kind == ManifestKindScheduled {
+		set("Schedule", dc.Schedule)
+		set("ScheduleTimeZone", dc.ScheduleTimeZone)
+	}
+	setMap("Env", dc.Env)
+	setMap("Metadata", dc.Metadata)
+	setMap("Resources", dc.Resources)
+	if len(dc.Volumes) > 0 {
+		set("Volumes", dc.Volumes.String())
+	}
+	set("SingularityRequestID", dc.SingularityRequestID)
+
+	// The rest of Startup is ignored when the check is skipped.
+	startup := dc.Startup
+	if startup.SkipCheck {
+		startup = Startup{SkipCheck: true}
+	}
+	sv := reflect.ValueOf(startup)
+	for i := 0; i < sv.NumField(); i++ {
+		f := sv.Field(i)
+		if reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+			continue
+		}
+		set("Startup."+sv.Type().Field(i).Name, fmt.Sprint(f.Interface()))
+	}
+	return fields
+}
+
+func dropPrefix(fields map[string]string, prefix string) {
+	for n := range fields {
+		if strings.HasPrefix(n, prefix) {
+			delete(fields, n)
+		}
+	}
+}
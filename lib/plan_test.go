@@ -0,0 +1,108 @@
+package sous
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nyarly/spies"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func planDeployment(repo, version string, instances int) *Deployment {
+	cluster := &Cluster{Name: "cluster-1"}
+	return &Deployment{
+		ClusterName:  "cluster-1",
+		Cluster:      cluster,
+		SourceID:     MustParseSourceID(repo + "," + version),
+		DeployConfig: DeployConfig{NumInstances: instances, Env: Env{"A": "1"}},
+	}
+}
+
+// planRegistry returns an artifact for each SourceID except unresolvable.
+type planRegistry struct {
+	*DummyRegistry
+	unresolvable SourceID
+}
+
+func (r planRegistry) GetArtifact(sid SourceID) (*BuildArtifact, error) {
+	if sid.Equal(r.unresolvable) {
+		return nil, fmt.Errorf("no such image")
+	}
+	return &BuildArtifact{DigestReference: "docker.example.com/" + sid.String()}, nil
+}
+
+func TestResolver_Plan(t *testing.T) {
+	running := planDeployment("github.com/ot/modified", "1.0.0", 1)
+	removed := planDeployment("github.com/ot/removed", "1.0.0", 1)
+	same := planDeployment("github.com/ot/same", "1.0.0", 1)
+	deployer, ctrl := NewDeployerSpy()
+	ctrl.MatchMethod("RunningDeployments", spies.AnyArgs, NewDeployStates(
+		&DeployState{Deployment: *running, Status: DeployStatusActive},
+		&DeployState{Deployment: *removed, Status: DeployStatusActive},
+		&DeployState{Deployment: *same, Status: DeployStatusActive},
+	), nil)
+
+	modified := planDeployment("github.com/ot/modified", "2.0.0", 3)
+	modified.Env = Env{"B": "2"}
+	added := planDeployment("github.com/ot/added", "1.0.0", 1)
+	unresolvable := planDeployment("github.com/ot/unresolvable", "1.0.0", 1)
+	uninitialized := planDeployment("github.com/ot/uninitialized", "0.0.0", 1)
+	intended := NewDeployments(modified, added, unresolvable, uninitialized, same.Clone())
+
+	registry := planRegistry{DummyRegistry: NewDummyRegistry(), unresolvable: unresolvable.SourceID}
+	ls, _ := logging.NewLogSinkSpy()
+	queues := NewR11nQueueSet()
+	r := NewResolver(deployer, registry, &ResolveFilter{}, ls, queues)
+
+	changes, err := r.Plan(intended, Clusters{"cluster-1": running.Cluster})
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	assert.Len(t, queues.Queues(), 0, "nothing should be queued")
+
+	byRepo := map[string]PlannedChange{}
+	for _, c := range changes {
+		byRepo[c.DeploymentID.ManifestID.Source.Repo] = c
+	}
+
+	a := byRepo["github.com/ot/added"]
+	assert.Equal(t, "added", a.Kind)
+	assert.Equal(t, "create new deployment", a.Action)
+	assert.Contains(t, a.Diffs, FieldDiff{Field: "Version", Post: "1.0.0"})
+
+	m := byRepo["github.com/ot/modified"]
+	assert.Equal(t, "modified", m.Kind)
+	assert.Equal(t, []FieldDiff{
+		{Field: "Env.A", Prior: "1"},
+		{Field: "Env.B", Post: "2"},
+		{Field: "NumInstances", Prior: "1", Post: "3"},
+		{Field: "Version", Prior: "1.0.0", Post: "2.0.0"},
+	}, m.Diffs)
+	assert.Empty(t, m.Error)
+	assert.Equal(t, "docker.example.com/"+modified.SourceID.String(), m.Artifact)
+
+	rm := byRepo["github.com/ot/removed"]
+	assert.Equal(t, "removed", rm.Kind)
+	assert.Contains(t, rm.Diffs, FieldDiff{Field: "Version", Prior: "1.0.0"})
+	assert.Empty(t, rm.Artifact)
+
+	u := byRepo["github.com/ot/unresolvable"]
+	assert.Contains(t, u.Error, "no such image")
+	assert.Empty(t, u.Artifact)
+}
+
+func TestFieldDiffs(t *testing.T) {
+	prior := &Deployable{Deployment: planDeployment("github.com/ot/one", "1.0.0", 1)}
+	post := &Deployable{Deployment: planDeployment("github.com/ot/one", "1.0.0", 1)}
+	prior.Resources = Resources{"cpus": "1", "memory": "100", "ports": "1"}
+	post.Resources = Resources{"cpus": "1.0", "memory": "100", "ports": "1"}
+	post.Startup = Startup{SkipCheck: true}
+	prior.Startup = Startup{Timeout: 10}
+
+	assert.Equal(t, []FieldDiff{
+		{Field: "Startup.SkipCheck", Post: "true"},
+		{Field: "Startup.Timeout", Prior: "10"},
+	}, FieldDiffs(prior, post))
+	assert.Equal(t, "Startup.Timeout: 10 -> (unset)", FieldDiffs(prior, post)[1].String())
+}
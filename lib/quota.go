@@ -0,0 +1,159 @@
+package sous
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	// Quotas is a collection of Quota.
+	Quotas []Quota
+
+	// A Quota limits the total resources of the deployments of an owner,
+	// computed as the Resources of each deployment times its NumInstances.
+	// Paused deployments use no resources. Deployments with several owners
+	// count towards the quotas of each. Zero limits are unlimited.
+	Quota struct {
+		// Owner is the owner of the manifests whose deployments are limited.
+		Owner string
+		// Cluster, if not empty, limits only the deployments to that cluster.
+		Cluster string `yaml:",omitempty"`
+		// Cpus is the total number of cpus.
+		Cpus float64 `yaml:",omitempty"`
+		// Memory is the total memory, in MB.
+		Memory float64 `yaml:",omitempty"`
+		// Instances is the total number of instances.
+		Instances int `yaml:",omitempty"`
+	}
+
+	// A QuotaUsage is the total resources used by the deployments a Quota
+	// limits.
+	QuotaUsage struct {
+		Quota     Quota
+		Cpus      float64
+		Memory    float64
+		Instances int
+	}
+)
+
+// Clone returns an independent copy of qs.
+func (qs Quotas) Clone() Quotas {
+	if qs == nil {
+		return nil
+	}
+	return append(Quotas{}, qs...)
+}
+
+// Validate returns a flaw for each quota without an owner, or with negative
+// limits.
+func (qs Quotas) Validate() []Flaw {
+	var flaws []Flaw
+	for i, q := range qs {
+		if strings.TrimSpace(q.Owner) == "" {
+			flaws = append(flaws, FatalFlaw("quota %d has no Owner", i))
+		}
+		if q.Cpus < 0 || q.Memory < 0 || q.Instances < 0 {
+			flaws = append(flaws, FatalFlaw("quota for %s has a negative limit", q))
+		}
+	}
+	return flaws
+}
+
+func (q Quota) String() string {
+	if q.Cluster == "" {
+		return q.Owner
+	}
+	return q.Owner + " in " + q.Cluster
+}
+
+// limits returns true if q limits d.
+func (q Quota) limits(d *Deployment) bool {
+	if q.Cluster != "" && q.Cluster != d.ClusterName {
+		return false
+	}
+	for owner := range d.Owners {
+		if strings.EqualFold(strings.TrimSpace(owner), strings.TrimSpace(q.Owner)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage returns the resources used by the deployments in ds that q limits.
+func (q Quota) Usage(ds Deployments) QuotaUsage {
+	u := QuotaUsage{Quota: q}
+	for _, d := range ds.Snapshot() {
+		if !q.limits(d) || d.IsPaused() {
+			continue
+		}
+		n := float64(d.NumInstances)
+		u.Cpus += d.Resources.Cpus() * n
+		u.Memory += d.Resources.Memory() * n
+		u.Instances += d.NumInstances
+	}
+	return u
+}
+
+// Usage returns the usage of each quota by the deployments in ds, ordered by
+// owner and cluster.
+func (qs Quotas) Usage(ds Deployments) []QuotaUsage {
+	us := make([]QuotaUsage, 0, len(qs))
+	for _, q := range qs {
+		us = append(us, q.Usage(ds))
+	}
+	sort.SliceStable(us, func(i, j int) bool {
+		a, b := us[i].Quota, us[j].Quota
+		return a.Owner < b.Owner || a.Owner == b.Owner && a.Cluster < b.Cluster
+	})
+	return us
+}
+
+// Exceeded describes each limit of its Quota that u exceeds, or returns nil.
+func (u QuotaUsage) Exceeded() []string {
+	return u.exceededSince(QuotaUsage{})
+}
+
+// exceededSince describes each limit of its Quota that u exceeds by using
+// more than was.
+func (u QuotaUsage) exceededSince(was QuotaUsage) []string {
+	var over []string
+	q := u.Quota
+	if q.Cpus > 0 && u.Cpus > q.Cpus && u.Cpus > was.Cpus {
+		over = append(over, fmt.Sprintf("%g cpus of %g", u.Cpus, q.Cpus))
+	}
+	if q.Memory > 0 && u.Memory > q.Memory && u.Memory > was.Memory {
+		over = append(over, fmt.Sprintf("%g MB memory of %g", u.Memory, q.Memory))
+	}
+	if q.Instances > 0 && u.Instances > q.Instances && u.Instances > was.Instances {
+		over = append(over, fmt.Sprintf("%d instances of %d", u.Instances, q.Instances))
+	}
+	return over
+}
+
+// Check returns a *QuotaExceededError describing each quota that the
+// deployments in after exceed, by using more of a limited resource than those
+// in before. A change that does not add to the use of a resource that is
+// already over quota, e.g. one that scales down, is allowed.
+func (qs Quotas) Check(before, after Deployments) error {
+	var exceeded []string
+	for _, q := range qs {
+		if over := q.Usage(after).exceededSince(q.Usage(before)); len(over) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s would use %s", q, strings.Join(over, ", ")))
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	return &QuotaExceededError{Exceeded: exceeded}
+}
+
+// QuotaExceededError is returned when a change would exceed quotas.
+type QuotaExceededError struct {
+	// Exceeded describes each quota that would be exceeded.
+	Exceeded []string
+}
+
+func (e *QuotaExceededError) Error() string {
+	return "quota exceeded: " + strings.Join(e.Exceeded, "; ")
+}
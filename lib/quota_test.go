@@ -0,0 +1,75 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func quotaDeployment(repo, cluster, owner string, instances int) *Deployment {
+	return &Deployment{
+		SourceID:    MustNewSourceID(repo, "", "1.0.0"),
+		ClusterName: cluster,
+		Owners:      NewOwnerSet(owner),
+		DeployConfig: DeployConfig{
+			NumInstances: instances,
+			Resources:    Resources{"cpus": "0.5", "memory": "256", "ports": "1"},
+		},
+	}
+}
+
+func TestQuotas_Usage(t *testing.T) {
+	ds := NewDeployments(
+		quotaDeployment("github.com/example/a", "cluster-1", "Team-A", 2),
+		quotaDeployment("github.com/example/b", "cluster-2", "team-a", 3),
+		quotaDeployment("github.com/example/c", "cluster-1", "team-b", 4),
+	)
+	qs := Quotas{
+		{Owner: "team-a", Cluster: "cluster-1", Cpus: 2},
+		{Owner: "team-a", Instances: 4},
+	}
+
+	us := qs.Usage(ds)
+	require.Len(t, us, 2)
+	assert.Equal(t, QuotaUsage{Quota: qs[1], Cpus: 2.5, Memory: 1280, Instances: 5}, us[0])
+	assert.Equal(t, QuotaUsage{Quota: qs[0], Cpus: 1, Memory: 512, Instances: 2}, us[1])
+	assert.Equal(t, []string{"5 instances of 4"}, us[0].Exceeded())
+	assert.Empty(t, us[1].Exceeded())
+}
+
+func TestQuotas_Check(t *testing.T) {
+	qs := Quotas{{Owner: "team-a", Instances: 4}}
+	deployments := func(instances int) Deployments {
+		return NewDeployments(quotaDeployment("github.com/example/a", "cluster-1", "team-a", instances))
+	}
+
+	assert.NoError(t, qs.Check(deployments(2), deployments(4)))
+
+	err := qs.Check(deployments(2), deployments(5))
+	require.IsType(t, &QuotaExceededError{}, err)
+	assert.Equal(t, "quota exceeded: team-a would use 5 instances of 4", err.Error())
+
+	// Scaling down while over quota is allowed.
+	assert.NoError(t, qs.Check(deployments(8), deployments(6)))
+	assert.Error(t, qs.Check(deployments(6), deployments(8)))
+
+	// Deployments of other owners are not limited.
+	other := NewDeployments(quotaDeployment("github.com/example/b", "cluster-1", "team-b", 10))
+	assert.NoError(t, qs.Check(NewDeployments(), other))
+}
+
+func TestQuotas_Validate(t *testing.T) {
+	flaws := Quotas{
+		{Owner: "team-a", Cpus: 4},
+		{Cpus: 1},
+		{Owner: "team-b", Memory: -1},
+	}.Validate()
+	assert.Len(t, flaws, 2)
+
+	defs := Defs{
+		Clusters: Clusters{},
+		Quotas:   Quotas{{Owner: "team-a", Cluster: "nowhere", Instances: 1}},
+	}
+	assert.Len(t, defs.Validate(), 1)
+}
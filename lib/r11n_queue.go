@@ -5,6 +5,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/opentable/sous/util/logging"
 	"github.com/pborman/uuid"
 )
 
@@ -20,6 +21,8 @@ type (
 		fifoRefs      *ring.Ring
 		handler       func(*QueuedR11n) DiffResolution
 		start         bool
+		store         R11nStore
+		log           logging.LogSink
 		sync.Mutex
 	}
 	// QueuedR11n is a queue item wrapping a Rectification with an ID and position.
@@ -28,6 +31,9 @@ type (
 		Pos           int
 		Rectification *Rectification
 		done          chan struct{}
+		// queued is closed once the rectification has been recorded in the
+		// queue's R11nStore, if it has one.
+		queued chan struct{}
 	}
 
 	// R11nID is a QueuedR11n identifier.
@@ -129,6 +135,7 @@ func (rq *R11nQueue) Start(handler func(*QueuedR11n) DiffResolution) {
 		for {
 			qr := rq.next()
 			handler(qr)
+			rq.storeResolved(qr)
 			rq.Lock()
 			close(qr.done)
 			delete(rq.refs, qr.ID)
@@ -156,32 +163,41 @@ func (rq *R11nQueue) Wait(id R11nID) (DiffResolution, bool) {
 // returns nil and false.
 func (rq *R11nQueue) Push(r *Rectification) (*QueuedR11n, bool) {
 	rq.Lock()
-	defer rq.Unlock()
 	if len(rq.queue) == rq.cap {
+		rq.Unlock()
 		return nil, false
 	}
-	return rq.internalPush(r), true
+	qr := rq.pushWithID(NewR11nID(), r)
+	rq.Unlock()
+	rq.storeQueued(qr)
+	return qr, true
 }
 
-// internalPush assumes rq is already locked.
-func (rq *R11nQueue) internalPush(r *Rectification) *QueuedR11n {
-	id := NewR11nID()
+// pushWithID assumes rq is already locked.
+func (rq *R11nQueue) pushWithID(id R11nID, r *Rectification) *QueuedR11n {
 	qr := &QueuedR11n{
 		ID:            id,
 		Pos:           len(rq.queue),
 		Rectification: r,
 		done:          make(chan struct{}),
+		queued:        make(chan struct{}),
 	}
 	rq.refs[id] = qr
-	rq.allRefs[id] = qr
+	rq.remember(qr)
+	rq.queue <- qr
+	return qr
+}
+
+// remember adds qr to the most recent MaxRefsPerR11nQueue rectifications
+// that can be found by ID. It assumes rq is already locked.
+func (rq *R11nQueue) remember(qr *QueuedR11n) {
+	rq.allRefs[qr.ID] = qr
 	rq.fifoRefs = rq.fifoRefs.Next()
 	if rq.fifoRefs.Value != nil {
 		idToDelete := rq.fifoRefs.Value.(R11nID)
 		delete(rq.allRefs, idToDelete)
 	}
-	rq.fifoRefs.Value = id
-	rq.queue <- qr
-	return qr
+	rq.fifoRefs.Value = qr.ID
 }
 
 // PushIfEmpty adds an item to the queue if it is empty, and returns the wrapper
@@ -189,13 +205,24 @@ func (rq *R11nQueue) internalPush(r *Rectification) *QueuedR11n {
 // returns nil, false.
 func (rq *R11nQueue) PushIfEmpty(r *Rectification) (*QueuedR11n, bool) {
 	rq.Lock()
-	defer rq.Unlock()
 	// We look at refs since we only delete the ref after handling has happened.
 	// If we are busy handling a r11n, then we consider the queue non-empty.
 	if len(rq.refs) != 0 {
+		rq.Unlock()
 		return nil, false
 	}
-	return rq.internalPush(r), true
+	qr := rq.pushWithID(NewR11nID(), r)
+	rq.Unlock()
+	rq.storeQueued(qr)
+	return qr, true
+}
+
+// pending returns the number of rectifications in rq that are queued or
+// being handled.
+func (rq *R11nQueue) pending() int {
+	rq.Lock()
+	defer rq.Unlock()
+	return len(rq.refs)
 }
 
 // Len returns the current number of items in the queue.
@@ -1,9 +1,12 @@
 package sous
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/nyarly/spies"
+	"github.com/pkg/errors"
 )
 
 type (
@@ -20,6 +23,9 @@ type (
 		set  map[DeploymentID]*R11nQueue
 		opts []R11nQueueOpt
 		reg  Registry
+		// closed is set once the set is draining, after which nothing more
+		// can be pushed.
+		closed bool
 		sync.RWMutex
 	}
 
@@ -40,13 +46,9 @@ func NewR11nQueueSet(opts ...R11nQueueOpt) *R11nQueueSet {
 // PushIfEmpty creates a queue for the DeploymentID of r if it does not already
 // exist. It calls PushIfEmpty on that R11nQueue passing r.
 func (rqs *R11nQueueSet) PushIfEmpty(r *Rectification) (*QueuedR11n, bool) {
-	rqs.Lock()
-	defer rqs.Unlock()
-	id := r.Pair.ID()
-	queue, ok := rqs.set[id]
+	queue, ok := rqs.queueFor(r)
 	if !ok {
-		queue = NewR11nQueue(rqs.opts...)
-		rqs.set[id] = queue
+		return nil, false
 	}
 	return queue.PushIfEmpty(r)
 }
@@ -54,15 +56,59 @@ func (rqs *R11nQueueSet) PushIfEmpty(r *Rectification) (*QueuedR11n, bool) {
 // Push creates a queue for the DeploymentID of r if it does not already
 // exist. It calls Push on that R11nQueue passing r.
 func (rqs *R11nQueueSet) Push(r *Rectification) (*QueuedR11n, bool) {
+	queue, ok := rqs.queueFor(r)
+	if !ok {
+		return nil, false
+	}
+	return queue.Push(r)
+}
+
+// queueFor returns the queue for the DeploymentID of r, creating it if it
+// does not already exist, and true; or nil and false if rqs is closed. The
+// queue is pushed to without rqs locked, since it may write to its store.
+func (rqs *R11nQueueSet) queueFor(r *Rectification) (*R11nQueue, bool) {
 	rqs.Lock()
 	defer rqs.Unlock()
+	if rqs.closed {
+		return nil, false
+	}
 	id := r.Pair.ID()
 	queue, ok := rqs.set[id]
 	if !ok {
 		queue = NewR11nQueue(rqs.opts...)
 		rqs.set[id] = queue
 	}
-	return queue.Push(r)
+	return queue, true
+}
+
+// drainPollInterval is how often Drain checks whether the queues are empty.
+var drainPollInterval = 100 * time.Millisecond
+
+// Drain stops rqs accepting new rectifications, then waits until every
+// rectification already queued has been handled, or ctx is done. If ctx is
+// done first, it returns an error saying how many are still pending; if rqs
+// has a store, they are resumed when it is next restored.
+func (rqs *R11nQueueSet) Drain(ctx context.Context) error {
+	rqs.Lock()
+	rqs.closed = true
+	rqs.Unlock()
+
+	tick := time.NewTicker(drainPollInterval)
+	defer tick.Stop()
+	for {
+		pending := 0
+		for _, q := range rqs.Queues() {
+			pending += q.pending()
+		}
+		if pending == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "%d rectifications still pending", pending)
+		case <-tick.C:
+		}
+	}
 }
 
 // Wait waits for the r11n with id id to complete, if it is found in the
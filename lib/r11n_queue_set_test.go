@@ -1,6 +1,7 @@
 package sous
 
 import (
+	"context"
 	"strconv"
 	"strings"
 	"sync"
@@ -145,3 +146,29 @@ func TestR11nQueueSet_Wait(t *testing.T) {
 		}
 	}
 }
+
+func TestR11nQueueSet_Drain(t *testing.T) {
+	proceed := make(chan struct{})
+	rqs := NewR11nQueueSet(R11nQueueStartWithHandler(func(*QueuedR11n) DiffResolution {
+		<-proceed
+		return DiffResolution{}
+	}))
+	if _, ok := rqs.Push(makeTestR11nWithRepo("one")); !ok {
+		t.Fatalf("r11n not queued, cannot proceed with test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rqs.Drain(ctx); err == nil || !strings.Contains(err.Error(), "1 rectifications still pending") {
+		t.Errorf("got error %v; want 1 rectification pending", err)
+	}
+
+	if _, ok := rqs.Push(makeTestR11nWithRepo("two")); ok {
+		t.Errorf("pushed to a draining queue set")
+	}
+
+	close(proceed)
+	if err := rqs.Drain(context.Background()); err != nil {
+		t.Errorf("got error %v; want the queue drained", err)
+	}
+}
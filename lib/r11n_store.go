@@ -0,0 +1,183 @@
+package sous
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A StoredR11n is the durable record of a QueuedR11n.
+	StoredR11n struct {
+		ID           R11nID
+		DeploymentID DeploymentID
+		// Prior and Post are those of the DeployablePair being rectified:
+		// Prior is nil if the deployment is being created, and Post is nil if
+		// it is being deleted.
+		Prior, Post *Deployable
+		// ExecutorData is the JSON encoding of the pair's ExecutorData, or
+		// nil if it has none. Restored pairs carry it as a json.RawMessage,
+		// which Deployers decode for themselves.
+		ExecutorData json.RawMessage
+		// Resolution is nil until the rectification is resolved.
+		Resolution *DiffResolution
+		QueuedAt   time.Time
+		ResolvedAt time.Time
+	}
+
+	// An R11nStore durably records the rectifications queued by an
+	// R11nQueueSet, so that they survive server restarts.
+	R11nStore interface {
+		// Queued records that a rectification was queued.
+		Queued(StoredR11n) error
+		// Resolved records the resolution of a queued rectification.
+		Resolved(StoredR11n) error
+		// Load returns the rectifications of deployments to clusters that
+		// are still pending, or were resolved within the store's retention
+		// period, oldest first.
+		Load(clusters []string) ([]StoredR11n, error)
+	}
+)
+
+// R11nQueueStore makes an R11nQueue record its rectifications in store,
+// reporting any errors doing so to ls.
+func R11nQueueStore(store R11nStore, ls logging.LogSink) R11nQueueOpt {
+	return func(rq *R11nQueue) {
+		rq.store = store
+		rq.log = ls
+	}
+}
+
+// Restore adds the rectifications of deployments to clusters loaded from
+// store to the queues in rqs. Pending rectifications are queued again, in
+// their original order, under their original IDs, so that clients can
+// continue to wait for them. Call Restore before anything else is pushed to
+// rqs.
+func (rqs *R11nQueueSet) Restore(store R11nStore, clusters []string, ls logging.LogSink) error {
+	stored, err := store.Load(clusters)
+	if err != nil {
+		return errors.Wrapf(err, "loading queued rectifications")
+	}
+	for i, s := range stored {
+		if s.Resolution == nil && s.Prior == nil && s.Post == nil {
+			// There is no record of the deployment this rectification was
+			// to delete, so it cannot be resumed; the resolver will retry it.
+			s.Resolution = &DiffResolution{
+				DeploymentID: s.DeploymentID,
+				Desc:         DeleteDiff,
+				Error:        WrapResolveError(errors.New("interrupted by server restart")),
+			}
+			s.ResolvedAt = time.Now()
+			if err := store.Resolved(s); err != nil {
+				logging.ReportError(ls, errors.Wrapf(err, "recording interrupted rectification %s", s.ID))
+			}
+			stored[i] = s
+		}
+	}
+
+	rqs.Lock()
+	defer rqs.Unlock()
+	for _, s := range stored {
+		queue, ok := rqs.set[s.DeploymentID]
+		if !ok {
+			queue = NewR11nQueue(rqs.opts...)
+			rqs.set[s.DeploymentID] = queue
+		}
+		if !queue.restore(s, ls) {
+			logging.ReportError(ls, errors.Errorf("queue for %s is full, dropping rectification %s", s.DeploymentID, s.ID))
+		}
+	}
+	return nil
+}
+
+// restore adds the rectification recorded in s to rq, without storing it
+// again. It returns false if s is pending and rq is full.
+func (rq *R11nQueue) restore(s StoredR11n, ls logging.LogSink) bool {
+	pair := DeployablePair{Prior: s.Prior, Post: s.Post}
+	if len(s.ExecutorData) != 0 {
+		pair.ExecutorData = s.ExecutorData
+	}
+	r := NewRectification(pair, ls)
+	r.Pair.SetID(s.DeploymentID)
+	rq.Lock()
+	defer rq.Unlock()
+	if s.Resolution == nil {
+		if len(rq.queue) == rq.cap {
+			return false
+		}
+		qr := rq.pushWithID(s.ID, r)
+		close(qr.queued)
+		return true
+	}
+	r.Resolution = *s.Resolution
+	qr := &QueuedR11n{
+		ID:            s.ID,
+		Pos:           -1,
+		Rectification: r,
+		done:          make(chan struct{}),
+		queued:        make(chan struct{}),
+	}
+	close(qr.queued)
+	close(qr.done)
+	rq.remember(qr)
+	return true
+}
+
+// stored returns the durable record of qr.
+func (qr *QueuedR11n) stored() (StoredR11n, error) {
+	pair := qr.Rectification.Pair
+	s := StoredR11n{
+		ID:           qr.ID,
+		DeploymentID: pair.ID(),
+		Prior:        pair.Prior,
+		Post:         pair.Post,
+	}
+	if pair.ExecutorData != nil {
+		data, err := json.Marshal(pair.ExecutorData)
+		if err != nil {
+			return s, errors.Wrapf(err, "encoding executor data")
+		}
+		s.ExecutorData = data
+	}
+	return s, nil
+}
+
+// storeQueued records qr in rq's store, if it has one, then marks qr as
+// queued. It must be called without rq locked.
+func (rq *R11nQueue) storeQueued(qr *QueuedR11n) {
+	defer close(qr.queued)
+	if rq.store == nil {
+		return
+	}
+	s, err := qr.stored()
+	if err == nil {
+		s.QueuedAt = time.Now()
+		err = rq.store.Queued(s)
+	}
+	if err != nil {
+		logging.ReportError(rq.log, errors.Wrapf(err, "storing queued rectification %s", qr.ID))
+	}
+}
+
+// storeResolved records the resolution of qr in rq's store, if it has one,
+// once qr has been recorded as queued.
+func (rq *R11nQueue) storeResolved(qr *QueuedR11n) {
+	if rq.store == nil {
+		return
+	}
+	<-qr.queued
+	s, err := qr.stored()
+	if err == nil {
+		qr.Rectification.RLock()
+		rez := qr.Rectification.Resolution
+		qr.Rectification.RUnlock()
+		s.Resolution = &rez
+		s.ResolvedAt = time.Now()
+		err = rq.store.Resolved(s)
+	}
+	if err != nil {
+		logging.ReportError(rq.log, errors.Wrapf(err, "storing resolved rectification %s", qr.ID))
+	}
+}
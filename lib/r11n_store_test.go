@@ -0,0 +1,139 @@
+package sous
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryR11nStore struct {
+	sync.Mutex
+	queued, resolved, loaded []StoredR11n
+}
+
+func (s *memoryR11nStore) Queued(r StoredR11n) error {
+	s.Lock()
+	defer s.Unlock()
+	s.queued = append(s.queued, r)
+	return nil
+}
+
+func (s *memoryR11nStore) Resolved(r StoredR11n) error {
+	s.Lock()
+	defer s.Unlock()
+	s.resolved = append(s.resolved, r)
+	return nil
+}
+
+func (s *memoryR11nStore) Load(clusters []string) ([]StoredR11n, error) {
+	loaded := []StoredR11n{}
+	for _, r := range s.loaded {
+		for _, c := range clusters {
+			if r.DeploymentID.Cluster == c {
+				loaded = append(loaded, r)
+			}
+		}
+	}
+	return loaded, nil
+}
+
+func (s *memoryR11nStore) resolvedCount() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.resolved)
+}
+
+func storedHandler(desc ResolutionType) R11nQueueOpt {
+	return R11nQueueStartWithHandler(func(qr *QueuedR11n) DiffResolution {
+		return DiffResolution{DeploymentID: qr.Rectification.Pair.ID(), Desc: desc}
+	})
+}
+
+func TestR11nQueueStore(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	store := &memoryR11nStore{}
+	rqs := NewR11nQueueSet(storedHandler(CreateDiff), R11nQueueStore(store, ls))
+
+	qr, ok := rqs.Push(makeTestR11nWithRepo("one"))
+	require.True(t, ok)
+	_, ok = rqs.Wait(qr.Rectification.Pair.ID(), qr.ID)
+	require.True(t, ok)
+
+	require.Len(t, store.queued, 1)
+	assert.Equal(t, qr.ID, store.queued[0].ID)
+	assert.Equal(t, "one", store.queued[0].Post.SourceID.Location.Repo)
+	assert.Nil(t, store.queued[0].Resolution)
+
+	// The resolution is stored after Wait returns, so poll for it.
+	deadline := time.Now().Add(time.Second)
+	for store.resolvedCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	require.Equal(t, 1, store.resolvedCount())
+	require.NotNil(t, store.resolved[0].Resolution)
+	assert.Equal(t, CreateDiff, store.resolved[0].Resolution.Desc)
+}
+
+func TestR11nQueueSet_Restore(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	pair := makeTestR11nWithRepo("one").Pair
+	did := pair.ID()
+	did.Cluster = "cluster-1"
+	post := pair.Post
+	prior := &Deployable{Status: DeployStatusActive, Deployment: post.Deployment.Clone()}
+	prior.NumInstances = 3
+	otherCluster := did
+	otherCluster.Cluster = "cluster-2"
+	store := &memoryR11nStore{loaded: []StoredR11n{
+		{ID: "resolved", DeploymentID: did, Post: post, Resolution: &DiffResolution{DeploymentID: did, Desc: ModifyDiff}},
+		{ID: "pending", DeploymentID: did, Prior: prior, Post: post, ExecutorData: json.RawMessage(`{"RequestID":"req"}`)},
+		{ID: "deleting", DeploymentID: did, Prior: prior},
+		{ID: "unrecorded", DeploymentID: did},
+		{ID: "not-led", DeploymentID: otherCluster, Post: post},
+	}}
+	var handled []DeployablePair
+	var handledLock sync.Mutex
+	rqs := NewR11nQueueSet(R11nQueueStartWithHandler(func(qr *QueuedR11n) DiffResolution {
+		handledLock.Lock()
+		defer handledLock.Unlock()
+		handled = append(handled, qr.Rectification.Pair)
+		return DiffResolution{DeploymentID: qr.Rectification.Pair.ID(), Desc: CreateDiff}
+	}), R11nQueueStore(store, ls))
+	require.NoError(t, rqs.Restore(store, []string{"cluster-1"}, ls))
+
+	queue := rqs.Queues()[did]
+	require.NotNil(t, queue)
+	assert.Nil(t, rqs.Queues()[otherCluster])
+
+	resolved, ok := queue.ByID("resolved")
+	require.True(t, ok)
+	assert.Equal(t, -1, resolved.Pos)
+	assert.Equal(t, ModifyDiff, resolved.Rectification.Resolution.Desc)
+
+	rez, ok := rqs.Wait(did, "pending")
+	require.True(t, ok)
+	assert.Equal(t, CreateDiff, rez.Desc)
+	_, ok = rqs.Wait(did, "deleting")
+	require.True(t, ok)
+
+	handledLock.Lock()
+	require.Len(t, handled, 2)
+	assert.Equal(t, ModifiedKind, handled[0].Kind())
+	assert.Equal(t, 3, handled[0].Prior.NumInstances)
+	assert.Equal(t, DeployStatusActive, handled[0].Prior.Status)
+	assert.Equal(t, json.RawMessage(`{"RequestID":"req"}`), handled[0].ExecutorData)
+	assert.Equal(t, RemovedKind, handled[1].Kind())
+	handledLock.Unlock()
+
+	unrecorded, ok := queue.ByID("unrecorded")
+	require.True(t, ok)
+	assert.NotNil(t, unrecorded.Rectification.Resolution.Error)
+
+	// Restored rectifications are not stored again.
+	assert.Empty(t, store.queued)
+}
@@ -17,6 +17,8 @@ type Rectification struct {
 	// Resolution is the final resolution of this single rectification.
 	sync.RWMutex
 	Resolution DiffResolution
+	// rollout is the progress of a staged rollout, if this is one.
+	rollout *RolloutProgress
 
 	log    logging.LogSink
 	uuid   uuid.UUID
@@ -55,9 +57,22 @@ func (r *Rectification) Begin(d Deployer, reg Registry, rf *ResolveFilter, state
 	})
 }
 
+// RolloutProgress returns a copy of the progress of this rectification's
+// staged rollout, or nil if it is not a staged rollout.
+func (r *Rectification) RolloutProgress() *RolloutProgress {
+	r.RLock()
+	defer r.RUnlock()
+	if r.rollout == nil {
+		return nil
+	}
+	p := *r.rollout
+	return &p
+}
+
 func (r *Rectification) enact(d Deployer, reg Registry, rf *ResolveFilter, stateReader StateReader) {
 	defer r.cancel()
-	r.rectify(d, reg)
+	defer observeRectification(r, time.Now())
+	staged := r.rectify(d, reg)
 	if r.Resolution.Error != nil {
 		logging.Deliver(r.log,
 			logging.SousGenericV1,
@@ -68,17 +83,24 @@ func (r *Rectification) enact(d Deployer, reg Registry, rf *ResolveFilter, state
 		)
 		return
 	}
+	if staged != nil {
+		if !r.stepRollout(staged, reg, rf, stateReader) {
+			return
+		}
+	}
 	r.awaitDone(d, reg, rf, stateReader)
 }
 
-func (r *Rectification) rectify(d Deployer, reg Registry) {
+// rectify applies r.Pair using d, and returns the StagedDeployer that is
+// rolling it out, if it is a staged rollout.
+func (r *Rectification) rectify(d Deployer, reg Registry) StagedDeployer {
 	if r.Pair.Post.BuildArtifact == nil {
 		pair, diff := HandlePairsByRegistry(reg, &r.Pair, r.log)
 		if diff != nil && diff.Error != nil {
 			r.Lock()
 			r.Resolution.Error = WrapResolveError(diff.Error)
 			r.Unlock()
-			return
+			return nil
 		}
 		if pair != nil {
 			r.Pair = *pair
@@ -86,16 +108,39 @@ func (r *Rectification) rectify(d Deployer, reg Registry) {
 			r.Lock()
 			r.Resolution.Error = WrapResolveError(fmt.Errorf("Unknown Error Occurred, no resolve error and no pair present"))
 			r.Unlock()
-			return
+			return nil
 		}
 	}
 	r.Lock()
+	defer r.Unlock()
+	if sd, plan, ok := stagedRollout(d, &r.Pair); ok {
+		var staged bool
+		r.Resolution, staged = sd.BeginRollout(&r.Pair, plan[0])
+		if staged {
+			r.rollout = &RolloutProgress{
+				Step:            1,
+				Steps:           len(plan),
+				TargetInstances: plan[0],
+				Phase:           RolloutDeploying,
+			}
+			return sd
+		}
+		return nil
+	}
 	r.Resolution = d.Rectify(&r.Pair)
-	r.Unlock()
+	return nil
 }
 
-func (r *Rectification) awaitDone(d Deployer, reg Registry, rf *ResolveFilter, stateReader StateReader) {
+func (r *Rectification) clusters(rf *ResolveFilter, stateReader StateReader) (Clusters, error) {
 	state, err := stateReader.ReadState()
+	if err != nil {
+		return nil, err
+	}
+	return rf.FilteredClusters(state.Defs.Clusters), nil
+}
+
+func (r *Rectification) awaitDone(d Deployer, reg Registry, rf *ResolveFilter, stateReader StateReader) {
+	clusters, err := r.clusters(rf, stateReader)
 	if err != nil {
 		r.Lock()
 		r.Resolution.Error = WrapResolveError(err)
@@ -103,9 +148,6 @@ func (r *Rectification) awaitDone(d Deployer, reg Registry, rf *ResolveFilter, s
 		return
 	}
 
-	clusters := state.Defs.Clusters
-	clusters = rf.FilteredClusters(clusters)
-
 	// TODO constants / configs
 	tick := time.NewTicker(250 * time.Millisecond)
 	defer tick.Stop()
@@ -166,6 +208,111 @@ func (r *Rectification) awaitDone(d Deployer, reg Registry, rf *ResolveFilter, s
 
 }
 
+// rolloutPollInterval is how often the status of a staged rollout is checked.
+var rolloutPollInterval = time.Second
+
+// stepRollout advances a staged rollout through each step of its plan, only
+// proceeding from each step once it has been healthy for the soak period.
+// It returns false if the rollout was halted.
+func (r *Rectification) stepRollout(sd StagedDeployer, reg Registry, rf *ResolveFilter, stateReader StateReader) bool {
+	clusters, err := r.clusters(rf, stateReader)
+	if err != nil {
+		return r.haltRollout(sd, err)
+	}
+	rollout := r.Pair.Post.Deployment.Rollout
+	plan := rollout.Plan(r.Pair.Post.Deployment.NumInstances)
+
+	for i, target := range plan {
+		if i > 0 {
+			r.updateRollout(func(p *RolloutProgress) {
+				p.Step = i + 1
+				p.TargetInstances = target
+				p.Phase = RolloutDeploying
+			})
+			if err := sd.AdvanceRollout(&r.Pair, target); err != nil {
+				return r.haltRollout(sd, err)
+			}
+		}
+		deadline := time.Now().Add(rollout.StepTimeout())
+		if err := r.watchRollout(sd, reg, clusters, deadline, func(s *RolloutStepStatus) bool {
+			return s.StepComplete && s.ActiveInstances >= target
+		}); err != nil {
+			return r.haltRollout(sd, err)
+		}
+		if i == len(plan)-1 {
+			break
+		}
+		r.updateRollout(func(p *RolloutProgress) { p.Phase = RolloutSoaking })
+		soakEnd := time.Now().Add(rollout.Soak())
+		if err := r.watchRollout(sd, reg, clusters, soakEnd, func(*RolloutStepStatus) bool {
+			return !time.Now().Before(soakEnd)
+		}); err != nil {
+			return r.haltRollout(sd, err)
+		}
+	}
+	r.updateRollout(func(p *RolloutProgress) { p.Phase = RolloutComplete })
+	return true
+}
+
+// watchRollout polls the status of the rollout until done returns true. It
+// returns an error if the rollout fails, or deadline passes first.
+func (r *Rectification) watchRollout(sd StagedDeployer, reg Registry, clusters Clusters, deadline time.Time, done func(*RolloutStepStatus) bool) error {
+	tick := time.NewTicker(rolloutPollInterval)
+	defer tick.Stop()
+	for {
+		s, err := sd.RolloutStatus(reg, clusters, &r.Pair)
+		if err != nil {
+			return err
+		}
+		r.updateRollout(func(p *RolloutProgress) { p.ActiveInstances = s.ActiveInstances })
+		if s.Status == DeployStatusFailed {
+			return fmt.Errorf("deploy failed: %s", s.Message)
+		}
+		if done(s) {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("step did not become healthy in time (%d instances active)", s.ActiveInstances)
+		}
+		select {
+		case <-tick.C:
+		case <-r.ctx.Done():
+			return fmt.Errorf("rectification cancelled")
+		}
+	}
+}
+
+func (r *Rectification) updateRollout(f func(*RolloutProgress)) {
+	r.Lock()
+	defer r.Unlock()
+	f(r.rollout)
+}
+
+// haltRollout aborts the rollout, leaving the prior deployment in place, and
+// records err as the cause.
+func (r *Rectification) haltRollout(sd StagedDeployer, err error) bool {
+	if abortErr := sd.AbortRollout(&r.Pair); abortErr != nil {
+		err = fmt.Errorf("%s; also failed to abort: %s", err, abortErr)
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.rollout.Phase = RolloutHalted
+	r.rollout.Message = err.Error()
+	r.Resolution.Error = WrapResolveError(&RolloutHaltedError{
+		Step:  r.rollout.Step,
+		Steps: r.rollout.Steps,
+		Err:   err,
+	})
+	logging.Deliver(r.log,
+		logging.SousGenericV1,
+		logging.GetCallerInfo(logging.NotHere()),
+		logging.WarningLevel,
+		logging.ConsoleAndMessage(fmt.Sprintf("Rollout halted: %s", r.Resolution.Error)),
+		r.Pair,
+	)
+	return false
+}
+
 func (r *Rectification) pollOnce(d Deployer, reg Registry, clusters Clusters) (*DeployState, error) {
 	// XXX thread the context from Begin into Deployer.Status
 	depState, err := d.Status(reg, clusters, &r.Pair)
@@ -37,6 +37,8 @@ type (
 		finished chan struct{}
 		// err is the final error returned from a phase that ends the resolution.
 		err error
+		// subscribers are called with each diff resolution as it is logged.
+		subscribers []func(DiffResolution)
 		sync.RWMutex
 		logSink logging.LogSink
 	}
@@ -55,6 +57,10 @@ type (
 
 		// SchedulerURL is a URL where this deployment can be seen.
 		SchedulerURL string
+
+		// Rollback is set if the GDM was rolled back because this deploy
+		// failed.
+		Rollback *Rollback `json:",omitempty"`
 	}
 
 	// ResolutionType marks the kind of a DiffResolution
@@ -120,6 +126,9 @@ func NewResolveRecorder(intended Deployments, ls logging.LogSink, f func(*Resolv
 					rr.status.Errs.Causes = append(rr.status.Errs.Causes, ErrorWrapper{error: rez.Error})
 					messages.ReportLogFieldsMessage("resolve error", logging.DebugLevel, ls, rez.Error)
 				}
+				for _, f := range rr.subscribers {
+					f(rez)
+				}
 			})
 		}
 	}()
@@ -159,6 +168,18 @@ func (rr *ResolveRecorder) CurrentStatus() (rs ResolveStatus) {
 	return
 }
 
+// Subscribe calls f with each diff resolution logged by rr, starting with
+// those already logged. f is called with rr locked, so it must not block or
+// call back into rr.
+func (rr *ResolveRecorder) Subscribe(f func(DiffResolution)) {
+	rr.write(func() {
+		for _, rez := range rr.status.Log {
+			f(rez)
+		}
+		rr.subscribers = append(rr.subscribers, f)
+	})
+}
+
 // Done returns true if the resolution has finished. Otherwise it returns false.
 func (rr *ResolveRecorder) Done() bool {
 	select {
@@ -198,7 +219,10 @@ func (rr *ResolveRecorder) performPhase(name string, f func() error) {
 	}
 	logging.Debug(rr.logSink, "Performing phase", name)
 	rr.setPhase(name)
-	if err := f(); err != nil {
+	start := time.Now()
+	err := f()
+	resolvePhaseDuration.Observe(time.Since(start).Seconds(), name, outcome(err))
+	if err != nil {
 		rr.doneWithError(err)
 	}
 }
@@ -0,0 +1,136 @@
+package sous
+
+import (
+	"fmt"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+// RollbackUser is recorded as the author of GDM changes made by automatic
+// rollbacks.
+var RollbackUser = User{Name: "Sous Auto Rollback", Email: "sous-rollback@localhost"}
+
+type (
+	// Rollback records that the intended version of a deployment was set back
+	// to the last version that became active, after a deploy failed.
+	Rollback struct {
+		// From is the version whose deploy failed.
+		From semv.Version
+		// To is the last version that became active.
+		To semv.Version
+		// Reason describes why the deploy was considered failed.
+		Reason string
+	}
+
+	// A Rollbacker rolls failed deploys back in the GDM, for deployments whose
+	// manifest sets AutoRollback. Without this, the resolver would keep
+	// retrying a failed version every cycle.
+	Rollbacker struct {
+		StateManager
+		log logging.LogSink
+	}
+)
+
+func (rb Rollback) String() string {
+	return fmt.Sprintf("rolled back from %s to %s: %s", rb.From, rb.To, rb.Reason)
+}
+
+// NewRollbacker creates a Rollbacker which updates the GDM in sm.
+func NewRollbacker(sm StateManager, ls logging.LogSink) *Rollbacker {
+	return &Rollbacker{StateManager: sm, log: ls}
+}
+
+// HandleResolution rolls pair back if rez shows that its deploy failed, and
+// pair's manifest has AutoRollback set. It returns rez, with Rollback set if
+// a rollback was made.
+func (rb *Rollbacker) HandleResolution(pair *DeployablePair, rez DiffResolution) DiffResolution {
+	reason, failed := deployFailure(rez)
+	if !failed || pair.Post == nil || !pair.Post.AutoRollback {
+		return rez
+	}
+	to, ok := lastActiveVersion(pair)
+	if !ok {
+		logging.Deliver(rb.log,
+			logging.SousGenericV1,
+			logging.GetCallerInfo(logging.NotHere()),
+			logging.WarningLevel,
+			logging.MessageField(fmt.Sprintf("Not rolling back %s: no prior active version", pair.ID())),
+			pair,
+		)
+		return rez
+	}
+	rollback := &Rollback{
+		From:   pair.Post.SourceID.Version,
+		To:     to,
+		Reason: reason,
+	}
+	if err := rb.rollback(pair.ID(), rollback); err != nil {
+		logging.ReportError(rb.log, errors.Wrapf(err, "rolling back %s", pair.ID()))
+		return rez
+	}
+	rez.Rollback = rollback
+	logging.Deliver(rb.log,
+		logging.SousGenericV1,
+		logging.GetCallerInfo(logging.NotHere()),
+		logging.WarningLevel,
+		logging.ConsoleAndMessage(fmt.Sprintf("Deployment %s %s", pair.ID(), rollback)),
+		pair,
+	)
+	return rez
+}
+
+// rollback sets the intended version of did back to rollback.To, unless it
+// has been changed from rollback.From since the deploy began.
+func (rb *Rollbacker) rollback(did DeploymentID, rollback *Rollback) error {
+	state, err := rb.ReadState()
+	if err != nil {
+		return err
+	}
+	m, ok := state.Manifests.Get(did.ManifestID)
+	if !ok {
+		return errors.Errorf("no manifest %q", did.ManifestID)
+	}
+	spec, ok := m.Deployments[did.Cluster]
+	if !ok {
+		return errors.Errorf("manifest %q has no deployment to cluster %q", did.ManifestID, did.Cluster)
+	}
+	if !spec.Version.Equals(rollback.From) {
+		return errors.Errorf("intended version is now %s, not %s", spec.Version, rollback.From)
+	}
+	spec.Version = rollback.To
+	m.Deployments[did.Cluster] = spec
+	state.Manifests.Set(did.ManifestID, m)
+	return rb.WriteState(state, RollbackUser)
+}
+
+// deployFailure returns a description of why rez is a failed deploy, and
+// true, or false if it is not.
+func deployFailure(rez DiffResolution) (string, bool) {
+	if rez.Error != nil {
+		if _, halted := rez.Error.error.(*RolloutHaltedError); halted {
+			return rez.Error.Error(), true
+		}
+	}
+	if rez.DeployState == nil || rez.DeployState.Status != DeployStatusFailed {
+		return "", false
+	}
+	if rez.Error != nil {
+		return rez.Error.Error(), true
+	}
+	return "deploy failed", true
+}
+
+// lastActiveVersion returns the version that was active before pair was
+// rectified, if there was one and it differs from the intended version.
+func lastActiveVersion(pair *DeployablePair) (semv.Version, bool) {
+	if pair.Kind() != ModifiedKind || pair.Prior.Status != DeployStatusActive {
+		return semv.Version{}, false
+	}
+	prior := pair.Prior.SourceID.Version
+	if prior.Equals(pair.Post.SourceID.Version) {
+		return semv.Version{}, false
+	}
+	return prior, true
+}
@@ -0,0 +1,102 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rollbackFixture(autoRollback bool) (*DummyStateManager, *DeployablePair) {
+	m := &Manifest{
+		Source:       SourceLocation{Repo: "github.com/opentable/example"},
+		Kind:         ManifestKindService,
+		AutoRollback: autoRollback,
+		Deployments: DeploySpecs{
+			"cluster-1": {Version: semv.MustParse("2.0.0"), DeployConfig: DeployConfig{NumInstances: 1}},
+		},
+	}
+	sm := NewDummyStateManager()
+	sm.State.Manifests.Add(m)
+
+	deployment := func(version string) *Deployment {
+		return &Deployment{
+			ClusterName:  "cluster-1",
+			Cluster:      &Cluster{Name: "cluster-1"},
+			SourceID:     m.Source.SourceID(semv.MustParse(version)),
+			Kind:         ManifestKindService,
+			DeployConfig: DeployConfig{NumInstances: 1},
+			AutoRollback: autoRollback,
+		}
+	}
+	pair := &DeployablePair{
+		Prior: &Deployable{Deployment: deployment("1.0.0"), Status: DeployStatusActive},
+		Post:  &Deployable{Deployment: deployment("2.0.0")},
+	}
+	pair.SetID(pair.Post.ID())
+	return sm, pair
+}
+
+func failedResolution() DiffResolution {
+	return DiffResolution{
+		Desc:        ModifyDiff,
+		DeployState: &DeployState{Status: DeployStatusFailed},
+		Error:       WrapResolveError(&FailedStatusError{}),
+	}
+}
+
+func intendedVersion(t *testing.T, sm *DummyStateManager, pair *DeployablePair) string {
+	m, ok := sm.State.Manifests.Get(pair.ID().ManifestID)
+	require.True(t, ok)
+	return m.Deployments["cluster-1"].Version.String()
+}
+
+func TestRollbacker_HandleResolution(t *testing.T) {
+	sm, pair := rollbackFixture(true)
+	ls, _ := logging.NewLogSinkSpy()
+	rb := NewRollbacker(sm, ls)
+
+	rez := rb.HandleResolution(pair, failedResolution())
+
+	require.NotNil(t, rez.Rollback)
+	assert.Equal(t, "2.0.0", rez.Rollback.From.String())
+	assert.Equal(t, "1.0.0", rez.Rollback.To.String())
+	assert.Equal(t, "1.0.0", intendedVersion(t, sm, pair))
+	assert.Equal(t, 1, sm.WriteCount)
+}
+
+func TestRollbacker_HandleResolution_notOptedIn(t *testing.T) {
+	sm, pair := rollbackFixture(false)
+	ls, _ := logging.NewLogSinkSpy()
+
+	rez := NewRollbacker(sm, ls).HandleResolution(pair, failedResolution())
+
+	assert.Nil(t, rez.Rollback)
+	assert.Equal(t, "2.0.0", intendedVersion(t, sm, pair))
+}
+
+func TestRollbacker_HandleResolution_succeeded(t *testing.T) {
+	sm, pair := rollbackFixture(true)
+	ls, _ := logging.NewLogSinkSpy()
+
+	rez := NewRollbacker(sm, ls).HandleResolution(pair, DiffResolution{
+		Desc:        ModifyDiff,
+		DeployState: &DeployState{Status: DeployStatusActive},
+	})
+
+	assert.Nil(t, rez.Rollback)
+	assert.Equal(t, "2.0.0", intendedVersion(t, sm, pair))
+}
+
+func TestRollbacker_HandleResolution_noActivePrior(t *testing.T) {
+	sm, pair := rollbackFixture(true)
+	pair.Prior.Status = DeployStatusFailed
+	ls, _ := logging.NewLogSinkSpy()
+
+	rez := NewRollbacker(sm, ls).HandleResolution(pair, failedResolution())
+
+	assert.Nil(t, rez.Rollback)
+	assert.Equal(t, "2.0.0", intendedVersion(t, sm, pair))
+}
@@ -0,0 +1,247 @@
+package sous
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultRolloutStepTimeout is how long each step of a staged rollout may
+// take to become healthy, if Rollout.StepTimeoutSeconds is not set.
+const DefaultRolloutStepTimeout = 10 * time.Minute
+
+type (
+	// Rollout describes a staged rollout, in which a new version of a
+	// deployment first replaces only a few canary instances, then
+	// progressively more, with each step soaking for a while and passing a
+	// health gate before the next begins.
+	//
+	// Rollout describes how a change is applied rather than what is running,
+	// so it is not compared by DeployConfig.Diff.
+	Rollout struct {
+		// CanaryInstances is the number of instances in the first step.
+		CanaryInstances int `yaml:",omitempty"`
+		// StepPercents are percentages of NumInstances to proceed to after the
+		// canary step, in increasing order. A final step of 100% is implied.
+		StepPercents []int `yaml:",omitempty"`
+		// SoakSeconds is how long each step must remain healthy before the
+		// next begins.
+		SoakSeconds int `yaml:",omitempty"`
+		// StepTimeoutSeconds is how long each step may take to become
+		// healthy before the rollout is halted. If zero,
+		// DefaultRolloutStepTimeout is used.
+		StepTimeoutSeconds int `yaml:",omitempty"`
+	}
+
+	// RolloutPhase describes what a staged rollout is currently doing.
+	RolloutPhase string
+
+	// RolloutProgress reports the progress of a staged rollout.
+	RolloutProgress struct {
+		// Step is the 1-based index of the current step.
+		Step int
+		// Steps is the total number of steps.
+		Steps int
+		// TargetInstances is the number of new instances the current step
+		// brings up.
+		TargetInstances int
+		// ActiveInstances is the number of new instances last seen active.
+		ActiveInstances int
+		// Phase is what the current step is doing.
+		Phase RolloutPhase
+		// Message describes why a rollout halted, if it did.
+		Message string `json:",omitempty"`
+	}
+
+	// RolloutStepStatus is reported by a StagedDeployer about the current step
+	// of a rollout.
+	RolloutStepStatus struct {
+		// Status is DeployStatusFailed if the rollout has failed.
+		Status DeployStatus
+		// ActiveInstances is the number of new instances active.
+		ActiveInstances int
+		// StepComplete is true when all instances of the current step are
+		// healthy.
+		StepComplete bool
+		// Message is any message from the scheduler.
+		Message string
+	}
+
+	// RolloutHaltedError reports that a staged rollout was halted because a
+	// step failed its health gate.
+	RolloutHaltedError struct {
+		Step, Steps int
+		Err         error
+	}
+
+	// StagedDeployer is implemented by Deployers which can roll out changes
+	// in steps.
+	StagedDeployer interface {
+		// BeginRollout starts rectifying pair, bringing up no more than
+		// instances new instances until AdvanceRollout is called. If the
+		// change cannot be staged, it is applied in full and staged is false.
+		BeginRollout(pair *DeployablePair, instances int) (resolution DiffResolution, staged bool)
+		// AdvanceRollout allows the rollout of pair to proceed to instances
+		// new instances.
+		AdvanceRollout(pair *DeployablePair, instances int) error
+		// AbortRollout cancels the rollout of pair, leaving the prior
+		// deployment running.
+		AbortRollout(pair *DeployablePair) error
+		// RolloutStatus reports on the current step of the rollout of pair.
+		RolloutStatus(reg Registry, clusters Clusters, pair *DeployablePair) (*RolloutStepStatus, error)
+	}
+)
+
+const (
+	// RolloutDeploying means the current step's instances are starting.
+	RolloutDeploying RolloutPhase = "deploying"
+	// RolloutSoaking means the current step is healthy, and is being watched
+	// for SoakSeconds before the next step.
+	RolloutSoaking RolloutPhase = "soaking"
+	// RolloutComplete means every step has been deployed.
+	RolloutComplete RolloutPhase = "complete"
+	// RolloutHalted means a step failed its health gate, and the rollout was
+	// aborted.
+	RolloutHalted RolloutPhase = "halted"
+)
+
+// IsZero returns true if r does not describe a staged rollout.
+func (r Rollout) IsZero() bool {
+	return r.CanaryInstances == 0 && len(r.StepPercents) == 0 &&
+		r.SoakSeconds == 0 && r.StepTimeoutSeconds == 0
+}
+
+// Clone returns a deep copy of r.
+func (r Rollout) Clone() Rollout {
+	if r.StepPercents != nil {
+		r.StepPercents = append([]int{}, r.StepPercents...)
+	}
+	return r
+}
+
+// Diff returns the differences between r and o.
+func (r Rollout) Diff(o Rollout) []string {
+	var diffs []string
+	diff := func(format string, a ...interface{}) { diffs = append(diffs, fmt.Sprintf(format, a...)) }
+	if r.CanaryInstances != o.CanaryInstances {
+		diff("rollout canary instances; this: %d; other: %d", r.CanaryInstances, o.CanaryInstances)
+	}
+	if fmt.Sprint(r.StepPercents) != fmt.Sprint(o.StepPercents) {
+		diff("rollout step percents; this: %v; other: %v", r.StepPercents, o.StepPercents)
+	}
+	if r.SoakSeconds != o.SoakSeconds {
+		diff("rollout soak seconds; this: %d; other: %d", r.SoakSeconds, o.SoakSeconds)
+	}
+	if r.StepTimeoutSeconds != o.StepTimeoutSeconds {
+		diff("rollout step timeout seconds; this: %d; other: %d", r.StepTimeoutSeconds, o.StepTimeoutSeconds)
+	}
+	return diffs
+}
+
+// Validate implements Flawed for Rollout.
+func (r *Rollout) Validate() []Flaw {
+	var flaws []Flaw
+	if r.CanaryInstances < 0 {
+		flaws = append(flaws, FatalFlaw("Rollout.CanaryInstances must not be negative, was %d", r.CanaryInstances))
+	}
+	last := 0
+	for _, p := range r.StepPercents {
+		if p <= last || p > 100 {
+			flaws = append(flaws, FatalFlaw("Rollout.StepPercents must be increasing percentages between 1 and 100, were %v", r.StepPercents))
+			break
+		}
+		last = p
+	}
+	if r.SoakSeconds < 0 {
+		flaws = append(flaws, FatalFlaw("Rollout.SoakSeconds must not be negative, was %d", r.SoakSeconds))
+	}
+	if r.StepTimeoutSeconds < 0 {
+		flaws = append(flaws, FatalFlaw("Rollout.StepTimeoutSeconds must not be negative, was %d", r.StepTimeoutSeconds))
+	}
+	return flaws
+}
+
+// Plan returns the number of new instances brought up by each step of a
+// rollout of numInstances instances. A plan of fewer than two steps means the
+// rollout is not staged.
+func (r Rollout) Plan(numInstances int) []int {
+	if numInstances < 1 {
+		return nil
+	}
+	var steps []int
+	add := func(n int) {
+		if n > numInstances {
+			n = numInstances
+		}
+		if n < 1 {
+			n = 1
+		}
+		if len(steps) == 0 || n > steps[len(steps)-1] {
+			steps = append(steps, n)
+		}
+	}
+	if r.CanaryInstances > 0 {
+		add(r.CanaryInstances)
+	}
+	for _, p := range r.StepPercents {
+		add((p*numInstances + 99) / 100)
+	}
+	add(numInstances)
+	return steps
+}
+
+// StepTimeout returns how long each step may take to become healthy.
+func (r Rollout) StepTimeout() time.Duration {
+	if r.StepTimeoutSeconds == 0 {
+		return DefaultRolloutStepTimeout
+	}
+	return time.Duration(r.StepTimeoutSeconds) * time.Second
+}
+
+// Soak returns how long each step must remain healthy.
+func (r Rollout) Soak() time.Duration {
+	return time.Duration(r.SoakSeconds) * time.Second
+}
+
+func (e *RolloutHaltedError) Error() string {
+	return fmt.Sprintf("rollout halted at step %d/%d: %s", e.Step, e.Steps, e.Err)
+}
+
+func (rp RolloutProgress) String() string {
+	s := fmt.Sprintf("rollout step %d/%d (%d instances): %s, %d active",
+		rp.Step, rp.Steps, rp.TargetInstances, rp.Phase, rp.ActiveInstances)
+	if rp.Message != "" {
+		s += ": " + rp.Message
+	}
+	return s
+}
+
+// stagedDeployerFor returns the StagedDeployer that would rectify pair, if
+// there is one.
+func stagedDeployerFor(d Deployer, pair *DeployablePair) (StagedDeployer, bool) {
+	if dr, is := d.(*DeployerRegistry); is {
+		backend, err := dr.pairBackend(pair)
+		if err != nil {
+			return nil, false
+		}
+		d = backend
+	}
+	sd, is := d.(StagedDeployer)
+	return sd, is
+}
+
+// stagedRollout returns the StagedDeployer and plan for rolling pair out in
+// steps, and false if pair should be rectified all at once.
+func stagedRollout(d Deployer, pair *DeployablePair) (StagedDeployer, []int, bool) {
+	if pair.Kind() != ModifiedKind {
+		return nil, nil, false
+	}
+	plan := pair.Post.Deployment.Rollout.Plan(pair.Post.Deployment.NumInstances)
+	if len(plan) < 2 {
+		return nil, nil, false
+	}
+	sd, ok := stagedDeployerFor(d, pair)
+	if !ok {
+		return nil, nil, false
+	}
+	return sd, plan, true
+}
@@ -0,0 +1,150 @@
+package sous
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nyarly/spies"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollout_Plan(t *testing.T) {
+	cases := []struct {
+		rollout   Rollout
+		instances int
+		want      []int
+	}{
+		{Rollout{}, 4, []int{4}},
+		{Rollout{CanaryInstances: 1}, 4, []int{1, 4}},
+		{Rollout{CanaryInstances: 1, StepPercents: []int{50}}, 4, []int{1, 2, 4}},
+		{Rollout{CanaryInstances: 1, StepPercents: []int{10, 50, 100}}, 5, []int{1, 3, 5}},
+		{Rollout{StepPercents: []int{25}}, 10, []int{3, 10}},
+		{Rollout{CanaryInstances: 8}, 4, []int{4}},
+		{Rollout{CanaryInstances: 1}, 0, nil},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, c.rollout.Plan(c.instances), "%+v of %d", c.rollout, c.instances)
+	}
+}
+
+func TestRollout_Validate(t *testing.T) {
+	r := Rollout{CanaryInstances: 1, StepPercents: []int{25, 50}, SoakSeconds: 60}
+	assert.Empty(t, r.Validate())
+
+	r = Rollout{CanaryInstances: -1, StepPercents: []int{50, 25}, SoakSeconds: -1, StepTimeoutSeconds: -1}
+	assert.Len(t, r.Validate(), 4)
+
+	r = Rollout{StepPercents: []int{150}}
+	assert.Len(t, r.Validate(), 1)
+}
+
+type fakeStagedDeployer struct {
+	Deployer
+	sync.Mutex
+	target   int
+	failAt   int
+	began    []int
+	advanced []int
+	aborted  bool
+}
+
+func (f *fakeStagedDeployer) BeginRollout(pair *DeployablePair, instances int) (DiffResolution, bool) {
+	f.Lock()
+	defer f.Unlock()
+	f.began = append(f.began, instances)
+	f.target = instances
+	return DiffResolution{DeploymentID: pair.ID(), Desc: ModifyDiff}, true
+}
+
+func (f *fakeStagedDeployer) AdvanceRollout(pair *DeployablePair, instances int) error {
+	f.Lock()
+	defer f.Unlock()
+	f.advanced = append(f.advanced, instances)
+	f.target = instances
+	return nil
+}
+
+func (f *fakeStagedDeployer) AbortRollout(pair *DeployablePair) error {
+	f.Lock()
+	defer f.Unlock()
+	f.aborted = true
+	return nil
+}
+
+func (f *fakeStagedDeployer) RolloutStatus(reg Registry, clusters Clusters, pair *DeployablePair) (*RolloutStepStatus, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.target == f.failAt {
+		return &RolloutStepStatus{Status: DeployStatusFailed, Message: "unhealthy"}, nil
+	}
+	return &RolloutStepStatus{Status: DeployStatusPending, ActiveInstances: f.target, StepComplete: true}, nil
+}
+
+func stagedRectification(t *testing.T, failAt int) (*Rectification, *fakeStagedDeployer) {
+	defer func(i time.Duration) { rolloutPollInterval = i }(rolloutPollInterval)
+	rolloutPollInterval = time.Millisecond
+
+	prior := &Deployment{DeployConfig: DeployConfig{NumInstances: 2}}
+	post := prior.Clone()
+	post.NumInstances = 4
+	post.Rollout = Rollout{CanaryInstances: 1, StepPercents: []int{50}}
+
+	log, _ := logging.NewLogSinkSpy()
+	r := NewRectification(DeployablePair{
+		Prior: &Deployable{Deployment: prior},
+		Post:  &Deployable{Deployment: post, BuildArtifact: &BuildArtifact{}},
+	}, log)
+
+	spy, c := NewDeployerSpy()
+	c.MatchMethod("Status", spies.AnyArgs, &DeployState{Status: DeployStatusActive}, nil)
+	fake := &fakeStagedDeployer{Deployer: spy, failAt: failAt}
+
+	r.Begin(fake, &DummyRegistry{}, &ResolveFilter{}, NewDummyStateManager())
+	done := make(chan struct{})
+	go func() {
+		r.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("rollout did not complete")
+	}
+	return r, fake
+}
+
+func TestRectification_stagedRollout(t *testing.T) {
+	r, fake := stagedRectification(t, -1)
+
+	require.Nil(t, r.Resolution.Error)
+	assert.Equal(t, []int{1}, fake.began)
+	assert.Equal(t, []int{2, 4}, fake.advanced)
+	assert.False(t, fake.aborted)
+
+	progress := r.RolloutProgress()
+	require.NotNil(t, progress)
+	assert.Equal(t, RolloutProgress{
+		Step:            3,
+		Steps:           3,
+		TargetInstances: 4,
+		ActiveInstances: 4,
+		Phase:           RolloutComplete,
+	}, *progress)
+}
+
+func TestRectification_stagedRollout_halts(t *testing.T) {
+	r, fake := stagedRectification(t, 2)
+
+	require.NotNil(t, r.Resolution.Error)
+	assert.True(t, strings.Contains(r.Resolution.Error.Error(), "rollout halted at step 2/3"), r.Resolution.Error.Error())
+	assert.Equal(t, []int{2}, fake.advanced)
+	assert.True(t, fake.aborted)
+
+	progress := r.RolloutProgress()
+	require.NotNil(t, progress)
+	assert.Equal(t, RolloutHalted, progress.Phase)
+}
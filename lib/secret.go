@@ -0,0 +1,120 @@
+package sous
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A SecretRef refers to a secret held by a SecretProvider. Env values of
+	// the form "secret://path#key" are references to secrets, which are
+	// resolved only when a deployment is rectified, so that their values are
+	// never stored in the GDM.
+	SecretRef struct {
+		// Path identifies the secret, e.g. a file or a Vault path.
+		Path string
+		// Key selects one value of a secret that holds several. It may be
+		// empty if the secret is a single value.
+		Key string
+	}
+
+	// A SecretProvider resolves references to secrets.
+	SecretProvider interface {
+		// Secret returns the value of the secret ref refers to.
+		Secret(ref SecretRef) (string, error)
+	}
+)
+
+// SecretScheme prefixes Env values that are references to secrets.
+const SecretScheme = "secret://"
+
+// SecretEnvLabel prefixes the names of deploy metadata that record which
+// environment variables were resolved from secrets, and from which. The
+// variable name follows the prefix, and the value is the reference.
+const SecretEnvLabel = "com.opentable.sous.secret."
+
+// IsSecretRef returns true if value is a reference to a secret.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretScheme)
+}
+
+// ParseSecretRef parses a reference to a secret of the form
+// "secret://path#key", where "#key" is optional.
+func ParseSecretRef(value string) (SecretRef, error) {
+	if !IsSecretRef(value) {
+		return SecretRef{}, errors.Errorf("%q is not a secret reference: it does not begin with %s", value, SecretScheme)
+	}
+	ref := SecretRef{Path: strings.TrimPrefix(value, SecretScheme)}
+	if i := strings.LastIndex(ref.Path, "#"); i >= 0 {
+		ref.Path, ref.Key = ref.Path[:i], ref.Path[i+1:]
+	}
+	if ref.Path == "" {
+		return SecretRef{}, errors.Errorf("secret reference %q has no path", value)
+	}
+	return ref, nil
+}
+
+func (ref SecretRef) String() string {
+	if ref.Key == "" {
+		return SecretScheme + ref.Path
+	}
+	return SecretScheme + ref.Path + "#" + ref.Key
+}
+
+// SecretRefs returns the variables in e whose values are references to
+// secrets.
+func (e Env) SecretRefs() Env {
+	refs := Env{}
+	for name, value := range e {
+		if IsSecretRef(value) {
+			refs[name] = value
+		}
+	}
+	return refs
+}
+
+// ResolveSecrets returns a copy of e with every reference to a secret
+// replaced by its value from p. The error returned names the variables that
+// could not be resolved, but never their values.
+func (e Env) ResolveSecrets(p SecretProvider) (Env, error) {
+	refs := e.SecretRefs()
+	if len(refs) == 0 {
+		return e, nil
+	}
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if p == nil {
+		return nil, errors.Errorf("no secret provider is configured to resolve %s", strings.Join(names, ", "))
+	}
+
+	resolved := e.Clone()
+	for _, name := range names {
+		ref, err := ParseSecretRef(refs[name])
+		if err != nil {
+			return nil, errors.Wrapf(err, "env %s", name)
+		}
+		value, err := p.Secret(ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving %s for env %s", ref, name)
+		}
+		resolved[name] = value
+	}
+	return resolved, nil
+}
+
+// validateSecretRefs returns a flaw for each malformed reference to a secret
+// in e.
+func (e Env) validateSecretRefs() []Flaw {
+	var flaws []Flaw
+	for name, value := range e.SecretRefs() {
+		if _, err := ParseSecretRef(value); err != nil {
+			flaws = append(flaws, FatalFlaw("env %s: %s", name, err))
+		}
+	}
+	return flaws
+}
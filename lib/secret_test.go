@@ -0,0 +1,86 @@
+package sous
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type mapSecrets map[string]string
+
+func (m mapSecrets) Secret(ref SecretRef) (string, error) {
+	v, ok := m[ref.String()]
+	if !ok {
+		return "", errors.Errorf("no secret %s", ref)
+	}
+	return v, nil
+}
+
+func TestParseSecretRef(t *testing.T) {
+	good := map[string]SecretRef{
+		"secret://db/prod":           {Path: "db/prod"},
+		"secret://db/prod#password":  {Path: "db/prod", Key: "password"},
+		"secret://a#b#c":             {Path: "a#b", Key: "c"},
+		"secret://kv/data/app#token": {Path: "kv/data/app", Key: "token"},
+	}
+	for in, want := range good {
+		got, err := ParseSecretRef(in)
+		if err != nil {
+			t.Errorf("ParseSecretRef(%q): %s", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseSecretRef(%q) = %#v; want %#v", in, got, want)
+		}
+		if got.String() != in {
+			t.Errorf("%#v.String() = %q; want %q", got, got.String(), in)
+		}
+	}
+	for _, in := range []string{"plain", "secret://", "secret://#key"} {
+		if _, err := ParseSecretRef(in); err == nil {
+			t.Errorf("ParseSecretRef(%q) did not return an error", in)
+		}
+	}
+}
+
+func TestEnv_ResolveSecrets(t *testing.T) {
+	env := Env{"PLAIN": "value", "PASSWORD": "secret://db#password"}
+
+	resolved, err := env.ResolveSecrets(mapSecrets{"secret://db#password": "hunter2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resolved.Equal(Env{"PLAIN": "value", "PASSWORD": "hunter2"}) {
+		t.Errorf("got %v", resolved)
+	}
+	if env["PASSWORD"] != "secret://db#password" {
+		t.Errorf("resolving secrets changed the original Env: %v", env)
+	}
+
+	_, err = env.ResolveSecrets(nil)
+	if err == nil || !strings.Contains(err.Error(), "PASSWORD") {
+		t.Errorf("got error %v; want one naming PASSWORD", err)
+	}
+	_, err = env.ResolveSecrets(mapSecrets{})
+	if err == nil || !strings.Contains(err.Error(), "PASSWORD") {
+		t.Errorf("got error %v; want one naming PASSWORD", err)
+	}
+
+	plain := Env{"PLAIN": "value"}
+	if got, err := plain.ResolveSecrets(nil); err != nil || !got.Equal(plain) {
+		t.Errorf("got %v, %v; want %v, nil", got, err, plain)
+	}
+}
+
+func TestDeployConfig_Validate_secretRefs(t *testing.T) {
+	dc := DeployConfig{
+		Resources: Resources{"cpus": "1", "memory": "100", "ports": "1"},
+		Startup:   Startup{SkipCheck: true},
+		Env:       Env{"GOOD": "secret://db#password", "BAD": "secret://#password"},
+	}
+	flaws := dc.Validate()
+	if len(flaws) != 1 {
+		t.Fatalf("got flaws %v; want 1", flaws)
+	}
+}
@@ -8,6 +8,11 @@ import (
 
 // Startup is the configuration for startup checks for a service deployment.
 // c.f. DeployConfig for use.
+//
+// CheckReadyProtocol selects the kind of check: HTTP and HTTPS checks request
+// CheckReadyURIPath, TCP checks connect to the port at CheckReadyPortIndex,
+// and COMMAND checks run CheckReadyCommand in the container, passing when it
+// exits 0.
 type Startup struct { //                             Singularity fields
 	SkipCheck bool `yaml:",omitempty"`
 
@@ -23,6 +28,9 @@ type Startup struct { //                             Singularity fields
 	CheckReadyInterval        int    `yaml:",omitempty"` // Healthcheck.IntervalSeconds
 	CheckReadyRetries         int    `yaml:",omitempty"` // Healthcheck.MaxRetries
 
+	// CheckReadyCommand is the command and arguments run by COMMAND checks.
+	CheckReadyCommand []string `yaml:",omitempty"`
+
 	// ??? We don't deploy fixed port services...
 	// ??? CheckReadyPortNumber int    `yaml:",omitempty"` // Healthcheck.PortNumber
 
@@ -61,14 +69,22 @@ func (s *Startup) Validate() []Flaw {
 
 		switch s.CheckReadyProtocol {
 		default:
-			flaws = append(flaws, FatalFlaw("CheckReadyProtocol must be HTTP or HTTPS, was %q.", s.CheckReadyProtocol))
-		case "https", "http":
-			flaws = append(flaws, NewFlaw(fmt.Sprintf("CheckReadyProtocol must be HTTP or HTTPS, was %q (lowercase).", s.CheckReadyProtocol),
+			flaws = append(flaws, FatalFlaw("CheckReadyProtocol must be HTTP, HTTPS, TCP or COMMAND, was %q.", s.CheckReadyProtocol))
+		case "https", "http", "tcp", "command":
+			flaws = append(flaws, NewFlaw(fmt.Sprintf("CheckReadyProtocol must be HTTP, HTTPS, TCP or COMMAND, was %q (lowercase).", s.CheckReadyProtocol),
 				func() error {
 					s.CheckReadyProtocol = strings.ToUpper(s.CheckReadyProtocol)
 					return nil
 				}))
-		case "HTTPS", "HTTP":
+		case "HTTPS", "HTTP", "TCP", "COMMAND":
+		}
+
+		if strings.ToUpper(s.CheckReadyProtocol) == "COMMAND" {
+			if len(s.CheckReadyCommand) == 0 || s.CheckReadyCommand[0] == "" {
+				flaws = append(flaws, FatalFlaw("CheckReadyCommand must be set for COMMAND checks."))
+			}
+		} else if len(s.CheckReadyCommand) > 0 {
+			flaws = append(flaws, FatalFlaw("CheckReadyCommand is only used by COMMAND checks, CheckReadyProtocol was %q.", s.CheckReadyProtocol))
 		}
 
 		for _, status := range s.CheckReadyFailureStatuses {
@@ -130,6 +146,10 @@ func (s Startup) MergeDefaults(base Startup) Startup {
 		n.CheckReadyRetries = s.CheckReadyRetries
 	}
 
+	if len(n.CheckReadyCommand) == len(zeroStartup.CheckReadyCommand) {
+		n.CheckReadyCommand = s.CheckReadyCommand
+	}
+
 	return n
 }
 
@@ -203,6 +223,11 @@ func (s Startup) UnmergeDefaults(base, old Startup) Startup {
 		n.CheckReadyRetries = zeroStartup.CheckReadyRetries
 	}
 
+	if stringSlicesEqual(base.CheckReadyCommand, s.CheckReadyCommand) &&
+		len(old.CheckReadyCommand) == len(zeroStartup.CheckReadyCommand) {
+		n.CheckReadyCommand = zeroStartup.CheckReadyCommand
+	}
+
 	return n
 }
 
@@ -279,5 +304,9 @@ func (s Startup) diff(o Startup) []string {
 		diff("CheckReadyURITimeout; this %d, other %d", l.CheckReadyURITimeout, r.CheckReadyURITimeout)
 	}
 
+	if !stringSlicesEqual(l.CheckReadyCommand, r.CheckReadyCommand) {
+		diff("CheckReadyCommand; this %q, other %q", l.CheckReadyCommand, r.CheckReadyCommand)
+	}
+
 	return diffs
 }
@@ -57,6 +57,12 @@ func (s *StartupTest) TestPutGet() {
 		Startup{CheckReadyURIPath: "/health", CheckReadyURITimeout: 100, Timeout: 10},
 		Startup{SkipCheck: true},
 	)
+
+	s.PutGet(
+		Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"grpc-health-probe"}},
+		Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"grpc-health-probe", "-addr=:9000"}},
+		Startup{}, //zero base
+	)
 }
 
 func (s *StartupTest) GetPut(defaults, base Startup) {
@@ -84,6 +90,11 @@ func (s *StartupTest) TestGetPut() {
 		Startup{CheckReadyFailureStatuses: []int{410, 503}},
 	)
 
+	s.GetPut(
+		Startup{CheckReadyProtocol: "HTTP", CheckReadyURIPath: "/health"},
+		Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"check"}},
+	)
+
 	s.GetPut(
 		Startup{
 			ConnectDelay:    234,
@@ -136,6 +147,26 @@ func (s *StartupTest) TestMerge() {
 
 }
 
+func (s *StartupTest) TestValidate() {
+	flaws := func(st Startup) []Flaw { return st.Validate() }
+
+	s.Empty(flaws(Startup{CheckReadyProtocol: "HTTP", CheckReadyURIPath: "/health"}))
+	s.Empty(flaws(Startup{CheckReadyProtocol: "TCP", CheckReadyPortIndex: 1}))
+	s.Empty(flaws(Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"/bin/check", "-q"}}))
+	s.Empty(flaws(Startup{SkipCheck: true, CheckReadyProtocol: "UDP"}))
+
+	s.Len(flaws(Startup{CheckReadyProtocol: "UDP"}), 1)
+	s.Len(flaws(Startup{CheckReadyProtocol: "COMMAND"}), 1, "COMMAND checks need a command")
+	s.Len(flaws(Startup{CheckReadyProtocol: "TCP", CheckReadyCommand: []string{"check"}}), 1, "only COMMAND checks use a command")
+
+	st := Startup{CheckReadyProtocol: "tcp"}
+	fs := st.Validate()
+	s.Len(fs, 1)
+	_, errs := RepairAll(fs)
+	s.Empty(errs)
+	s.Equal("TCP", st.CheckReadyProtocol)
+}
+
 // PutPut is guaranteed by the instance receiver
 
 func TestStartup_diff(t *testing.T) {
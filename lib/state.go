@@ -35,14 +35,34 @@ type (
 		Resources FieldDefinitions
 		// Metadata contains the definitions for metadata fields
 		Metadata FieldDefinitions
+		// Admins lists the email addresses or names of the users who may
+		// change any manifest, and the definitions themselves, when the
+		// server authenticates its clients.
+		Admins []string `yaml:",omitempty"`
+		// Notifications routes notifications about deployments to the
+		// people and systems interested in them.
+		Notifications NotificationRoutes `yaml:",omitempty"`
+		// Quotas limit the total resources of the deployments of each owner.
+		// Changes to the GDM which would exceed them are rejected.
+		Quotas Quotas `yaml:",omitempty"`
 	}
 
 	// EnvDefs is a collection of EnvDef
 	EnvDefs []EnvDef
-	// EnvDef is an environment variable definition.
+	// EnvDef is an environment variable definition. The Env of every
+	// deployment, and of every cluster, is validated against them.
 	EnvDef struct {
-		Name, Desc, Scope string
-		Type              VarType
+		Name, Desc string
+		// Scope is where the variable may be set: ScopeCluster, ScopeDeployment,
+		// or anywhere if it is empty.
+		Scope string
+		// Type is the type values of the variable must have.
+		Type VarType
+		// Values lists the values allowed for VarTypeEnum variables.
+		Values []string `yaml:",omitempty"`
+		// Required variables must be set for every deployment, either by the
+		// deployment or by its cluster.
+		Required bool `yaml:",omitempty"`
 	}
 
 	// FieldDefinitions is just a type alias for a slice of FieldDefinition-s
@@ -71,8 +91,8 @@ type (
 	Cluster struct {
 		// Name is the unique name of this cluster.
 		Name string
-		// Kind is the kind of cluster. Currently the only legal value is
-		// "singularity"
+		// Kind is the kind of cluster. Currently the legal values are
+		// "singularity" and "kubernetes".
 		Kind string
 		// BaseURL is the main entrypoint URL for interacting with this cluster.
 		BaseURL string
@@ -91,7 +111,8 @@ type (
 	// files. It will implement sane YAML marshalling and unmarshalling. (Not
 	// yet implemented.)
 	Var string
-	// VarType represents the type of a Var (not yet implemented).
+	// VarType represents the type of the values of an environment variable,
+	// e.g. VarTypeInt.
 	VarType string
 )
 
@@ -138,6 +159,11 @@ func (d Defs) Clone() Defs {
 	d.EnvVars = d.EnvVars.Clone()
 	d.Resources = d.Resources.Clone()
 	d.Metadata = d.Metadata.Clone()
+	if d.Admins != nil {
+		d.Admins = append([]string{}, d.Admins...)
+	}
+	d.Notifications = d.Notifications.Clone()
+	d.Quotas = d.Quotas.Clone()
 	return d
 }
 
@@ -174,6 +200,11 @@ func (c Cluster) Clone() *Cluster {
 func (evs EnvDefs) Clone() EnvDefs {
 	e := make(EnvDefs, len(evs))
 	copy(e, evs)
+	for i := range e {
+		if e[i].Values != nil {
+			e[i].Values = append([]string{}, e[i].Values...)
+		}
+	}
 	return e
 }
 
@@ -206,10 +237,11 @@ func (s *State) BaseURLs() []string {
 
 // Validate implements Flawed for State
 func (s *State) Validate() []Flaw {
-	var flaws []Flaw
+	flaws := s.Defs.Validate()
 
 	for _, m := range s.Manifests.Snapshot() {
 		flaws = append(flaws, m.Validate()...)
+		flaws = append(flaws, s.Defs.ValidateEnv(m)...)
 	}
 
 	ds, err := s.Deployments()
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/opentable/sous/config"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+type (
+	// An Authenticator identifies the user making a request.
+	Authenticator interface {
+		// Authenticate returns the user making req and true, or false if
+		// req carries no credentials this Authenticator recognizes. It
+		// returns an error if req carries credentials that are invalid.
+		Authenticate(req *http.Request) (ClientUser, bool, error)
+	}
+
+	// Authenticators tries each of its Authenticators in turn, and
+	// authenticates a request as the first one that recognizes it.
+	Authenticators []Authenticator
+
+	// BearerTokenAuthenticator authenticates requests with an
+	// "Authorization: Bearer <token>" header, by the user of the token.
+	BearerTokenAuthenticator map[string]ClientUser
+
+	// ClientCertAuthenticator authenticates requests made with a TLS client
+	// certificate issued by one of Roots.
+	ClientCertAuthenticator struct {
+		Roots *x509.CertPool
+	}
+
+	authenticatedUserKey struct{}
+)
+
+// NewAuthenticator returns the Authenticator configured by c, or nil if c
+// does not enable authentication.
+func NewAuthenticator(c config.AuthConfig) (Authenticator, error) {
+	as := Authenticators{}
+	if c.TokensFile != "" {
+		f, err := os.Open(c.TokensFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "opening tokens file")
+		}
+		defer f.Close()
+		tokens, err := ReadBearerTokens(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", c.TokensFile)
+		}
+		as = append(as, tokens)
+	}
+	if c.ClientCAFile != "" {
+		roots, err := loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		as = append(as, ClientCertAuthenticator{Roots: roots})
+	}
+	if len(as) == 0 {
+		return nil, nil
+	}
+	return as, nil
+}
+
+// loadCertPool returns a pool of the PEM encoded certificates in file.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading certificates")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// ReadBearerTokens reads a BearerTokenAuthenticator from r, in the format
+// described by config.AuthConfig.TokensFile.
+func ReadBearerTokens(r io.Reader) (BearerTokenAuthenticator, error) {
+	tokens := BearerTokenAuthenticator{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, errors.Errorf("line %d: want a token and an email address", n)
+		}
+		tokens[fields[0]] = ClientUser{
+			Email: fields[1],
+			Name:  strings.Join(fields[2:], " "),
+		}
+	}
+	return tokens, scanner.Err()
+}
+
+// Authenticate implements Authenticator on Authenticators.
+func (as Authenticators) Authenticate(req *http.Request) (ClientUser, bool, error) {
+	for _, a := range as {
+		if user, ok, err := a.Authenticate(req); ok || err != nil {
+			return user, ok, err
+		}
+	}
+	return ClientUser{}, false, nil
+}
+
+// Authenticate implements Authenticator on BearerTokenAuthenticator.
+func (ba BearerTokenAuthenticator) Authenticate(req *http.Request) (ClientUser, bool, error) {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ClientUser{}, false, nil
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	for t, user := range ba {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return user, true, nil
+		}
+	}
+	return ClientUser{}, false, errors.New("unknown bearer token")
+}
+
+// Authenticate implements Authenticator on ClientCertAuthenticator.
+func (ca ClientCertAuthenticator) Authenticate(req *http.Request) (ClientUser, bool, error) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return ClientUser{}, false, nil
+	}
+	cert := req.TLS.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range req.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         ca.Roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return ClientUser{}, false, errors.Wrapf(err, "verifying client certificate")
+	}
+	if len(cert.EmailAddresses) == 0 {
+		return ClientUser{}, false, errors.New("client certificate has no email address")
+	}
+	return ClientUser{Email: cert.EmailAddresses[0], Name: cert.Subject.CommonName}, true, nil
+}
+
+// authenticating wraps h so that requests are authenticated by a, and
+// only read requests may be made without credentials. The authenticated
+// user is available to handlers from authenticatedUser. If a is nil, h is
+// returned unchanged.
+func authenticating(a Authenticator, h http.Handler, ls logging.LogSink) http.Handler {
+	if a == nil {
+		return h
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		user, ok, err := a.Authenticate(req)
+		if err != nil {
+			logging.ReportError(ls, errors.Wrapf(err, "authenticating %s %s", req.Method, req.URL))
+			unauthenticated(rw, "Invalid credentials.")
+			return
+		}
+		if !ok {
+			switch req.Method {
+			default:
+				unauthenticated(rw, "Authentication required.")
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				h.ServeHTTP(rw, req)
+			}
+			return
+		}
+		ctx := context.WithValue(req.Context(), authenticatedUserKey{}, user)
+		h.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+func unauthenticated(rw http.ResponseWriter, msg string) {
+	rw.Header().Set("WWW-Authenticate", `Bearer realm="sous"`)
+	http.Error(rw, msg, http.StatusUnauthorized)
+}
+
+// authenticatedUser returns the user authenticated as making req, and true,
+// or false if req was not authenticated.
+func authenticatedUser(req *http.Request) (ClientUser, bool) {
+	user, ok := req.Context().Value(authenticatedUserKey{}).(ClientUser)
+	return user, ok
+}
+
+// authorize returns the error from calling check with the authenticated
+// user making req. Requests that were not authenticated are authorized,
+// since only servers that do not authenticate clients accept them.
+func authorize(req *http.Request, check func(sous.User) error) error {
+	user, ok := authenticatedUser(req)
+	if !ok {
+		return nil
+	}
+	return check(sous.User(user))
+}
+
+// authorizeAdmin returns an error unless u is an admin in the state read
+// from sr.
+func authorizeAdmin(sr sous.StateReader, u sous.User) error {
+	state, err := sr.ReadState()
+	if err != nil {
+		return errors.Wrapf(err, "reading admins")
+	}
+	return sous.AuthorizeAdmin(state.Defs, u)
+}
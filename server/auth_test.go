@@ -0,0 +1,116 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBearerTokens(t *testing.T) {
+	tokens, err := ReadBearerTokens(strings.NewReader(`
+# deployers
+s3cret  jlester@example.com  Judson Lester
+t0ken   sam@example.com
+`))
+	require.NoError(t, err)
+	assert.Equal(t, BearerTokenAuthenticator{
+		"s3cret": {Email: "jlester@example.com", Name: "Judson Lester"},
+		"t0ken":  {Email: "sam@example.com"},
+	}, tokens)
+
+	_, err = ReadBearerTokens(strings.NewReader("lonely-token\n"))
+	assert.Error(t, err)
+}
+
+func TestAuthenticating(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	tokens := BearerTokenAuthenticator{"s3cret": {Email: "jlester@example.com"}}
+	var seen ClientUser
+	h := authenticating(tokens, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		seen = userExtractor{}.GetUser(req)
+	}), ls)
+
+	serve := func(method, token string) int {
+		req := httptest.NewRequest(method, "/gdm", nil)
+		req.Header.Set("Sous-User-Email", "spoofed@example.com")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rw := httptest.NewRecorder()
+		seen = ClientUser{}
+		h.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	assert.Equal(t, 200, serve("GET", ""))
+	assert.Equal(t, "spoofed@example.com", seen.Email, "unauthenticated reads keep the old behavior")
+
+	assert.Equal(t, 401, serve("PUT", ""))
+	assert.Equal(t, 401, serve("PUT", "wrong"))
+
+	assert.Equal(t, 200, serve("PUT", "s3cret"))
+	assert.Equal(t, "jlester@example.com", seen.Email)
+}
+
+func TestClientCertAuthenticator(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Sous Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		Subject:        pkix.Name{CommonName: "Judson Lester"},
+		EmailAddresses: []string{"jlester@example.com"},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, &clientKey.PublicKey, caKey)
+	require.NoError(t, err)
+	client, err := x509.ParseCertificate(clientDER)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	a := ClientCertAuthenticator{Roots: roots}
+
+	req := httptest.NewRequest("PUT", "/gdm", nil)
+	_, ok, err := a.Authenticate(req)
+	assert.NoError(t, err)
+	assert.False(t, ok, "plain HTTP requests carry no certificate")
+
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{client}}
+	user, ok, err := a.Authenticate(req)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ClientUser{Name: "Judson Lester", Email: "jlester@example.com"}, user)
+
+	_, _, err = ClientCertAuthenticator{Roots: x509.NewCertPool()}.Authenticate(req)
+	assert.Error(t, err, "certificates from other CAs are rejected")
+}
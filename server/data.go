@@ -26,6 +26,9 @@ type (
 	// ServerListData is the DTO for lists of servers
 	ServerListData struct { // not actually a stutter - "server" means two different things.
 		Servers []NameData
+		// Leaders lists the server elected to lead each cluster, if leaders
+		// are elected.
+		Leaders []sous.Leader `json:",omitempty"`
 	}
 
 	// ClientUser is a local alias for sous.User
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// A DeploymentOperationResource changes one aspect of a single deployment
+	// in the GDM, e.g. its number of instances, and queues its rectification.
+	DeploymentOperationResource struct {
+		single    *SingleDeploymentResource
+		operation deploymentOperation
+	}
+
+	// PUTDeploymentOperationHandler applies a deploymentOperation to the
+	// deployment spec selected by the query, and writes it back to the GDM.
+	PUTDeploymentOperationHandler struct {
+		PUTSingleDeploymentHandler
+		operation deploymentOperation
+	}
+
+	// A deploymentOperation changes spec as directed by the query values of
+	// a request.
+	deploymentOperation func(spec *sous.DeploySpec, qv restful.QueryValues) error
+
+	// BounceResource replaces the running instances of a single deployment,
+	// without changing it.
+	BounceResource struct {
+		context ComponentLocator
+	}
+
+	// PUTBounceHandler handles PUT exchanges that bounce a single deployment.
+	PUTBounceHandler struct {
+		userExtractor
+		GDM           *sous.State
+		Bouncer       sous.Bouncer
+		ResolveFilter *sous.ResolveFilter
+		Leadership    *sous.Leadership
+		req           *http.Request
+		log           logging.LogSink
+	}
+)
+
+func newScaleResource(ctx ComponentLocator) *DeploymentOperationResource {
+	return &DeploymentOperationResource{
+		single:    newSingleDeploymentResource(ctx),
+		operation: scaleOperation,
+	}
+}
+
+func newPauseResource(ctx ComponentLocator, paused bool) *DeploymentOperationResource {
+	return &DeploymentOperationResource{
+		single: newSingleDeploymentResource(ctx),
+		operation: func(spec *sous.DeploySpec, _ restful.QueryValues) error {
+			p := paused
+			spec.Paused = &p
+			return nil
+		},
+	}
+}
+
+// scaleOperation sets the NumInstances of spec to the instances query value.
+func scaleOperation(spec *sous.DeploySpec, qv restful.QueryValues) error {
+	n, err := qv.Single("instances")
+	if err != nil {
+		return err
+	}
+	instances, err := strconv.Atoi(n)
+	if err != nil {
+		return fmt.Errorf("instances: %s", err)
+	}
+	if instances < 1 {
+		return fmt.Errorf("instances must be at least 1; pause the deployment to stop all of them")
+	}
+	spec.NumInstances = instances
+	return nil
+}
+
+// Put returns a configured PUTDeploymentOperationHandler.
+func (r *DeploymentOperationResource) Put(rm *restful.RouteMap, ls logging.LogSink, rw http.ResponseWriter, req *http.Request, p httprouter.Params) restful.Exchanger {
+	psd := r.single.Put(rm, ls, rw, req, p).(*PUTSingleDeploymentHandler)
+	return &PUTDeploymentOperationHandler{
+		PUTSingleDeploymentHandler: *psd,
+		operation:                  r.operation,
+	}
+}
+
+// Exchange applies the operation to the current deployment spec, and writes
+// and queues the result as a PUT to /single-deployment would.
+func (h *PUTDeploymentOperationHandler) Exchange() (interface{}, int) {
+	did, err := h.depID()
+	if err != nil {
+		return h.err(400, "Cannot decode Deployment ID: %s.", err)
+	}
+	m, ok := h.GDM.Manifests.Get(did.ManifestID)
+	if !ok {
+		return h.err(404, "No manifest with ID %q.", did.ManifestID)
+	}
+	spec, ok := m.Deployments[did.Cluster]
+	if !ok {
+		return h.err(404, "Manifest %q has no deployment for cluster %q.", did.ManifestID, did.Cluster)
+	}
+	if err := h.operation(&spec, restful.QueryValues{Values: h.req.URL.Query()}); err != nil {
+		return h.err(400, "%s.", err)
+	}
+	h.Body.Deployment = &spec
+	return h.update(did, false)
+}
+
+func newBounceResource(ctx ComponentLocator) *BounceResource {
+	return &BounceResource{context: ctx}
+}
+
+// Put returns a configured PUTBounceHandler.
+func (r *BounceResource) Put(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &PUTBounceHandler{
+		GDM:           r.context.liveState(),
+		Bouncer:       r.context.Bouncer,
+		ResolveFilter: r.context.ResolveFilter,
+		Leadership:    r.context.Leadership,
+		req:           req,
+		log:           ls,
+	}
+}
+
+// Exchange bounces the deployment selected by the query.
+func (h *PUTBounceHandler) Exchange() (interface{}, int) {
+	if h.Bouncer == nil {
+		return "Deployments cannot be bounced by this server.", http.StatusNotFound
+	}
+	did, err := deploymentIDFromValues(restful.QueryValues{Values: h.req.URL.Query()})
+	if err != nil {
+		return fmt.Sprintf("Cannot decode Deployment ID: %s.", err), http.StatusBadRequest
+	}
+	m, ok := h.GDM.Manifests.Get(did.ManifestID)
+	if !ok {
+		return fmt.Sprintf("No manifest with ID %q.", did.ManifestID), http.StatusNotFound
+	}
+	if err := authorize(h.req, func(u sous.User) error {
+		return sous.AuthorizeManifest(h.GDM.Defs, m, m, u)
+	}); err != nil {
+		return fmt.Sprintf("%s.", err), http.StatusForbidden
+	}
+	deployments, err := h.GDM.Deployments()
+	if err != nil {
+		return fmt.Sprintf("Failed to read deployments from GDM: %s.", err), http.StatusInternalServerError
+	}
+	d, ok := deployments.Get(did)
+	if !ok {
+		return fmt.Sprintf("No deployment %q.", did), http.StatusNotFound
+	}
+	if msg, status, refused := refuseUnhandled(d, h.ResolveFilter, h.Leadership); refused {
+		return msg, status
+	}
+
+	user := sous.User(h.GetUser(h.req))
+	messages.ReportLogFieldsMessageToConsole(fmt.Sprintf("Bouncing %s for %s", did, user), logging.InformationLevel, h.log, did, user)
+	if err := h.Bouncer.Bounce(d, user); err != nil {
+		return fmt.Sprintf("Cannot bounce %s: %s.", did, err), http.StatusInternalServerError
+	}
+	return fmt.Sprintf("Bounced %s.", did), http.StatusOK
+}
+
+// refuseUnhandled returns a message and status refusing to act on d, and
+// true, if this server does not handle d: if rf filters it out, or if l
+// leads elections and this server does not lead d's cluster.
+func refuseUnhandled(d *sous.Deployment, rf *sous.ResolveFilter, l *sous.Leadership) (string, int, bool) {
+	if rf != nil && !rf.FilterDeployment(d) {
+		return fmt.Sprintf("Deployment %q is not handled by this server.", d.ID()), http.StatusNotFound, true
+	}
+	if !l.Leads(d.ClusterName) {
+		return fmt.Sprintf("%s.", &sous.NotLeaderError{Cluster: d.ClusterName}), http.StatusConflict, true
+	}
+	return "", 0, false
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nyarly/spies"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bouncerSpy struct {
+	bounced []sous.DeploymentID
+	user    sous.User
+}
+
+func (b *bouncerSpy) Bounce(d *sous.Deployment, user sous.User) error {
+	b.bounced = append(b.bounced, d.ID())
+	b.user = user
+	return nil
+}
+
+// operationState returns a quotaState whose deployment of 3 instances is
+// valid after a round-trip to the GDM.
+func operationState() *sous.State {
+	state := quotaState(3)
+	m, _ := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	spec := m.Deployments["ci"]
+	spec.Startup.CheckReadyProtocol = "HTTP"
+	m.Deployments["ci"] = spec
+	return state
+}
+
+func operationRequest(path, query string) *http.Request {
+	req := httptest.NewRequest("PUT", fmt.Sprintf("http://sous.example.com%s?repo=gh&cluster=ci&%s", path, query), nil)
+	req.Header.Set("Sous-User-Name", "Test User")
+	req.Header.Set("Sous-User-Email", "testuser@example.com")
+	return req
+}
+
+func TestPUTDeploymentOperationHandler_Exchange(t *testing.T) {
+	exchange := func(r func(ComponentLocator) *DeploymentOperationResource, req *http.Request) (*sous.DummyStateManager, interface{}, int) {
+		qs, qsCtrl := sous.NewQueueSetSpy()
+		qsCtrl.MatchMethod("Push", spies.AnyArgs, &sous.QueuedR11n{}, true)
+		sm := sous.NewDummyStateManager()
+		sm.State = operationState()
+		c := ComponentLocator{StateManager: sm, QueueSet: qs}
+		ls, _ := logging.NewLogSinkSpy()
+		data, status := r(c).Put(routemap(c), ls, httptest.NewRecorder(), req, nil).Exchange()
+		return sm, data, status
+	}
+	spec := func(sm *sous.DummyStateManager) sous.DeploySpec {
+		m, ok := sm.State.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+		require.True(t, ok)
+		return m.Deployments["ci"]
+	}
+	pause := func(c ComponentLocator) *DeploymentOperationResource { return newPauseResource(c, true) }
+
+	sm, data, status := exchange(newScaleResource, operationRequest("/scale", "instances=4"))
+	require.Equal(t, http.StatusCreated, status, "%v", data)
+	assert.Equal(t, 4, spec(sm).NumInstances)
+	assert.Equal(t, 1, sm.WriteCount)
+
+	_, data, status = exchange(newScaleResource, operationRequest("/scale", "instances=5"))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, data, "quota exceeded")
+
+	_, _, status = exchange(newScaleResource, operationRequest("/scale", "instances=0"))
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	_, _, status = exchange(newScaleResource, operationRequest("/scale", ""))
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	sm, data, status = exchange(pause, operationRequest("/pause", ""))
+	require.Equal(t, http.StatusCreated, status, "%v", data)
+	assert.True(t, spec(sm).IsPaused())
+	assert.Equal(t, 3, spec(sm).NumInstances)
+
+	_, _, status = exchange(newScaleResource, operationRequest("/scale", "instances=3&flavor=none"))
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestPUTBounceHandler_Exchange(t *testing.T) {
+	exchange := func(bouncer sous.Bouncer, query string, configure ...func(*ComponentLocator)) (interface{}, int) {
+		sm := sous.NewDummyStateManager()
+		sm.State = operationState()
+		c := ComponentLocator{StateManager: sm, Bouncer: bouncer}
+		for _, f := range configure {
+			f(&c)
+		}
+		ls, _ := logging.NewLogSinkSpy()
+		return newBounceResource(c).Put(routemap(c), ls, nil, operationRequest("/bounce", query), nil).Exchange()
+	}
+
+	b := &bouncerSpy{}
+	data, status := exchange(b, "")
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	require.Len(t, b.bounced, 1)
+	assert.Equal(t, "ci", b.bounced[0].Cluster)
+	assert.Equal(t, "Test User", b.user.Name)
+
+	_, status = exchange(b, "flavor=none")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Len(t, b.bounced, 1)
+
+	_, status = exchange(nil, "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	_, status = exchange(b, "", func(c *ComponentLocator) {
+		c.ResolveFilter = &sous.ResolveFilter{Cluster: sous.NewResolveFieldMatcher("other")}
+	})
+	assert.Equal(t, http.StatusNotFound, status, "deployments filtered out are not bounced")
+	assert.Len(t, b.bounced, 1)
+
+	_, status = exchange(b, "", func(c *ComponentLocator) {
+		c.Leadership = sous.NewLeadership("https://here.sous.com", fixedElector{}, logging.SilentLogSet())
+	})
+	assert.Equal(t, http.StatusConflict, status, "deployments to clusters led elsewhere are not bounced")
+	assert.Len(t, b.bounced, 1)
+}
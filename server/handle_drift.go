@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// DriftResource describes how running deployments differ from the
+	// intended ones.
+	DriftResource struct {
+		context ComponentLocator
+	}
+
+	// GETDriftHandler handles GET exchanges for the latest drift report.
+	GETDriftHandler struct {
+		DriftDetector *sous.DriftDetector
+		Filter        *sous.ResolveFilter
+		FilterErr     error
+	}
+)
+
+func newDriftResource(ctx ComponentLocator) *DriftResource {
+	return &DriftResource{context: ctx}
+}
+
+// Get returns a configured GETDriftHandler.
+func (r *DriftResource) Get(_ *restful.RouteMap, _ logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	filter, err := deploymentFilterFromValues(restful.QueryValues{Values: req.URL.Query()})
+	return &GETDriftHandler{
+		DriftDetector: r.context.DriftDetector,
+		Filter:        filter,
+		FilterErr:     err,
+	}
+}
+
+// Exchange returns the latest sous.DriftReport, with only the deployments
+// selected by the request.
+func (h *GETDriftHandler) Exchange() (interface{}, int) {
+	if h.FilterErr != nil {
+		return h.FilterErr.Error(), http.StatusBadRequest
+	}
+	if h.DriftDetector == nil {
+		return "This server does not detect drift.", http.StatusNotFound
+	}
+	report := h.DriftDetector.Report()
+	if report == nil {
+		return "No drift report has been made yet.", http.StatusServiceUnavailable
+	}
+	return report.Filter(h.Filter), http.StatusOK
+}
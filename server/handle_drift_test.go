@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nyarly/spies"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func driftExchange(t *testing.T, c ComponentLocator, query string) (interface{}, int) {
+	t.Helper()
+	ls, _ := logging.NewLogSinkSpy()
+	rm := routemap(c)
+	h := newDriftResource(c).Get(rm, ls, nil, makeRequestWithQuery(t, query), nil)
+	return h.Exchange()
+}
+
+func TestGETDriftHandler_Exchange(t *testing.T) {
+	sm := sous.NewDummyStateManager()
+	sm.State = sous.DefaultStateFixture()
+	deployer, ctrl := sous.NewDeployerSpy()
+	ctrl.MatchMethod("RunningDeployments", spies.AnyArgs, sous.NewDeployStates(), nil)
+	dd := sous.NewDriftDetector(deployer, sous.NewDummyRegistry(), sm, &sous.ResolveFilter{}, time.Minute, logging.SilentLogSet())
+	c := ComponentLocator{DriftDetector: dd}
+
+	_, status := driftExchange(t, c, "")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	_, err := dd.Detect()
+	require.NoError(t, err)
+
+	data, status := driftExchange(t, c, "")
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	// Nothing is running, so all 3 manifests in each of 3 clusters are missing.
+	assert.Len(t, data.(*sous.DriftReport).Missing, 9)
+
+	data, status = driftExchange(t, c, "repo=github.com/user1/repo1&offset=dir1&flavor=flavor1")
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	assert.Len(t, data.(*sous.DriftReport).Missing, 3)
+
+	_, status = driftExchange(t, ComponentLocator{}, "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
@@ -101,6 +101,13 @@ func (h *PUTGDMHandler) Exchange() (interface{}, int) {
 
 	reportDebugHandleGDMMessage(fmt.Sprintf("Put GDM Handler Exchange with Server State: %v", state), nil, nil, h.LogSink)
 
+	prior := state.Manifests.Clone()
+	before, err := quotaDeployments(state)
+	if err != nil {
+		msg := "Error getting state"
+		reportHandleGDMMessage(msg, nil, err, h.LogSink)
+		return msg, http.StatusInternalServerError
+	}
 	state.Manifests, err = deps.PutbackManifests(state.Defs, state.Manifests, h.LogSink)
 	if err != nil {
 		msg := "Error getting state"
@@ -108,6 +115,12 @@ func (h *PUTGDMHandler) Exchange() (interface{}, int) {
 		return msg, http.StatusConflict
 	}
 
+	if err := authorize(h.Request, func(u sous.User) error {
+		return sous.AuthorizeManifests(state.Defs, prior, state.Manifests, u)
+	}); err != nil {
+		return err.Error(), http.StatusForbidden
+	}
+
 	flaws := state.Validate()
 	if len(flaws) > 0 {
 		msg := "Invalid GDM"
@@ -115,6 +128,17 @@ func (h *PUTGDMHandler) Exchange() (interface{}, int) {
 		return msg, http.StatusBadRequest
 	}
 
+	after, err := quotaDeployments(state)
+	if err != nil {
+		msg := "Invalid GDM"
+		reportHandleGDMMessage(msg, nil, err, h.LogSink)
+		return msg, http.StatusBadRequest
+	}
+	if err := state.Defs.Quotas.Check(before, after); err != nil {
+		reportHandleGDMMessage("Quota exceeded", nil, err, h.LogSink)
+		return err.Error(), http.StatusBadRequest
+	}
+
 	if _, got := h.Header["Etag"]; got {
 		state.SetEtag(h.Header.Get("Etag"))
 	}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	sous "github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
 	"github.com/opentable/sous/util/restful"
 	"github.com/samsalisbury/semv"
@@ -15,13 +16,19 @@ type (
 	}
 
 	getHealthHandler struct {
-		version semv.Version
+		version    semv.Version
+		leadership *sous.Leadership
+		log        logging.LogSink
 	}
 
 	// Health is the DTO for representing the health of the Sous server
 	Health struct {
 		Version  string
 		Revision string
+		// Server identifies this server in Leaders, if leaders are elected.
+		Server string `json:",omitempty"`
+		// Leaders lists the server elected to lead each cluster.
+		Leaders []sous.Leader `json:",omitempty"`
 	}
 )
 
@@ -29,15 +36,22 @@ func newHealthResource(loc ComponentLocator) *healthResource {
 	return &healthResource{locator: loc}
 }
 
-func (hr *healthResource) Get(*restful.RouteMap, logging.LogSink, http.ResponseWriter, *http.Request, httprouter.Params) restful.Exchanger {
+func (hr *healthResource) Get(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, _ *http.Request, _ httprouter.Params) restful.Exchanger {
 	return &getHealthHandler{
-		version: hr.locator.Version,
+		version:    hr.locator.Version,
+		leadership: hr.locator.Leadership,
+		log:        ls,
 	}
 }
 
 func (ghh *getHealthHandler) Exchange() (interface{}, int) {
-	return Health{
+	h := Health{
 		Version:  ghh.version.Format(semv.MMPPre),
 		Revision: ghh.version.Format(semv.Meta),
-	}, 200
+	}
+	if ghh.leadership != nil {
+		h.Server = ghh.leadership.Server
+		h.Leaders = currentLeaders(ghh.leadership, ghh.log)
+	}
+	return h, 200
 }
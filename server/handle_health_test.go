@@ -3,6 +3,8 @@ package server
 import (
 	"testing"
 
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
 	"github.com/samsalisbury/semv"
 )
 
@@ -28,3 +30,23 @@ func TestHandleHealth_Get(t *testing.T) {
 		t.Errorf("Expecting %q; got %q", version, rez.Version)
 	}
 }
+
+func TestHandleHealth_Get_leaders(t *testing.T) {
+	leaders := fixedElector{{ClusterName: "left", Server: "https://left.sous.com"}}
+	ls, _ := logging.NewLogSinkSpy()
+	h := &getHealthHandler{
+		version:    semv.MustParse("3.4.5"),
+		leadership: sous.NewLeadership("https://left.sous.com", leaders, ls),
+		log:        ls,
+	}
+
+	data, _ := h.Exchange()
+	rez := data.(Health)
+
+	if rez.Server != "https://left.sous.com" {
+		t.Errorf("Expecting Server %q; got %q", "https://left.sous.com", rez.Server)
+	}
+	if len(rez.Leaders) != 1 || rez.Leaders[0].ClusterName != "left" {
+		t.Errorf("Expecting the leader of cluster left; got %v", rez.Leaders)
+	}
+}
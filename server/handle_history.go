@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// HistoryResource describes the deployment change history.
+	HistoryResource struct {
+		context ComponentLocator
+	}
+
+	// GETHistoryHandler handles GET exchanges for the deployment change
+	// history.
+	GETHistoryHandler struct {
+		HistoryReader sous.HistoryReader
+		Filter        sous.HistoryFilter
+		FilterErr     error
+		log           logging.LogSink
+	}
+)
+
+func newHistoryResource(ctx ComponentLocator) *HistoryResource {
+	return &HistoryResource{context: ctx}
+}
+
+// Get returns a configured GETHistoryHandler.
+func (r *HistoryResource) Get(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	qv := restful.QueryValues{Values: req.URL.Query()}
+	filter, err := historyFilterFromValues(qv)
+	return &GETHistoryHandler{
+		HistoryReader: r.context.HistoryReader,
+		Filter:        filter,
+		FilterErr:     err,
+		log:           ls,
+	}
+}
+
+// Exchange returns a dto.HistoryResponse with the changes and deploy outcomes
+// selected by the request.
+func (h *GETHistoryHandler) Exchange() (interface{}, int) {
+	if h.FilterErr != nil {
+		return h.FilterErr.Error(), http.StatusBadRequest
+	}
+	if h.HistoryReader == nil {
+		return "No deployment history is recorded by this server.", http.StatusNotFound
+	}
+	changes, err := h.HistoryReader.ReadHistory(h.Filter)
+	if err != nil {
+		logging.ReportError(h.log, errors.Wrapf(err, "reading deployment history"))
+		return err.Error(), http.StatusInternalServerError
+	}
+	outcomes, err := h.HistoryReader.ReadOutcomes(h.Filter)
+	if err != nil {
+		logging.ReportError(h.log, errors.Wrapf(err, "reading deploy outcomes"))
+		return err.Error(), http.StatusInternalServerError
+	}
+	return dto.HistoryResponse{Changes: changes, Outcomes: outcomes}, http.StatusOK
+}
+
+// historyFilterFromValues reads an optional manifest ID (repo, offset,
+// flavor), cluster and limit from qv.
+func historyFilterFromValues(qv restful.QueryValues) (sous.HistoryFilter, error) {
+	filter := sous.HistoryFilter{}
+	if _, ok := qv.Values["repo"]; ok {
+		mid, err := manifestIDFromValues(qv)
+		if err != nil {
+			return filter, err
+		}
+		filter.ManifestID = &mid
+	}
+	cluster, err := qv.Single("cluster", "")
+	if err != nil {
+		return filter, err
+	}
+	filter.Cluster = cluster
+	limit, err := qv.Single("limit", "0")
+	if err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+		return filter, errors.Errorf("invalid limit %q", limit)
+	}
+	return filter, nil
+}
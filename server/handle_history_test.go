@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type historyReaderStub struct {
+	changes  []sous.DeploymentChange
+	outcomes []sous.DeployOutcome
+	err      error
+}
+
+func (h historyReaderStub) ReadHistory(filter sous.HistoryFilter) ([]sous.DeploymentChange, error) {
+	selected := []sous.DeploymentChange{}
+	for _, c := range h.changes {
+		if filter.Match(c.DeploymentID) {
+			selected = append(selected, c)
+		}
+	}
+	if filter.Limit > 0 && len(selected) > filter.Limit {
+		selected = selected[:filter.Limit]
+	}
+	return selected, h.err
+}
+
+func (h historyReaderStub) ReadOutcomes(filter sous.HistoryFilter) ([]sous.DeployOutcome, error) {
+	selected := []sous.DeployOutcome{}
+	for _, o := range h.outcomes {
+		if filter.Match(o.DeploymentID) {
+			selected = append(selected, o)
+		}
+	}
+	return selected, h.err
+}
+
+func historyChange(repo, cluster string) sous.DeploymentChange {
+	return sous.DeploymentChange{
+		DeploymentID: sous.DeploymentID{
+			ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: repo}},
+			Cluster:    cluster,
+		},
+		At:          time.Now(),
+		User:        sous.User{Name: "Test User", Email: "test@example.com"},
+		Kind:        "added",
+		PostVersion: "1.0.0",
+	}
+}
+
+func historyExchange(t *testing.T, reader sous.HistoryReader, query string) (interface{}, int) {
+	t.Helper()
+	ls, _ := logging.NewLogSinkSpy()
+	c := ComponentLocator{HistoryReader: reader}
+	rm := routemap(c)
+	h := newHistoryResource(c).Get(rm, ls, nil, makeRequestWithQuery(t, query), nil)
+	return h.Exchange()
+}
+
+func TestGETHistoryHandler_Exchange(t *testing.T) {
+	reader := historyReaderStub{changes: []sous.DeploymentChange{
+		historyChange("github.com/example/one", "cluster-1"),
+		historyChange("github.com/example/one", "cluster-2"),
+		historyChange("github.com/example/two", "cluster-1"),
+	}, outcomes: []sous.DeployOutcome{
+		{DeploymentID: historyChange("github.com/example/one", "cluster-1").DeploymentID, Version: "1.0.0", Succeeded: true},
+	}}
+
+	testCases := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"cluster=cluster-1", 2},
+		{"repo=github.com%2Fexample%2Fone", 2},
+		{"repo=github.com%2Fexample%2Fone&cluster=cluster-2", 1},
+		{"limit=1", 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			body, status := historyExchange(t, reader, tc.query)
+			require.Equal(t, http.StatusOK, status)
+			assert.Len(t, body.(dto.HistoryResponse).Changes, tc.want)
+		})
+	}
+
+	body, status := historyExchange(t, reader, "cluster=cluster-1")
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, body.(dto.HistoryResponse).Outcomes, 1)
+	body, status = historyExchange(t, reader, "cluster=cluster-2")
+	require.Equal(t, http.StatusOK, status)
+	assert.Empty(t, body.(dto.HistoryResponse).Outcomes)
+}
+
+func TestGETHistoryHandler_Exchange_errors(t *testing.T) {
+	_, status := historyExchange(t, historyReaderStub{}, "limit=lots")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	_, status = historyExchange(t, nil, "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	_, status = historyExchange(t, historyReaderStub{err: errors.New("no database")}, "")
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
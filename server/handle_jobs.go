@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/logging/messages"
+	"github.com/opentable/sous/util/restful"
+	uuid "github.com/satori/go.uuid"
+)
+
+// defaultJobRunCount is the number of runs listed by /job-history when no
+// count is given.
+const defaultJobRunCount = 10
+
+type (
+	// JobRunResource starts runs of jobs on demand.
+	JobRunResource struct {
+		context ComponentLocator
+	}
+
+	// PUTJobRunHandler handles PUT exchanges that run a job. The body of
+	// the request is a sous.JobRunOptions.
+	PUTJobRunHandler struct {
+		userExtractor
+		GDM           *sous.State
+		JobRunner     sous.JobRunner
+		ResolveFilter *sous.ResolveFilter
+		Leadership    *sous.Leadership
+		req           *http.Request
+		log           logging.LogSink
+	}
+
+	// JobHistoryResource lists the recent runs of jobs.
+	JobHistoryResource struct {
+		context ComponentLocator
+	}
+
+	// GETJobHistoryHandler handles GET exchanges listing the runs of a job.
+	GETJobHistoryHandler struct {
+		GDM       *sous.State
+		JobRunner sous.JobRunner
+		req       *http.Request
+	}
+)
+
+func newJobRunResource(ctx ComponentLocator) *JobRunResource {
+	return &JobRunResource{context: ctx}
+}
+
+// Put returns a configured PUTJobRunHandler.
+func (r *JobRunResource) Put(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &PUTJobRunHandler{
+		GDM:           r.context.liveState(),
+		JobRunner:     r.context.JobRunner,
+		ResolveFilter: r.context.ResolveFilter,
+		Leadership:    r.context.Leadership,
+		req:           req,
+		log:           ls,
+	}
+}
+
+// Exchange runs the job selected by the query, and returns the options it
+// was run with, including its RunID.
+func (h *PUTJobRunHandler) Exchange() (interface{}, int) {
+	if h.JobRunner == nil {
+		return "Jobs cannot be run by this server.", http.StatusNotFound
+	}
+	did, err := deploymentIDFromValues(restful.QueryValues{Values: h.req.URL.Query()})
+	if err != nil {
+		return fmt.Sprintf("Cannot decode Deployment ID: %s.", err), http.StatusBadRequest
+	}
+	opts := sous.JobRunOptions{}
+	if err := json.NewDecoder(h.req.Body).Decode(&opts); err != nil {
+		return fmt.Sprintf("Error parsing body: %s.", err), http.StatusBadRequest
+	}
+	m, ok := h.GDM.Manifests.Get(did.ManifestID)
+	if !ok {
+		return fmt.Sprintf("No manifest with ID %q.", did.ManifestID), http.StatusNotFound
+	}
+	if err := authorize(h.req, func(u sous.User) error {
+		return sous.AuthorizeManifest(h.GDM.Defs, m, m, u)
+	}); err != nil {
+		return fmt.Sprintf("%s.", err), http.StatusForbidden
+	}
+	d, msg, status := jobDeployment(h.GDM, did)
+	if d == nil {
+		return msg, status
+	}
+	if msg, status, refused := refuseUnhandled(d, h.ResolveFilter, h.Leadership); refused {
+		return msg, status
+	}
+	if flaws := h.GDM.Defs.ValidateJobEnv(did, opts.Env); len(flaws) > 0 {
+		descs := make([]string, len(flaws))
+		for i, f := range flaws {
+			descs[i] = fmt.Sprint(f)
+		}
+		return fmt.Sprintf("Invalid Env: %s.", strings.Join(descs, "; ")), http.StatusBadRequest
+	}
+
+	if opts.RunID == "" {
+		opts.RunID = uuid.NewV4().String()
+	}
+	user := sous.User(h.GetUser(h.req))
+	messages.ReportLogFieldsMessageToConsole(fmt.Sprintf("Running %s as %s for %s", did, opts.RunID, user), logging.InformationLevel, h.log, did, user)
+	if err := h.JobRunner.RunJob(d, opts, user); err != nil {
+		return fmt.Sprintf("Cannot run %s: %s.", did, err), http.StatusInternalServerError
+	}
+	return opts, http.StatusOK
+}
+
+func newJobHistoryResource(ctx ComponentLocator) *JobHistoryResource {
+	return &JobHistoryResource{context: ctx}
+}
+
+// Get returns a configured GETJobHistoryHandler.
+func (r *JobHistoryResource) Get(_ *restful.RouteMap, _ logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &GETJobHistoryHandler{
+		GDM:       r.context.liveState(),
+		JobRunner: r.context.JobRunner,
+		req:       req,
+	}
+}
+
+// Exchange returns a dto.JobHistoryResponse listing the most recent runs of
+// the job selected by the query, up to its count parameter.
+func (h *GETJobHistoryHandler) Exchange() (interface{}, int) {
+	if h.JobRunner == nil {
+		return "Job history is not available from this server.", http.StatusNotFound
+	}
+	qv := restful.QueryValues{Values: h.req.URL.Query()}
+	did, err := deploymentIDFromValues(qv)
+	if err != nil {
+		return fmt.Sprintf("Cannot decode Deployment ID: %s.", err), http.StatusBadRequest
+	}
+	count := defaultJobRunCount
+	if c, err := qv.Single("count", ""); err != nil {
+		return fmt.Sprintf("%s.", err), http.StatusBadRequest
+	} else if c != "" {
+		if count, err = strconv.Atoi(c); err != nil || count < 1 {
+			return fmt.Sprintf("count must be a positive integer, not %q.", c), http.StatusBadRequest
+		}
+	}
+	d, msg, status := jobDeployment(h.GDM, did)
+	if d == nil {
+		return msg, status
+	}
+
+	runs, err := h.JobRunner.JobRuns(d, count)
+	if err != nil {
+		return fmt.Sprintf("Cannot list runs of %s: %s.", did, err), http.StatusInternalServerError
+	}
+	return dto.JobHistoryResponse{Runs: runs}, http.StatusOK
+}
+
+// jobDeployment returns the deployment of the job did in gdm. If there is no
+// such job, it returns nil and a message and status describing why.
+func jobDeployment(gdm *sous.State, did sous.DeploymentID) (*sous.Deployment, string, int) {
+	deployments, err := gdm.Deployments()
+	if err != nil {
+		return nil, fmt.Sprintf("Failed to read deployments from GDM: %s.", err), http.StatusInternalServerError
+	}
+	d, ok := deployments.Get(did)
+	if !ok {
+		return nil, fmt.Sprintf("No deployment %q.", did), http.StatusNotFound
+	}
+	if !d.Kind.IsJob() {
+		return nil, fmt.Sprintf("%q is a %s, not a job.", did, d.Kind), http.StatusBadRequest
+	}
+	return d, "", http.StatusOK
+}
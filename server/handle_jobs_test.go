@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jobRunnerSpy struct {
+	ran   []sous.JobRunOptions
+	count int
+}
+
+func (j *jobRunnerSpy) RunJob(d *sous.Deployment, opts sous.JobRunOptions, user sous.User) error {
+	j.ran = append(j.ran, opts)
+	return nil
+}
+
+func (j *jobRunnerSpy) JobRuns(d *sous.Deployment, count int) ([]sous.JobRun, error) {
+	j.count = count
+	return []sous.JobRun{{TaskID: "task-1"}}, nil
+}
+
+// jobState returns a quotaState whose deployment is an on-demand job.
+func jobState() *sous.State {
+	state := quotaState(1)
+	m, _ := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	m.Kind = sous.ManifestKindOnDemand
+	return state
+}
+
+func TestPUTJobRunHandler_Exchange(t *testing.T) {
+	exchange := func(state *sous.State, jr sous.JobRunner, query, body string, configure ...func(*ComponentLocator)) (interface{}, int) {
+		sm := sous.NewDummyStateManager()
+		sm.State = state
+		c := ComponentLocator{StateManager: sm, JobRunner: jr}
+		for _, f := range configure {
+			f(&c)
+		}
+		req := httptest.NewRequest("PUT", "http://sous.example.com/job-run?repo=gh&cluster=ci&"+query, bytes.NewBufferString(body))
+		ls, _ := logging.NewLogSinkSpy()
+		return newJobRunResource(c).Put(routemap(c), ls, nil, req, nil).Exchange()
+	}
+
+	jr := &jobRunnerSpy{}
+	data, status := exchange(jobState(), jr, "", `{"Args": ["-v"], "Env": {"A": "1"}}`)
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	require.Len(t, jr.ran, 1)
+	assert.Equal(t, []string{"-v"}, jr.ran[0].Args)
+	assert.Equal(t, map[string]string{"A": "1"}, jr.ran[0].Env)
+	assert.NotEmpty(t, jr.ran[0].RunID)
+	assert.Equal(t, jr.ran[0], data)
+
+	_, status = exchange(jobState(), jr, "", `{"RunID": "run-1"}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "run-1", jr.ran[1].RunID)
+
+	data, status = exchange(quotaState(1), jr, "", `{}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, data, "not a job")
+
+	_, status = exchange(jobState(), jr, "", `not json`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	_, status = exchange(jobState(), jr, "flavor=none", `{}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	_, status = exchange(jobState(), nil, "", `{}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	data, status = exchange(jobState(), jr, "", `{}`, func(c *ComponentLocator) {
+		c.ResolveFilter = &sous.ResolveFilter{Cluster: sous.NewResolveFieldMatcher("other")}
+	})
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Contains(t, data, "not handled")
+
+	_, status = exchange(jobState(), jr, "", `{}`, func(c *ComponentLocator) {
+		c.Leadership = sous.NewLeadership("https://here.sous.com", fixedElector{}, logging.SilentLogSet())
+	})
+	assert.Equal(t, http.StatusConflict, status)
+
+	state := jobState()
+	state.Defs.EnvVars = sous.EnvDefs{{Name: "WORKERS", Type: sous.VarTypeInt}}
+	data, status = exchange(state, jr, "", `{"Env": {"WORKERS": "lots", "DSN": "secret://"}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, data, "WORKERS")
+	assert.Contains(t, data, "DSN")
+	assert.Len(t, jr.ran, 2)
+}
+
+func TestGETJobHistoryHandler_Exchange(t *testing.T) {
+	jr := &jobRunnerSpy{}
+	exchange := func(query string) (interface{}, int) {
+		sm := sous.NewDummyStateManager()
+		sm.State = jobState()
+		c := ComponentLocator{StateManager: sm, JobRunner: jr}
+		ls, _ := logging.NewLogSinkSpy()
+		return newJobHistoryResource(c).Get(routemap(c), ls, nil, makeRequestWithQuery(t, "repo=gh&cluster=ci&"+query), nil).Exchange()
+	}
+
+	data, status := exchange("")
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	assert.Equal(t, dto.JobHistoryResponse{Runs: []sous.JobRun{{TaskID: "task-1"}}}, data)
+	assert.Equal(t, defaultJobRunCount, jr.count)
+
+	_, status = exchange("count=3")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 3, jr.count)
+
+	_, status = exchange("count=0")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
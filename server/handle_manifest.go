@@ -39,6 +39,7 @@ type (
 	// DELETEManifestHandler handles DELETE exchanges for manifests
 	DELETEManifestHandler struct {
 		*sous.State
+		*http.Request
 		restful.QueryValues
 		StateWriter sous.StateWriter
 	}
@@ -72,6 +73,7 @@ func (mr *ManifestResource) Put(_ *restful.RouteMap, ls logging.LogSink, _ http.
 func (mr *ManifestResource) Delete(_ *restful.RouteMap, _ logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
 	return &DELETEManifestHandler{
 		State:       mr.context.liveState(),
+		Request:     req,
 		QueryValues: mr.ParseQuery(req),
 		StateWriter: sous.StateWriter(mr.context.StateManager),
 	}
@@ -96,10 +98,15 @@ func (dmh *DELETEManifestHandler) Exchange() (interface{}, int) {
 	if err != nil {
 		return err, http.StatusNotFound
 	}
-	_, there := dmh.State.Manifests.Get(mid)
+	m, there := dmh.State.Manifests.Get(mid)
 	if !there {
 		return nil, http.StatusNotFound
 	}
+	if err := authorize(dmh.Request, func(u sous.User) error {
+		return sous.AuthorizeManifest(dmh.State.Defs, m, nil, u)
+	}); err != nil {
+		return err.Error(), http.StatusForbidden
+	}
 	dmh.State.Manifests.Remove(mid)
 
 	return nil, http.StatusNoContent
@@ -117,11 +124,29 @@ func (pmh *PUTManifestHandler) Exchange() (interface{}, int) {
 	dec.Decode(m)
 
 	flaws := m.Validate()
+	flaws = append(flaws, pmh.State.Defs.ValidateEnv(m)...)
 	if len(flaws) > 0 {
 		messages.ReportLogFieldsMessageToConsole("Exchange contains flaws", logging.ExtraDebug1Level, pmh.LogSink, flaws)
 		return "Invalid manifest", http.StatusBadRequest
 	}
+	prior, _ := pmh.State.Manifests.Get(mid)
+	if err := authorize(pmh.Request, func(u sous.User) error {
+		return sous.AuthorizeManifest(pmh.State.Defs, prior, m, u)
+	}); err != nil {
+		return err.Error(), http.StatusForbidden
+	}
+	before, err := quotaDeployments(pmh.State)
+	if err != nil {
+		return err.Error(), http.StatusInternalServerError
+	}
 	pmh.State.Manifests.Set(mid, m)
+	after, err := quotaDeployments(pmh.State)
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if err := pmh.State.Defs.Quotas.Check(before, after); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
 	if err := pmh.StateWriter.WriteState(pmh.State, sous.User(pmh.User)); err != nil {
 		return errors.Wrapf(err, "state recording collision - retry"), http.StatusConflict
 	}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -179,3 +180,103 @@ func TestHandlesManifestPut(t *testing.T) {
 	assert.Equal(changed.Deployments["ci"].SingularityRequestID, "custom-sing-req-id")
 
 }
+
+func TestHandlesManifestPut_unauthorized(t *testing.T) {
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(t, err)
+	state := sous.NewState()
+	state.Manifests.Add(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"sam@example.com"},
+		Kind:   sous.ManifestKindService,
+	})
+	writer := &sous.DummyStateManager{State: state}
+
+	buf := &bytes.Buffer{}
+	json.NewEncoder(buf).Encode(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"intruder@example.com"},
+		Kind:   sous.ManifestKindService,
+	})
+	req, err := http.NewRequest("PUT", "", buf)
+	require.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), authenticatedUserKey{}, ClientUser{Email: "intruder@example.com"}))
+
+	log, _ := logging.NewLogSinkSpy()
+	th := &PUTManifestHandler{
+		Request:     req,
+		StateWriter: writer,
+		State:       state,
+		QueryValues: restful.QueryValues{Values: q},
+		LogSink:     log,
+	}
+
+	_, status := th.Exchange()
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, 0, writer.WriteCount)
+}
+
+func TestHandlesManifestPut_invalidEnv(t *testing.T) {
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(t, err)
+	state := sous.NewState()
+	state.Defs.EnvVars = sous.EnvDefs{{Name: "WORKERS", Type: sous.VarTypeInt}}
+	writer := &sous.DummyStateManager{State: state}
+
+	buf := &bytes.Buffer{}
+	json.NewEncoder(buf).Encode(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Kind:   sous.ManifestKindService,
+		Deployments: sous.DeploySpecs{
+			"ci": sous.DeploySpec{
+				DeployConfig: sous.DeployConfig{Env: sous.Env{"WORKERS": "many"}},
+			},
+		},
+	})
+	req, err := http.NewRequest("PUT", "", buf)
+	require.NoError(t, err)
+
+	th := &PUTManifestHandler{
+		Request:     req,
+		StateWriter: writer,
+		State:       state,
+		QueryValues: restful.QueryValues{Values: q},
+		LogSink:     logging.SilentLogSet(),
+	}
+
+	_, status := th.Exchange()
+	assert.Equal(t, http.StatusBadRequest, status)
+	_, found := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	assert.False(t, found)
+}
+
+func TestHandlesManifestPut_quotaExceeded(t *testing.T) {
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(t, err)
+
+	put := func(state *sous.State, instances int) (interface{}, int) {
+		buf := &bytes.Buffer{}
+		json.NewEncoder(buf).Encode(quotaManifest(instances))
+		req, err := http.NewRequest("PUT", "", buf)
+		require.NoError(t, err)
+		th := &PUTManifestHandler{
+			Request:     req,
+			StateWriter: &sous.DummyStateManager{State: state},
+			State:       state,
+			QueryValues: restful.QueryValues{Values: q},
+			LogSink:     logging.SilentLogSet(),
+		}
+		return th.Exchange()
+	}
+
+	data, status := put(quotaState(2), 5)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "quota exceeded: team-a would use 5 instances of 4", data)
+
+	_, status = put(quotaState(2), 4)
+	assert.Equal(t, http.StatusOK, status)
+
+	// Scaling down is allowed while over quota.
+	_, status = put(quotaState(6), 5)
+	assert.Equal(t, http.StatusOK, status)
+}
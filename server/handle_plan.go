@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// PlanResource describes the changes rectification would make.
+	PlanResource struct {
+		context ComponentLocator
+	}
+
+	// GETPlanHandler handles GET exchanges for the changes rectification
+	// would make.
+	GETPlanHandler struct {
+		StateReader sous.StateReader
+		Deployer    sous.Deployer
+		Registry    sous.Registry
+		// ServerFilter selects the clusters this server resolves.
+		ServerFilter *sous.ResolveFilter
+		// Filter selects the deployments to plan, as requested.
+		Filter    *sous.ResolveFilter
+		FilterErr error
+		log       logging.LogSink
+	}
+)
+
+func newPlanResource(ctx ComponentLocator) *PlanResource {
+	return &PlanResource{context: ctx}
+}
+
+// Get returns a configured GETPlanHandler.
+func (r *PlanResource) Get(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	filter, err := deploymentFilterFromValues(restful.QueryValues{Values: req.URL.Query()})
+	h := &GETPlanHandler{
+		StateReader:  r.context.StateManager,
+		Registry:     r.context.Registry,
+		ServerFilter: r.context.ResolveFilter,
+		Filter:       filter,
+		FilterErr:    err,
+		log:          ls,
+	}
+	if r.context.AutoResolver != nil && r.context.AutoResolver.Resolver != nil {
+		h.Deployer = r.context.AutoResolver.Deployer
+	}
+	return h
+}
+
+// Exchange returns a dto.PlanResponse with the changes rectification would
+// make to the deployments selected by the request.
+func (h *GETPlanHandler) Exchange() (interface{}, int) {
+	if h.FilterErr != nil {
+		return h.FilterErr.Error(), http.StatusBadRequest
+	}
+	if h.Deployer == nil {
+		return "This server does not resolve deployments.", http.StatusNotFound
+	}
+	state, err := h.StateReader.ReadState()
+	if err != nil {
+		logging.ReportError(h.log, errors.Wrapf(err, "reading state to plan"))
+		return err.Error(), http.StatusInternalServerError
+	}
+	intended, err := state.Deployments()
+	if err != nil {
+		logging.ReportError(h.log, errors.Wrapf(err, "reading deployments to plan"))
+		return err.Error(), http.StatusInternalServerError
+	}
+	clusters := state.Defs.Clusters
+	if h.ServerFilter != nil {
+		clusters = h.ServerFilter.FilteredClusters(clusters)
+	}
+
+	resolver := sous.NewResolver(h.Deployer, h.Registry, h.Filter, h.log, nil)
+	changes, err := resolver.Plan(intended, clusters)
+	if err != nil {
+		logging.ReportError(h.log, errors.Wrapf(err, "planning rectification"))
+		return err.Error(), http.StatusInternalServerError
+	}
+	return dto.PlanResponse{Changes: changes}, http.StatusOK
+}
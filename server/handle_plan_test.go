@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func planExchange(t *testing.T, c ComponentLocator, query string) (interface{}, int) {
+	t.Helper()
+	ls, _ := logging.NewLogSinkSpy()
+	rm := routemap(c)
+	h := newPlanResource(c).Get(rm, ls, nil, makeRequestWithQuery(t, query), nil)
+	return h.Exchange()
+}
+
+func TestGETPlanHandler_Exchange(t *testing.T) {
+	sm := sous.NewDummyStateManager()
+	sm.State = sous.DefaultStateFixture()
+	queues := sous.NewR11nQueueSet()
+	resolver := sous.NewResolver(sous.NewDummyDeployer(), sous.NewDummyRegistry(), &sous.ResolveFilter{}, logging.SilentLogSet(), queues)
+	c := ComponentLocator{
+		StateManager:  sm,
+		Registry:      sous.NewDummyRegistry(),
+		ResolveFilter: &sous.ResolveFilter{},
+		AutoResolver:  &sous.AutoResolver{Resolver: resolver},
+		QueueSet:      queues,
+	}
+
+	t.Run("all", func(t *testing.T) {
+		data, status := planExchange(t, c, "")
+		require.Equal(t, http.StatusOK, status, "%v", data)
+		// 3 manifests in each of 3 clusters, none of them running yet.
+		assert.Len(t, data.(dto.PlanResponse).Changes, 9)
+		assert.Len(t, queues.Queues(), 0)
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		data, status := planExchange(t, c, "repo=github.com/user1/repo1&offset=dir1&flavor=flavor1&cluster=cluster2")
+		require.Equal(t, http.StatusOK, status, "%v", data)
+		changes := data.(dto.PlanResponse).Changes
+		require.Len(t, changes, 1)
+		assert.Equal(t, "cluster2", changes[0].DeploymentID.Cluster)
+		assert.Equal(t, "github.com/user1/repo1", changes[0].DeploymentID.ManifestID.Source.Repo)
+		assert.Equal(t, "added", changes[0].Kind)
+		assert.Contains(t, changes[0].Diffs, sous.FieldDiff{Field: "NumInstances", Post: "3"})
+	})
+
+	t.Run("bad query", func(t *testing.T) {
+		_, status := planExchange(t, c, "cluster=a&cluster=b")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("no resolver", func(t *testing.T) {
+		_, status := planExchange(t, ComponentLocator{StateManager: sm}, "")
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// QuotaResource describes the usage of resource quotas.
+	QuotaResource struct {
+		context ComponentLocator
+	}
+
+	// GETQuotaHandler handles GET exchanges for the usage of resource
+	// quotas.
+	GETQuotaHandler struct {
+		StateReader sous.StateReader
+		// Owner and Cluster, if not empty, select the quotas of that owner
+		// and for that cluster.
+		Owner, Cluster string
+		QueryErr       error
+		log            logging.LogSink
+	}
+)
+
+func newQuotaResource(ctx ComponentLocator) *QuotaResource {
+	return &QuotaResource{context: ctx}
+}
+
+// Get returns a configured GETQuotaHandler.
+func (r *QuotaResource) Get(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	qv := restful.QueryValues{Values: req.URL.Query()}
+	h := &GETQuotaHandler{StateReader: r.context.StateManager, log: ls}
+	h.Owner, h.QueryErr = qv.Single("owner", "")
+	if h.QueryErr == nil {
+		h.Cluster, h.QueryErr = qv.Single("cluster", "")
+	}
+	return h
+}
+
+// Exchange returns a dto.QuotaResponse with the usage of the selected quotas.
+func (h *GETQuotaHandler) Exchange() (interface{}, int) {
+	if h.QueryErr != nil {
+		return h.QueryErr.Error(), http.StatusBadRequest
+	}
+	state, err := h.StateReader.ReadState()
+	if err != nil {
+		logging.ReportError(h.log, errors.Wrapf(err, "reading state for quotas"))
+		return err.Error(), http.StatusInternalServerError
+	}
+	deployments, err := state.Deployments()
+	if err != nil {
+		logging.ReportError(h.log, errors.Wrapf(err, "reading deployments for quotas"))
+		return err.Error(), http.StatusInternalServerError
+	}
+	quotas := sous.Quotas{}
+	for _, q := range state.Defs.Quotas {
+		if h.Owner != "" && !strings.EqualFold(h.Owner, q.Owner) {
+			continue
+		}
+		if h.Cluster != "" && h.Cluster != q.Cluster {
+			continue
+		}
+		quotas = append(quotas, q)
+	}
+	return dto.QuotaResponse{Usage: quotas.Usage(deployments)}, http.StatusOK
+}
+
+// quotaDeployments returns the deployments of state to check its quotas
+// against, or none if it has no quotas, so that changing a state that does not
+// use quotas does not require resolving its deployments.
+func quotaDeployments(state *sous.State) (sous.Deployments, error) {
+	if len(state.Defs.Quotas) == 0 {
+		return sous.NewDeployments(), nil
+	}
+	return state.Deployments()
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quotaState returns a state with a manifest owned by team-a deploying
+// instances to cluster ci, and a quota of 4 instances for team-a.
+func quotaState(instances int) *sous.State {
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{"ci": &sous.Cluster{Name: "ci"}}
+	state.Defs.Quotas = sous.Quotas{{Owner: "team-a", Instances: 4}, {Owner: "team-b", Cluster: "ci"}}
+	state.Manifests.Add(quotaManifest(instances))
+	return state
+}
+
+func quotaManifest(instances int) *sous.Manifest {
+	return &sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"team-a"},
+		Kind:   sous.ManifestKindService,
+		Deployments: sous.DeploySpecs{
+			"ci": sous.DeploySpec{
+				Version: semv.MustParse("1.0.0"),
+				DeployConfig: sous.DeployConfig{
+					NumInstances: instances,
+					Resources:    sous.Resources{"cpus": "0.1", "memory": "100", "ports": "1"},
+				},
+			},
+		},
+	}
+}
+
+func TestGETQuotaHandler_Exchange(t *testing.T) {
+	sm := sous.NewDummyStateManager()
+	sm.State = quotaState(5)
+	c := ComponentLocator{StateManager: sm}
+	exchange := func(query string) (interface{}, int) {
+		ls, _ := logging.NewLogSinkSpy()
+		return newQuotaResource(c).Get(routemap(c), ls, nil, makeRequestWithQuery(t, query), nil).Exchange()
+	}
+
+	data, status := exchange("")
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	usage := data.(dto.QuotaResponse).Usage
+	require.Len(t, usage, 2)
+	assert.Equal(t, "team-a", usage[0].Quota.Owner)
+	assert.Equal(t, 5, usage[0].Instances)
+	assert.InDelta(t, 0.5, usage[0].Cpus, 0.0001)
+	assert.Equal(t, []string{"5 instances of 4"}, usage[0].Exceeded())
+	assert.Equal(t, 0, usage[1].Instances)
+
+	data, status = exchange("owner=TEAM-B")
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	require.Len(t, data.(dto.QuotaResponse).Usage, 1)
+	assert.Equal(t, "team-b", data.(dto.QuotaResponse).Usage[0].Quota.Owner)
+
+	data, status = exchange("cluster=other")
+	require.Equal(t, http.StatusOK, status, "%v", data)
+	assert.Empty(t, data.(dto.QuotaResponse).Usage)
+
+	_, status = exchange("owner=a&owner=b")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
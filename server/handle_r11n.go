@@ -21,11 +21,14 @@ type (
 	// GETR11nHandler handles getting r11ns.
 	GETR11nHandler struct {
 		WaitForResolution bool
-		QueueSet          sous.QueueSet
-		DeploymentID      sous.DeploymentID
-		DeploymentIDErr   error
-		R11nID            sous.R11nID
-		R11nIDErr         error
+		// Stream asks for the progress of the rectification to be streamed
+		// as server-sent events, until it is resolved.
+		Stream          bool
+		QueueSet        sous.QueueSet
+		DeploymentID    sous.DeploymentID
+		DeploymentIDErr error
+		R11nID          sous.R11nID
+		R11nIDErr       error
 	}
 )
 
@@ -49,6 +52,7 @@ func (r *R11nResource) Get(_ *restful.RouteMap, _ logging.LogSink, _ http.Respon
 	return &GETR11nHandler{
 		QueueSet:          r.context.QueueSet,
 		WaitForResolution: wait,
+		Stream:            restful.WantsEventStream(req),
 		DeploymentID:      did,
 		DeploymentIDErr:   didErr,
 		R11nID:            rid,
@@ -82,7 +86,14 @@ func (h *GETR11nHandler) Exchange() (interface{}, int) {
 		return fmt.Sprintf("Deploy action %q not found in queue for %q.",
 			h.R11nID, h.DeploymentID), http.StatusNotFound
 	}
+	if h.Stream {
+		return &r11nStream{queue: queue, id: h.R11nID}, http.StatusOK
+	}
+	return newR11nResponse(qr), http.StatusOK
+}
 
+// newR11nResponse returns the current state of qr.
+func newR11nResponse(qr *sous.QueuedR11n) dto.R11nResponse {
 	// XXX Should this be part of the ByID contract?
 	// Specifically, the Resolution field would need to be *DiffResolution
 	rez := &qr.Rectification.Resolution
@@ -93,7 +104,8 @@ func (h *GETR11nHandler) Exchange() (interface{}, int) {
 	return dto.R11nResponse{
 		QueuePosition: qr.Pos,
 		Resolution:    rez,
-	}, http.StatusOK
+		Rollout:       qr.Rectification.RolloutProgress(),
+	}
 }
 
 /*
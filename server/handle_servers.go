@@ -7,6 +7,7 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/opentable/sous/config"
+	sous "github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
 	"github.com/opentable/sous/util/restful"
 )
@@ -19,14 +20,17 @@ type (
 
 	// ServerListHandler handles GET for /servers
 	ServerListHandler struct {
-		Config *config.Config
+		Config     *config.Config
+		Leadership *sous.Leadership
+		Log        logging.LogSink
 	}
 
 	// ServerListUpdater handles PUT for /servers
 	ServerListUpdater struct {
 		*http.Request
-		Config *config.Config
-		Log    logging.LogSink
+		Config      *config.Config
+		StateReader sous.StateReader
+		Log         logging.LogSink
 	}
 )
 
@@ -35,18 +39,21 @@ func newServerListResource(context ComponentLocator) *ServerListResource {
 }
 
 // Get implements Getable on ServerListResource, which marks it as accepting GET requests
-func (slr *ServerListResource) Get(*restful.RouteMap, logging.LogSink, http.ResponseWriter, *http.Request, httprouter.Params) restful.Exchanger {
+func (slr *ServerListResource) Get(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, _ *http.Request, _ httprouter.Params) restful.Exchanger {
 	return &ServerListHandler{
-		Config: slr.context.Config,
+		Config:     slr.context.Config,
+		Leadership: slr.context.Leadership,
+		Log:        ls,
 	}
 }
 
 // Put implements Putable on ServerListResource, which marks is as accepting PUT requests
 func (slr *ServerListResource) Put(_ *restful.RouteMap, ls logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
 	return &ServerListUpdater{
-		Config:  slr.context.Config,
-		Log:     ls,
-		Request: req,
+		Config:      slr.context.Config,
+		StateReader: slr.context.StateManager,
+		Log:         ls,
+		Request:     req,
 	}
 }
 
@@ -56,11 +63,18 @@ func (slh *ServerListHandler) Exchange() (interface{}, int) {
 	for name, url := range slh.Config.SiblingURLs {
 		data.Servers = append(data.Servers, NameData{ClusterName: name, URL: url})
 	}
+	data.Leaders = currentLeaders(slh.Leadership, slh.Log)
 	return data, 200
 }
 
 // Exchange implements restful.Exchanger on ServerListUpdater
 func (slh *ServerListUpdater) Exchange() (interface{}, int) {
+	if err := authorize(slh.Request, func(u sous.User) error {
+		return authorizeAdmin(slh.StateReader, u)
+	}); err != nil {
+		return err.Error(), http.StatusForbidden
+	}
+
 	dec := json.NewDecoder(slh.Request.Body)
 	data := ServerListData{Servers: []NameData{}}
 	dec.Decode(&data)
@@ -77,3 +91,15 @@ func (slh *ServerListUpdater) Exchange() (interface{}, int) {
 
 	return data, 200
 }
+
+// currentLeaders returns the current cluster leaders, or nil if they are
+// unknown. Errors are reported rather than returned, since the leaders are
+// informational.
+func currentLeaders(l *sous.Leadership, ls logging.LogSink) []sous.Leader {
+	leaders, err := l.Leaders()
+	if err != nil {
+		logging.ReportError(ls, err)
+		return nil
+	}
+	return leaders
+}
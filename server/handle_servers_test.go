@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/opentable/sous/config"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,3 +36,26 @@ func TestHandleServerList_Get(t *testing.T) {
 	assert.Equal(list.Servers[0].ClusterName, "left")
 	assert.Equal(list.Servers[1].ClusterName, "right")
 }
+
+type fixedElector []sous.Leader
+
+func (e fixedElector) Campaign(cluster string) (bool, error) { return false, nil }
+
+func (e fixedElector) Leaders() ([]sous.Leader, error) { return e, nil }
+
+func (e fixedElector) Resign() {}
+
+func TestHandleServerList_Get_leaders(t *testing.T) {
+	leaders := fixedElector{{ClusterName: "left", Server: "https://left.sous.com"}}
+	ls, _ := logging.NewLogSinkSpy()
+	h := &ServerListHandler{
+		Config:     &config.Config{},
+		Leadership: sous.NewLeadership("https://right.sous.com", leaders, ls),
+		Log:        ls,
+	}
+
+	rez, stat := h.Exchange()
+	assert.Equal(t, 200, stat)
+	list := rez.(ServerListData)
+	assert.Equal(t, []sous.Leader(leaders), list.Leaders)
+}
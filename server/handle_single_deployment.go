@@ -109,7 +109,7 @@ func (h *GETSingleDeploymentHandler) Exchange() (interface{}, int) {
 		return h.err(404, "Manifest %q has no deployment for cluster %q.", m.ID(), did.Cluster)
 	}
 
-	if dep.NumInstances == 0 {
+	if m.WithDefaults(dep).NumInstances == 0 {
 		return h.err(400, "Cannot deploy, current num instances set to zero, please update manifest.")
 	}
 
@@ -181,6 +181,13 @@ func (psd *PUTSingleDeploymentHandler) Exchange() (interface{}, int) {
 
 	messages.ReportLogFieldsMessageToConsole("Exchange PutSingleDeplymentHandler", logging.ExtraDebug1Level, psd.log, did, psd.Body)
 
+	return psd.update(did, force)
+}
+
+// update writes psd.Body.Deployment to the GDM as the deployment spec for
+// did, and queues its rectification, if it differs from the current spec or
+// force is true.
+func (psd *PUTSingleDeploymentHandler) update(did sous.DeploymentID, force bool) (interface{}, int) {
 	if _, clusterOK := psd.GDM.Defs.Clusters[did.Cluster]; !clusterOK {
 		return psd.err(404, "Cluster %q not defined.", did.Cluster)
 	}
@@ -189,13 +196,18 @@ func (psd *PUTSingleDeploymentHandler) Exchange() (interface{}, int) {
 	if !ok {
 		return psd.err(404, "No manifest with ID %q.", did.ManifestID)
 	}
+	if err := authorize(psd.req, func(u sous.User) error {
+		return sous.AuthorizeManifest(psd.GDM.Defs, m, m, u)
+	}); err != nil {
+		return psd.err(403, "%s.", err)
+	}
 	original, ok := m.Deployments[did.Cluster]
 	if !ok {
 		return psd.err(404, "Manifest %q has no deployment for cluster %q.",
 			did.ManifestID, did.Cluster)
 	}
 
-	if psd.Body.Deployment.NumInstances == 0 {
+	if m.WithDefaults(*psd.Body.Deployment).NumInstances == 0 {
 		return psd.err(400, "Cannot deploy: NumInstances is 0 for this deployment. Please update your manifest to NumInstances > 0 to enable deploying.")
 	}
 
@@ -204,7 +216,18 @@ func (psd *PUTSingleDeploymentHandler) Exchange() (interface{}, int) {
 		return psd.ok(200, nil)
 	}
 
+	before, err := quotaDeployments(psd.GDM)
+	if err != nil {
+		return psd.err(500, "Failed to read deployments from GDM: %s", err)
+	}
 	m.Deployments[did.Cluster] = *psd.Body.Deployment
+	after, err := quotaDeployments(psd.GDM)
+	if err != nil {
+		return psd.err(400, "Failed to merge new deployment spec into GDM: %s", err)
+	}
+	if err := psd.GDM.Defs.Quotas.Check(before, after); err != nil {
+		return psd.err(400, "Cannot deploy: %s.", err)
+	}
 
 	user := sous.User(psd.GetUser(psd.req))
 
@@ -357,6 +357,21 @@ func TestPUTSingleDeploymentHandler_Exchange(t *testing.T) {
 			"sous.example.com/deploy-queue-item?action=actionid1&cluster=cluster1&flavor=flavor1&offset=dir1&repo=github.com%2Fuser1%2Frepo1")
 	})
 
+	t.Run("instances from manifest defaults", func(t *testing.T) {
+		body, query := makeBodyAndQuery(t, false)
+		body.Deployment.Version = semv.MustParse("2.0.0")
+		body.Deployment.NumInstances = 0
+		scenario := setup(body, query)
+		for _, m := range scenario.gdm.Manifests.Snapshot() {
+			m.Defaults.NumInstances = 3
+		}
+		scenario.queueSet.MatchMethod("Push", spies.AnyArgs, &sous.QueuedR11n{ID: "actionid1"}, true)
+		scenario.exercise()
+
+		scenario.assertStatus(t, 201)
+		scenario.assertDeploymentWritten(t)
+	})
+
 }
 
 func TestMakeSingularityURL_valid(t *testing.T) {
@@ -25,8 +25,9 @@ type (
 	// StateDefPutHandler handles PUT /defs.
 	StateDefPutHandler struct {
 		sous.StateManager
-		req  *http.Request
-		user ClientUser
+		deployers *sous.DeployerRegistry
+		req       *http.Request
+		user      ClientUser
 	}
 )
 
@@ -47,6 +48,7 @@ func (sdr *StateDefResource) Get(*restful.RouteMap, logging.LogSink, http.Respon
 func (sdr *StateDefResource) Put(_ *restful.RouteMap, _ logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
 	return &StateDefPutHandler{
 		StateManager: sdr.context.StateManager,
+		deployers:    sdr.context.Deployers,
 		req:          req,
 		user:         sdr.GetUser(req),
 	}
@@ -63,12 +65,26 @@ func (sdp *StateDefPutHandler) Exchange() (interface{}, int) {
 	dec := json.NewDecoder(sdp.req.Body)
 	dec.Decode(&defs)
 
+	flaws := defs.Validate()
+	if sdp.deployers != nil {
+		flaws = append(flaws, sdp.deployers.ValidateDefs(defs)...)
+	}
+	if len(flaws) > 0 {
+		return "Invalid defs: " + sous.FlawMessage{Flaws: flaws}.ReturnFlawMsg(), http.StatusBadRequest
+	}
+
 	state, err := sdp.StateManager.ReadState()
 	if err != nil {
 		msg := "Error loading state from storage"
 		return msg, http.StatusInternalServerError
 	}
 
+	if err := authorize(sdp.req, func(u sous.User) error {
+		return sous.AuthorizeAdmin(state.Defs, u)
+	}); err != nil {
+		return err.Error(), http.StatusForbidden
+	}
+
 	state.Defs = defs
 	err = sdp.StateManager.WriteState(state, sous.User(sdp.user))
 	if err != nil {
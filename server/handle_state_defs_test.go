@@ -1,9 +1,12 @@
 package server
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
 )
 
 func TestStateDefGet(t *testing.T) {
@@ -29,3 +32,18 @@ func TestStateDefGet(t *testing.T) {
 		t.Errorf("returned data wasn't a sous.Defs: %T", defs)
 	}
 }
+
+func TestStateDefPut_UnknownClusterKind(t *testing.T) {
+	req, err := http.NewRequest("PUT", "/defs", strings.NewReader(`{"Clusters":{"c":{"Name":"c","Kind":"no-such-kind"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployers := sous.NewDeployerRegistry(logging.SilentLogSet())
+	deployers.Register(sous.DefaultClusterKind, sous.NewDummyDeployer())
+	th := &StateDefPutHandler{req: req, deployers: deployers}
+
+	_, status := th.Exchange()
+	if status != http.StatusBadRequest {
+		t.Errorf("Status was %v not %v", status, http.StatusBadRequest)
+	}
+}
@@ -32,6 +32,7 @@ type (
 		clusterName string
 		req         *http.Request
 		User        ClientUser
+		StateReader sous.StateReader
 	}
 )
 
@@ -55,6 +56,7 @@ func (res *StateDeploymentResource) Put(_ *restful.RouteMap, _ logging.LogSink,
 		clusterName: res.loc.ResolveFilter.Cluster.ValueOr("no-cluster"),
 		req:         req,
 		User:        res.GetUser(req),
+		StateReader: res.loc.StateManager,
 	}
 }
 
@@ -78,6 +80,12 @@ func (gsd *GETStateDeployments) Exchange() (interface{}, int) {
 
 // Exchange implements Exchanger on PUTStateDeployments
 func (psd *PUTStateDeployments) Exchange() (interface{}, int) {
+	if err := authorize(psd.req, func(u sous.User) error {
+		return authorizeAdmin(psd.StateReader, u)
+	}); err != nil {
+		return err.Error(), http.StatusForbidden
+	}
+
 	data := dto.GDMWrapper{}
 	dec := json.NewDecoder(psd.req.Body)
 	err := dec.Decode(&data)
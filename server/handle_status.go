@@ -19,6 +19,9 @@ type (
 	StatusHandler struct {
 		AutoResolver *sous.AutoResolver
 		*sous.ResolveFilter
+		// Stream asks for the status to be streamed as server-sent events
+		// whenever it changes.
+		Stream bool
 	}
 
 	statusData struct {
@@ -32,19 +35,27 @@ func newStatusResource(ctx ComponentLocator) *StatusResource {
 }
 
 // Get implements Getable on StatusResource.
-func (sr *StatusResource) Get(*restful.RouteMap, logging.LogSink, http.ResponseWriter, *http.Request, httprouter.Params) restful.Exchanger {
+func (sr *StatusResource) Get(_ *restful.RouteMap, _ logging.LogSink, _ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
 	return &StatusHandler{
 		AutoResolver:  sr.context.AutoResolver,
 		ResolveFilter: sr.context.ResolveFilter,
+		Stream:        restful.WantsEventStream(req),
 	}
 }
 
 // Exchange implements the Handler interface.
 func (h *StatusHandler) Exchange() (interface{}, int) {
+	if h.Stream {
+		return &statusStream{handler: h}, http.StatusOK
+	}
+	return h.status(), http.StatusOK
+}
+
+func (h *StatusHandler) status() statusData {
 	status := statusData{}
 	for _, d := range h.AutoResolver.GDM.Filter(h.ResolveFilter.FilterDeployment).Snapshot() {
 		status.Deployments = append(status.Deployments, d)
 	}
 	status.Completed, status.InProgress = h.AutoResolver.Statuses()
-	return status, http.StatusOK
+	return status
 }
@@ -56,3 +56,26 @@ func deploymentIDFromValues(qv restful.QueryValues) (sous.DeploymentID, error) {
 		Cluster:    cluster,
 	}, nil
 }
+
+// deploymentFilterFromValues reads an optional repo, offset, flavor and
+// cluster from qv. Each one that is given restricts the deployments selected
+// to those that match it.
+func deploymentFilterFromValues(qv restful.QueryValues) (*sous.ResolveFilter, error) {
+	filter := &sous.ResolveFilter{}
+	for name, matcher := range map[string]*sous.ResolveFieldMatcher{
+		"repo":    &filter.Repo,
+		"offset":  &filter.Offset,
+		"flavor":  &filter.Flavor,
+		"cluster": &filter.Cluster,
+	} {
+		if _, ok := qv.Values[name]; !ok {
+			continue
+		}
+		value, err := qv.Single(name, "")
+		if err != nil {
+			return nil, err
+		}
+		*matcher = sous.NewResolveFieldMatcher(value)
+	}
+	return filter, nil
+}
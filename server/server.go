@@ -1,10 +1,13 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/opentable/sous/config"
@@ -12,6 +15,7 @@ import (
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
 	"github.com/opentable/sous/util/logging/messages"
+	"github.com/opentable/sous/util/prometheus"
 	"github.com/opentable/sous/util/restful"
 	"github.com/pkg/errors"
 	"github.com/samsalisbury/semv"
@@ -19,6 +23,13 @@ import (
 
 type (
 	userExtractor struct{}
+
+	// A Server serves the Sous HTTP API, over TLS if it is configured to.
+	// Stop it gracefully with Shutdown.
+	Server struct {
+		*http.Server
+		certFile, keyFile string
+	}
 )
 
 type (
@@ -36,6 +47,31 @@ type (
 		*sous.AutoResolver
 		Version  semv.Version
 		QueueSet sous.QueueSet
+		// HistoryReader reads the deployment change history. It is nil if
+		// this server does not record one.
+		HistoryReader sous.HistoryReader
+		// Leadership tracks the clusters this server leads. It is nil if
+		// leaders are not elected.
+		Leadership *sous.Leadership
+		// Authenticator authenticates clients. If it is nil, clients are
+		// trusted to identify themselves, and writes are not authorized.
+		Authenticator Authenticator
+		// DriftDetector reports how running deployments differ from the
+		// intended ones. It is nil if drift is not detected.
+		DriftDetector *sous.DriftDetector
+		// Bouncer replaces the running instances of deployments. It is nil if
+		// deployments cannot be bounced.
+		Bouncer sous.Bouncer
+		// JobRunner runs jobs on demand, and lists their runs. It is nil if
+		// jobs cannot be run.
+		JobRunner sous.JobRunner
+		// Deployers are the Deployers for each kind of cluster; defs naming
+		// clusters of other kinds are refused. It is nil if cluster kinds are
+		// not checked.
+		Deployers *sous.DeployerRegistry
+		// Metrics are served at /metrics. It is nil if metrics are not
+		// served.
+		Metrics *prometheus.Registry
 	}
 )
 
@@ -52,6 +88,9 @@ func (ctx ComponentLocator) liveState() *sous.State {
 }
 
 func (userExtractor) GetUser(req *http.Request) ClientUser {
+	if user, ok := authenticatedUser(req); ok {
+		return user
+	}
 	clu := ClientUser{
 		Name:  req.Header.Get("Sous-User-Name"),
 		Email: req.Header.Get("Sous-User-Email"),
@@ -60,16 +99,87 @@ func (userExtractor) GetUser(req *http.Request) ClientUser {
 	return clu
 }
 
-// Run starts a server up.
-func Run(laddr string, handler http.Handler) error {
-	s := &http.Server{Addr: laddr, Handler: handler}
-	err := s.ListenAndServe()
-	if err == nil {
+// NewServer creates a Server for handler, which will listen on laddr. If c
+// names a TLS certificate and key, it serves HTTPS, and asks clients for
+// certificates issued by c.Auth.ClientCAFile, if that is set.
+// The contexts of event stream requests are cancelled when the Server is shut
+// down, so that streaming responses end rather than holding the shutdown up.
+func NewServer(laddr string, handler http.Handler, c *config.Config) (*Server, error) {
+	shutdown := make(chan struct{})
+	s := &Server{
+		Server: &http.Server{
+			Addr:    laddr,
+			Handler: endingStreams(shutdown, handler),
+		},
+		certFile: c.TLSCertFile,
+		keyFile:  c.TLSKeyFile,
+	}
+	var once sync.Once
+	s.RegisterOnShutdown(func() { once.Do(func() { close(shutdown) }) })
+	if s.certFile == "" {
+		return s, nil
+	}
+	s.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.Auth.ClientCAFile != "" {
+		pool, err := loadCertPool(c.Auth.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		s.TLSConfig.ClientCAs = pool
+		s.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return s, nil
+}
+
+// endingStreams wraps handler so that the contexts of requests for event
+// streams are cancelled when shutdown is closed.
+func endingStreams(shutdown <-chan struct{}, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !restful.WantsEventStream(req) {
+			handler.ServeHTTP(rw, req)
+			return
+		}
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		go func() {
+			select {
+			case <-shutdown:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		handler.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+// Scheme returns "https" if s serves TLS, and "http" otherwise.
+func (s *Server) Scheme() string {
+	if s.certFile != "" {
+		return "https"
+	}
+	return "http"
+}
+
+// Run serves until s is shut down, when it returns nil. If s cannot listen,
+// it tries once more after a pause.
+func (s *Server) Run() error {
+	err := s.listenAndServe()
+	if err == nil || err == http.ErrServerClosed {
 		return nil
 	}
 	pause := 5 * time.Second
 	fmt.Fprintf(os.Stderr, "Error listening: %s; Trying again in %s", err, pause)
 	time.Sleep(pause)
+	if err := s.listenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) listenAndServe() error {
+	if s.certFile != "" {
+		return s.ListenAndServeTLS(s.certFile, s.keyFile)
+	}
 	return s.ListenAndServe()
 }
 
@@ -92,7 +202,10 @@ func mux(sc ComponentLocator, ls logging.LogSink) *http.ServeMux {
 	router := routemap(sc).BuildRouter(ls)
 
 	handler := http.NewServeMux()
-	handler.Handle("/", router)
+	handler.Handle("/", authenticating(sc.Authenticator, router, ls))
+	if sc.Metrics != nil {
+		handler.Handle("/metrics", sc.Metrics)
+	}
 	return handler
 }
 
@@ -110,6 +223,16 @@ func routemap(context ComponentLocator) *restful.RouteMap {
 		re("deploy-queue", "/deploy-queue", newDeployQueueResource(context))
 		re("deploy-queue-item", "/deploy-queue-item", newR11nResource(context))
 		re("single-deployment", "/single-deployment", newSingleDeploymentResource(context))
+		re("history", "/history", newHistoryResource(context))
+		re("plan", "/plan", newPlanResource(context))
+		re("drift", "/drift", newDriftResource(context))
+		re("quota", "/quota", newQuotaResource(context))
+		re("scale", "/scale", newScaleResource(context))
+		re("pause", "/pause", newPauseResource(context, true))
+		re("unpause", "/unpause", newPauseResource(context, false))
+		re("bounce", "/bounce", newBounceResource(context))
+		re("job-run", "/job-run", newJobRunResource(context))
+		re("job-history", "/job-history", newJobHistoryResource(context))
 		re("default", "/", newDefaultResource(context))
 	})
 }
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSignedCert writes a certificate and key for 127.0.0.1 into dir,
+// and returns their paths.
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sous.test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestServer_TLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-server-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeSelfSignedCert(t, dir)
+	addr := freeAddr(t)
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	srv, err := NewServer(addr, handler, &config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	require.NoError(t, err)
+	assert.Equal(t, "https", srv.Scheme())
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Run() }()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	var res *http.Response
+	for i := 0; i < 50; i++ {
+		if res, err = client.Get("https://" + addr + "/health"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.NotNil(t, res.TLS)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	select {
+	case err := <-served:
+		assert.NoError(t, err, "Run should return nil after Shutdown")
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Shutdown")
+	}
+}
+
+func TestNewServer_plain(t *testing.T) {
+	srv, err := NewServer(":0", http.NotFoundHandler(), &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "http", srv.Scheme())
+	assert.Nil(t, srv.TLSConfig)
+}
+
+func TestServer_Shutdown_endsStreams(t *testing.T) {
+	addr := freeAddr(t)
+	streaming := make(chan struct{})
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		rw.(http.Flusher).Flush()
+		close(streaming)
+		<-req.Context().Done()
+	})
+
+	srv, err := NewServer(addr, handler, &config.Config{})
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- srv.Run() }()
+
+	var res *http.Response
+	for i := 0; i < 50; i++ {
+		req, _ := http.NewRequest("GET", "http://"+addr+"/deploy-queue-item", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		if res, err = http.DefaultClient.Do(req); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	defer res.Body.Close()
+	<-streaming
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, srv.Shutdown(ctx), "Shutdown should not wait for the stream")
+	assert.NoError(t, <-served)
+}
+
+func TestHandler_metrics(t *testing.T) {
+	ls, _ := logging.NewLogSinkSpy()
+	metrics := prometheus.NewRegistry()
+	metrics.Register(prometheus.NewCounterVec("test_total", "A counter."))
+
+	rw := httptest.NewRecorder()
+	Handler(ComponentLocator{Metrics: metrics}, http.NotFoundHandler(), ls).ServeHTTP(rw, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), "# TYPE test_total counter")
+
+	rw = httptest.NewRecorder()
+	Handler(ComponentLocator{}, http.NotFoundHandler(), ls).ServeHTTP(rw, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotEqual(t, http.StatusOK, rw.Code)
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// r11nStream streams the progress of a queued rectification.
+	r11nStream struct {
+		queue *sous.R11nQueue
+		id    sous.R11nID
+	}
+
+	// statusStream streams the status of the AutoResolver.
+	statusStream struct {
+		handler *StatusHandler
+	}
+)
+
+// r11nStreamInterval is how often a streamed rectification is checked for
+// changes to its queue position and rollout progress. Its resolution is sent
+// as soon as it is resolved.
+var r11nStreamInterval = 250 * time.Millisecond
+
+// statusStreamInterval is how often a streamed status is checked for changes.
+var statusStreamInterval = time.Second
+
+// Stream implements restful.Streamer on r11nStream. It sends an "r11n" event
+// with a dto.R11nResponse whenever the rectification changes, ending with
+// the one that carries its resolution.
+func (s *r11nStream) Stream(ctx context.Context, es *restful.EventStream) {
+	resolved := make(chan struct{})
+	go func() {
+		s.queue.Wait(s.id)
+		close(resolved)
+	}()
+	streamChanges(ctx, es, "r11n", r11nStreamInterval, resolved, func() (interface{}, bool) {
+		qr, ok := s.queue.ByID(s.id)
+		if !ok {
+			return nil, true
+		}
+		response := newR11nResponse(qr)
+		return response, response.Resolution != nil
+	})
+}
+
+// Stream implements restful.Streamer on statusStream. It sends a "status"
+// event whenever the status changes, until the client goes away.
+func (s *statusStream) Stream(ctx context.Context, es *restful.EventStream) {
+	streamChanges(ctx, es, "status", statusStreamInterval, nil, func() (interface{}, bool) {
+		return s.handler.status(), false
+	})
+}
+
+// streamChanges sends an event named name to es with the value returned by
+// current whenever that changes. It checks every interval, and as soon as
+// wake is closed, until current returns a nil value or reports that its
+// value is final, or ctx is done. Idle streams are kept alive.
+func streamChanges(ctx context.Context, es *restful.EventStream, name string, interval time.Duration, wake <-chan struct{}, current func() (interface{}, bool)) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	keepAlive := time.NewTicker(restful.StreamKeepAlive)
+	defer keepAlive.Stop()
+
+	var last []byte
+	for {
+		v, final := current()
+		if v == nil {
+			return
+		}
+		if b, err := json.Marshal(v); err == nil && !bytes.Equal(b, last) {
+			if err := es.Send(name, v); err != nil {
+				return
+			}
+			last = b
+		}
+		if final {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+			wake = nil
+		case <-tick.C:
+		case <-keepAlive.C:
+			if err := es.KeepAlive(); err != nil {
+				return
+			}
+		}
+	}
+}
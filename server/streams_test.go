@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentable/sous/dto"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestR11nStream(t *testing.T) {
+	release := make(chan struct{})
+	queues := sous.NewR11nQueueSet(sous.R11nQueueStartWithHandler(
+		func(qr *sous.QueuedR11n) sous.DiffResolution {
+			<-release
+			return sous.DiffResolution{
+				DeploymentID: qr.Rectification.Pair.ID(),
+				Desc:         sous.ModifyDiff,
+			}
+		}))
+	first, ok := queues.Push(newR11n("one"))
+	require.True(t, ok)
+	second, ok := queues.Push(newR11n("one"))
+	require.True(t, ok)
+
+	ls := logging.SilentLogSet()
+	srv := httptest.NewServer(routemap(ComponentLocator{QueueSet: queues}).BuildRouter(ls))
+	defer srv.Close()
+	client, err := restful.NewClient(srv.URL, ls)
+	require.NoError(t, err)
+
+	q := newDid("one").QueryMap()
+	q["action"] = string(second.ID)
+	responses := []dto.R11nResponse{}
+	err = client.StreamEvents(context.Background(), "./deploy-queue-item", q, nil, func(ev restful.Event) error {
+		assert.Equal(t, "r11n", ev.Name)
+		response := dto.R11nResponse{}
+		require.NoError(t, json.Unmarshal(ev.Data, &response))
+		responses = append(responses, response)
+		if len(responses) == 1 {
+			close(release)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.True(t, len(responses) >= 2, "want at least 2 responses, got %d", len(responses))
+	assert.Equal(t, 0, responses[0].QueuePosition)
+	assert.Nil(t, responses[0].Resolution)
+	last := responses[len(responses)-1]
+	assert.Equal(t, -1, last.QueuePosition)
+	require.NotNil(t, last.Resolution)
+	assert.Equal(t, sous.ModifyDiff, last.Resolution.Desc)
+
+	_, ok = queues.Wait(newDid("one"), first.ID)
+	assert.True(t, ok)
+}
+
+func TestR11nStream_notFound(t *testing.T) {
+	ls := logging.SilentLogSet()
+	srv := httptest.NewServer(routemap(ComponentLocator{QueueSet: sous.NewR11nQueueSet()}).BuildRouter(ls))
+	defer srv.Close()
+	client, err := restful.NewClient(srv.URL, ls)
+	require.NoError(t, err)
+
+	q := newDid("one").QueryMap()
+	q["action"] = "missing"
+	err = client.StreamEvents(context.Background(), "./deploy-queue-item", q, nil, func(restful.Event) error {
+		t.Error("unexpected event")
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestStatusStream(t *testing.T) {
+	ls := logging.SilentLogSet()
+	c := ComponentLocator{
+		AutoResolver: &sous.AutoResolver{GDM: sous.NewDeployments(), LogSink: ls},
+	}
+	srv := httptest.NewServer(routemap(c).BuildRouter(ls))
+	defer srv.Close()
+	client, err := restful.NewClient(srv.URL, ls)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got statusData
+	client.StreamEvents(ctx, "./status", nil, nil, func(ev restful.Event) error {
+		assert.Equal(t, "status", ev.Name)
+		require.NoError(t, json.Unmarshal(ev.Data, &got))
+		cancel()
+		return nil
+	})
+	assert.Len(t, got.Deployments, 0)
+}
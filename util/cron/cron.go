@@ -0,0 +1,246 @@
+// Package cron parses five-field cron schedules, as accepted by Singularity
+// and Kubernetes, and computes the times they run at.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// A Schedule is a parsed cron schedule.
+	Schedule struct {
+		minute, hour, dom, month, dow bits
+		// domAny and dowAny are true if the day of month or day of week
+		// field is unrestricted. If both are restricted, a day matches if
+		// either does.
+		domAny, dowAny bool
+	}
+
+	// bits is a set of the values of a field.
+	bits uint64
+
+	field struct {
+		name     string
+		min, max int
+		names    map[string]int
+		// day is true for the day fields, in which ? is the same as *.
+		day bool
+	}
+)
+
+// searchYears is how far ahead Next looks for a time a schedule runs at.
+const searchYears = 5
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31, day: true}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Both 0 and 7 are Sunday.
+	dowField = field{name: "day of week", min: 0, max: 7, day: true, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	macros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Parse parses a cron schedule of five fields: minute, hour, day of month,
+// month and day of week. Each field is *, or a comma separated list of values,
+// ranges (a-b) and steps (*/n, a/n or a-b/n). Months and days of the week may
+// be given by their first three letters. ? is the same as * in the day
+// fields. The macros @yearly, @annually, @monthly, @weekly, @daily, @midnight
+// and @hourly are also accepted.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	} else if strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("unknown schedule %q", spec)
+	}
+	fs := strings.Fields(spec)
+	if len(fs) != 5 {
+		return nil, fmt.Errorf("schedule %q has %d fields, not 5 (minute, hour, day of month, month, day of week)", spec, len(fs))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, err = minuteField.parse(fs[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = hourField.parse(fs[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = domField.parse(fs[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = monthField.parse(fs[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = dowField.parse(fs[4]); err != nil {
+		return nil, err
+	}
+	if s.dow.has(7) {
+		s.dow |= 1
+	}
+	s.domAny = domField.isAny(fs[2])
+	s.dowAny = dowField.isAny(fs[4])
+	return s, nil
+}
+
+// isAny returns true if spec is the whole range of f.
+func (f field) isAny(spec string) bool {
+	return spec == "*" || f.day && spec == "?"
+}
+
+// parse returns the set of values of f given by spec.
+func (f field) parse(spec string) (bits, error) {
+	var b bits
+	for _, part := range strings.Split(spec, ",") {
+		pb, err := f.parsePart(part)
+		if err != nil {
+			return 0, fmt.Errorf("%s %q: %s", f.name, spec, err)
+		}
+		b |= pb
+	}
+	return b, nil
+}
+
+func (f field) parsePart(part string) (bits, error) {
+	rng, step := part, 1
+	if i := strings.Index(part, "/"); i >= 0 {
+		var err error
+		rng = part[:i]
+		if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+			return 0, fmt.Errorf("step %q is not a positive number", part[i+1:])
+		}
+	}
+
+	var lo, hi int
+	switch {
+	case f.isAny(rng):
+		lo, hi = f.min, f.max
+	case strings.Contains(rng, "-"):
+		ends := strings.SplitN(rng, "-", 2)
+		var err error
+		if lo, err = f.value(ends[0]); err != nil {
+			return 0, err
+		}
+		if hi, err = f.value(ends[1]); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("range %q is backwards", rng)
+		}
+	default:
+		var err error
+		if lo, err = f.value(rng); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if step > 1 {
+			hi = f.max
+		}
+	}
+
+	var b bits
+	for v := lo; v <= hi; v += step {
+		b |= 1 << uint(v)
+	}
+	return b, nil
+}
+
+// value parses a single value of f.
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%d is not between %d and %d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (b bits) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+// Next returns the first time after t that s runs at, in the location of t.
+// It returns the zero time if s does not run within a few years of t, e.g.
+// because it names a day that does not exist, such as February 30th.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + searchYears
+
+wrap:
+	for t.Year() <= limit {
+		for !s.month.has(int(t.Month())) {
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+		for !s.dayMatches(t) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+		for !s.hour.has(t.Hour()) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+		for !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// advance returns next, or if a daylight saving transition means the local
+// time next was made from does not exist, the first whole hour after prev.
+func advance(prev, next time.Time) time.Time {
+	for !next.After(prev) {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom.has(t.Day())
+	dow := s.dow.has(int(t.Weekday()))
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"? * * * *",
+		"* * * foo *",
+		"@sometimes",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, "%q", spec)
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		tm, err := time.Parse("2006-01-02 15:04 Mon", s)
+		require.NoError(t, err)
+		return tm
+	}
+	// 2018-01-01 was a Monday.
+	start := at("2018-01-01 10:30 Mon")
+
+	for spec, want := range map[string][]string{
+		"*/20 * * * *":           {"2018-01-01 10:40 Mon", "2018-01-01 11:00 Mon", "2018-01-01 11:20 Mon"},
+		"0 9-17/4 * * *":         {"2018-01-01 13:00 Mon", "2018-01-01 17:00 Mon", "2018-01-02 09:00 Tue"},
+		"15 2 * * sat,SUN":       {"2018-01-06 02:15 Sat", "2018-01-07 02:15 Sun", "2018-01-13 02:15 Sat"},
+		"0 0 * * 7":              {"2018-01-07 00:00 Sun", "2018-01-14 00:00 Sun", "2018-01-21 00:00 Sun"},
+		"0 0 31 * ?":             {"2018-01-31 00:00 Wed", "2018-03-31 00:00 Sat", "2018-05-31 00:00 Thu"},
+		"0 0 13 * 5":             {"2018-01-05 00:00 Fri", "2018-01-12 00:00 Fri", "2018-01-13 00:00 Sat"},
+		"0 12 29 feb *":          {"2020-02-29 12:00 Sat", "2024-02-29 12:00 Thu"},
+		"@monthly":               {"2018-02-01 00:00 Thu", "2018-03-01 00:00 Thu"},
+		"30 10 1 1 *":            {"2019-01-01 10:30 Tue"},
+		"0,30 10-11 * jan-mar *": {"2018-01-01 11:00 Mon", "2018-01-01 11:30 Mon", "2018-01-02 10:00 Tue"},
+	} {
+		s, err := Parse(spec)
+		require.NoError(t, err, "%q", spec)
+		next := start
+		for _, w := range want {
+			next = s.Next(next)
+			assert.Equal(t, at(w), next, "%q", spec)
+		}
+	}
+
+	never, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(start).IsZero())
+}
+
+func TestSchedule_Next_location(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	s, err := Parse("0 9 * * *")
+	require.NoError(t, err)
+
+	next := s.Next(time.Date(2018, 3, 10, 12, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2018, 3, 11, 9, 0, 0, 0, loc), next)
+	assert.Equal(t, "2018-03-11T16:00:00Z", next.UTC().Format(time.RFC3339))
+}
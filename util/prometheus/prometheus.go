@@ -0,0 +1,308 @@
+// Package prometheus exposes labelled metrics in the Prometheus text
+// exposition format.
+//
+// Metrics are registered with a Registry, which serves them over HTTP. Counters, gauges and histograms are kept per set of
+// label values; metrics that are cheaper to compute when scraped can be
+// provided by any Collector.
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// A Family is a named set of samples of one type of metric.
+	Family struct {
+		Name, Help string
+		// Type is "counter", "gauge" or "histogram".
+		Type    string
+		Samples []Sample
+	}
+
+	// A Sample is a single value of a metric family.
+	Sample struct {
+		// Suffix is appended to the family name, e.g. "_bucket".
+		Suffix string
+		Labels []Label
+		Value  float64
+	}
+
+	// A Label is the name and value of a label of a sample.
+	Label struct {
+		Name, Value string
+	}
+
+	// A Collector provides a metric family whenever metrics are scraped.
+	Collector interface {
+		Collect() Family
+	}
+
+	// A CollectorFunc is a function that implements Collector.
+	CollectorFunc func() Family
+
+	// A Registry serves the metric families of its collectors, in the text
+	// exposition format. It implements http.Handler.
+	Registry struct {
+		sync.Mutex
+		collectors map[string]Collector
+	}
+
+	// vec holds the values of a metric for each set of label values.
+	vec struct {
+		name, help string
+		labels     []string
+		sync.Mutex
+		values map[string]*value
+	}
+
+	value struct {
+		labels  []Label
+		v       float64
+		buckets []uint64
+		count   uint64
+	}
+
+	// A CounterVec is a counter for each set of label values.
+	CounterVec struct{ vec }
+
+	// A GaugeVec is a gauge for each set of label values.
+	GaugeVec struct{ vec }
+
+	// A HistogramVec is a histogram for each set of label values.
+	HistogramVec struct {
+		vec
+		buckets []float64
+	}
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are histogram buckets suitable for durations in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: map[string]Collector{}}
+}
+
+// Collect implements Collector on CollectorFunc.
+func (f CollectorFunc) Collect() Family {
+	return f()
+}
+
+// Register adds c to r, replacing any collector already registered for the
+// same family name.
+func (r *Registry) Register(c Collector) {
+	r.Lock()
+	defer r.Unlock()
+	r.collectors[c.Collect().Name] = c
+}
+
+// Gather returns the families of every collector in r, sorted by name.
+func (r *Registry) Gather() []Family {
+	r.Lock()
+	cs := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		cs = append(cs, c)
+	}
+	r.Unlock()
+	fs := make([]Family, 0, len(cs))
+	for _, c := range cs {
+		fs = append(fs, c.Collect())
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name })
+	return fs
+}
+
+// ServeHTTP implements http.Handler on Registry.
+func (r *Registry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", ContentType)
+	w := bufio.NewWriter(rw)
+	for _, f := range r.Gather() {
+		f.write(w)
+	}
+	w.Flush()
+}
+
+func (f Family) write(w *bufio.Writer) {
+	if f.Help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.Name, helpEscaper.Replace(f.Help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.Name, f.Type)
+	for _, s := range f.Samples {
+		w.WriteString(f.Name + s.Suffix)
+		if len(s.Labels) > 0 {
+			w.WriteByte('{')
+			for i, l := range s.Labels {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				fmt.Fprintf(w, `%s="%s"`, l.Name, labelEscaper.Replace(l.Value))
+			}
+			w.WriteByte('}')
+		}
+		w.WriteByte(' ')
+		w.WriteString(formatValue(s.Value))
+		w.WriteByte('\n')
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func newVec(name, help string, labels []string) vec {
+	return vec{name: name, help: help, labels: labels, values: map[string]*value{}}
+}
+
+// with returns the value for labelValues, creating it if need be. It assumes
+// v is locked.
+func (v *vec) with(labelValues []string, buckets int) *value {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("%s has labels %v, got values %v", v.name, v.labels, labelValues))
+	}
+	key := strings.Join(labelValues, "\xff")
+	val, ok := v.values[key]
+	if !ok {
+		val = &value{buckets: make([]uint64, buckets)}
+		for i, l := range v.labels {
+			val.labels = append(val.labels, Label{Name: l, Value: labelValues[i]})
+		}
+		v.values[key] = val
+	}
+	return val
+}
+
+// sorted returns the values of v, ordered by their label values. It assumes
+// v is locked.
+func (v *vec) sorted() []*value {
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	vals := make([]*value, len(keys))
+	for i, k := range keys {
+		vals[i] = v.values[k]
+	}
+	return vals
+}
+
+func (v *vec) simpleFamily(typ string) Family {
+	v.Lock()
+	defer v.Unlock()
+	f := Family{Name: v.name, Help: v.help, Type: typ}
+	for _, val := range v.sorted() {
+		f.Samples = append(f.Samples, Sample{Labels: val.labels, Value: val.v})
+	}
+	return f
+}
+
+// NewCounterVec returns a CounterVec with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{newVec(name, help, labels)}
+}
+
+// Add adds n to the counter for labelValues, which must be given in the
+// order of the CounterVec's labels.
+func (c *CounterVec) Add(n float64, labelValues ...string) {
+	c.Lock()
+	defer c.Unlock()
+	c.with(labelValues, 0).v += n
+}
+
+// Inc adds 1 to the counter for labelValues.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Collect implements Collector on CounterVec.
+func (c *CounterVec) Collect() Family {
+	return c.simpleFamily("counter")
+}
+
+// NewGaugeVec returns a GaugeVec with the given label names.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{newVec(name, help, labels)}
+}
+
+// Set sets the gauge for labelValues to n.
+func (g *GaugeVec) Set(n float64, labelValues ...string) {
+	g.Lock()
+	defer g.Unlock()
+	g.with(labelValues, 0).v = n
+}
+
+// Collect implements Collector on GaugeVec.
+func (g *GaugeVec) Collect() Family {
+	return g.simpleFamily("gauge")
+}
+
+// NewHistogramVec returns a HistogramVec with the given upper bounds of its
+// buckets, in increasing order, and label names. If buckets is nil,
+// DefaultBuckets are used.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	return &HistogramVec{vec: newVec(name, help, labels), buckets: buckets}
+}
+
+// Observe records n in the histogram for labelValues.
+func (h *HistogramVec) Observe(n float64, labelValues ...string) {
+	h.Lock()
+	defer h.Unlock()
+	val := h.with(labelValues, len(h.buckets))
+	for i, b := range h.buckets {
+		if n <= b {
+			val.buckets[i]++
+		}
+	}
+	val.v += n
+	val.count++
+}
+
+// Collect implements Collector on HistogramVec.
+func (h *HistogramVec) Collect() Family {
+	h.Lock()
+	defer h.Unlock()
+	f := Family{Name: h.name, Help: h.help, Type: "histogram"}
+	for _, val := range h.sorted() {
+		for i, b := range h.buckets {
+			f.Samples = append(f.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: append(append([]Label{}, val.labels...), Label{Name: "le", Value: formatValue(b)}),
+				Value:  float64(val.buckets[i]),
+			})
+		}
+		f.Samples = append(f.Samples,
+			Sample{
+				Suffix: "_bucket",
+				Labels: append(append([]Label{}, val.labels...), Label{Name: "le", Value: "+Inf"}),
+				Value:  float64(val.count),
+			},
+			Sample{Suffix: "_sum", Labels: val.labels, Value: val.v},
+			Sample{Suffix: "_count", Labels: val.labels, Value: float64(val.count)},
+		)
+	}
+	return f
+}
@@ -0,0 +1,59 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	c := NewCounterVec("test_total", "A counter.", "kind")
+	c.Inc("b")
+	c.Add(2, `a"quoted"`)
+	h := NewHistogramVec("test_seconds", "A histogram.\nTwo lines.", []float64{1, 5}, "phase")
+	h.Observe(0.5, "one")
+	h.Observe(3, "one")
+	g := NewGaugeVec("test_depth", "")
+	g.Set(7)
+	r.Register(c)
+	r.Register(h)
+	r.Register(g)
+
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, ContentType, rw.Header().Get("Content-Type"))
+	assert.Equal(t, `# TYPE test_depth gauge
+test_depth 7
+# HELP test_seconds A histogram.\nTwo lines.
+# TYPE test_seconds histogram
+test_seconds_bucket{phase="one",le="1"} 1
+test_seconds_bucket{phase="one",le="5"} 2
+test_seconds_bucket{phase="one",le="+Inf"} 2
+test_seconds_sum{phase="one"} 3.5
+test_seconds_count{phase="one"} 2
+# HELP test_total A counter.
+# TYPE test_total counter
+test_total{kind="a\"quoted\""} 2
+test_total{kind="b"} 1
+`, rw.Body.String())
+}
+
+func TestRegistry_Register_replaces(t *testing.T) {
+	r := NewRegistry()
+	r.Register(CollectorFunc(func() Family { return Family{Name: "x", Type: "gauge"} }))
+	r.Register(CollectorFunc(func() Family {
+		return Family{Name: "x", Type: "gauge", Samples: []Sample{{Value: 1}}}
+	}))
+	fs := r.Gather()
+	if assert.Len(t, fs, 1) {
+		assert.Len(t, fs[0].Samples, 1)
+	}
+}
+
+func TestVec_wrongLabels(t *testing.T) {
+	c := NewCounterVec("test_total", "", "kind")
+	assert.Panics(t, func() { c.Inc() })
+}
@@ -0,0 +1,173 @@
+package restful
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// An Event is a server-sent event.
+	Event struct {
+		// Name is the type of the event, or "" for the default, "message".
+		Name string
+		Data []byte
+	}
+
+	// An EventStream writes server-sent events to a client.
+	EventStream struct {
+		w       io.Writer
+		flusher http.Flusher
+	}
+
+	// A Streamer is response data that is streamed to the client as
+	// server-sent events, rather than rendered as JSON. Exchangers return a
+	// Streamer from GET requests that ask for an event stream.
+	Streamer interface {
+		// Stream sends events to es until it is finished, or ctx is done.
+		Stream(ctx context.Context, es *EventStream)
+	}
+
+	// EventStreamer is implemented by HTTPClients that can consume
+	// server-sent events.
+	EventStreamer interface {
+		// StreamEvents makes a GET request on urlPath asking for an event
+		// stream, and calls handle with each event received, until the
+		// stream ends, ctx is done, or handle returns an error. It returns
+		// ErrNoEventStream if the server responds with something other than
+		// an event stream.
+		StreamEvents(ctx context.Context, urlPath string, qParms map[string]string, headers map[string]string, handle func(Event) error) error
+	}
+)
+
+// EventStreamContentType is the content type of server-sent events.
+const EventStreamContentType = "text/event-stream"
+
+// StreamKeepAlive is how often an otherwise idle EventStream should send a
+// comment, to keep proxies from closing the connection.
+const StreamKeepAlive = 15 * time.Second
+
+// ErrNoEventStream is returned by StreamEvents when the server does not
+// respond with an event stream, so that clients can fall back to polling.
+var ErrNoEventStream = errors.New("server did not respond with an event stream")
+
+// WantsEventStream returns true if req asks for server-sent events.
+func WantsEventStream(req *http.Request) bool {
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		if mt, _, err := mime.ParseMediaType(accept); err == nil && mt == EventStreamContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Send sends an event named name, with data encoded as JSON.
+func (es *EventStream) Send(name string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrapf(err, "encoding %s event", name)
+	}
+	buf := &bytes.Buffer{}
+	if name != "" {
+		fmt.Fprintf(buf, "event: %s\n", name)
+	}
+	fmt.Fprintf(buf, "data: %s\n\n", b)
+	return es.write(buf.Bytes())
+}
+
+// KeepAlive sends a comment, which clients ignore.
+func (es *EventStream) KeepAlive() error {
+	return es.write([]byte(":\n\n"))
+}
+
+func (es *EventStream) write(b []byte) error {
+	if _, err := es.w.Write(b); err != nil {
+		return err
+	}
+	es.flusher.Flush()
+	return nil
+}
+
+// streamData streams s to w as server-sent events.
+func (mh *MetaHandler) streamData(w *loggingResponseWriter, r *http.Request, s Streamer) {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		mh.writeHeaders(http.StatusNotAcceptable, w, r, "Streaming is not supported.")
+		return
+	}
+	w.Header().Set(contentTypeHeader, EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	s.Stream(r.Context(), &EventStream{w: w, flusher: flusher})
+	w.sendLog()
+}
+
+// ReadEvents reads server-sent events from r, calling handle with each, until
+// r is exhausted or handle returns an error.
+func ReadEvents(r io.Reader, handle func(Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	ev := Event{}
+	var data [][]byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data != nil {
+				ev.Data = bytes.Join(data, []byte("\n"))
+				if err := handle(ev); err != nil {
+					return err
+				}
+			}
+			ev, data = Event{}, nil
+		case strings.HasPrefix(line, ":"):
+		default:
+			field, value := line, ""
+			if i := strings.Index(line, ":"); i >= 0 {
+				field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+			}
+			switch field {
+			case "event":
+				ev.Name = value
+			case "data":
+				data = append(data, []byte(value))
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// StreamEvents implements EventStreamer on LiveHTTPClient.
+func (client *LiveHTTPClient) StreamEvents(ctx context.Context, urlPath string, qParms map[string]string, headers map[string]string, handle func(Event) error) error {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headers["Accept"] = EventStreamContentType
+	url, err := client.buildURL(urlPath, qParms)
+	rq, err := client.buildRequest("GET", url, headers, nil, nil, err)
+	if err != nil {
+		return err
+	}
+	rz, err := client.Client.Do(rq.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "GET %s", url)
+	}
+	defer rz.Body.Close()
+	if rz.StatusCode < 200 || rz.StatusCode >= 300 {
+		return errors.Errorf("GET %s: %s", url, rz.Status)
+	}
+	if mt, _, _ := mime.ParseMediaType(rz.Header.Get("Content-Type")); mt != EventStreamContentType {
+		return ErrNoEventStream
+	}
+	return ReadEvents(rz.Body, handle)
+}
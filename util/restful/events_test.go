@@ -0,0 +1,64 @@
+package restful
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEvents(t *testing.T) {
+	stream := "event: one\ndata: {\"a\":1}\n\n" +
+		":keep-alive\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		"event: ignored-without-data\n\n" +
+		"event: three\ndata:x\n\n"
+
+	got := []Event{}
+	require.NoError(t, ReadEvents(strings.NewReader(stream), func(ev Event) error {
+		got = append(got, ev)
+		return nil
+	}))
+
+	assert.Equal(t, []Event{
+		{Name: "one", Data: []byte(`{"a":1}`)},
+		{Data: []byte("line one\nline two")},
+		{Name: "three", Data: []byte("x")},
+	}, got)
+}
+
+func TestWantsEventStream(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.False(t, WantsEventStream(req))
+	req.Header.Set("Accept", "application/json, text/event-stream; q=0.9")
+	assert.True(t, WantsEventStream(req))
+}
+
+func TestStreamEvents_noEventStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, logging.SilentLogSet())
+	require.NoError(t, err)
+	err = client.StreamEvents(context.Background(), "/", nil, nil, func(Event) error { return nil })
+	assert.Equal(t, ErrNoEventStream, err)
+}
+
+func TestEventStream_Send(t *testing.T) {
+	rw := httptest.NewRecorder()
+	es := &EventStream{w: rw, flusher: rw}
+
+	require.NoError(t, es.Send("thing", map[string]int{"n": 1}))
+	require.NoError(t, es.KeepAlive())
+
+	assert.Equal(t, "event: thing\ndata: {\"n\":1}\n\n:\n\n", rw.Body.String())
+	assert.True(t, rw.Flushed)
+}
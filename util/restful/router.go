@@ -141,6 +141,10 @@ func (mh *MetaHandler) GetHandling(resName string, factory ExchangeFactory) http
 	return func(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		lrw, data, status := mh.genericHandling(resName, factory, rw, r, p)
 		lrw.Header().Add("Access-Control-Allow-Origin", "*") //XXX configurable by app
+		if s, is := data.(Streamer); is && status < 300 {
+			mh.streamData(lrw, r, s)
+			return
+		}
 		mh.renderData(status, lrw, r, data)
 	}
 }